@@ -0,0 +1,124 @@
+// Package auth verifies JWT bearer tokens
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Roles a token may grant
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// leeway tolerates clock skew between the token issuer and this server
+const leeway = 30 * time.Second
+
+var (
+	ErrMalformed = errors.New("token is not a well-formed JWT")
+	ErrAlgorithm = errors.New("token must be signed with HS256")
+	ErrSignature = errors.New("token signature does not match")
+	ErrExpired   = errors.New("token has expired or carries no expiry")
+	ErrNotYet    = errors.New("token is not valid yet")
+	ErrIssuer    = errors.New("token was issued by someone else")
+	ErrNoKey     = errors.New("no signing key is configured")
+)
+
+// Claims are the registered and role claims a token carries. A role may be given as
+// "role" or as a list under "roles".
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Role      string   `json:"role,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+// HasRole reports whether the token grants role. Admins may do everything users can.
+func (c Claims) HasRole(role string) bool {
+	granted := func(r string) bool { return c.Role == r || slices.Contains(c.Roles, r) }
+	return granted(role) || (role == RoleUser && granted(RoleAdmin))
+}
+
+// Verifier checks HS256 tokens against a shared signing key
+type Verifier struct {
+	// Key returns the current signing key, so a rotated key applies to the next token
+	Key func() []byte
+
+	// Issuer, when set, must match the token's iss claim
+	Issuer string
+}
+
+// Verify checks token's signature and validity period and returns its claims.
+// Tokens must expire.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	var claims Claims
+	key := v.Key()
+	if len(key) == 0 {
+		return claims, ErrNoKey
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, ErrMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return claims, err
+	}
+	if header.Alg != "HS256" {
+		return claims, ErrAlgorithm
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, ErrMalformed
+	}
+	if !hmac.Equal(signature, sign(key, parts[0]+"."+parts[1])) {
+		return claims, ErrSignature
+	}
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return claims, err
+	}
+	now := time.Now()
+	switch {
+	case claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(leeway)):
+		return claims, ErrExpired
+	case claims.NotBefore != 0 && now.Add(leeway).Before(time.Unix(claims.NotBefore, 0)):
+		return claims, ErrNotYet
+	case v.Issuer != "" && claims.Issuer != v.Issuer:
+		return claims, ErrIssuer
+	}
+	return claims, nil
+}
+
+// sign returns the HS256 signature of a token's signing input under key
+func sign(key []byte, input string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+// decodeSegment decodes one base64url JSON segment of a token into v
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"leaderboard-api/models"
+	"strings"
+	"time"
+)
+
+// PDF renders one plain A4 page per certificate, with the audit reference needed to
+// verify it. Text is set in the standard Helvetica font, so characters outside
+// Latin-1 print as "?"; register a richer renderer for branded output.
+type PDF struct{}
+
+func (PDF) ContentType() string { return "application/pdf" }
+
+func (PDF) Render(w io.Writer, certs []models.Certificate) error {
+	doc := &pdfWriter{}
+	doc.header()
+
+	// Objects 1-3 are the catalog, the page tree and the font; each page then takes
+	// a page object and a content stream
+	pageIDs := make([]string, len(certs))
+	for i := range certs {
+		pageIDs[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	doc.object("<< /Type /Catalog /Pages 2 0 R >>")
+	doc.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageIDs, " "), len(certs)))
+	doc.object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	for i, cert := range certs {
+		content := pageContent(cert)
+		doc.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i))
+		doc.object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+	doc.trailer()
+
+	_, err := w.Write(doc.buf.Bytes())
+	return err
+}
+
+// pageContent lays out one certificate as lines of text
+func pageContent(cert models.Certificate) string {
+	name := cert.Username
+	if cert.DisplayName != "" {
+		name = fmt.Sprintf("%s (%s)", cert.DisplayName, cert.Username)
+	}
+	lines := []struct {
+		size int
+		text string
+	}{
+		{22, "Certificate of Standing"},
+		{12, ""},
+		{14, name},
+		{12, fmt.Sprintf("Rank %d of %d on %s", cert.Rank, cert.TotalUsers, cert.Board)},
+		{12, fmt.Sprintf("Rating %s (top %.2f%%)", cert.Display, cert.Percentile)},
+		{12, "As of " + cert.AsOf.UTC().Format(time.RFC1123)},
+		{12, ""},
+		{9, "Digest " + cert.Digest},
+		{9, fmt.Sprintf("Audit entry %d, hash %s", cert.AuditSeq, cert.AuditHash)},
+		{9, "Verify at POST /api/certificates/verify with the JSON form of this certificate"},
+	}
+
+	var content strings.Builder
+	content.WriteString("BT\n72 760 Td\n")
+	for _, line := range lines {
+		fmt.Fprintf(&content, "/F1 %d Tf\n(%s) Tj\n0 -%d Td\n", line.size, pdfText(line.text), line.size*2)
+	}
+	content.WriteString("ET")
+	return content.String()
+}
+
+// pdfText escapes a string for a PDF literal, in WinAnsi (Latin-1) bytes
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// pdfWriter assembles numbered objects and the cross-reference table pointing at them
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (d *pdfWriter) header() {
+	d.buf.WriteString("%PDF-1.4\n")
+}
+
+func (d *pdfWriter) object(body string) {
+	d.offsets = append(d.offsets, d.buf.Len())
+	fmt.Fprintf(&d.buf, "%d 0 obj\n%s\nendobj\n", len(d.offsets), body)
+}
+
+func (d *pdfWriter) trailer() {
+	xref := d.buf.Len()
+	fmt.Fprintf(&d.buf, "xref\n0 %d\n0000000000 65535 f \n", len(d.offsets)+1)
+	for _, offset := range d.offsets {
+		fmt.Fprintf(&d.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&d.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.offsets)+1, xref)
+}
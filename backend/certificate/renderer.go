@@ -0,0 +1,61 @@
+package certificate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"leaderboard-api/models"
+	"sort"
+	"sync"
+)
+
+// Renderer writes a batch of certificates in one output format
+type Renderer interface {
+	ContentType() string
+	Render(w io.Writer, certs []models.Certificate) error
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{
+		"json": JSON{},
+		"pdf":  PDF{},
+	}
+)
+
+// Register makes a renderer available under format, replacing any registered before,
+// so deployments can plug in branded or signed layouts
+func Register(format string, renderer Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[format] = renderer
+}
+
+// Lookup returns the renderer registered for format
+func Lookup(format string) (Renderer, error) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	if renderer, ok := renderers[format]; ok {
+		return renderer, nil
+	}
+	formats := make([]string, 0, len(renderers))
+	for name := range renderers {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return nil, fmt.Errorf("unknown certificate format %q (want one of %v)", format, formats)
+}
+
+// JSON renders certificates as a JSON object holding them, ready to be handed back for
+// verification
+type JSON struct{}
+
+func (JSON) ContentType() string { return "application/json" }
+
+func (JSON) Render(w io.Writer, certs []models.Certificate) error {
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"certificates": certs,
+		"count":        len(certs),
+	})
+}
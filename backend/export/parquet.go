@@ -0,0 +1,246 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// The subset of the Parquet format the writer uses. Every column is REQUIRED and
+// PLAIN-encoded in a single uncompressed data page, all in one row group, which every
+// Parquet reader accepts. Field IDs and enum values are those of parquet.thrift.
+const (
+	parquetMagic = "PAR1"
+
+	parquetInt64     = 2 // Type
+	parquetByteArray = 6
+
+	parquetRequired = 0 // FieldRepetitionType
+
+	parquetUTF8            = 0 // ConvertedType
+	parquetTimestampMillis = 9
+
+	parquetPlain = 0 // Encoding
+	parquetRLE   = 3
+
+	parquetUncompressed = 0 // CompressionCodec
+	parquetDataPage     = 0 // PageType
+)
+
+// WriteParquet writes the table as a Parquet file
+func (t *Table) WriteParquet(w io.Writer) error {
+	// Column chunks record their own offsets, so the file is assembled in memory
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	chunks := make([]*thriftStruct, 0, len(t.columns))
+	var rowGroupBytes int64
+	for i, column := range t.columns {
+		data := t.plainValues(i)
+
+		header := newThriftStruct()
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(len(data)))
+		header.i32(3, int32(len(data)))
+		page := newThriftStruct()
+		page.i32(1, int32(t.rows))
+		page.i32(2, parquetPlain)
+		page.i32(3, parquetRLE)
+		page.i32(4, parquetRLE)
+		header.structField(5, page)
+		encodedHeader := header.bytes()
+
+		offset := int64(file.Len())
+		file.Write(encodedHeader)
+		file.Write(data)
+		size := int64(len(encodedHeader) + len(data))
+		rowGroupBytes += size
+
+		meta := newThriftStruct()
+		meta.i32(1, parquetType(column.Kind))
+		meta.i32List(2, parquetPlain, parquetRLE)
+		meta.stringList(3, column.Name)
+		meta.i32(4, parquetUncompressed)
+		meta.i64(5, int64(t.rows))
+		meta.i64(6, size)
+		meta.i64(7, size)
+		meta.i64(9, offset)
+
+		chunk := newThriftStruct()
+		chunk.i64(2, offset)
+		chunk.structField(3, meta)
+		chunks = append(chunks, chunk)
+	}
+
+	schema := make([]*thriftStruct, 0, len(t.columns)+1)
+	root := newThriftStruct()
+	root.binary(4, "schema")
+	root.i32(5, int32(len(t.columns)))
+	schema = append(schema, root)
+	for _, column := range t.columns {
+		element := newThriftStruct()
+		element.i32(1, parquetType(column.Kind))
+		element.i32(3, parquetRequired)
+		element.binary(4, column.Name)
+		switch column.Kind {
+		case String:
+			element.i32(6, parquetUTF8)
+		case Timestamp:
+			element.i32(6, parquetTimestampMillis)
+		}
+		schema = append(schema, element)
+	}
+
+	rowGroup := newThriftStruct()
+	rowGroup.structList(1, chunks)
+	rowGroup.i64(2, rowGroupBytes)
+	rowGroup.i64(3, int64(t.rows))
+
+	footer := newThriftStruct()
+	footer.i32(1, 1)
+	footer.structList(2, schema)
+	footer.i64(3, int64(t.rows))
+	footer.structList(4, []*thriftStruct{rowGroup})
+	footer.binary(6, "leaderboard-api")
+	encodedFooter := footer.bytes()
+
+	file.Write(encodedFooter)
+	binary.Write(&file, binary.LittleEndian, uint32(len(encodedFooter)))
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// parquetType maps a column kind to its Parquet physical type
+func parquetType(kind Kind) int32 {
+	if kind == String {
+		return parquetByteArray
+	}
+	return parquetInt64
+}
+
+// plainValues PLAIN-encodes a column: little-endian integers, or length-prefixed bytes
+func (t *Table) plainValues(column int) []byte {
+	var data bytes.Buffer
+	if t.columns[column].Kind == String {
+		for _, s := range t.strings[column] {
+			binary.Write(&data, binary.LittleEndian, uint32(len(s)))
+			data.WriteString(s)
+		}
+		return data.Bytes()
+	}
+	for _, n := range t.ints[column] {
+		binary.Write(&data, binary.LittleEndian, n)
+	}
+	return data.Bytes()
+}
+
+// Thrift compact protocol type codes
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// thriftStruct encodes one struct in the Thrift compact protocol, which Parquet uses
+// for its page headers and footer. Fields must be added in increasing ID order.
+type thriftStruct struct {
+	buf     bytes.Buffer
+	lastID  int16
+	written bool
+}
+
+func newThriftStruct() *thriftStruct {
+	return &thriftStruct{}
+}
+
+// field writes a field header, using the short delta form where it fits
+func (s *thriftStruct) field(id int16, typ byte) {
+	if delta := id - s.lastID; delta > 0 && delta <= 15 {
+		s.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		s.buf.WriteByte(typ)
+		s.varint(zigzag(int64(id)))
+	}
+	s.lastID = id
+}
+
+func (s *thriftStruct) i32(id int16, v int32) {
+	s.field(id, compactI32)
+	s.varint(zigzag(int64(v)))
+}
+
+func (s *thriftStruct) i64(id int16, v int64) {
+	s.field(id, compactI64)
+	s.varint(zigzag(v))
+}
+
+func (s *thriftStruct) binary(id int16, v string) {
+	s.field(id, compactBinary)
+	s.writeBinary(v)
+}
+
+func (s *thriftStruct) structField(id int16, v *thriftStruct) {
+	s.field(id, compactStruct)
+	s.buf.Write(v.bytes())
+}
+
+func (s *thriftStruct) i32List(id int16, values ...int32) {
+	s.field(id, compactList)
+	s.listHeader(len(values), compactI32)
+	for _, v := range values {
+		s.varint(zigzag(int64(v)))
+	}
+}
+
+func (s *thriftStruct) stringList(id int16, values ...string) {
+	s.field(id, compactList)
+	s.listHeader(len(values), compactBinary)
+	for _, v := range values {
+		s.writeBinary(v)
+	}
+}
+
+func (s *thriftStruct) structList(id int16, values []*thriftStruct) {
+	s.field(id, compactList)
+	s.listHeader(len(values), compactStruct)
+	for _, v := range values {
+		s.buf.Write(v.bytes())
+	}
+}
+
+func (s *thriftStruct) listHeader(size int, elem byte) {
+	if size < 15 {
+		s.buf.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+	s.buf.WriteByte(0xf0 | elem)
+	s.varint(uint64(size))
+}
+
+func (s *thriftStruct) writeBinary(v string) {
+	s.varint(uint64(len(v)))
+	s.buf.WriteString(v)
+}
+
+func (s *thriftStruct) varint(v uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	s.buf.Write(scratch[:binary.PutUvarint(scratch[:], v)])
+}
+
+// bytes returns the encoded struct, terminated by a stop field
+func (s *thriftStruct) bytes() []byte {
+	if !s.written {
+		s.buf.WriteByte(0)
+		s.written = true
+	}
+	return s.buf.Bytes()
+}
+
+// zigzag maps signed integers to unsigned so small magnitudes encode short
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
@@ -0,0 +1,172 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Formats an export can be written in
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Kind is the type of a column's values
+type Kind int
+
+const (
+	Int64     Kind = iota // 64-bit integers
+	String                // UTF-8 text
+	Timestamp             // instants, stored with millisecond precision
+)
+
+// Column names and types one column of a table
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Table is a typed, column-oriented dataset built row by row for export
+type Table struct {
+	columns []Column
+	ints    [][]int64  // per column; used by Int64 and Timestamp (Unix milliseconds)
+	strings [][]string // per column; used by String
+	rows    int
+}
+
+// NewTable creates an empty table with the given columns
+func NewTable(columns ...Column) *Table {
+	return &Table{
+		columns: columns,
+		ints:    make([][]int64, len(columns)),
+		strings: make([][]string, len(columns)),
+	}
+}
+
+// Append adds a row. Values must match the columns in order: int64 for Int64,
+// string for String and time.Time for Timestamp.
+func (t *Table) Append(values ...interface{}) {
+	if len(values) != len(t.columns) {
+		panic(fmt.Sprintf("export: row has %d values for %d columns", len(values), len(t.columns)))
+	}
+	for i, value := range values {
+		switch v := value.(type) {
+		case int64:
+			if t.columns[i].Kind != Int64 {
+				panic("export: int64 value for column " + t.columns[i].Name)
+			}
+			t.ints[i] = append(t.ints[i], v)
+		case string:
+			if t.columns[i].Kind != String {
+				panic("export: string value for column " + t.columns[i].Name)
+			}
+			t.strings[i] = append(t.strings[i], v)
+		case time.Time:
+			if t.columns[i].Kind != Timestamp {
+				panic("export: time value for column " + t.columns[i].Name)
+			}
+			t.ints[i] = append(t.ints[i], v.UnixMilli())
+		default:
+			panic(fmt.Sprintf("export: unsupported value %T for column %s", value, t.columns[i].Name))
+		}
+	}
+	t.rows++
+}
+
+// Rows returns the number of rows
+func (t *Table) Rows() int {
+	return t.rows
+}
+
+// text returns the cell at row, column as it is rendered in text formats
+func (t *Table) text(row, column int) string {
+	switch t.columns[column].Kind {
+	case Int64:
+		return strconv.FormatInt(t.ints[column][row], 10)
+	case Timestamp:
+		return time.UnixMilli(t.ints[column][row]).UTC().Format(time.RFC3339Nano)
+	default:
+		return t.strings[column][row]
+	}
+}
+
+// Write writes the table to w in format
+func (t *Table) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		return t.WriteCSV(w)
+	case FormatParquet:
+		return t.WriteParquet(w)
+	case FormatJSON, "":
+		return t.WriteJSON(w)
+	}
+	return fmt.Errorf("unknown export format %q (json, csv or parquet)", format)
+}
+
+// ContentType returns the media type of format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	}
+	return "application/json"
+}
+
+// WriteCSV writes a header row followed by every row
+func (t *Table) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	record := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		record[i] = column.Name
+	}
+	if err := out.Write(record); err != nil {
+		return err
+	}
+	for row := 0; row < t.rows; row++ {
+		for i := range t.columns {
+			record[i] = t.text(row, i)
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteJSON writes the rows as a JSON array of objects keyed by column name
+func (t *Table) WriteJSON(w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for row := 0; row < t.rows; row++ {
+		object := make(map[string]interface{}, len(t.columns))
+		for i, column := range t.columns {
+			switch column.Kind {
+			case Int64:
+				object[column.Name] = t.ints[i][row]
+			default:
+				object[column.Name] = t.text(row, i)
+			}
+		}
+		encoded, err := json.Marshal(object)
+		if err != nil {
+			return err
+		}
+		if row > 0 {
+			encoded = append([]byte(","), encoded...)
+		}
+		if _, err := w.Write(encoded); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
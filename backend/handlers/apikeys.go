@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"leaderboard-api/secrets"
+	"net/http"
+	"strings"
+)
+
+// API key scopes
+const (
+	ScopeRead      = "read"       // may call the read endpoints
+	ScopeReadWrite = "read-write" // may call every endpoint
+)
+
+// APIKeyPolicy gates the API behind keys sent in an X-API-Key header or, for
+// EventSource clients opening a stream, an apiKey query parameter. Writes need a
+// read-write key; reads need any key unless they are public. Health checks are not
+// gated, and admin routes are left to RequireAdmin.
+type APIKeyPolicy struct {
+	// scope reports what a key may do, or "" for a key that isn't valid
+	scope func(key string) string
+
+	PublicReads bool
+}
+
+// NewSecretAPIKeyPolicy checks keys against two managed secrets listing read-write
+// and read-only keys, so rotated keys take effect immediately and the previous keys
+// keep working for the grace period
+func NewSecretAPIKeyPolicy(m *secrets.Manager, readWrite, readOnly string, publicReads bool) *APIKeyPolicy {
+	return &APIKeyPolicy{
+		scope: func(key string) string {
+			switch {
+			case m.Valid(readWrite, key):
+				return ScopeReadWrite
+			case m.Valid(readOnly, key):
+				return ScopeRead
+			}
+			return ""
+		},
+		PublicReads: publicReads,
+	}
+}
+
+// requestAPIKey returns the key a request carries, if any. Only streams may take it
+// from the query, as EventSource cannot set headers; anywhere else a key in the URL
+// would only end up in access logs and browser history.
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if r.Method == http.MethodGet && (r.URL.Path == "/api/stream" || strings.HasPrefix(r.URL.Path, "/api/stream/")) {
+		return r.URL.Query().Get("apiKey")
+	}
+	return ""
+}
+
+// Key returns the valid key a request carries, or "" when it carries none or one that
+// isn't valid
+func (p *APIKeyPolicy) Key(r *http.Request) string {
+	key := requestAPIKey(r)
+	if key == "" || p.scope(key) == "" {
+		return ""
+	}
+	return key
+}
+
+// Middleware refuses a request without a key it needs with 401, and a write with a
+// read-only key with 403
+func (p *APIKeyPolicy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if r.Method == http.MethodOptions || !strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/api/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		write := writes(r)
+		key := requestAPIKey(r)
+		if key == "" {
+			if !write && p.PublicReads {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", "APIKey")
+			http.Error(w, "An API key is required; send it in the X-API-Key header", http.StatusUnauthorized)
+			return
+		}
+
+		switch scope := p.scope(key); {
+		case scope == "":
+			w.Header().Set("WWW-Authenticate", "APIKey")
+			http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		case write && scope != ScopeReadWrite:
+			http.Error(w, "This API key is read-only", http.StatusForbidden)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// RequireAdmin guards every route it wraps behind a read-write key, for the admin
+// routes when no token policy guards them
+func (p *APIKeyPolicy) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch key := r.Header.Get("X-API-Key"); {
+		case key == "" || p.scope(key) == "":
+			w.Header().Set("WWW-Authenticate", "APIKey")
+			http.Error(w, "A read-write API key is required; send it in the X-API-Key header", http.StatusUnauthorized)
+		case p.scope(key) != ScopeReadWrite:
+			http.Error(w, "This API key is read-only", http.StatusForbidden)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// audit records a write in the audit log, if one is configured. Failures are logged
+// rather than surfaced since the write itself already happened.
+func (h *Handler) audit(action, board, subject string, data interface{}) {
+	if h.Audit == nil {
+		return
+	}
+	if _, err := h.Audit.Append(action, board, subject, data); err != nil {
+		log.Printf("audit: failed to record %s: %v", action, err)
+	}
+}
+
+// ListAuditLog handles GET /api/admin/audit. Entries are the API actions in the
+// hash-chained log, paged with after; with since (RFC 3339) they are the ones recorded
+// after that time instead. When the change log is on, changes lists every rating
+// change since then too, whatever made it, optionally only those to user.
+func (h *Handler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	if h.Audit == nil {
+		http.Error(w, "Audit log is not enabled", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 100
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+	var after uint64
+	if a, err := strconv.ParseUint(query.Get("after"), 10, 64); err == nil {
+		after = a
+	}
+	var since time.Time
+	if query.Has("since") {
+		var err error
+		if since, err = time.Parse(time.RFC3339, query.Get("since")); err != nil {
+			http.Error(w, "since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var entries []models.AuditEntry
+	if query.Has("since") {
+		entries = h.Audit.Since(since, limit)
+	} else {
+		entries = h.Audit.List(after, limit)
+	}
+	response := map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	}
+	if h.ChangeLog != nil {
+		response["changes"] = h.ChangeLog.Since(since, query.Get("user"), limit)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// VerifyAuditLog handles GET /api/admin/audit/verify
+func (h *Handler) VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	if h.Audit == nil {
+		http.Error(w, "Audit log is not enabled", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Audit.Verify())
+}
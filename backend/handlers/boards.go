@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+	"net/url"
+)
+
+// board resolves the {name} path segment to a leaderboard, writing a 404 if missing
+func (h *Handler) board(w http.ResponseWriter, r *http.Request) (store.Store, bool) {
+	lb, found := h.Boards.Get(r.PathValue("name"))
+	if !found {
+		http.Error(w, "Leaderboard not found", http.StatusNotFound)
+		return nil, false
+	}
+	return attributed(r, withLockTrace(r, lb)), true
+}
+
+// ListBoards handles GET /api/leaderboards
+func (h *Handler) ListBoards(w http.ResponseWriter, r *http.Request) {
+	boards := h.Boards.List()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"leaderboards": boards,
+		"count":        len(boards),
+	})
+}
+
+// CreateBoard handles POST /api/leaderboards
+func (h *Handler) CreateBoard(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name        string                `json:"name"`
+		Description string                `json:"description"`
+		ScoreUnit   string                `json:"scoreUnit"`
+		Decimals    int                   `json:"decimals"`
+		Symbol      string                `json:"symbol"`
+		SortKeys    []models.SortKey      `json:"sortKeys"`
+		RankingMode string                `json:"rankingMode"`
+		TieBreak    string                `json:"tieBreak"`
+		Capacity    int                   `json:"capacity"`
+		Metrics     []models.MetricWeight `json:"metrics"`
+		Layout      string                `json:"layout"`
+
+		Validation *models.ValidationRules `json:"validation"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	ranking, err := store.ParseRankingMode(req.RankingMode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tieBreak, err := store.ParseTieBreak(req.TieBreak)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Capacity < 0 {
+		http.Error(w, "Capacity must not be negative", http.StatusBadRequest)
+		return
+	}
+	if req.Decimals < 0 || req.Decimals > store.MaxScoreDecimals {
+		http.Error(w, fmt.Sprintf("decimals must be between 0 and %d", store.MaxScoreDecimals), http.StatusBadRequest)
+		return
+	}
+	if err := store.ValidateMetricWeights(req.Metrics); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	layout, err := store.ParseLayout(req.Layout)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := store.ValidateRules(req.Validation); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format, err := store.NewScoreFormat(req.ScoreUnit, req.Decimals, req.Symbol)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lb, err := h.Boards.Create(models.BoardMetadata{
+		Name:        req.Name,
+		Description: req.Description,
+		ScoreFormat: format,
+		SortKeys:    req.SortKeys,
+		RankingMode: ranking,
+		TieBreak:    tieBreak,
+		Capacity:    req.Capacity,
+		Metrics:     req.Metrics,
+		Layout:      layout,
+		Validation:  req.Validation,
+	})
+	if errors.Is(err, store.ErrBoardExists) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.audit("board.create", req.Name, "", lb.Metadata())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(lb.Metadata())
+}
+
+// GetBoard handles GET /api/leaderboards/{name}
+func (h *Handler) GetBoard(w http.ResponseWriter, r *http.Request) {
+	lb, ok := h.board(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.BoardSummary{
+		BoardMetadata: lb.Metadata(),
+		TotalUsers:    lb.GetTotalUsers(),
+	})
+}
+
+// DeleteBoard handles DELETE /api/leaderboards/{name}
+func (h *Handler) DeleteBoard(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	err := h.Boards.Delete(name)
+	if errors.Is(err, store.ErrBoardNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.audit("board.delete", name, "", nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBoardLeaderboard handles GET /api/leaderboards/{name}/leaderboard
+func (h *Handler) GetBoardLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveLeaderboard(w, r, lb)
+	}
+}
+
+// PollBoardLeaderboard handles GET /api/leaderboards/{name}/leaderboard/poll
+func (h *Handler) PollBoardLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.servePoll(w, r, lb)
+	}
+}
+
+// GetBoardRankRange handles GET /api/leaderboards/{name}/leaderboard/range
+func (h *Handler) GetBoardRankRange(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveRankRange(w, r, lb)
+	}
+}
+
+// GetBoardRatingRank handles GET /api/leaderboards/{name}/leaderboard/rank
+func (h *Handler) GetBoardRatingRank(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveRatingRank(w, r, lb)
+	}
+}
+
+// GetBoardUsersAtRank handles GET /api/leaderboards/{name}/leaderboard/rank/{rank}
+func (h *Handler) GetBoardUsersAtRank(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveUsersAtRank(w, r, lb)
+	}
+}
+
+// GetBoardStats handles GET /api/leaderboards/{name}/stats
+func (h *Handler) GetBoardStats(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveStats(w, r, lb)
+	}
+}
+
+// SearchBoardUsers handles GET /api/leaderboards/{name}/users/search
+func (h *Handler) SearchBoardUsers(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveSearch(w, r, lb)
+	}
+}
+
+// GetBoardUser handles GET /api/leaderboards/{name}/users/{username}
+func (h *Handler) GetBoardUser(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveUser(w, r, lb)
+	}
+}
+
+// GetBoardUserHistory handles GET /api/leaderboards/{name}/users/{username}/history
+func (h *Handler) GetBoardUserHistory(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveHistory(w, r, lb)
+	}
+}
+
+// UpdateBoardUserScores handles PUT /api/leaderboards/{name}/users/{username}/scores
+func (h *Handler) UpdateBoardUserScores(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveUpdateScores(w, r, lb)
+	}
+}
+
+// UpdateBoardUserProfile handles PUT /api/leaderboards/{name}/users/{username}/profile
+func (h *Handler) UpdateBoardUserProfile(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveUpdateProfile(w, r, lb)
+	}
+}
+
+// AddBoardUser handles POST /api/leaderboards/{name}/users
+func (h *Handler) AddBoardUser(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveAddUser(w, r, lb, "/api/leaderboards/"+url.PathEscape(r.PathValue("name"))+"/users/")
+	}
+}
+
+// UpdateBoardUserRating handles PUT /api/leaderboards/{name}/users/{username}/rating.
+// The body may also carry the user's gamesPlayed and wins.
+func (h *Handler) UpdateBoardUserRating(w http.ResponseWriter, r *http.Request) {
+	lb, ok := h.board(w, r)
+	if !ok {
+		return
+	}
+	lb = live(lb)
+
+	var req struct {
+		Rating      int64 `json:"rating"`
+		GamesPlayed *int  `json:"gamesPlayed"`
+		Wins        *int  `json:"wins"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if len(lb.Metadata().Metrics) > 0 {
+		http.Error(w, "Ratings on this leaderboard are computed from metrics; submit them instead", http.StatusBadRequest)
+		return
+	}
+
+	username := r.PathValue("username")
+	before, found := lb.GetUserRank(username)
+	if !found {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	// Checked up front so a refused rating leaves games played alone too
+	if !allowed(w, lb, username, &before.Rating, req.Rating) {
+		return
+	}
+
+	// Games played and wins are optional and must be sent together
+	if req.GamesPlayed != nil || req.Wins != nil {
+		games, ok := lb.(store.GameStatsStore)
+		if !ok {
+			http.Error(w, "This leaderboard does not track games played", http.StatusNotImplemented)
+			return
+		}
+		if req.GamesPlayed == nil || req.Wins == nil || *req.Wins < 0 || *req.Wins > *req.GamesPlayed {
+			http.Error(w, "gamesPlayed and wins must be sent together, with 0 <= wins <= gamesPlayed", http.StatusBadRequest)
+			return
+		}
+		games.UpdateGameStats(username, *req.GamesPlayed, *req.Wins)
+	}
+
+	updated, err := updateRating(lb, username, req.Rating)
+	if refused(w, err) {
+		return
+	}
+	if !updated {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	result, _ := lb.GetUserRank(username)
+	h.audit("rating.update", lb.Metadata().Name, username, map[string]int64{
+		"before": before.Rating,
+		"after":  result.Rating,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// IncrementBoardUserRating handles PATCH /api/leaderboards/{name}/users/{username}/rating
+func (h *Handler) IncrementBoardUserRating(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveIncrementRating(w, r, lb)
+	}
+}
+
+// UpdateBoardUserMetrics handles PUT /api/leaderboards/{name}/users/{username}/metrics:
+// it merges the submitted metrics into the user's record and recomputes their rating
+// from the board's weights
+func (h *Handler) UpdateBoardUserMetrics(w http.ResponseWriter, r *http.Request) {
+	lb, ok := h.board(w, r)
+	if !ok {
+		return
+	}
+	lb = live(lb)
+	composite, ok := lb.(store.CompositeStore)
+	if !ok {
+		http.Error(w, "This leaderboard cannot compute composite scores", http.StatusNotImplemented)
+		return
+	}
+
+	var req struct {
+		Metrics map[string]float64 `json:"metrics"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Metrics) == 0 {
+		http.Error(w, "Body must contain a non-empty metrics object", http.StatusBadRequest)
+		return
+	}
+
+	username := r.PathValue("username")
+	before, found := lb.GetUserRank(username)
+	if !found {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	updated, err := composite.UpdateMetrics(username, req.Metrics)
+	if refused(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !updated {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	result, _ := lb.GetUserRank(username)
+	h.audit("metrics.update", lb.Metadata().Name, username, map[string]interface{}{
+		"metrics": req.Metrics,
+		"before":  before.Rating,
+		"after":   result.Rating,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
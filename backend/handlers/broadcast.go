@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"sync"
+	"time"
+)
+
+// broadcastInterval is how often a page topic checks its board for changes; it matches
+// the fastest stream cadence any access tier gets
+const broadcastInterval = 500 * time.Millisecond
+
+// pageBroadcaster fans leaderboard pages out to SSE connections. Each distinct page
+// being streamed is a topic with one goroutine that encodes the page once per board
+// change and hands it to every connection following it, so the cost of a change
+// doesn't grow with the number of connections.
+type pageBroadcaster struct {
+	frames *frameCache
+
+	mu        sync.Mutex
+	topics    map[frameKey]*pageTopic
+	published uint64 // frames encoded for topics
+}
+
+// pageTopic is one page of one board and the connections following it
+type pageTopic struct {
+	lb          store.Store
+	key         frameKey
+	subscribers map[chan pageUpdate]struct{}
+	stop        chan struct{}
+}
+
+// pageUpdate is one encoded page and the store version it reflects
+type pageUpdate struct {
+	data    []byte
+	version uint64
+}
+
+func newPageBroadcaster(frames *frameCache) *pageBroadcaster {
+	return &pageBroadcaster{frames: frames, topics: make(map[frameKey]*pageTopic)}
+}
+
+// subscribe follows a page of lb. The channel holds at most the latest frame, starting
+// with the current one; cancel stops delivery and ends the topic once nobody follows it.
+func (pb *pageBroadcaster) subscribe(lb store.Store, limit, offset int) (frames <-chan pageUpdate, cancel func()) {
+	key := frameKey{board: lb.Metadata().Name, limit: limit, offset: offset}
+	ch := make(chan pageUpdate, 1)
+	data, version, _ := pb.frames.page(lb, "", limit, offset)
+
+	pb.mu.Lock()
+	topic, found := pb.topics[key]
+	if !found {
+		topic = &pageTopic{lb: lb, key: key, subscribers: make(map[chan pageUpdate]struct{}), stop: make(chan struct{})}
+		pb.topics[key] = topic
+		go pb.run(topic)
+	}
+	topic.subscribers[ch] = struct{}{}
+	ch <- pageUpdate{data, version}
+	pb.mu.Unlock()
+
+	return ch, func() {
+		pb.mu.Lock()
+		defer pb.mu.Unlock()
+		delete(topic.subscribers, ch)
+		if len(topic.subscribers) == 0 && pb.topics[key] == topic {
+			delete(pb.topics, key)
+			close(topic.stop)
+		}
+	}
+}
+
+// run publishes the topic's page whenever its board or baseline has moved on
+func (pb *pageBroadcaster) run(topic *pageTopic) {
+	ticker := time.NewTicker(broadcastInterval)
+	defer ticker.Stop()
+
+	var last, lastBaseline uint64
+	for {
+		select {
+		case <-ticker.C:
+			if topic.lb.Version() == last && baselineGeneration(topic.lb) == lastBaseline {
+				continue
+			}
+			var data []byte
+			data, last, lastBaseline = pb.frames.page(topic.lb, "", topic.key.limit, topic.key.offset)
+			pb.publish(topic, pageUpdate{data, last})
+		case <-topic.stop:
+			return
+		}
+	}
+}
+
+// publish replaces each subscriber's pending frame with update
+func (pb *pageBroadcaster) publish(topic *pageTopic, update pageUpdate) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.published++
+	for ch := range topic.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- update
+	}
+}
+
+// stats reports how many pages are being broadcast to how many connections
+func (pb *pageBroadcaster) stats() models.BroadcastStats {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	stats := models.BroadcastStats{Topics: len(pb.topics), Published: pb.published}
+	for _, topic := range pb.topics {
+		stats.Subscribers += len(topic.subscribers)
+	}
+	return stats
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard-api/certificate"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// IssueCertificates handles POST /api/admin/certificates?format=json|pdf with a JSON
+// array of usernames. Every certificate in the batch is taken from the same moment
+// and attested by one audit entry, so organizers can prove final standings later.
+// The board parameter names the board (the default board without one).
+func (h *Handler) IssueCertificates(w http.ResponseWriter, r *http.Request) {
+	if h.Audit == nil {
+		http.Error(w, "Audit log is not enabled", http.StatusNotFound)
+		return
+	}
+
+	lb := h.Leaderboard
+	if name := r.URL.Query().Get("board"); name != "" {
+		var found bool
+		if lb, found = h.Boards.Get(name); !found {
+			http.Error(w, "Leaderboard not found", http.StatusNotFound)
+			return
+		}
+	}
+	// Certificates attest the latest standings, not a view published a moment ago
+	certifying, ok := live(lb).(store.CertifyingStore)
+	if !ok {
+		http.Error(w, "This leaderboard cannot certify standings", http.StatusNotImplemented)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	renderer, err := certificate.Lookup(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var usernames []string
+	if err := json.NewDecoder(r.Body).Decode(&usernames); err != nil || len(usernames) == 0 {
+		http.Error(w, "Body must be a non-empty JSON array of usernames", http.StatusBadRequest)
+		return
+	}
+	if len(usernames) > store.MaxSubsetSize {
+		http.Error(w, fmt.Sprintf("At most %d usernames can be certified together", store.MaxSubsetSize), http.StatusBadRequest)
+		return
+	}
+
+	certs, missing := certifying.Certify(usernames)
+	if len(missing) > 0 {
+		http.Error(w, "Not on the board: "+strings.Join(missing, ", "), http.StatusNotFound)
+		return
+	}
+	if err := h.Audit.Attest(certs); err != nil {
+		log.Printf("certificates: failed to attest %d: %v", len(certs), err)
+		http.Error(w, "Failed to record the certificates in the audit log", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", renderer.ContentType())
+	if format != "json" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q",
+			fmt.Sprintf("%s-certificates-%d.%s", lb.Metadata().Name, certs[0].AuditSeq, format)))
+	}
+	if err := renderer.Render(w, certs); err != nil {
+		log.Printf("certificates: render as %s: %v", format, err)
+	}
+}
+
+// VerifyCertificate handles POST /api/certificates/verify with a certificate as issued
+// in JSON. Anyone holding a certificate can check it without admin access.
+func (h *Handler) VerifyCertificate(w http.ResponseWriter, r *http.Request) {
+	if h.Audit == nil {
+		http.Error(w, "Audit log is not enabled", http.StatusNotFound)
+		return
+	}
+
+	var cert models.Certificate
+	if err := json.NewDecoder(r.Body).Decode(&cert); err != nil {
+		http.Error(w, "Body must be a certificate as issued", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Audit.VerifyCertificate(cert))
+}
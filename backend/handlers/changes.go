@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"sync/atomic"
+)
+
+// changeCounter tallies the default board's changes by kind from its change feed
+type changeCounter struct {
+	source store.ObservableStore
+
+	added   atomic.Uint64
+	updated atomic.Uint64
+	removed atomic.Uint64
+}
+
+// newChangeCounter subscribes a counter to source
+func newChangeCounter(source store.ObservableStore) *changeCounter {
+	c := &changeCounter{source: source}
+	source.Subscribe(c.count)
+	return c
+}
+
+func (c *changeCounter) count(event models.ChangeEvent) {
+	switch event.Type {
+	case models.ChangeAdded:
+		c.added.Add(1)
+	case models.ChangeUpdated:
+		c.updated.Add(1)
+	case models.ChangeRemoved:
+		c.removed.Add(1)
+	}
+}
+
+// stats returns the counts so far
+func (c *changeCounter) stats() models.ChangeStats {
+	return models.ChangeStats{
+		Added:   c.added.Load(),
+		Updated: c.updated.Load(),
+		Removed: c.removed.Load(),
+		Dropped: c.source.DroppedChanges(),
+	}
+}
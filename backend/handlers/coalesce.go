@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"leaderboard-api/models"
+	"sync"
+)
+
+// coalescer runs identical concurrent reads once. A request arriving while the same
+// read is being computed waits for it and shares its encoded response, so a herd of
+// requests right after a version bump scans the board once rather than once each.
+// Keys carry the store version the request saw, so nobody is handed a response older
+// than the board it arrived at.
+type coalescer struct {
+	mu       sync.Mutex
+	calls    map[string]*coalescedCall
+	computed uint64
+	shared   uint64
+}
+
+type coalescedCall struct {
+	done chan struct{}
+	data []byte
+	ok   bool // false if the computation panicked
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{calls: make(map[string]*coalescedCall)}
+}
+
+// do returns compute's result for key, running it only if no identical read is
+// already in flight
+func (c *coalescer) do(key string, compute func() []byte) []byte {
+	c.mu.Lock()
+	if call, found := c.calls[key]; found {
+		c.mu.Unlock()
+		<-call.done
+		if !call.ok {
+			// The leader panicked; don't share its fate
+			return compute()
+		}
+		c.mu.Lock()
+		c.shared++
+		c.mu.Unlock()
+		return call.data
+	}
+	call := &coalescedCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.computed++
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+	call.data = compute()
+	call.ok = true
+	return call.data
+}
+
+// stats returns a snapshot of the coalescing counters
+func (c *coalescer) stats() models.CoalescingStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return models.CoalescingStats{
+		Computed: c.computed,
+		Shared:   c.shared,
+		InFlight: len(c.calls),
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// CompactBoard handles POST /api/admin/compact?board=name: it rebuilds the board's
+// structures at their current size and reports the memory given back. Without a
+// board parameter the default board is compacted.
+func (h *Handler) CompactBoard(w http.ResponseWriter, r *http.Request) {
+	lb := h.Leaderboard
+	if name := r.URL.Query().Get("board"); name != "" {
+		var found bool
+		if lb, found = h.Boards.Get(name); !found {
+			http.Error(w, "Leaderboard not found", http.StatusNotFound)
+			return
+		}
+	}
+	compacting, ok := lb.(store.CompactingStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not support compaction", http.StatusNotImplemented)
+		return
+	}
+
+	report := compacting.Compact()
+	h.audit("board.compact", report.Board, "", report)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// trendLength is how many of a user's latest rating changes a comparison's trend covers
+const trendLength = 10
+
+// CompareUsers handles GET /api/users/compare?a=rahul_007&b=priya_dev: both users'
+// standings, the gaps between them and their recent trends in one response
+func (h *Handler) CompareUsers(w http.ResponseWriter, r *http.Request) {
+	h.serveCompare(w, r, h.defaultBoard(r))
+}
+
+// CompareBoardUsers handles GET /api/leaderboards/{name}/users/compare
+func (h *Handler) CompareBoardUsers(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveCompare(w, r, lb)
+	}
+}
+
+// serveCompare implements CompareUsers against a specific board
+func (h *Handler) serveCompare(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	query := r.URL.Query()
+	a, b := query.Get("a"), query.Get("b")
+	if a == "" || b == "" {
+		http.Error(w, "Both a and b usernames are required", http.StatusBadRequest)
+		return
+	}
+
+	lb, _, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+	var users [2]models.ComparedUser
+	for i, username := range []string{a, b} {
+		result, found := lookupUser(lb, username)
+		if !found {
+			http.Error(w, "User not found: "+username, http.StatusNotFound)
+			return
+		}
+		users[i] = models.ComparedUser{SearchResult: *result, Trend: trend(lb, result.Username)}
+	}
+
+	comparison := models.Comparison{
+		A:         users[0],
+		B:         users[1],
+		RankGap:   users[1].GlobalRank - users[0].GlobalRank,
+		RatingGap: users[0].Rating - users[1].Rating,
+	}
+	switch {
+	case comparison.RankGap > 0:
+		comparison.Leader = users[0].Username
+	case comparison.RankGap < 0:
+		comparison.Leader = users[1].Username
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
+
+// trend summarizes a user's latest rating changes, or returns nil where the board
+// keeps no history
+func trend(lb store.Store, username string) *models.Trend {
+	history, ok := lb.(store.HistoryStore)
+	if !ok {
+		return nil
+	}
+	changes, found := history.GetHistory(username, trendLength)
+	if !found {
+		return nil
+	}
+
+	if changes == nil {
+		changes = []models.RatingChange{}
+	}
+	t := &models.Trend{Direction: models.TrendFlat, Recent: changes}
+	if len(changes) > 0 {
+		t.Delta = changes[len(changes)-1].New - changes[0].Old
+	}
+	switch {
+	case t.Delta > 0:
+		t.Direction = models.TrendUp
+	case t.Delta < 0:
+		t.Direction = models.TrendDown
+	}
+	return t
+}
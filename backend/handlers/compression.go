@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"leaderboard-api/models"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Stream encodings
+const (
+	encodingGzip     = "gzip"
+	encodingIdentity = "identity"
+)
+
+// compressionCounter tallies bytes before and after compression
+type compressionCounter struct {
+	raw  atomic.Int64
+	wire atomic.Int64
+}
+
+func (c *compressionCounter) add(raw, wire int) {
+	c.raw.Add(int64(raw))
+	c.wire.Add(int64(wire))
+}
+
+func (c *compressionCounter) stats() models.CompressionStats {
+	stats := models.CompressionStats{RawBytes: c.raw.Load(), WireBytes: c.wire.Load()}
+	if stats.RawBytes > 0 {
+		saved := float64(stats.RawBytes-stats.WireBytes) / float64(stats.RawBytes) * 100
+		stats.SavedPercent = math.Round(saved*100) / 100
+	}
+	return stats
+}
+
+// streamCompression totals compression across every stream connection
+type streamCompression struct {
+	compressionCounter
+	compressed   atomic.Uint64
+	uncompressed atomic.Uint64
+}
+
+func (sc *streamCompression) stats() models.CompressionStats {
+	stats := sc.compressionCounter.stats()
+	stats.Compressed, stats.Uncompressed = sc.compressed.Load(), sc.uncompressed.Load()
+	return stats
+}
+
+// compressedStream is an SSE response, gzipped when the client accepts it. The
+// compressor is flushed with every frame, so each reaches the client at once while
+// later frames are still compressed against the ones before them; leaderboard pages
+// repeat most of the previous page, so they shrink far more than they would alone.
+type compressedStream struct {
+	http.ResponseWriter
+	flusher  http.Flusher
+	encoding string
+	gz       *gzip.Writer
+	wire     wireCounter
+
+	conn   compressionCounter // this connection
+	totals *streamCompression // every connection
+}
+
+// wireCounter counts what reaches the underlying response
+type wireCounter struct {
+	w http.ResponseWriter
+	n int
+}
+
+func (wc *wireCounter) Write(p []byte) (int, error) {
+	n, err := wc.w.Write(p)
+	wc.n += n
+	return n, err
+}
+
+// compressStream negotiates gzip from the request's Accept-Encoding and wraps w, which
+// must have its stream headers set but not yet written. ?compress=false opts out.
+// The caller must Close the stream when done.
+func (h *Handler) compressStream(w http.ResponseWriter, r *http.Request, flusher http.Flusher) *compressedStream {
+	s := &compressedStream{
+		ResponseWriter: w,
+		flusher:        flusher,
+		encoding:       encodingIdentity,
+		wire:           wireCounter{w: w},
+		totals:         h.compression,
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	if acceptsGzip(r) && r.URL.Query().Get("compress") != "false" {
+		w.Header().Set("Content-Encoding", encodingGzip)
+		s.encoding = encodingGzip
+		s.gz = gzip.NewWriter(&s.wire)
+		h.compression.compressed.Add(1)
+	} else {
+		h.compression.uncompressed.Add(1)
+	}
+	return s
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, field := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(field), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), encodingGzip) {
+			continue
+		}
+		// q=0 rules it out
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (s *compressedStream) Write(p []byte) (int, error) {
+	before := s.wire.n
+	var n int
+	var err error
+	if s.gz != nil {
+		n, err = s.gz.Write(p)
+	} else {
+		n, err = s.wire.Write(p)
+	}
+	s.count(n, s.wire.n-before)
+	return n, err
+}
+
+// Flush pushes everything written so far to the client
+func (s *compressedStream) Flush() {
+	if s.gz != nil {
+		before := s.wire.n
+		s.gz.Flush()
+		s.count(0, s.wire.n-before)
+	}
+	s.flusher.Flush()
+}
+
+// Close ends the gzip stream once the connection is done
+func (s *compressedStream) Close() {
+	if s.gz != nil {
+		before := s.wire.n
+		s.gz.Close()
+		s.count(0, s.wire.n-before)
+		s.flusher.Flush()
+	}
+}
+
+func (s *compressedStream) count(raw, wire int) {
+	s.conn.add(raw, wire)
+	s.totals.add(raw, wire)
+}
+
+// stats reports the connection's savings so far
+func (s *compressedStream) stats() models.CompressionStats {
+	stats := s.conn.stats()
+	stats.Encoding = s.encoding
+	return stats
+}
+
+// GetStreamStats handles GET /api/stream/{connectionId}/stats: how many bytes a live
+// SSE connection has been sent, before and after compression
+func (h *Handler) GetStreamStats(w http.ResponseWriter, r *http.Request) {
+	conn, found := h.subscriptions.get(r.PathValue("connectionId"))
+	if !found || conn.stream == nil {
+		http.Error(w, "Stream connection not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"connectionId": conn.id,
+		"compression":  conn.stream.stats(),
+	})
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// CheckConsistency handles POST /api/admin/consistency/check: it runs a sampled
+// consistency check of the default board now and reports what it found and repaired
+func (h *Handler) CheckConsistency(w http.ResponseWriter, r *http.Request) {
+	if h.Consistency == nil {
+		http.Error(w, "Consistency checks run only when snapshots are enabled", http.StatusNotImplemented)
+		return
+	}
+
+	report := h.Consistency.Check()
+	if len(report.Repairs) > 0 {
+		h.audit("consistency.repair", h.Leaderboard.Metadata().Name, "", report)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
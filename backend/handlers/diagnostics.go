@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Diagnostics thresholds
+const (
+	// Lock waits whose p99 reaches this are reported as contention
+	diagnosticLockWaitMs = 50
+
+	// Goroutines beyond this suggest a leak, e.g. streams never closed
+	diagnosticGoroutines = 10000
+
+	// How many of the busiest functions the goroutine summary lists
+	diagnosticGoroutineGroups = 10
+
+	// Users sampled when no periodic consistency checker is configured
+	diagnosticSampleSize = 100
+)
+
+// GetDiagnostics handles GET /api/admin/diagnostics: it runs a battery of self-checks
+// on the default board and the process (index consistency on a sample of users, lock
+// contention, goroutines and persistence lag) and reports each with the worst status
+// overall, so one call gives on-call a picture of the server's health. The report is
+// served with 503 when a check is failing.
+func (h *Handler) GetDiagnostics(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	report := models.DiagnosticsReport{At: start, Status: models.DiagnosticOK}
+	for _, check := range []struct {
+		name string
+		run  func() (status, message string, details interface{})
+	}{
+		{"consistency", h.diagnoseConsistency},
+		{"locks", h.diagnoseLocks},
+		{"goroutines", diagnoseGoroutines},
+		{"persistence", h.diagnosePersistence},
+	} {
+		checkStart := time.Now()
+		status, message, details := check.run()
+		report.Checks = append(report.Checks, models.DiagnosticCheck{
+			Name:       check.name,
+			Status:     status,
+			Message:    message,
+			DurationMs: float64(time.Since(checkStart).Microseconds()) / 1000,
+			Details:    details,
+		})
+		report.Status = worseDiagnostic(report.Status, status)
+	}
+	report.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status == models.DiagnosticFailing {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// worseDiagnostic returns the more severe of two check statuses; skipped checks don't
+// count against the report
+func worseDiagnostic(a, b string) string {
+	severity := map[string]int{models.DiagnosticWarn: 1, models.DiagnosticFailing: 2}
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+// diagnoseConsistency compares a sample of users against the view and rating tree
+// derived from them, through the periodic checker when there is one so the run counts
+// towards its stats and checks the snapshot too. Drift found is repaired as usual.
+func (h *Handler) diagnoseConsistency() (string, string, interface{}) {
+	checker := h.Consistency
+	if checker == nil {
+		lb, ok := h.Leaderboard.(*store.Leaderboard)
+		if !ok {
+			return models.DiagnosticSkipped, "the default board's store has no derived indexes to check", nil
+		}
+		checker = store.NewConsistencyChecker(lb, "", diagnosticSampleSize)
+	}
+
+	report := checker.Check()
+	if len(report.Repairs) > 0 {
+		h.audit("consistency.repair", h.Leaderboard.Metadata().Name, "", report)
+	}
+	drift := report.ViewDrift + report.TreeDrift + report.DurableDrift
+	if drift > 0 {
+		return models.DiagnosticWarn, fmt.Sprintf("%d of %d sampled users drifted and were repaired", drift, report.Sampled), report
+	}
+	return models.DiagnosticOK, fmt.Sprintf("%d sampled users agree across every index", report.Sampled), report
+}
+
+// diagnoseLocks reports the store's lock timings, warning about any lock callers
+// commonly wait long for
+func (h *Handler) diagnoseLocks() (string, string, interface{}) {
+	traced, ok := h.Leaderboard.(store.LockTracedStore)
+	if !ok {
+		return models.DiagnosticSkipped, "the default board's store doesn't time its locks", nil
+	}
+
+	stats := traced.LockStats()
+	var contended []string
+	for name, lock := range stats {
+		if lock.WaitP99Ms >= diagnosticLockWaitMs {
+			contended = append(contended, fmt.Sprintf("%s (p99 wait %.1fms)", name, lock.WaitP99Ms))
+		}
+	}
+	if len(contended) > 0 {
+		sort.Strings(contended)
+		return models.DiagnosticWarn, "contended: " + strings.Join(contended, ", "), stats
+	}
+	return models.DiagnosticOK, fmt.Sprintf("no lock has a p99 wait of %dms or more", diagnosticLockWaitMs), stats
+}
+
+// diagnoseGoroutines summarizes every goroutine in the process by state and by the
+// function at the top of its stack
+func diagnoseGoroutines() (string, string, interface{}) {
+	summary := summarizeGoroutines()
+	message := fmt.Sprintf("%d goroutines", summary.Total)
+	if summary.Total > diagnosticGoroutines {
+		return models.DiagnosticWarn, message + fmt.Sprintf(", more than %d; look for leaked streams", diagnosticGoroutines), summary
+	}
+	return models.DiagnosticOK, message, summary
+}
+
+// summarizeGoroutines parses a full goroutine dump. Each goroutine starts with a
+// header such as "goroutine 7 [chan receive, 2 minutes]:", followed by its innermost
+// function.
+func summarizeGoroutines() models.GoroutineSummary {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	summary := models.GoroutineSummary{ByState: make(map[string]int), Functions: make([]models.GoroutineGroup, 0)}
+	functions := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	scanner.Buffer(make([]byte, 64*1024), len(buf)+1)
+	header := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "goroutine "):
+			state := line
+			if open := strings.IndexByte(line, '['); open >= 0 {
+				state = strings.TrimSuffix(line[open+1:], "]:")
+				state, _, _ = strings.Cut(state, ",")
+			}
+			summary.Total++
+			summary.ByState[state]++
+			header = true
+		case header:
+			function := line
+			if paren := strings.LastIndexByte(line, '('); paren > 0 {
+				function = line[:paren]
+			}
+			functions[function]++
+			header = false
+		}
+	}
+
+	for function, count := range functions {
+		summary.Functions = append(summary.Functions, models.GoroutineGroup{Function: function, Count: count})
+	}
+	sort.Slice(summary.Functions, func(i, j int) bool {
+		a, b := summary.Functions[i], summary.Functions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Function < b.Function
+	})
+	if len(summary.Functions) > diagnosticGoroutineGroups {
+		summary.Functions = summary.Functions[:diagnosticGoroutineGroups]
+	}
+	return summary
+}
+
+// diagnosePersistence reports how far durable and published copies of the board trail
+// it: the last snapshot, the outbound mirror and the background view publisher. A
+// failed last attempt is failing; a snapshot or view more than two intervals old is a
+// warning.
+func (h *Handler) diagnosePersistence() (string, string, interface{}) {
+	details := make(map[string]interface{})
+	status := models.DiagnosticOK
+	var problems []string
+	flag := func(s, problem string) {
+		status = worseDiagnostic(status, s)
+		problems = append(problems, problem)
+	}
+
+	if h.Snapshots != nil {
+		stats := h.Snapshots.Stats()
+		details["snapshot"] = stats
+		switch {
+		case stats.LastError != "":
+			flag(models.DiagnosticFailing, "the last snapshot failed: "+stats.LastError)
+		case stats.LastSavedAt != nil && stats.IntervalMs > 0 && stats.SinceLastSaveMs > 2*stats.IntervalMs:
+			flag(models.DiagnosticWarn, fmt.Sprintf("no snapshot for %.0fs", stats.SinceLastSaveMs/1000))
+		}
+	}
+	if h.Mirror != nil {
+		stats := h.Mirror.Stats()
+		details["mirror"] = stats
+		if stats.LastError != "" {
+			flag(models.DiagnosticFailing, "the mirror's last push failed: "+stats.LastError)
+		}
+	}
+	if h.Publisher != nil {
+		stats := h.Publisher.Stats()
+		details["publisher"] = stats
+		if stats.PendingWrites > 0 && stats.IntervalMs > 0 && stats.SinceLastBuildMs > 2*stats.IntervalMs {
+			flag(models.DiagnosticWarn, fmt.Sprintf("the published view trails %d writes", stats.PendingWrites))
+		}
+	}
+
+	switch {
+	case len(details) == 0:
+		return models.DiagnosticSkipped, "the default board isn't persisted, mirrored or published in the background", nil
+	case len(problems) > 0:
+		return status, strings.Join(problems, "; "), details
+	}
+	return status, "every copy of the board is keeping up", details
+}
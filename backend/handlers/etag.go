@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// etagEpoch distinguishes this process's ETags from another's. Versions start again
+// from zero on restart, so a version alone could match a tag served before it.
+var etagEpoch = strconv.FormatInt(time.Now().UnixNano(), 36)
+
+// versionETag is the entity tag of a response fully determined by a store version
+func versionETag(version uint64) string {
+	return `"` + etagEpoch + "." + strconv.FormatUint(version, 10) + `"`
+}
+
+// pageETag is the entity tag of a leaderboard page, which also depends on the baseline
+// its deltas were measured against
+func pageETag(version, baseline uint64) string {
+	return `"` + etagEpoch + "." + strconv.FormatUint(version, 10) + "." + strconv.FormatUint(baseline, 10) + `"`
+}
+
+// notModified sets the response's ETag and reports whether the request's
+// If-None-Match already names it, in which case it answers 304 and the caller writes
+// nothing more. Clients are asked to revalidate before reusing a response.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header names etag, comparing weakly as
+// RFC 9110 asks for GET
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
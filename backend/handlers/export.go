@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"leaderboard-api/export"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"log"
+	"math"
+	"net/http"
+)
+
+// Export handles GET /api/admin/export?dataset=users|history|matches&format=json|csv|parquet.
+// Users and history come from the board named by the board parameter (the default
+// board without one); matches are recorded for the default board only. Parquet files
+// drop straight into a lakehouse without a conversion step.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	lb := h.Leaderboard
+	if name := r.URL.Query().Get("board"); name != "" {
+		var found bool
+		if lb, found = h.Boards.Get(name); !found {
+			http.Error(w, "Leaderboard not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", export.FormatJSON, export.FormatCSV, export.FormatParquet:
+	default:
+		http.Error(w, "format must be json, csv or parquet", http.StatusBadRequest)
+		return
+	}
+
+	dataset := r.URL.Query().Get("dataset")
+	var table *export.Table
+	var err error
+	switch dataset {
+	case "users":
+		exporting, ok := lb.(store.ExportingStore)
+		if !ok {
+			http.Error(w, "This leaderboard does not support export", http.StatusNotImplemented)
+			return
+		}
+		table, err = usersTable(r.Context(), lb, exporting)
+	case "history":
+		exporting, ok := lb.(store.ExportingStore)
+		history, tracked := lb.(store.HistoryStore)
+		if !ok || !tracked {
+			http.Error(w, "This leaderboard does not export rating history", http.StatusNotImplemented)
+			return
+		}
+		table, err = historyTable(r.Context(), exporting, history)
+	case "matches":
+		table = h.matchesTable()
+	default:
+		http.Error(w, "dataset must be users, history or matches", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		// The client went away mid-export
+		log.Printf("export %s: %v", dataset, err)
+		return
+	}
+
+	if format == "" {
+		format = export.FormatJSON
+	}
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", lb.Metadata().Name+"-"+dataset+"."+format))
+	if err := table.Write(w, format); err != nil {
+		log.Printf("export %s as %s: %v", dataset, format, err)
+	}
+}
+
+// usersTable lists every user in standings order
+func usersTable(ctx context.Context, lb store.Store, exporting store.ExportingStore) (*export.Table, error) {
+	format := lb.Metadata().ScoreFormat
+	table := export.NewTable(
+		export.Column{Name: "rank", Kind: export.Int64},
+		export.Column{Name: "username", Kind: export.String},
+		export.Column{Name: "displayName", Kind: export.String},
+		export.Column{Name: "country", Kind: export.String},
+		export.Column{Name: "rating", Kind: export.Int64},
+		export.Column{Name: "display", Kind: export.String},
+		export.Column{Name: "source", Kind: export.String},
+		export.Column{Name: "gamesPlayed", Kind: export.Int64},
+		export.Column{Name: "wins", Kind: export.Int64},
+		export.Column{Name: "peakRating", Kind: export.Int64},
+		export.Column{Name: "lowestRating", Kind: export.Int64},
+		export.Column{Name: "ratingUpdatedAt", Kind: export.Timestamp},
+		export.Column{Name: "lastActiveAt", Kind: export.Timestamp},
+	)
+	for user, err := range exporting.Export(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		table.Append(int64(user.Rank), user.Username, user.DisplayName, user.Country, user.Rating, store.FormatScore(format, user.Rating),
+			user.Source, int64(user.GamesPlayed), int64(user.Wins), user.PeakRating, user.LowestRating,
+			user.RatingUpdatedAt, user.LastActiveAt)
+	}
+	return table, nil
+}
+
+// historyTable lists every user's retained rating changes, oldest first per user
+func historyTable(ctx context.Context, exporting store.ExportingStore, history store.HistoryStore) (*export.Table, error) {
+	table := export.NewTable(
+		export.Column{Name: "username", Kind: export.String},
+		export.Column{Name: "old", Kind: export.Int64},
+		export.Column{Name: "new", Kind: export.Int64},
+		export.Column{Name: "at", Kind: export.Timestamp},
+	)
+	for user, err := range exporting.Export(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		changes, _ := history.GetHistory(user.Username, math.MaxInt)
+		for _, change := range changes {
+			table.Append(user.Username, change.Old, change.New, change.At)
+		}
+	}
+	return table, nil
+}
+
+// matchesTable lists every recorded match, one row per player, oldest first
+func (h *Handler) matchesTable() *export.Table {
+	table := export.NewTable(
+		export.Column{Name: "matchId", Kind: export.Int64},
+		export.Column{Name: "playedAt", Kind: export.Timestamp},
+		export.Column{Name: "username", Kind: export.String},
+		export.Column{Name: "score", Kind: export.Int64},
+		export.Column{Name: "ratingBefore", Kind: export.Int64},
+		export.Column{Name: "ratingAfter", Kind: export.Int64},
+		export.Column{Name: "ratingDelta", Kind: export.Int64},
+	)
+
+	// Matches are listed newest first, a page at a time
+	var matches []models.Match
+	for cursor := int64(0); ; {
+		page, next := h.Matches.List("", cursor, 1000)
+		matches = append(matches, page...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	for i := len(matches) - 1; i >= 0; i-- {
+		match := matches[i]
+		for _, player := range match.Players {
+			table.Append(match.ID, match.PlayedAt, player.Username, int64(player.Score),
+				player.RatingBefore, player.RatingAfter, player.RatingDelta)
+		}
+	}
+	return table
+}
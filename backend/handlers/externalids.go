@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/ids"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// newUserID returns the ID for a user joining through the API
+func (h *Handler) newUserID() string {
+	if h.IDs == nil {
+		return ids.UUID{}.NewID()
+	}
+	return h.IDs.NewID()
+}
+
+// GetUserByExternalID handles GET /api/users/by-external/{id}, and its alias
+// /api/external-ids/{id}, looking a user up by the ID the caller's game backend gave
+// when adding them
+func (h *Handler) GetUserByExternalID(w http.ResponseWriter, r *http.Request) {
+	h.serveUserByExternalID(w, r, h.defaultBoard(r))
+}
+
+// serveUserByExternalID implements GetUserByExternalID against a specific board
+func (h *Handler) serveUserByExternalID(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	externalID := r.PathValue("id")
+	if externalID == "" {
+		http.Error(w, "External ID required", http.StatusBadRequest)
+		return
+	}
+	external, ok := live(lb).(store.ExternalIDStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not support external IDs", http.StatusNotImplemented)
+		return
+	}
+
+	result, found := external.UserByExternalID(externalID)
+	if !found {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
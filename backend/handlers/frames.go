@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"sync"
+	"time"
+)
+
+const (
+	// maxFramePages bounds the frame cache; it is cleared wholesale when full
+	maxFramePages = 256
+
+	// pollInterval is how often a waiting long-poll request checks the store version
+	pollInterval = 100 * time.Millisecond
+)
+
+type frameKey struct {
+	board   string
+	ranking string // requested ranking mode; empty for the board's own
+	limit   int
+	offset  int
+}
+
+// pageFrame is the serialized JSON of one leaderboard page at one store version and
+// baseline generation
+type pageFrame struct {
+	mu       sync.Mutex // held while (re)serializing, so each version is encoded once
+	version  uint64
+	baseline uint64
+	data     []byte
+}
+
+// frameCache keeps pre-serialized leaderboard pages for the REST, SSE and long-poll
+// endpoints. A page is re-encoded at most once per store version however many
+// requests and streams are reading it.
+type frameCache struct {
+	mu     sync.Mutex
+	frames map[frameKey]*pageFrame
+	hits   uint64
+	misses uint64
+}
+
+func newFrameCache() *frameCache {
+	return &frameCache{frames: make(map[frameKey]*pageFrame)}
+}
+
+// page returns the JSON for a leaderboard page, the store version it reflects and the
+// generation of the baseline its deltas were measured against. ranking names the mode
+// lb was switched to with WithRanking, if any.
+func (fc *frameCache) page(lb store.Store, ranking string, limit, offset int) ([]byte, uint64, uint64) {
+	key := frameKey{board: lb.Metadata().Name, ranking: ranking, limit: limit, offset: offset}
+
+	fc.mu.Lock()
+	frame, found := fc.frames[key]
+	if !found {
+		if len(fc.frames) >= maxFramePages {
+			fc.frames = make(map[frameKey]*pageFrame)
+		}
+		frame = &pageFrame{}
+		fc.frames[key] = frame
+	}
+	fc.mu.Unlock()
+
+	frame.mu.Lock()
+	defer frame.mu.Unlock()
+
+	// Read the version first so the frame never claims writes it might have missed
+	version, baseline := lb.Version(), baselineGeneration(lb)
+	if frame.data != nil && frame.version == version && frame.baseline == baseline {
+		fc.count(true)
+		return frame.data, version, baseline
+	}
+	fc.count(false)
+
+	entries := lb.GetLeaderboard(limit, offset)
+	stats := lb.GetStats()
+	frame.data, _ = json.Marshal(map[string]interface{}{
+		"entries":     entries,
+		"totalUsers":  stats.TotalUsers,
+		"limit":       limit,
+		"offset":      offset,
+		"hasMore":     offset+limit < stats.TotalUsers,
+		"scoreFormat": lb.Metadata().ScoreFormat,
+		"version":     version,
+	})
+	frame.version, frame.baseline = version, baseline
+	return frame.data, version, baseline
+}
+
+// baselineGeneration returns lb's baseline generation, or 0 for stores without deltas
+func baselineGeneration(lb store.Store) uint64 {
+	if deltas, ok := lb.(store.BaselineStore); ok {
+		return deltas.BaselineGeneration()
+	}
+	return 0
+}
+
+func (fc *frameCache) count(hit bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if hit {
+		fc.hits++
+	} else {
+		fc.misses++
+	}
+}
+
+// stats returns a snapshot of the cache counters
+func (fc *frameCache) stats() models.CacheStats {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	stats := models.CacheStats{
+		Hits:    fc.hits,
+		Misses:  fc.misses,
+		Entries: len(fc.frames),
+	}
+	if total := fc.hits + fc.misses; total > 0 {
+		stats.HitRate = float64(fc.hits) / float64(total)
+	}
+	return stats
+}
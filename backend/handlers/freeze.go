@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// FreezeBoard handles POST /api/admin/freeze?board=name. Readers keep getting the
+// standings as of the freeze while writes carry on; without a board parameter the
+// default board is frozen.
+func (h *Handler) FreezeBoard(w http.ResponseWriter, r *http.Request) {
+	h.serveFreeze(w, r, true)
+}
+
+// UnfreezeBoard handles POST /api/admin/unfreeze?board=name, publishing every write
+// made during the freeze as the final standings
+func (h *Handler) UnfreezeBoard(w http.ResponseWriter, r *http.Request) {
+	h.serveFreeze(w, r, false)
+}
+
+// serveFreeze implements FreezeBoard and UnfreezeBoard
+func (h *Handler) serveFreeze(w http.ResponseWriter, r *http.Request, freeze bool) {
+	lb, freezable, ok := h.freezableBoard(w, r)
+	if !ok {
+		return
+	}
+
+	var status models.FreezeStatus
+	if freeze {
+		if !freezable.Freeze() {
+			http.Error(w, "Leaderboard is already frozen", http.StatusConflict)
+			return
+		}
+		status = freezable.FreezeStatus()
+		h.audit("board.freeze", lb.Metadata().Name, "", status)
+	} else {
+		// Taken first so the response says how many writes were published
+		status = freezable.FreezeStatus()
+		if !freezable.Unfreeze() {
+			http.Error(w, "Leaderboard is not frozen", http.StatusConflict)
+			return
+		}
+		h.audit("board.unfreeze", lb.Metadata().Name, "", status)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// GetFreezeStatus handles GET /api/leaderboard/freeze?board=name, so clients can tell
+// viewers the standings are frozen
+func (h *Handler) GetFreezeStatus(w http.ResponseWriter, r *http.Request) {
+	if _, freezable, ok := h.freezableBoard(w, r); ok {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(freezable.FreezeStatus())
+	}
+}
+
+// freezableBoard resolves the board parameter (the default board without one) to a
+// board that can be frozen, answering the request itself when there is none
+func (h *Handler) freezableBoard(w http.ResponseWriter, r *http.Request) (store.Store, store.FreezableStore, bool) {
+	lb := h.Leaderboard
+	if name := r.URL.Query().Get("board"); name != "" {
+		var found bool
+		if lb, found = h.Boards.Get(name); !found {
+			http.Error(w, "Leaderboard not found", http.StatusNotFound)
+			return nil, nil, false
+		}
+	}
+	freezable, ok := lb.(store.FreezableStore)
+	if !ok {
+		http.Error(w, "This leaderboard cannot be frozen", http.StatusNotImplemented)
+		return nil, nil, false
+	}
+	return lb, freezable, true
+}
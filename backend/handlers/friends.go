@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// GetSubsetLeaderboard handles POST /api/leaderboard/subset with a JSON array of
+// usernames: a mini-leaderboard of just those users, each with their rank within the
+// set and on the whole board. With ?friendsOf=username the stored friends list of that
+// user, plus the user, is ranked instead and the body may be empty.
+func (h *Handler) GetSubsetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	h.serveSubset(w, r, h.defaultBoard(r))
+}
+
+// GetBoardSubsetLeaderboard handles POST /api/leaderboards/{name}/leaderboard/subset
+func (h *Handler) GetBoardSubsetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveSubset(w, r, lb)
+	}
+}
+
+// serveSubset implements GetSubsetLeaderboard against a specific board
+func (h *Handler) serveSubset(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	var usernames []string
+	friendsOf := r.URL.Query().Get("friendsOf")
+	if friendsOf != "" {
+		usernames = append(h.Friends.Get(friendsOf), friendsOf)
+	} else if err := json.NewDecoder(r.Body).Decode(&usernames); err != nil || len(usernames) == 0 {
+		http.Error(w, "Body must be a non-empty JSON array of usernames", http.StatusBadRequest)
+		return
+	}
+	if len(usernames) > store.MaxSubsetSize {
+		http.Error(w, fmt.Sprintf("At most %d usernames can be ranked together", store.MaxSubsetSize), http.StatusBadRequest)
+		return
+	}
+
+	lb, _, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+	subsets, ok := lb.(store.SubsetStore)
+	if !ok {
+		http.Error(w, "This leaderboard cannot rank a subset of users", http.StatusNotImplemented)
+		return
+	}
+	entries, missing := subsets.GetSubset(usernames)
+
+	response := map[string]interface{}{
+		"entries":     entries,
+		"count":       len(entries),
+		"missing":     missing,
+		"scoreFormat": lb.Metadata().ScoreFormat,
+	}
+	if friendsOf != "" {
+		response["friendsOf"] = friendsOf
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetFriends handles GET /api/users/{username}/friends
+func (h *Handler) GetFriends(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("username")
+	friends := h.Friends.Get(username)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"username": username,
+		"friends":  friends,
+		"count":    len(friends),
+	})
+}
+
+// SetFriends handles PUT /api/users/{username}/friends with a JSON array of usernames,
+// replacing the user's stored friends list
+func (h *Handler) SetFriends(w http.ResponseWriter, r *http.Request) {
+	var friends []string
+	if err := json.NewDecoder(r.Body).Decode(&friends); err != nil {
+		http.Error(w, "Body must be a JSON array of usernames", http.StatusBadRequest)
+		return
+	}
+
+	username := r.PathValue("username")
+	list, err := h.Friends.Set(username, friends)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.audit("friends.set", "", username, list)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"username": username,
+		"friends":  list,
+		"count":    len(list),
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// UserHeader names the caller on /api/me routes. The server doesn't authenticate it
+// unless bearer tokens are required, when it is taken from the token's subject;
+// otherwise deployments put these routes behind a gateway that sets it from the session.
+const UserHeader = "X-Username"
+
+// me returns the calling user, writing a 401 when the request doesn't name one
+func me(w http.ResponseWriter, r *http.Request) (string, bool) {
+	username := r.Header.Get(UserHeader)
+	if username == "" {
+		http.Error(w, UserHeader+" header is required", http.StatusUnauthorized)
+		return "", false
+	}
+	return username, true
+}
+
+// goals returns the default board's goal store, writing a 501 when it has none
+func (h *Handler) goals(w http.ResponseWriter, r *http.Request) (store.Store, store.GoalStore, bool) {
+	lb := h.defaultBoard(r)
+	goals, ok := lb.(store.GoalStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not track goals", http.StatusNotImplemented)
+		return nil, nil, false
+	}
+	return lb, goals, true
+}
+
+// GetMyGoal handles GET /api/me/goal
+func (h *Handler) GetMyGoal(w http.ResponseWriter, r *http.Request) {
+	username, ok := me(w, r)
+	if !ok {
+		return
+	}
+	_, goals, ok := h.goals(w, r)
+	if !ok {
+		return
+	}
+
+	progress, found := goals.GetGoal(username)
+	if !found {
+		http.Error(w, "No goal set", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// SetMyGoal handles PUT /api/me/goal with a body like {"type": "rating", "target": 2500}
+// or {"type": "rank", "target": 100}. Reaching it records a goal_reached milestone.
+func (h *Handler) SetMyGoal(w http.ResponseWriter, r *http.Request) {
+	username, ok := me(w, r)
+	if !ok {
+		return
+	}
+	lb, goals, ok := h.goals(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Type   string `json:"type"`
+		Target int64  `json:"target"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Body must be a JSON object with type and target", http.StatusBadRequest)
+		return
+	}
+
+	progress, found, err := goals.SetGoal(username, req.Type, req.Target)
+	if !found {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, store.ErrInvalidGoal) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.audit("goal.set", lb.Metadata().Name, username, req)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// ClearMyGoal handles DELETE /api/me/goal
+func (h *Handler) ClearMyGoal(w http.ResponseWriter, r *http.Request) {
+	username, ok := me(w, r)
+	if !ok {
+		return
+	}
+	lb, goals, ok := h.goals(w, r)
+	if !ok {
+		return
+	}
+
+	if !goals.ClearGoal(username) {
+		http.Error(w, "No goal set", http.StatusNotFound)
+		return
+	}
+	h.audit("goal.clear", lb.Metadata().Name, username, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
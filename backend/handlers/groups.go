@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"leaderboard-api/store"
+	"net/http"
+	"strconv"
+)
+
+// CreateDuo handles POST /api/duos
+func (h *Handler) CreateDuo(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Members []string `json:"members"`
+		Rating  int64    `json:"rating"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Duos.AddGroup(req.Members, req.Rating)
+	if errors.Is(err, store.ErrGroupExists) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	group, _ := h.Duos.GetGroup(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(group)
+}
+
+// DeleteDuo handles DELETE /api/duos/{groupId}
+func (h *Handler) DeleteDuo(w http.ResponseWriter, r *http.Request) {
+	if !h.Duos.RemoveGroup(r.PathValue("groupId")) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateDuoRating handles PUT /api/duos/{groupId}/rating
+func (h *Handler) UpdateDuoRating(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Rating int64 `json:"rating"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("groupId")
+	if !h.Duos.UpdateGroupRating(id, req.Rating) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	group, _ := h.Duos.GetGroup(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(group)
+}
+
+// GetDuoLeaderboard handles GET /api/duos/leaderboard
+func (h *Handler) GetDuoLeaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	offset := 0
+
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	entries := h.Duos.GetLeaderboard(limit, offset)
+	total := h.Duos.GetTotalGroups()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries":     entries,
+		"groupSize":   h.Duos.Size(),
+		"scoreFormat": h.Duos.Metadata().ScoreFormat,
+		"totalGroups": total,
+		"limit":       limit,
+		"offset":      offset,
+		"hasMore":     offset+limit < total,
+	})
+}
+
+// SearchDuos handles GET /api/duos/search
+func (h *Handler) SearchDuos(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	results := h.Duos.SearchByMember(query, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": results,
+		"query":   query,
+		"count":   len(results),
+	})
+}
+
+// GetMemberDuos handles GET /api/duos/members/{username}
+func (h *Handler) GetMemberDuos(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("username")
+	groups := h.Duos.GetMemberGroups(username)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"username": username,
+		"groups":   groups,
+		"count":    len(groups),
+	})
+}
@@ -2,31 +2,181 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"leaderboard-api/ids"
+	"leaderboard-api/mirror"
+	"leaderboard-api/models"
+	"leaderboard-api/rating"
+	"leaderboard-api/secrets"
+	"leaderboard-api/simulator"
 	"leaderboard-api/store"
+	"leaderboard-api/webhook"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"sync/atomic"
 	"time"
 )
 
 // Handler holds dependencies for HTTP handlers
 type Handler struct {
-	Leaderboard *store.Leaderboard
+	Boards       *store.Manager
+	Leaderboard  store.Store // default board served by the single-board routes
+	Duos         *store.GroupLeaderboard
+	Teams        *store.TeamLeaderboard // nil when the default board is not in memory
+	Reign        *store.ReignTracker    // nil when the default board is not in memory
+	Friends      *store.FriendLists
+	Matches      *store.MatchStore
+	Seasons      *store.SeasonArchive // nil when the default board is not in memory
+	Streams      *StreamPolicy        // nil means every stream subscriber gets partner access
+	Secrets      *secrets.Manager
+	Audit        *store.AuditLog           // nil disables audit logging
+	ChangeLog    *store.ChangeLog          // nil unless every change to the default board is logged
+	Load         *StreamLoadMonitor        // nil keeps stream cadence fixed
+	Mirror       *mirror.Worker            // nil when no outbound mirror is configured
+	Migration    *store.DualStore          // nil unless the default board is being migrated
+	Consistency  *store.ConsistencyChecker // nil unless the default board is persisted
+	Snapshots    *store.Snapshotter        // nil unless the default board is persisted
+	StatsHistory *store.StatsRecorder      // nil when stats aren't sampled over time
+	Publisher    *store.Publisher          // nil when views are rebuilt on read
+	Milestones   *webhook.Dispatcher       // nil when no milestone webhook is configured
+	GoalHooks    *webhook.Dispatcher       // nil when no goal webhook is configured
+	Elo          rating.Elo                // rates matches submitted as a winner and loser
+	IDs          ids.Generator             // nil gives new users random UUIDs
+
+	// Load simulator and where its kill switch posts alerts; nil when the simulator
+	// isn't running or no alert webhook is configured
+	Simulator       *simulator.ScoreUpdater
+	SimulatorAlerts *webhook.Dispatcher
+
+	// Live SSE connections that accept subscription changes
+	subscriptions *streamRegistry
+
+	// Counts of the default board's changes, when it has a change feed
+	changes *changeCounter
+
+	// Serialized leaderboard pages shared by the REST, SSE and long-poll endpoints
+	frames *frameCache
+
+	// Shares stats and search responses between identical concurrent requests
+	reads *coalescer
+
+	// How long writes take to reach stream clients
+	propagation *propagation
+
+	// Bytes streamed before and after compression
+	compression *streamCompression
+
+	// Set while the API is read-only for maintenance (see maintenance.go)
+	maintenance atomic.Pointer[models.MaintenanceStatus]
+
+	// Streamed pages, encoded once per change and fanned out to every SSE connection
+	broadcast *pageBroadcaster
+
+	// Set once WarmUp has finished; GET /ready reports 503 until then
+	ready atomic.Bool
 }
 
-// NewHandler creates a new handler instance
-func NewHandler(lb *store.Leaderboard) *Handler {
-	return &Handler{Leaderboard: lb}
+// NewHandler creates a new handler instance; the single-board routes serve boards.Default()
+func NewHandler(boards *store.Manager, duos *store.GroupLeaderboard, matches *store.MatchStore, seasons *store.SeasonArchive) *Handler {
+	h := &Handler{
+		Boards:      boards,
+		Leaderboard: boards.Default(),
+		Duos:        duos,
+		Matches:     matches,
+		Seasons:     seasons,
+		Friends:     store.NewFriendLists(),
+
+		subscriptions: newStreamRegistry(),
+		frames:        newFrameCache(),
+		reads:         newCoalescer(),
+		propagation:   &propagation{},
+		compression:   &streamCompression{},
+	}
+	h.broadcast = newPageBroadcaster(h.frames)
+	if observable, ok := h.Leaderboard.(store.ObservableStore); ok {
+		h.changes = newChangeCounter(observable)
+	}
+	return h
+}
+
+// defaultBoard returns the board served by the single-board routes, attributing its
+// lock timings to the request's trace when there is one
+func (h *Handler) defaultBoard(r *http.Request) store.Store {
+	return attributed(r, withLockTrace(r, h.Leaderboard))
+}
+
+// attributed tags writes through lb as coming from the API caller behind r: the
+// X-Username header when set, else the client address
+func attributed(r *http.Request, lb store.Store) store.Store {
+	observable, ok := lb.(store.ObservableStore)
+	if !ok {
+		return lb
+	}
+	actor := r.Header.Get(UserHeader)
+	if actor == "" {
+		actor, _, _ = net.SplitHostPort(r.RemoteAddr)
+	}
+	return observable.WithSource(models.ChangeSourceAPI, actor)
+}
+
+// live returns a handle onto lb that reads through a freeze, for write handlers that
+// check and report the state they change. Writers see their own result; everyone else
+// keeps seeing the frozen standings.
+func live(lb store.Store) store.Store {
+	if freezable, ok := lb.(store.FreezableStore); ok {
+		return freezable.Live()
+	}
+	return lb
+}
+
+// withLockTrace attaches the request's lock trace to lb if both support it
+func withLockTrace(r *http.Request, lb store.Store) store.Store {
+	trace := store.LockTraceFrom(r.Context())
+	if traced, ok := lb.(store.LockTracedStore); ok && trace != nil {
+		return traced.WithLockTrace(trace)
+	}
+	return lb
+}
+
+// withRanking applies the ?ranking= query parameter to lb, writing a 400 for unknown
+// modes and a 501 if the store can't rank by it. It also returns the requested mode,
+// which is empty when the board's own mode applies.
+func withRanking(w http.ResponseWriter, r *http.Request, lb store.Store) (store.Store, string, bool) {
+	name := r.URL.Query().Get("ranking")
+	if name == "" {
+		return lb, "", true
+	}
+	mode, err := store.ParseRankingMode(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, "", false
+	}
+	ranking, ok := lb.(store.RankingStore)
+	if !ok {
+		if mode == models.RankingDense {
+			return lb, "", true
+		}
+		http.Error(w, "This leaderboard only supports dense ranking", http.StatusNotImplemented)
+		return nil, "", false
+	}
+	return ranking.WithRanking(mode), mode, true
 }
 
 // GetLeaderboard handles GET /api/leaderboard
 func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
+	h.serveLeaderboard(w, r, h.defaultBoard(r))
+}
+
+// pageParams parses the limit (1-100, default 50) and offset query parameters
+func pageParams(r *http.Request) (limit, offset int) {
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
 
-	limit := 50
-	offset := 0
+	limit = 50
+	offset = 0
 
 	if limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
@@ -39,24 +189,285 @@ func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 			offset = o
 		}
 	}
+	return limit, offset
+}
+
+// serveLeaderboard implements GetLeaderboard against a specific board
+func (h *Handler) serveLeaderboard(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	limit, offset := pageParams(r)
+
+	if r.URL.Query().Has("atPercentile") {
+		h.servePercentilePage(w, r, lb)
+		return
+	}
+
+	if r.URL.Query().Has("cursor") {
+		h.serveCursorPage(w, r, lb, limit)
+		return
+	}
+
+	if r.URL.Query().Has("country") || r.URL.Query().Has("tier") {
+		h.serveSegmentLeaderboard(w, r, lb, limit, offset)
+		return
+	}
+
+	if window := r.URL.Query().Get("window"); window != "" && window != "alltime" {
+		windowed, ok := lb.(store.WindowedStore)
+		if !ok {
+			http.Error(w, "This leaderboard does not track windowed gains", http.StatusNotImplemented)
+			return
+		}
+		entries, total, err := windowed.GetWindowLeaderboard(window, limit, offset)
+		if err != nil {
+			http.Error(w, "window must be one of daily, weekly, monthly, alltime", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entries":     entries,
+			"window":      window,
+			"rankedUsers": total,
+			"limit":       limit,
+			"offset":      offset,
+			"hasMore":     offset+limit < total,
+		})
+		return
+	}
 
-	entries := h.Leaderboard.GetLeaderboard(limit, offset)
-	stats := h.Leaderboard.GetStats()
+	lb, ranking, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+	data, version, baseline := h.frames.page(lb, ranking, limit, offset)
+
+	// The page is a function of the version and baseline, so pollers holding it get a 304
+	if notModified(w, r, pageETag(version, baseline)) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
+
+// serveCursorPage implements GetLeaderboard with ?cursor=, paging by the position of
+// the last entry seen rather than an offset. An empty cursor starts at the top; each
+// page carries the nextCursor to pass back, omitted on the last page.
+func (h *Handler) serveCursorPage(w http.ResponseWriter, r *http.Request, lb store.Store, limit int) {
+	query := r.URL.Query()
+	if query.Has("offset") || query.Has("country") || query.Has("tier") || query.Has("window") {
+		http.Error(w, "cursor cannot be combined with offset, country, tier or window", http.StatusBadRequest)
+		return
+	}
+
+	lb, _, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+	paged, ok := lb.(store.CursorStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not support cursor pagination", http.StatusNotImplemented)
+		return
+	}
+
+	version := lb.Version()
+	entries, next, err := paged.GetLeaderboardAfter(query.Get("cursor"), limit)
+	if err != nil {
+		http.Error(w, "cursor is not valid for this leaderboard", http.StatusBadRequest)
+		return
+	}
 
 	response := map[string]interface{}{
-		"entries":    entries,
-		"totalUsers": stats.TotalUsers,
-		"limit":      limit,
-		"offset":     offset,
-		"hasMore":    offset+limit < stats.TotalUsers,
+		"entries":     entries,
+		"totalUsers":  lb.GetTotalUsers(),
+		"limit":       limit,
+		"hasMore":     next != "",
+		"scoreFormat": lb.Metadata().ScoreFormat,
+		"version":     version,
+	}
+	if next != "" {
+		response["nextCursor"] = next
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// PollLeaderboard handles GET /api/leaderboard/poll?since=<version>. It answers as soon
+// as the board moves past since, or with 204 No Content once timeoutMs (default
+// 25000, at most 60000) passes without a change.
+func (h *Handler) PollLeaderboard(w http.ResponseWriter, r *http.Request) {
+	h.servePoll(w, r, h.defaultBoard(r))
+}
+
+// servePoll implements PollLeaderboard against a specific board
+func (h *Handler) servePoll(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	limit, offset := pageParams(r)
+	lb, ranking, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+
+	since, err := strconv.ParseUint(r.URL.Query().Get("since"), 10, 64)
+	if err != nil {
+		http.Error(w, "since must be a store version", http.StatusBadRequest)
+		return
+	}
+
+	timeout := 25 * time.Second
+	if ms, err := strconv.Atoi(r.URL.Query().Get("timeoutMs")); err == nil && ms > 0 && ms <= 60000 {
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for lb.Version() <= since {
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	data, _, _ := h.frames.page(lb, ranking, limit, offset)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
+
+// GetBoardMetadata handles GET /api/leaderboard/meta
+func (h *Handler) GetBoardMetadata(w http.ResponseWriter, r *http.Request) {
+	h.serveMetadata(w, r, h.defaultBoard(r))
+}
+
+// serveMetadata implements GetBoardMetadata against a specific board
+func (h *Handler) serveMetadata(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lb.Metadata())
+}
+
+// GetRankRange handles GET /api/leaderboard/range
+func (h *Handler) GetRankRange(w http.ResponseWriter, r *http.Request) {
+	h.serveRankRange(w, r, h.defaultBoard(r))
+}
+
+// serveRankRange implements GetRankRange against a specific board
+func (h *Handler) serveRankRange(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	fromRank, err := strconv.Atoi(r.URL.Query().Get("fromRank"))
+	if err != nil || fromRank < 1 {
+		http.Error(w, "fromRank must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	toRank, err := strconv.Atoi(r.URL.Query().Get("toRank"))
+	if err != nil || toRank < fromRank {
+		http.Error(w, "toRank must be an integer >= fromRank", http.StatusBadRequest)
+		return
+	}
+
+	if toRank-fromRank >= 1000 {
+		http.Error(w, "Rank range cannot span more than 1000 ranks", http.StatusBadRequest)
+		return
+	}
+
+	lb, _, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+
+	entries := lb.GetRankRange(fromRank, toRank)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries":  entries,
+		"fromRank": fromRank,
+		"toRank":   toRank,
+		"count":    len(entries),
+	})
+}
+
+// GetUsersAtRank handles GET /api/leaderboard/rank/{rank}?limit=100&offset=0: every user
+// sharing a rank, with how many there are. Under dense ranking (the default) that is
+// everyone on the same rating.
+func (h *Handler) GetUsersAtRank(w http.ResponseWriter, r *http.Request) {
+	h.serveUsersAtRank(w, r, h.defaultBoard(r))
+}
+
+// serveUsersAtRank implements GetUsersAtRank against a specific board
+func (h *Handler) serveUsersAtRank(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	rank, err := strconv.Atoi(r.PathValue("rank"))
+	if err != nil || rank < 1 {
+		http.Error(w, "rank must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	limit, offset := 100, 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	lb, _, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+	// The view's ranks are searched, not scanned, for the tie group
+	tied := lb.GetRankRange(rank, rank)
+	if len(tied) == 0 {
+		http.Error(w, "No users hold that rank", http.StatusNotFound)
+		return
+	}
+
+	entries := tied[min(offset, len(tied)):min(offset+limit, len(tied))]
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rank":    rank,
+		"rating":  tied[0].Rating,
+		"display": tied[0].Display,
+		"count":   len(tied),
+		"entries": entries,
+		"limit":   limit,
+		"offset":  offset,
+		"hasMore": offset+limit < len(tied),
+	})
+}
+
+// GetRatingRank handles GET /api/leaderboard/rank?rating=1500
+func (h *Handler) GetRatingRank(w http.ResponseWriter, r *http.Request) {
+	h.serveRatingRank(w, r, h.defaultBoard(r))
+}
+
+// serveRatingRank implements GetRatingRank against a specific board: the standard
+// competition rank a rating holds, or would hold if submitted now
+func (h *Handler) serveRatingRank(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	rating, err := strconv.ParseInt(r.URL.Query().Get("rating"), 10, 64)
+	if err != nil {
+		http.Error(w, "rating must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rating":          rating,
+		"competitionRank": lb.CompetitionRank(rating),
+		"totalUsers":      lb.GetTotalUsers(),
+	})
+}
+
 // SearchUsers handles GET /api/users/search
 func (h *Handler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	h.serveSearch(w, r, h.defaultBoard(r))
+}
+
+// serveSearch implements SearchUsers against a specific board
+func (h *Handler) serveSearch(w http.ResponseWriter, r *http.Request, lb store.Store) {
 	query := r.URL.Query().Get("q")
 	limitStr := r.URL.Query().Get("limit")
 
@@ -76,25 +487,76 @@ func (h *Handler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results := h.Leaderboard.SearchUsers(query, limit)
+	lb, ranking, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+	key := fmt.Sprintf("search\x00%s\x00%s\x00%d\x00%d\x00%s", lb.Metadata().Name, ranking, lb.Version(), limit, query)
+	data := h.reads.do(key, func() []byte {
+		results := lb.SearchUsers(query, limit)
+		data, _ := json.Marshal(map[string]interface{}{
+			"results": results,
+			"query":   query,
+			"count":   len(results),
+		})
+		return data
+	})
 
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
+
+// SuggestUsers handles GET /api/users/suggest (lightweight autocomplete)
+func (h *Handler) SuggestUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+
+	limit := 10
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 50 {
+		limit = l
+	}
+
+	suggestions := []string{}
+	if query != "" {
+		suggestions = h.defaultBoard(r).SuggestUsernames(query, limit)
+	}
+
+	// Usernames change far less often than ratings, so let clients and CDNs cache hard
+	w.Header().Set("Cache-Control", "public, max-age=60, stale-while-revalidate=300")
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"results": results,
-		"query":   query,
-		"count":   len(results),
+		"suggestions": suggestions,
+		"query":       query,
 	})
 }
 
 // GetUser handles GET /api/users/{username}
 func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+	h.serveUser(w, r, h.defaultBoard(r))
+}
+
+// lookupUser finds a user by username regardless of case where the store can, as
+// search does
+func lookupUser(lb store.Store, username string) (*models.SearchResult, bool) {
+	if folding, ok := lb.(store.CaseInsensitiveStore); ok {
+		return folding.LookupUser(username)
+	}
+	return lb.GetUserRank(username)
+}
+
+// serveUser implements GetUser against a specific board
+func (h *Handler) serveUser(w http.ResponseWriter, r *http.Request, lb store.Store) {
 	username := r.PathValue("username")
 	if username == "" {
 		http.Error(w, "Username required", http.StatusBadRequest)
 		return
 	}
 
-	result, found := h.Leaderboard.GetUserRank(username)
+	lb, _, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+	result, found := lookupUser(lb, username)
 	if !found {
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
@@ -104,12 +566,374 @@ func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
+// AddUser handles POST /api/users, adding a user to the default board. It answers 201
+// with the user as ranked on arrival, or 409 if the username is taken.
+func (h *Handler) AddUser(w http.ResponseWriter, r *http.Request) {
+	h.serveAddUser(w, r, h.defaultBoard(r), "/api/users/")
+}
+
+// serveAddUser implements AddUser against a specific board, whose users live under
+// location. On boards with a composite score the rating is computed from the
+// submitted metrics instead. The body may also carry the user's country, avatarUrl and
+// displayName, at the top level or in a metadata object.
+func (h *Handler) serveAddUser(w http.ResponseWriter, r *http.Request, lb store.Store, location string) {
+	// Writers check and report live state even while the board is frozen
+	lb = live(lb)
+
+	var req struct {
+		Username string             `json:"username"`
+		Rating   int64              `json:"rating"`
+		Scores   map[string]int     `json:"scores"`
+		Metrics  map[string]float64 `json:"metrics"`
+		Metadata *models.Profile    `json:"metadata"`
+		models.Profile
+
+		// ID the caller's game backend knows the user by, for GET /api/external-ids/{id}
+		ExternalID string `json:"externalId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Username == "" {
+		http.Error(w, "Body must contain a username", http.StatusBadRequest)
+		return
+	}
+	if err := store.ValidateUsername(req.Username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Metadata != nil {
+		req.Profile = *req.Metadata
+	}
+	profile, err := store.NormalizeProfile(req.Profile)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	meta := lb.Metadata()
+	weights := meta.Metrics
+	if len(req.Metrics) > 0 && len(weights) == 0 {
+		http.Error(w, store.ErrNotComposite.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := lb.(store.ExternalIDStore); req.ExternalID != "" && !ok {
+		http.Error(w, "This leaderboard does not support external IDs", http.StatusNotImplemented)
+		return
+	}
+
+	user := &models.User{
+		ID:         h.newUserID(),
+		ExternalID: req.ExternalID,
+		Username:   req.Username,
+		Rating:     req.Rating,
+		Scores:     req.Scores,
+		Profile:    profile,
+	}
+	if len(weights) > 0 {
+		if err := store.CheckMetrics(weights, req.Metrics); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		user.Metrics = req.Metrics
+		user.Rating = store.CompositeRating(meta, req.Metrics)
+	}
+	if !allowed(w, lb, user.Username, nil, user.Rating) {
+		return
+	}
+	switch err := lb.AddUser(user); {
+	case errors.Is(err, store.ErrUserExists):
+		http.Error(w, "User already exists", http.StatusConflict)
+		return
+	case errors.Is(err, store.ErrExternalIDTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, store.ErrRatingOverflow):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Failed to add user", http.StatusInternalServerError)
+		return
+	}
+	result, found := lb.GetUserRank(req.Username)
+	if !found {
+		// A full board evicts its lowest-rated user, which was the newcomer
+		http.Error(w, "Rating is too low for a leaderboard at capacity", http.StatusConflict)
+		return
+	}
+	h.audit("user.add", lb.Metadata().Name, req.Username, map[string]interface{}{
+		"rating":     result.Rating,
+		"scores":     req.Scores,
+		"metrics":    req.Metrics,
+		"profile":    profile,
+		"id":         user.ID,
+		"externalId": req.ExternalID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", location+url.PathEscape(req.Username))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// IncrementUserRating handles PATCH /api/users/{username}/rating with a body of
+// {"delta": 25}, for callers that know how much a match moved the rating but not the
+// rating itself
+func (h *Handler) IncrementUserRating(w http.ResponseWriter, r *http.Request) {
+	h.serveIncrementRating(w, r, h.defaultBoard(r))
+}
+
+// serveIncrementRating implements IncrementUserRating against a specific board. It
+// answers with the user's new rating and rank.
+func (h *Handler) serveIncrementRating(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	lb = live(lb)
+	incrementing, ok := lb.(store.IncrementingStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not support relative rating changes", http.StatusNotImplemented)
+		return
+	}
+
+	var req struct {
+		Delta *int64 `json:"delta"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Delta == nil {
+		http.Error(w, "Body must contain a delta", http.StatusBadRequest)
+		return
+	}
+	if len(lb.Metadata().Metrics) > 0 {
+		http.Error(w, "Ratings on this leaderboard are computed from metrics; submit them instead", http.StatusBadRequest)
+		return
+	}
+
+	username := r.PathValue("username")
+	rating, found, err := incrementing.IncrementRating(username, *req.Delta)
+	switch {
+	case refused(w, err):
+		return
+	case errors.Is(err, store.ErrRatingOverflow):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "Failed to update rating", http.StatusInternalServerError)
+		return
+	case !found:
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	h.audit("rating.increment", lb.Metadata().Name, username, map[string]int64{
+		"delta": *req.Delta,
+		"after": rating,
+	})
+
+	result, found := lb.GetUserRank(username)
+	if !found {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetUserHistory handles GET /api/users/{username}/history?limit=100
+func (h *Handler) GetUserHistory(w http.ResponseWriter, r *http.Request) {
+	h.serveHistory(w, r, h.defaultBoard(r))
+}
+
+// serveHistory implements GetUserHistory against a specific board
+func (h *Handler) serveHistory(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	history, ok := lb.(store.HistoryStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not keep rating history", http.StatusNotImplemented)
+		return
+	}
+
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+
+	username := r.PathValue("username")
+	changes, found := history.GetHistory(username, limit)
+	if !found {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"username": username,
+		"history":  changes,
+		"count":    len(changes),
+	})
+}
+
+// UpdateUserScores handles PUT /api/users/{username}/scores
+func (h *Handler) UpdateUserScores(w http.ResponseWriter, r *http.Request) {
+	h.serveUpdateScores(w, r, h.defaultBoard(r))
+}
+
+// serveUpdateScores implements UpdateUserScores against a specific board
+func (h *Handler) serveUpdateScores(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	lb = live(lb)
+	var req struct {
+		Scores map[string]int `json:"scores"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Scores) == 0 {
+		http.Error(w, "Body must contain a non-empty scores object", http.StatusBadRequest)
+		return
+	}
+
+	username := r.PathValue("username")
+	if !lb.UpdateScores(username, req.Scores) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	h.audit("scores.update", lb.Metadata().Name, username, req.Scores)
+
+	result, _ := lb.GetUserRank(username)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// UpdateUserProfile handles PUT /api/users/{username}/profile. Fields left out of the
+// body keep their value; an empty string clears one.
+func (h *Handler) UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
+	h.serveUpdateProfile(w, r, h.defaultBoard(r))
+}
+
+// serveUpdateProfile implements UpdateUserProfile against a specific board
+func (h *Handler) serveUpdateProfile(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	lb = live(lb)
+	profiles, ok := lb.(store.ProfileStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not store user profiles", http.StatusNotImplemented)
+		return
+	}
+
+	var req models.ProfileUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Body must be a JSON object with country, avatarUrl or displayName", http.StatusBadRequest)
+		return
+	}
+	if req.Country == nil && req.AvatarURL == nil && req.DisplayName == nil {
+		http.Error(w, "Body must set at least one of country, avatarUrl or displayName", http.StatusBadRequest)
+		return
+	}
+
+	username := r.PathValue("username")
+	updated, err := profiles.UpdateProfile(username, req)
+	if errors.Is(err, store.ErrDisplayNameTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !updated {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	h.audit("profile.update", lb.Metadata().Name, username, req)
+
+	result, _ := lb.GetUserRank(username)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 // GetStats handles GET /api/stats
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
-	stats := h.Leaderboard.GetStats()
+	h.serveStats(w, r, h.defaultBoard(r))
+}
+
+// serveStats implements GetStats against a specific board
+func (h *Handler) serveStats(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	version := lb.Version()
+	if notModified(w, r, versionETag(version)) {
+		return
+	}
+	key := fmt.Sprintf("stats\x00%s\x00%d", lb.Metadata().Name, version)
+	data := h.reads.do(key, func() []byte {
+		data, _ := json.Marshal(lb.GetStats())
+		return data
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
+
+// GetStatsHistory handles GET /api/stats/history?duration=1h: the default board's
+// stats sampled over the trailing duration, oldest first, for activity charts
+func (h *Handler) GetStatsHistory(w http.ResponseWriter, r *http.Request) {
+	if h.StatsHistory == nil {
+		http.Error(w, "Stats history is not being recorded", http.StatusNotImplemented)
+		return
+	}
+
+	duration := time.Hour
+	if d := r.URL.Query().Get("duration"); d != "" {
+		parsed, err := time.ParseDuration(d)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "duration must be a positive duration such as 15m or 1h", http.StatusBadRequest)
+			return
+		}
+		duration = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"duration":    duration.String(),
+		"intervalMs":  h.StatsHistory.Interval().Milliseconds(),
+		"retentionMs": h.StatsHistory.Retention().Milliseconds(),
+		"samples":     h.StatsHistory.History(time.Now().Add(-duration)),
+	})
+}
+
+// GetMetrics handles GET /api/metrics
+func (h *Handler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics := map[string]interface{}{
+		"storeVersion": h.Leaderboard.Version(),
+	}
+	if cached, ok := h.Leaderboard.(store.CachingStore); ok {
+		metrics["searchCache"] = cached.SearchCacheStats()
+	}
+	if traced, ok := h.Leaderboard.(store.LockTracedStore); ok {
+		metrics["locks"] = traced.LockStats()
+	}
+	if h.Load != nil {
+		metrics["streamLoad"], _ = h.Load.Current()
+	}
+	metrics["frameCache"] = h.frames.stats()
+	metrics["broadcast"] = h.broadcast.stats()
+	metrics["coalescing"] = h.reads.stats()
+	metrics["propagation"] = h.propagation.stats()
+	metrics["streamCompression"] = h.compression.stats()
+	metrics["maintenance"] = h.maintenanceStatus()
+	if h.changes != nil {
+		metrics["changes"] = h.changes.stats()
+	}
+	if h.Mirror != nil {
+		metrics["mirror"] = h.Mirror.Stats()
+	}
+	if h.Consistency != nil {
+		metrics["consistency"] = h.Consistency.Stats()
+	}
+	if h.Publisher != nil {
+		metrics["publisher"] = h.Publisher.Stats()
+	}
+	if h.Milestones != nil {
+		metrics["milestoneWebhook"] = h.Milestones.Stats()
+	}
+	if h.GoalHooks != nil {
+		metrics["goalWebhook"] = h.GoalHooks.Stats()
+	}
+	if h.SimulatorAlerts != nil {
+		metrics["simulatorWebhook"] = h.SimulatorAlerts.Stats()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+	json.NewEncoder(w).Encode(metrics)
 }
 
 // HealthCheck handles GET /health
@@ -118,8 +942,57 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
 }
 
-// StreamUpdates handles GET /api/stream (Server-Sent Events for live updates)
+// warmPageSizes are the top-of-board page sizes pre-serialized by WarmUp
+var warmPageSizes = []int{10, 50, 100}
+
+// WarmUp builds every board's views and indexes and serializes the default board's
+// top pages, then marks the handler ready. Call it before serving traffic so the
+// first requests after boot don't pay for the rebuilds.
+func (h *Handler) WarmUp() {
+	for _, summary := range h.Boards.List() {
+		if lb, found := h.Boards.Get(summary.Name); found {
+			if warming, ok := lb.(store.WarmingStore); ok {
+				warming.WarmUp()
+			}
+		}
+	}
+	for _, limit := range warmPageSizes {
+		h.frames.page(h.Leaderboard, "", limit, 0)
+	}
+	h.ready.Store(true)
+}
+
+// ReadyCheck handles GET /ready: 503 until WarmUp has finished, for load balancers
+// that should hold traffic back until then
+func (h *Handler) ReadyCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !h.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"status": "warming"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
+}
+
+// StreamUpdates handles GET /api/stream (Server-Sent Events for live updates).
+// The first event carries a connection ID that can be used with
+// POST /api/stream/{connectionId}/subscription to change what the stream carries.
+// With ?country=XX or ?tier=name the stream is scoped to that segment: it carries the
+// segment's standings only when they change, and only its users' series events.
 func (h *Handler) StreamUpdates(w http.ResponseWriter, r *http.Request) {
+	seg, err := parseSegment(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lb := h.defaultBoard(r)
+	if !seg.all() {
+		if _, _, err := seg.page(lb, 1, 0); err != nil {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
@@ -130,24 +1003,138 @@ func (h *Handler) StreamUpdates(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 		return
 	}
+	stream := h.compressStream(w, r, flusher)
+	defer stream.Close()
+	w, flusher = stream, stream
+
+	// The caller's access tier bounds the page they may stream and how often it refreshes
+	access := h.Streams.Resolve(r)
+	sub := streamSubscription{Limit: 50, IntervalMs: access.IntervalMs, segment: seg}
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		sub.Limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		sub.Offset = o
+	}
+	sub = sub.clamp(access)
+
+	conn := h.subscriptions.register(access, stream)
+	defer h.subscriptions.unregister(conn.id)
+
+	connected, _ := json.Marshal(map[string]interface{}{
+		"connectionId": conn.id,
+		"access":       access,
+		"subscription": sub,
+	})
+	fmt.Fprintf(w, "event: connected\ndata: %s\n\n", connected)
+	flusher.Flush()
+
+	// Under load every stream is slowed down; clients hear about it in cadence events
+	h.Load.enter()
+	defer h.Load.leave()
+	load, loadChanged := h.Load.Current()
+	if load.Degraded {
+		writeCadence(w, flusher, load, load.Interval(sub.interval()))
+	}
 
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := time.NewTicker(load.Interval(sub.interval()))
 	defer ticker.Stop()
 
+	// Only forward series events that happen after the client connected
+	series, hasSeries := lb.(store.SeriesStore)
+	var lastSeriesSeq uint64
+	if hasSeries {
+		_, lastSeriesSeq = series.SeriesEventsSince(0)
+	}
+	milestones, hasMilestones := lb.(store.MilestoneStore)
+	var lastMilestoneSeq uint64
+	if hasMilestones {
+		_, lastMilestoneSeq = milestones.MilestoneEventsSince(0)
+	}
+
+	// Segment pages are only sent when their entries change, so movement elsewhere on
+	// the board doesn't reach the client
+	var lastSegmentPage []byte
+
+	// Plain pages come from the broadcaster; the latest one is resent on every tick
+	var pageFrames <-chan pageUpdate
+	var latestPage pageUpdate
+	unfollow := func() {}
+	follow := func() {
+		unfollow()
+		pageFrames, latestPage, unfollow = nil, pageUpdate{}, func() {}
+		if sub.segment.all() && sub.Query == "" {
+			pageFrames, unfollow = h.broadcast.subscribe(h.Leaderboard, sub.Limit, sub.Offset)
+		}
+	}
+	follow()
+	defer func() { unfollow() }()
+
+	// Frames that bring new writes are followed by a latency event
+	clock := newDeliveryClock(h.Leaderboard, h.propagation)
+
 	for {
 		select {
+		case <-loadChanged:
+			load, loadChanged = h.Load.Current()
+			ticker.Reset(load.Interval(sub.interval()))
+			writeCadence(w, flusher, load, load.Interval(sub.interval()))
+		case latestPage = <-pageFrames:
+		case next := <-conn.changes:
+			sub = next
+			lastSegmentPage = nil
+			clock.reset()
+			follow()
+			ticker.Reset(load.Interval(sub.interval()))
+			data, _ := json.Marshal(sub)
+			fmt.Fprintf(w, "event: subscription\ndata: %s\n\n", data)
+			flusher.Flush()
 		case <-ticker.C:
-			entries := h.Leaderboard.GetLeaderboard(50, 0)
-			stats := h.Leaderboard.GetStats()
-			response := map[string]interface{}{
-				"entries":    entries,
-				"totalUsers": stats.TotalUsers,
-				"limit":      50,
-				"offset":     0,
-				"hasMore":    50 < stats.TotalUsers,
+			if hasSeries {
+				var events []models.SeriesEvent
+				events, lastSeriesSeq = series.SeriesEventsSince(lastSeriesSeq)
+				for _, event := range events {
+					if !sub.includes(lb, event.Username) {
+						continue
+					}
+					data, _ := json.Marshal(event)
+					fmt.Fprintf(w, "event: series\ndata: %s\n\n", data)
+				}
+			}
+			if hasMilestones {
+				var events []models.MilestoneEvent
+				events, lastMilestoneSeq = milestones.MilestoneEventsSince(lastMilestoneSeq)
+				for _, event := range events {
+					if !sub.includes(lb, event.Username) {
+						continue
+					}
+					data, _ := json.Marshal(event)
+					fmt.Fprintf(w, "event: milestone\ndata: %s\n\n", data)
+				}
+			}
+
+			var data []byte
+			version := latestPage.version
+			if !sub.segment.all() && sub.Query == "" {
+				version = lb.Version()
+				data = segmentFrame(lb, sub, &lastSegmentPage)
+			} else if sub.Query != "" {
+				version = lb.Version()
+				results := lb.SearchUsers(sub.Query, sub.Limit)
+				data, _ = json.Marshal(map[string]interface{}{
+					"results": results,
+					"query":   sub.Query,
+					"count":   len(results),
+				})
+			} else {
+				data = latestPage.data
+			}
+			if data != nil {
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				clock.delivered(w, version)
+			} else {
+				clock.skip(version)
 			}
-			data, _ := json.Marshal(response)
-			fmt.Fprintf(w, "data: %s\n\n", data)
 			flusher.Flush()
 		case <-r.Context().Done():
 			return
@@ -173,14 +1160,38 @@ func (h *Handler) StreamSearchUpdates(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 		return
 	}
+	stream := h.compressStream(w, r, flusher)
+	defer stream.Close()
+	w, flusher = stream, stream
+
+	access := h.Streams.Resolve(r)
+	limit := 50
+	if limit > access.MaxLimit {
+		limit = access.MaxLimit
+	}
+
+	lb := h.defaultBoard(r)
+
+	h.Load.enter()
+	defer h.Load.leave()
+	load, loadChanged := h.Load.Current()
+	if load.Degraded {
+		writeCadence(w, flusher, load, load.Interval(access.Interval))
+	}
 
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := time.NewTicker(load.Interval(access.Interval))
 	defer ticker.Stop()
+	clock := newDeliveryClock(h.Leaderboard, h.propagation)
 
 	for {
 		select {
+		case <-loadChanged:
+			load, loadChanged = h.Load.Current()
+			ticker.Reset(load.Interval(access.Interval))
+			writeCadence(w, flusher, load, load.Interval(access.Interval))
 		case <-ticker.C:
-			results := h.Leaderboard.SearchUsers(query, 50)
+			version := lb.Version()
+			results := lb.SearchUsers(query, limit)
 			response := map[string]interface{}{
 				"results": results,
 				"query":   query,
@@ -188,6 +1199,7 @@ func (h *Handler) StreamSearchUpdates(w http.ResponseWriter, r *http.Request) {
 			}
 			data, _ := json.Marshal(response)
 			fmt.Fprintf(w, "data: %s\n\n", data)
+			clock.delivered(w, version)
 			flusher.Flush()
 		case <-r.Context().Done():
 			return
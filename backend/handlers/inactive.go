@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/store"
+	"net/http"
+	"strconv"
+)
+
+// ListInactiveUsers handles GET /api/admin/inactive?limit=50&offset=0: users evicted
+// from the default board for inactivity and kept in its archive
+func (h *Handler) ListInactiveUsers(w http.ResponseWriter, r *http.Request) {
+	archive, ok := h.Leaderboard.(store.InactiveStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not archive inactive users", http.StatusNotImplemented)
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	users, total := archive.InactiveUsers(limit, offset)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"users":   users,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+		"hasMore": offset+limit < total,
+	})
+}
+
+// RestoreInactiveUser handles POST /api/admin/inactive/{username}/restore
+func (h *Handler) RestoreInactiveUser(w http.ResponseWriter, r *http.Request) {
+	archive, ok := h.Leaderboard.(store.InactiveStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not archive inactive users", http.StatusNotImplemented)
+		return
+	}
+
+	username := r.PathValue("username")
+	if !archive.RestoreUser(username) {
+		http.Error(w, "No inactive user by that name", http.StatusNotFound)
+		return
+	}
+	h.audit("user.restore", h.Leaderboard.Metadata().Name, username, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"restored": username,
+	})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"leaderboard-api/secrets"
+	"net/http"
+)
+
+// RotateKeysRequest is the body of POST /api/admin/keys/rotate
+type RotateKeysRequest struct {
+	// Name of the key list to add a generated key to, such as api_keys; empty reloads
+	// every secret from its provider instead (e.g. after updating Vault or the secrets
+	// dir). Signing keys and credentials can only be changed through the provider.
+	Name string `json:"name"`
+
+	// Key the new one replaces, which stays valid for the grace period; empty keeps
+	// every existing key
+	Replace string `json:"replace,omitempty"`
+}
+
+// RotateKeys handles POST /api/admin/keys/rotate. It is only served when the admin
+// routes need a credential, as it hands out new client keys.
+func (h *Handler) RotateKeys(w http.ResponseWriter, r *http.Request) {
+	if h.Secrets == nil {
+		http.Error(w, "Secrets management is not configured", http.StatusNotFound)
+		return
+	}
+
+	var req RotateKeysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if req.Name == "" {
+		rotated, err := h.Secrets.Reload()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		h.audit("keys.reload", "", "", rotated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"rotated": rotated,
+		})
+		return
+	}
+
+	value, err := h.Secrets.Rotate(req.Name, req.Replace)
+	switch {
+	case errors.Is(err, secrets.ErrNotFound):
+		http.Error(w, "Unknown secret", http.StatusNotFound)
+		return
+	case errors.Is(err, secrets.ErrNotRotatable):
+		http.Error(w, "Only client key lists can be rotated here; change "+req.Name+" with its provider", http.StatusBadRequest)
+		return
+	case errors.Is(err, secrets.ErrKeyNotListed):
+		http.Error(w, "The key to replace is not in "+req.Name, http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.audit("keys.rotate", "", req.Name, nil)
+
+	// The generated value is only returned once; store it with the provider to keep it across restarts
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rotated": []string{req.Name},
+		"value":   value,
+	})
+}
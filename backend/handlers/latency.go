@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"sync"
+	"time"
+)
+
+// propagationBuckets are the upper bounds of the write-to-delivery histogram
+var propagationBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// propagation measures how long writes take to reach stream clients: from the moment
+// the store reached a version to the moment the first frame carrying it was written
+// to a connection. Each delivery counts its oldest new write, so the figures are the
+// worst any write in the frame saw.
+type propagation struct {
+	mu    sync.Mutex
+	count uint64
+	sum   time.Duration
+	max   time.Duration
+	hist  [9]uint64 // one bucket per propagationBuckets entry, plus overflow
+}
+
+func (p *propagation) observe(latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.count++
+	p.sum += latency
+	if latency > p.max {
+		p.max = latency
+	}
+	bucket := len(propagationBuckets)
+	for i, bound := range propagationBuckets {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	p.hist[bucket]++
+}
+
+// stats summarizes the deliveries so far. Percentiles are bucket upper bounds.
+func (p *propagation) stats() models.PropagationStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := models.PropagationStats{
+		Deliveries: p.count,
+		P50Ms:      durationMs(p.quantile(0.5)),
+		P99Ms:      durationMs(p.quantile(0.99)),
+		MaxMs:      durationMs(p.max),
+	}
+	if p.count > 0 {
+		stats.AvgMs = durationMs(p.sum / time.Duration(p.count))
+	}
+	return stats
+}
+
+func (p *propagation) quantile(q float64) time.Duration {
+	if p.count == 0 {
+		return 0
+	}
+	target := uint64(float64(p.count)*q + 0.5)
+	var seen uint64
+	for i, n := range p.hist {
+		seen += n
+		if seen >= target && i < len(propagationBuckets) {
+			return min(propagationBuckets[i], p.max)
+		}
+	}
+	return p.max
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// deliveryClock follows the versions one stream connection has been sent, reporting
+// the propagation latency of each frame that brings it newer writes
+type deliveryClock struct {
+	clock   store.StampedStore // nil when the board can't tell when versions were reached
+	metrics *propagation
+	last    uint64 // newest version delivered; 0 until the first frame
+}
+
+func newDeliveryClock(lb store.Store, metrics *propagation) *deliveryClock {
+	clock, _ := lb.(store.StampedStore)
+	return &deliveryClock{clock: clock, metrics: metrics}
+}
+
+// delivered records that a frame of version was just written, and writes a latency
+// event to w when it carried writes the connection hadn't seen. The first frame only
+// sets the starting point: the writes in it predate the connection.
+func (dc *deliveryClock) delivered(w io.Writer, version uint64) {
+	if version <= dc.last {
+		return
+	}
+	first := dc.last == 0
+	oldest := dc.last + 1
+	dc.last = version
+	if first || dc.clock == nil {
+		return
+	}
+
+	writtenAt, ok := dc.clock.VersionTime(oldest)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	latency := now.Sub(writtenAt)
+	dc.metrics.observe(latency)
+
+	data, _ := json.Marshal(models.LatencyEvent{
+		Version:     version,
+		WrittenAt:   writtenAt,
+		DeliveredAt: now,
+		LatencyMs:   durationMs(latency),
+	})
+	fmt.Fprintf(w, "event: latency\ndata: %s\n\n", data)
+}
+
+// reset starts over, as for a new connection, after the subscription changed
+func (dc *deliveryClock) reset() {
+	dc.last = 0
+}
+
+// skip moves the clock to version without a delivery, for frames left unsent because
+// nothing the connection follows changed
+func (dc *deliveryClock) skip(version uint64) {
+	dc.last = max(dc.last, version)
+}
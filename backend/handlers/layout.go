@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// SetBoardLayout handles PUT /api/admin/layout?board=name with a body like
+// {"layout": "columnar"}, switching a read-heavy board to the columnar layout or back
+// to rows. Without a board parameter the default board is switched.
+func (h *Handler) SetBoardLayout(w http.ResponseWriter, r *http.Request) {
+	lb := h.Leaderboard
+	if name := r.URL.Query().Get("board"); name != "" {
+		var found bool
+		if lb, found = h.Boards.Get(name); !found {
+			http.Error(w, "Leaderboard not found", http.StatusNotFound)
+			return
+		}
+	}
+	layouts, ok := lb.(store.LayoutStore)
+	if !ok {
+		http.Error(w, "This leaderboard has a fixed layout", http.StatusNotImplemented)
+		return
+	}
+
+	var req struct {
+		Layout string `json:"layout"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Layout == "" {
+		http.Error(w, "Body must contain a layout", http.StatusBadRequest)
+		return
+	}
+	if err := layouts.SetLayout(req.Layout); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	meta := lb.Metadata()
+	h.audit("board.layout", meta.Name, "", map[string]string{"layout": meta.Layout})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(meta)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaintenanceRetryAfter is the Retry-After sent when maintenance is switched on
+// without one
+const defaultMaintenanceRetryAfter = 60
+
+// readOnlyPosts end the paths of POST routes that only read, on the default board and
+// named ones, so they keep working in maintenance
+var readOnlyPosts = []string{
+	"/leaderboard/subset",
+	"/api/certificates/verify",
+}
+
+// SetMaintenance handles PUT /api/admin/maintenance with {"enabled", "reason",
+// "retryAfter"}: while enabled the API refuses writes with 503 and Retry-After, and
+// reads and streams keep serving the last state. Admin routes are never refused, so
+// seasons can still be finalized and maintenance switched off.
+func (h *Handler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled    bool   `json:"enabled"`
+		Reason     string `json:"reason"`
+		RetryAfter int    `json:"retryAfter"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RetryAfter < 0 {
+		http.Error(w, "Body must contain enabled, and retryAfter must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	status := models.MaintenanceStatus{}
+	if req.Enabled {
+		since := time.Now()
+		status = models.MaintenanceStatus{Enabled: true, Reason: req.Reason, Since: &since, RetryAfter: req.RetryAfter}
+		if status.RetryAfter == 0 {
+			status.RetryAfter = defaultMaintenanceRetryAfter
+		}
+	}
+	h.maintenance.Store(&status)
+	h.audit("maintenance.set", "", "", status)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// GetMaintenance handles GET /api/maintenance, so clients can explain refused writes
+func (h *Handler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.maintenanceStatus())
+}
+
+func (h *Handler) maintenanceStatus() models.MaintenanceStatus {
+	if status := h.maintenance.Load(); status != nil {
+		return *status
+	}
+	return models.MaintenanceStatus{}
+}
+
+// ReadOnlyDuringMaintenance wraps the API so that, while maintenance is on, requests
+// that write are refused with 503 and Retry-After. Reads, streams and admin routes pass.
+func (h *Handler) ReadOnlyDuringMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := h.maintenance.Load()
+		if status == nil || !status.Enabled || !writes(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		message := "The leaderboard is read-only for maintenance"
+		if status.Reason != "" {
+			message += ": " + status.Reason
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(status.RetryAfter))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":       message,
+			"maintenance": status,
+		})
+	})
+}
+
+// writes reports whether r may change state outside the admin routes
+func writes(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	path := r.URL.Path
+	if strings.HasPrefix(path, "/api/admin/") || strings.HasPrefix(path, "/api/stream/") {
+		// Stream subscription changes only reshape what a connection reads
+		return false
+	}
+	if r.Method == http.MethodPost {
+		for _, suffix := range readOnlyPosts {
+			if strings.HasSuffix(path, suffix) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encodeMatchCursor turns a match ID into an opaque pagination cursor
+func encodeMatchCursor(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte("m:" + strconv.FormatInt(id, 10)))
+}
+
+// decodeMatchCursor parses a cursor produced by encodeMatchCursor
+func decodeMatchCursor(cursor string) (int64, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), "m:") {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(string(raw), "m:"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// SubmitMatch handles POST /api/matches. The body either lists the players with the
+// rating change each took, or names a winner and loser (or two players who drew) whose
+// changes are computed with Elo.
+func (h *Handler) SubmitMatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Players []struct {
+			Username    string `json:"username"`
+			Score       int    `json:"score"`
+			RatingDelta int64  `json:"ratingDelta"`
+		} `json:"players"`
+		Winner   string    `json:"winner"`
+		Loser    string    `json:"loser"`
+		Draw     bool      `json:"draw"`
+		PlayedAt time.Time `json:"playedAt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.Winner != "" || req.Loser != "" {
+		h.submitRatedMatch(w, r, req.Winner, req.Loser, req.Draw, req.PlayedAt)
+		return
+	}
+
+	if len(req.Players) < 2 {
+		http.Error(w, "A match needs at least two players", http.StatusBadRequest)
+		return
+	}
+
+	// Ratings going into the match are the live ones, even while the board is frozen
+	lb := live(h.defaultBoard(r))
+	transactional, ok := lb.(store.TransactionalStore)
+	if !ok {
+		http.Error(w, "This leaderboard cannot record matches", http.StatusNotImplemented)
+		return
+	}
+
+	seen := make(map[string]bool)
+	usernames := make([]string, len(req.Players))
+	deltas := make([]int64, len(req.Players))
+	for i, p := range req.Players {
+		if seen[p.Username] {
+			http.Error(w, "Duplicate player: "+p.Username, http.StatusBadRequest)
+			return
+		}
+		seen[p.Username] = true
+		usernames[i], deltas[i] = p.Username, p.RatingDelta
+	}
+
+	// Deltas are added to the ratings the players hold when the match is applied, and
+	// every new rating is checked before any is written, so a refused match changes
+	// nothing
+	var overflow error
+	before, after, err := transactional.TransformRatings(usernames, func(ratings []int64) []int64 {
+		sums, err := store.AddDeltas(ratings, deltas)
+		overflow = err
+		return sums
+	})
+	if overflow != nil {
+		err = overflow
+	}
+	switch {
+	case refused(w, err):
+		return
+	case errors.Is(err, store.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, store.ErrRatingOverflow):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "Failed to apply match", http.StatusInternalServerError)
+		return
+	}
+
+	match := models.Match{PlayedAt: req.PlayedAt}
+	for i, p := range req.Players {
+		match.Players = append(match.Players, models.MatchPlayer{
+			Username:     p.Username,
+			Score:        p.Score,
+			RatingBefore: before[i],
+			RatingAfter:  after[i],
+			RatingDelta:  after[i] - before[i],
+		})
+	}
+
+	recorded, err := h.Matches.Append(match)
+	if err != nil {
+		http.Error(w, "Failed to record match", http.StatusInternalServerError)
+		return
+	}
+	h.audit("match.submit", lb.Metadata().Name, "", recorded)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(recorded)
+}
+
+// submitRatedMatch applies a game between winner and loser, or a draw between them,
+// rating both players with Elo from the ratings they hold when it is applied. Both
+// changes are written together or not at all.
+func (h *Handler) submitRatedMatch(w http.ResponseWriter, r *http.Request, winner, loser string, draw bool, playedAt time.Time) {
+	if winner == "" || loser == "" || winner == loser {
+		http.Error(w, "A rated match needs a winner and a different loser", http.StatusBadRequest)
+		return
+	}
+
+	lb := live(h.defaultBoard(r))
+	transactional, ok := lb.(store.TransactionalStore)
+	if !ok {
+		http.Error(w, "This leaderboard cannot rate matches", http.StatusNotImplemented)
+		return
+	}
+	if len(lb.Metadata().Metrics) > 0 {
+		http.Error(w, "Ratings on this leaderboard are computed from metrics; submit them instead", http.StatusBadRequest)
+		return
+	}
+
+	elo := h.Elo
+	if elo.Scale == 0 {
+		elo.Scale = store.ScaleRating(lb.Metadata().ScoreFormat, 1)
+	}
+	before, after, err := transactional.TransformRatings([]string{winner, loser}, func(ratings []int64) []int64 {
+		winnerDelta, loserDelta := elo.Result(ratings[0], ratings[1], draw)
+		return []int64{ratings[0] + winnerDelta, ratings[1] + loserDelta}
+	})
+	switch {
+	case refused(w, err):
+		return
+	case errors.Is(err, store.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, store.ErrRatingOverflow):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "Failed to apply match", http.StatusInternalServerError)
+		return
+	}
+
+	match := models.Match{PlayedAt: playedAt, Draw: draw}
+	for i, username := range []string{winner, loser} {
+		player := models.MatchPlayer{
+			Username:     username,
+			RatingBefore: before[i],
+			RatingAfter:  after[i],
+			RatingDelta:  after[i] - before[i],
+		}
+		if i == 0 && !draw {
+			player.Score = 1
+		}
+		match.Players = append(match.Players, player)
+	}
+
+	recorded, err := h.Matches.Append(match)
+	if err != nil {
+		http.Error(w, "Failed to record match", http.StatusInternalServerError)
+		return
+	}
+	h.audit("match.submit", lb.Metadata().Name, "", recorded)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(recorded)
+}
+
+// ListMatches handles GET /api/matches
+func (h *Handler) ListMatches(w http.ResponseWriter, r *http.Request) {
+	username := r.URL.Query().Get("user")
+
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	var beforeID int64
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		id, ok := decodeMatchCursor(cursor)
+		if !ok {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		beforeID = id
+	}
+
+	matches, next := h.Matches.List(username, beforeID, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"matches":    matches,
+		"count":      len(matches),
+		"nextCursor": encodeMatchCursor(next),
+		"hasMore":    next != 0,
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetMigration handles GET /api/admin/migration: dual-write counters and the most
+// recent divergences between the old and new backends
+func (h *Handler) GetMigration(w http.ResponseWriter, r *http.Request) {
+	if h.Migration == nil {
+		http.Error(w, "No backend migration is in progress", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Migration.Stats())
+}
+
+// CutoverMigration handles POST /api/admin/migration/cutover with {"readFrom":
+// "secondary"} to serve reads from the new backend, or "primary" to switch back
+func (h *Handler) CutoverMigration(w http.ResponseWriter, r *http.Request) {
+	if h.Migration == nil {
+		http.Error(w, "No backend migration is in progress", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		ReadFrom string `json:"readFrom"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil ||
+		(req.ReadFrom != "primary" && req.ReadFrom != "secondary") {
+		http.Error(w, `Body must set readFrom to "primary" or "secondary"`, http.StatusBadRequest)
+		return
+	}
+
+	h.Migration.Cutover(req.ReadFrom == "secondary")
+	h.audit("migration.cutover", h.Leaderboard.Metadata().Name, "", map[string]interface{}{
+		"readFrom": req.ReadFrom,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Migration.Stats())
+}
+
+// BackfillMigration handles POST /api/admin/migration/backfill: it copies the old
+// backend's users into the new one again, e.g. after divergence was reported
+func (h *Handler) BackfillMigration(w http.ResponseWriter, r *http.Request) {
+	if h.Migration == nil {
+		http.Error(w, "No backend migration is in progress", http.StatusNotFound)
+		return
+	}
+
+	report := h.Migration.Backfill()
+	h.audit("migration.backfill", h.Leaderboard.Metadata().Name, "", report)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/store"
+	"net/http"
+	"strconv"
+)
+
+// GetMovers handles GET /api/leaderboard/movers?window=15m&limit=20: the users who
+// gained and lost the most rating over the trailing window, for trending panels
+func (h *Handler) GetMovers(w http.ResponseWriter, r *http.Request) {
+	h.serveMovers(w, r, h.defaultBoard(r))
+}
+
+// GetBoardMovers handles GET /api/leaderboards/{name}/leaderboard/movers
+func (h *Handler) GetBoardMovers(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveMovers(w, r, lb)
+	}
+}
+
+// serveMovers implements GetMovers against a specific board
+func (h *Handler) serveMovers(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	movers, ok := lb.(store.MoversStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not track recent movers", http.StatusNotImplemented)
+		return
+	}
+
+	window := r.URL.Query().Get("window")
+	if window == "" {
+		window = "15m"
+	}
+	span, err := store.ParseMoverWindow(window)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	gainers, losers := movers.Movers(span, limit)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"window":  window,
+		"gainers": gainers,
+		"losers":  losers,
+		"limit":   limit,
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/store"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// Users shown either side of the one at the requested percentile
+const (
+	defaultPercentileRadius = 25
+	maxPercentileRadius     = 50
+)
+
+// servePercentilePage implements GetLeaderboard with ?atPercentile=50&radius=25: the
+// page centred on the user at that percentile ("top 50%"), so UIs can jump to the
+// middle of the pack without knowing the board's size
+func (h *Handler) servePercentilePage(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	query := r.URL.Query()
+	if query.Has("offset") || query.Has("cursor") || query.Has("country") || query.Has("tier") || query.Has("window") {
+		http.Error(w, "atPercentile cannot be combined with offset, cursor, country, tier or window", http.StatusBadRequest)
+		return
+	}
+	percentile, err := strconv.ParseFloat(query.Get("atPercentile"), 64)
+	if err != nil || math.IsNaN(percentile) || percentile < 0 || percentile > 100 {
+		http.Error(w, "atPercentile must be a number from 0 to 100", http.StatusBadRequest)
+		return
+	}
+	radius := defaultPercentileRadius
+	if s := query.Get("radius"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > maxPercentileRadius {
+			http.Error(w, "radius must be an integer from 0 to 50", http.StatusBadRequest)
+			return
+		}
+		radius = n
+	}
+
+	lb, _, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+
+	// Position (from 1) of the user the percentile falls on: "top 1%" of 10,000 users
+	// is the 100th
+	total := lb.GetTotalUsers()
+	position := max(int(math.Ceil(percentile/100*float64(total))), 1)
+	position = min(position, total)
+
+	limit := 2*radius + 1
+	offset := max(position-1-radius, 0)
+	if total > 0 {
+		// Keep the page full at the bottom of the board
+		offset = max(min(offset, total-limit), 0)
+	}
+	entries := lb.GetLeaderboard(limit, offset)
+
+	response := map[string]interface{}{
+		"entries":      entries,
+		"totalUsers":   total,
+		"limit":        limit,
+		"offset":       offset,
+		"hasMore":      offset+limit < total,
+		"scoreFormat":  lb.Metadata().ScoreFormat,
+		"atPercentile": percentile,
+		"position":     position,
+	}
+	// Rating at the percentile, read from the store's rating quantiles
+	if thresholds, ok := lb.(store.ThresholdStore); ok {
+		if rating, held := thresholds.RatingAtRank(position); held {
+			response["rating"] = rating
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
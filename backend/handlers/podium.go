@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetPodium handles GET /api/leaderboard/podium: the top three with their profiles,
+// how long the leader has held #1 and their lead over #2
+func (h *Handler) GetPodium(w http.ResponseWriter, r *http.Request) {
+	if h.Reign == nil {
+		http.Error(w, "The podium needs the in-memory store", http.StatusNotImplemented)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Reign.Podium())
+}
+
+// GetRecords handles GET /api/records: the longest reign at #1, the highest rating
+// ever held and the biggest 24-hour climb on the default board
+func (h *Handler) GetRecords(w http.ResponseWriter, r *http.Request) {
+	if h.Reign == nil {
+		http.Error(w, "Records need the in-memory store", http.StatusNotImplemented)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Reign.Records())
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+	"strconv"
+)
+
+// PredictRank handles GET /api/leaderboard/predict?rating=3200&rank=10: the rank a
+// rating would land at if submitted now and, with rank, how many points it is short
+// of reaching that rank. Nothing is written.
+func (h *Handler) PredictRank(w http.ResponseWriter, r *http.Request) {
+	h.servePrediction(w, r, h.defaultBoard(r))
+}
+
+// PredictBoardRank handles GET /api/leaderboards/{name}/leaderboard/predict
+func (h *Handler) PredictBoardRank(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.servePrediction(w, r, lb)
+	}
+}
+
+// servePrediction implements PredictRank against a specific board
+func (h *Handler) servePrediction(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	query := r.URL.Query()
+	rating, err := strconv.ParseInt(query.Get("rating"), 10, 64)
+	if err != nil {
+		http.Error(w, "rating must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	prediction := models.RankPrediction{
+		Rating:          rating,
+		CompetitionRank: lb.CompetitionRank(rating),
+		TotalUsers:      lb.GetTotalUsers(),
+	}
+	if rankStr := query.Get("rank"); rankStr != "" {
+		rank, err := strconv.Atoi(rankStr)
+		if err != nil || rank < 1 {
+			http.Error(w, "rank must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		thresholds, ok := lb.(store.ThresholdStore)
+		if !ok {
+			http.Error(w, "This leaderboard cannot predict the rating a rank takes", http.StatusNotImplemented)
+			return
+		}
+
+		target := &models.RankTarget{Rank: rank}
+		// Past the last user any rating reaches the rank
+		if needed, held := thresholds.RatingAtRank(rank); held {
+			target.Rating = needed
+			target.PointsNeeded = max(needed-rating, 0)
+		} else {
+			target.Rating = rating
+		}
+		prediction.Target = target
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prediction)
+}
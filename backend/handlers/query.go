@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// QueryBoard handles POST /api/admin/query?board=name: it answers a filter, group-by
+// and aggregate query (see models.Query) from one version of the board's standings.
+// Without a board parameter the default board is queried.
+func (h *Handler) QueryBoard(w http.ResponseWriter, r *http.Request) {
+	lb := h.Leaderboard
+	if name := r.URL.Query().Get("board"); name != "" {
+		var found bool
+		if lb, found = h.Boards.Get(name); !found {
+			http.Error(w, "Leaderboard not found", http.StatusNotFound)
+			return
+		}
+	}
+	querying, ok := lb.(store.QueryStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not support queries", http.StatusNotImplemented)
+		return
+	}
+
+	var q models.Query
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&q); err != nil {
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := querying.Query(q)
+	if errors.Is(err, store.ErrInvalidQuery) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
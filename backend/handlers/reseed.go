@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"leaderboard-api/seed"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// Reseed bounds
+const (
+	defaultReseedUsers = 10000
+	maxReseedUsers     = 100000
+)
+
+// ReseedRequest is the body of POST /api/admin/reseed
+type ReseedRequest struct {
+	// How many generated users replace the board's; default 10000
+	Users int `json:"users"`
+}
+
+// ResetBoard handles POST /api/admin/reset: it removes every user from the default
+// board, as at a fresh start without seed data
+func (h *Handler) ResetBoard(w http.ResponseWriter, r *http.Request) {
+	lb := live(h.defaultBoard(r))
+	removed := clearBoard(lb)
+	h.audit("board.reset", lb.Metadata().Name, "", map[string]int{"removed": removed})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"removed": removed})
+}
+
+// ReseedBoard handles POST /api/admin/reseed with an optional body like
+// {"users": 5000}: it replaces every user on the default board with generated ones,
+// rated like the seed data the server starts with
+func (h *Handler) ReseedBoard(w http.ResponseWriter, r *http.Request) {
+	var req ReseedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.Users == 0 {
+		req.Users = defaultReseedUsers
+	}
+	if req.Users < 0 || req.Users > maxReseedUsers {
+		http.Error(w, fmt.Sprintf("users must be between 1 and %d", maxReseedUsers), http.StatusBadRequest)
+		return
+	}
+
+	lb := live(h.defaultBoard(r))
+	removed := clearBoard(lb)
+
+	dist := seed.DefaultDistribution
+	dist.Scale = store.ScaleRating(lb.Metadata().ScoreFormat, 1)
+	users := seed.GenerateUsersWithDistribution(req.Users, dist)
+	for _, user := range users {
+		user.ID = h.newUserID()
+	}
+	lb.BulkAddUsers(users)
+
+	result := map[string]int{"removed": removed, "seeded": len(users)}
+	h.audit("board.reseed", lb.Metadata().Name, "", result)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// clearBoard removes every user on lb and returns how many were removed. Users added
+// meanwhile may survive.
+func clearBoard(lb store.Store) int {
+	removed := 0
+	for _, entry := range lb.GetLeaderboard(lb.GetTotalUsers(), 0) {
+		if lb.RemoveUser(entry.Username) {
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+	"strconv"
+)
+
+// maxScoreBatch bounds how many entries one batch of scores may carry
+const maxScoreBatch = 1000
+
+// SubmitScores handles POST /api/scores/batch: ratings for many users applied as one
+// store transaction, with each entry's outcome reported so importers can retry only
+// the failures
+func (h *Handler) SubmitScores(w http.ResponseWriter, r *http.Request) {
+	h.serveScoreBatch(w, r, h.defaultBoard(r))
+}
+
+// SubmitBoardScores handles POST /api/leaderboards/{name}/scores/batch
+func (h *Handler) SubmitBoardScores(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveScoreBatch(w, r, lb)
+	}
+}
+
+// serveScoreBatch implements SubmitScores against a specific board
+func (h *Handler) serveScoreBatch(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	lb = live(lb)
+	batcher, ok := lb.(store.BatchStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not accept score batches", http.StatusNotImplemented)
+		return
+	}
+
+	var req struct {
+		Scores []models.ScoreUpdate `json:"scores"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Scores) == 0 {
+		http.Error(w, "Body must contain a non-empty scores array", http.StatusBadRequest)
+		return
+	}
+	if len(req.Scores) > maxScoreBatch {
+		http.Error(w, "A batch holds at most "+strconv.Itoa(maxScoreBatch)+" scores", http.StatusBadRequest)
+		return
+	}
+	if len(lb.Metadata().Metrics) > 0 {
+		http.Error(w, "Ratings on this leaderboard are computed from metrics; submit them instead", http.StatusBadRequest)
+		return
+	}
+
+	results := batcher.UpdateRatings(req.Scores)
+
+	counts := map[string]int{
+		models.ScoreApplied:          0,
+		models.ScoreUserNotFound:     0,
+		models.ScoreValidationFailed: 0,
+	}
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	h.audit("scores.batch", lb.Metadata().Name, "", counts)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": results,
+		"counts":  counts,
+	})
+}
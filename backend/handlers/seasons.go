@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RotateSeason handles POST /api/admin/seasons/rotate
+func (h *Handler) RotateSeason(w http.ResponseWriter, r *http.Request) {
+	if h.Seasons == nil {
+		http.Error(w, "Seasons require the in-memory store", http.StatusNotImplemented)
+		return
+	}
+	var policy models.ResetPolicy
+	// An empty body means a hard reset to the default base rating
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil && err != io.EOF {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.Seasons.Rotate(policy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current, _ := h.Seasons.CurrentSeason()
+	h.audit("season.rotate", "", "", map[string]interface{}{
+		"archived": summary.ID,
+		"policy":   policy,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"archived":      summary,
+		"currentSeason": current,
+	})
+}
+
+// FinalizeSeason handles POST /api/admin/seasons/{id}/finalize: it makes an archived
+// season's standings final once its grace period has ended
+func (h *Handler) FinalizeSeason(w http.ResponseWriter, r *http.Request) {
+	if h.Seasons == nil {
+		http.Error(w, "Seasons require the in-memory store", http.StatusNotImplemented)
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Season ID must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.Seasons.Finalize(id, time.Now())
+	switch {
+	case errors.Is(err, store.ErrSeasonNotFound):
+		http.Error(w, "Season not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	h.audit("season.finalize", "", "", map[string]interface{}{"season": summary.ID})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// ListSeasons handles GET /api/seasons
+func (h *Handler) ListSeasons(w http.ResponseWriter, r *http.Request) {
+	if h.Seasons == nil {
+		http.Error(w, "Seasons require the in-memory store", http.StatusNotImplemented)
+		return
+	}
+	current, startedAt := h.Seasons.CurrentSeason()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"seasons":          h.Seasons.List(),
+		"currentSeason":    current,
+		"currentStartedAt": startedAt,
+	})
+}
+
+// GetSeasonLeaderboard handles GET /api/seasons/{id}/leaderboard
+func (h *Handler) GetSeasonLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if h.Seasons == nil {
+		http.Error(w, "Seasons require the in-memory store", http.StatusNotImplemented)
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Season ID must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	season, entries, found := h.Seasons.GetStandings(id, limit, offset)
+	if !found {
+		http.Error(w, "Season not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"season":     season,
+		"entries":    entries,
+		"totalUsers": season.TotalUsers,
+		"limit":      limit,
+		"offset":     offset,
+		"hasMore":    offset+limit < season.TotalUsers,
+	})
+}
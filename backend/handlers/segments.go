@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// segment scopes a leaderboard or stream to one country or one tier. The zero value
+// is the whole board.
+type segment struct {
+	Country string `json:"country,omitempty"`
+	Tier    string `json:"tier,omitempty"`
+}
+
+// errSegmentUnsupported is returned when the store keeps no index for the segment
+var errSegmentUnsupported = errors.New("this leaderboard does not index users by country or tier")
+
+// parseSegment validates the country and tier query parameters; at most one may be set
+func parseSegment(r *http.Request) (segment, error) {
+	return segment{Country: r.URL.Query().Get("country"), Tier: r.URL.Query().Get("tier")}.normalize()
+}
+
+// normalize validates a segment and puts it in canonical form
+func (s segment) normalize() (segment, error) {
+	if s.Country != "" && s.Tier != "" {
+		return segment{}, fmt.Errorf("country and tier cannot be combined")
+	}
+	if s.Country != "" {
+		profile, err := store.NormalizeProfile(models.Profile{Country: s.Country})
+		if err != nil {
+			return segment{}, err
+		}
+		s.Country = profile.Country
+	}
+	if s.Tier != "" {
+		tier, ok := store.ParseTier(s.Tier)
+		if !ok {
+			return segment{}, fmt.Errorf("unknown tier %q", s.Tier)
+		}
+		s.Tier = tier
+	}
+	return s, nil
+}
+
+// all reports whether the segment is the whole board
+func (s segment) all() bool {
+	return s.Country == "" && s.Tier == ""
+}
+
+// page returns a page of the segment's standings and how many users it holds
+func (s segment) page(lb store.Store, limit, offset int) ([]models.LeaderboardEntry, int, error) {
+	if s.Country != "" {
+		countries, ok := lb.(store.CountryStore)
+		if !ok {
+			return nil, 0, errSegmentUnsupported
+		}
+		entries, total := countries.GetCountryLeaderboard(s.Country, limit, offset)
+		return entries, total, nil
+	}
+	tiers, ok := lb.(store.TierStore)
+	if !ok {
+		return nil, 0, errSegmentUnsupported
+	}
+	entries, total := tiers.GetTierLeaderboard(s.Tier, limit, offset)
+	return entries, total, nil
+}
+
+// includes reports whether a user currently belongs to the segment
+func (s segment) includes(lb store.Store, username string) bool {
+	if s.all() {
+		return true
+	}
+	user, found := lb.GetUserRank(username)
+	if !found {
+		return false
+	}
+	if s.Country != "" {
+		return user.Country == s.Country
+	}
+	return store.TierForRating(lb.Metadata().ScoreFormat, user.Rating) == s.Tier
+}
+
+// serveSegmentLeaderboard implements GetLeaderboard?country=XX and ?tier=name: the
+// standings among one segment's users, ranked within the segment
+func (h *Handler) serveSegmentLeaderboard(w http.ResponseWriter, r *http.Request, lb store.Store, limit, offset int) {
+	if window := r.URL.Query().Get("window"); window != "" && window != "alltime" {
+		http.Error(w, "country and tier cannot be combined with window", http.StatusBadRequest)
+		return
+	}
+	seg, err := parseSegment(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lb, _, ok := withRanking(w, r, lb)
+	if !ok {
+		return
+	}
+	// Read the version first so the page never claims writes it might have missed
+	version := lb.Version()
+	entries, total, err := seg.page(lb, limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	response := map[string]interface{}{
+		"entries":     entries,
+		"totalUsers":  total,
+		"limit":       limit,
+		"offset":      offset,
+		"hasMore":     offset+limit < total,
+		"scoreFormat": lb.Metadata().ScoreFormat,
+		"version":     version,
+	}
+	if seg.Country != "" {
+		response["country"] = seg.Country
+	} else {
+		response["tier"] = seg.Tier
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// segmentStanding is what a segment stream compares between frames
+type segmentStanding struct {
+	Username string
+	Rank     int
+	Rating   int64
+}
+
+// segmentFrame renders the stream frame for a segment subscription, or returns nil
+// when the segment's standings are unchanged since last, which it then updates. Only
+// names, ranks within the segment and ratings count: global ranks shift with every
+// move elsewhere on the board.
+func segmentFrame(lb store.Store, sub streamSubscription, last *[]byte) []byte {
+	// Read the version first so the frame never claims writes it might have missed
+	version := lb.Version()
+	entries, total, err := sub.segment.page(lb, sub.Limit, sub.Offset)
+	if err != nil {
+		return nil
+	}
+	standings := make([]segmentStanding, len(entries))
+	for i, entry := range entries {
+		standings[i] = segmentStanding{entry.Username, entry.Rank, entry.Rating}
+	}
+	page, _ := json.Marshal(standings)
+	if bytes.Equal(page, *last) {
+		return nil
+	}
+	*last = page
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"entries":    entries,
+		"segment":    sub.segment,
+		"totalUsers": total,
+		"limit":      sub.Limit,
+		"offset":     sub.Offset,
+		"hasMore":    sub.Offset+sub.Limit < total,
+		"version":    version,
+	})
+	return data
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetSimulatorStatus handles GET /api/admin/simulator: whether the load simulator is
+// writing or has paused itself, the write health it last measured and recent alerts
+func (h *Handler) GetSimulatorStatus(w http.ResponseWriter, r *http.Request) {
+	if h.Simulator == nil {
+		http.Error(w, "The simulator is not running", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Simulator.Status())
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"leaderboard-api/secrets"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StreamAccess describes what a stream subscriber is allowed to receive
+type StreamAccess struct {
+	Tier     string        `json:"tier"`
+	MaxLimit int           `json:"maxLimit"` // largest page a subscriber may request
+	TopOnly  bool          `json:"topOnly"`  // restricted to the top of the board (offset 0)
+	Interval time.Duration `json:"-"`
+	// IntervalMs mirrors Interval for clients
+	IntervalMs int64 `json:"intervalMs"`
+}
+
+var (
+	// PublicStreamAccess applies to callers without a recognised key when tiers are enforced
+	PublicStreamAccess = StreamAccess{Tier: "public", MaxLimit: 10, TopOnly: true, Interval: 2 * time.Second, IntervalMs: 2000}
+
+	// PartnerStreamAccess applies to authenticated partner keys (and to everyone when tiers are off)
+	PartnerStreamAccess = StreamAccess{Tier: "partner", MaxLimit: 100, Interval: 500 * time.Millisecond, IntervalMs: 500}
+)
+
+// StreamPolicy maps caller credentials to stream access tiers
+type StreamPolicy struct {
+	// valid reports whether a key grants partner access
+	valid func(key string) bool
+}
+
+// NewStreamPolicy creates a policy that restricts callers without one of keys to the public tier
+func NewStreamPolicy(keys []string) *StreamPolicy {
+	partnerKeys := make(map[string]bool)
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			partnerKeys[key] = true
+		}
+	}
+	if len(partnerKeys) == 0 {
+		return &StreamPolicy{}
+	}
+	return &StreamPolicy{valid: func(key string) bool { return partnerKeys[key] }}
+}
+
+// NewSecretStreamPolicy checks partner keys against a managed secret, so rotated keys
+// take effect immediately and the previous keys keep working for the grace period
+func NewSecretStreamPolicy(m *secrets.Manager, name string) *StreamPolicy {
+	return &StreamPolicy{valid: func(key string) bool { return m.Valid(name, key) }}
+}
+
+// Resolve determines the caller's access from an "Authorization: Bearer" header or, since
+// browser EventSource cannot set headers, a "key" query parameter
+func (p *StreamPolicy) Resolve(r *http.Request) StreamAccess {
+	if p == nil || p.valid == nil {
+		return PartnerStreamAccess
+	}
+
+	key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if key == "" {
+		key = r.URL.Query().Get("key")
+	}
+	if key != "" && p.valid(key) {
+		return PartnerStreamAccess
+	}
+	return PublicStreamAccess
+}
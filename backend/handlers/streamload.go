@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DegradedStreamInterval is the slowest cadence streams are pushed to under load
+const DegradedStreamInterval = 2 * time.Second
+
+// Runtime estimates of CPU time available to and left idle by the process. They are
+// refreshed at each GC, which under the simulator's allocation rate is often enough.
+const (
+	cpuTotalMetric = "/cpu/classes/total:cpu-seconds"
+	cpuIdleMetric  = "/cpu/classes/idle:cpu-seconds"
+)
+
+// StreamLoad describes the current stream cadence policy; it is sent to clients
+// in "cadence" events along with the stream's resulting interval
+type StreamLoad struct {
+	Degraded   bool    `json:"degraded"`
+	Reason     string  `json:"reason,omitempty"`
+	IntervalMs int64   `json:"intervalMs,omitempty"`
+	Streams    int64   `json:"streams"`
+	CPU        float64 `json:"cpu"` // fraction of GOMAXPROCS in use
+}
+
+// StreamLoadMonitor samples CPU usage and the number of open streams and slows every
+// stream down to DegradedStreamInterval while either is above its threshold.
+// It recovers once both fall comfortably below (80% of the stream limit and 75% of
+// the CPU limit) so the cadence doesn't flap around the threshold.
+type StreamLoadMonitor struct {
+	maxStreams int64
+	maxCPU     float64
+
+	streams atomic.Int64
+
+	mu      sync.RWMutex
+	load    StreamLoad
+	changed chan struct{} // closed and replaced whenever Degraded flips
+
+	lastCPU    float64
+	lastSample time.Time
+}
+
+// NewStreamLoadMonitor creates a monitor that degrades above maxStreams open streams
+// or maxCPU (a fraction of GOMAXPROCS, e.g. 0.8)
+func NewStreamLoadMonitor(maxStreams int, maxCPU float64) *StreamLoadMonitor {
+	return &StreamLoadMonitor{
+		maxStreams: int64(maxStreams),
+		maxCPU:     maxCPU,
+		changed:    make(chan struct{}),
+		lastCPU:    cpuSeconds(),
+		lastSample: time.Now(),
+	}
+}
+
+// Start samples load every interval for the life of the process
+func (m *StreamLoadMonitor) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			m.sample()
+		}
+	}()
+}
+
+// Current returns the cadence policy and a channel closed when it next changes.
+// A nil monitor never degrades.
+func (m *StreamLoadMonitor) Current() (StreamLoad, <-chan struct{}) {
+	if m == nil {
+		return StreamLoad{}, nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.load, m.changed
+}
+
+// Interval applies the cadence policy to a stream's own interval
+func (load StreamLoad) Interval(base time.Duration) time.Duration {
+	if load.Degraded && base < DegradedStreamInterval {
+		return DegradedStreamInterval
+	}
+	return base
+}
+
+// writeCadence sends a "cadence" event announcing the stream's effective interval
+func writeCadence(w http.ResponseWriter, flusher http.Flusher, load StreamLoad, interval time.Duration) {
+	load.IntervalMs = interval.Milliseconds()
+	data, _ := json.Marshal(load)
+	fmt.Fprintf(w, "event: cadence\ndata: %s\n\n", data)
+	flusher.Flush()
+}
+
+// enter and leave track open streams
+func (m *StreamLoadMonitor) enter() {
+	if m != nil {
+		m.streams.Add(1)
+	}
+}
+
+func (m *StreamLoadMonitor) leave() {
+	if m != nil {
+		m.streams.Add(-1)
+	}
+}
+
+// sample measures CPU usage since the last sample and updates the policy
+func (m *StreamLoadMonitor) sample() {
+	now := time.Now()
+	total := cpuSeconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elapsed := now.Sub(m.lastSample).Seconds()
+	cpu := 0.0
+	if elapsed > 0 {
+		cpu = (total - m.lastCPU) / (elapsed * float64(runtime.GOMAXPROCS(0)))
+	}
+	m.lastCPU, m.lastSample = total, now
+
+	streams := m.streams.Load()
+	load := StreamLoad{Streams: streams, CPU: cpu}
+	switch {
+	case m.maxStreams > 0 && streams > m.maxStreams:
+		load.Degraded = true
+		load.Reason = fmt.Sprintf("%d open streams", streams)
+	case m.maxCPU > 0 && cpu > m.maxCPU:
+		load.Degraded = true
+		load.Reason = fmt.Sprintf("cpu at %.0f%%", cpu*100)
+	case m.load.Degraded && ((m.maxStreams > 0 && streams*10 > m.maxStreams*8) || (m.maxCPU > 0 && cpu > m.maxCPU*0.75)):
+		// Still above the recovery thresholds: stay degraded
+		load.Degraded = true
+		load.Reason = m.load.Reason
+	}
+	flipped := load.Degraded != m.load.Degraded
+	m.load = load
+	if flipped {
+		close(m.changed)
+		m.changed = make(chan struct{})
+	}
+}
+
+// cpuSeconds returns the CPU time used by the process so far
+func cpuSeconds() float64 {
+	samples := []metrics.Sample{{Name: cpuTotalMetric}, {Name: cpuIdleMetric}}
+	metrics.Read(samples)
+	for _, sample := range samples {
+		if sample.Value.Kind() != metrics.KindFloat64 {
+			return 0
+		}
+	}
+	return samples[0].Value.Float64() - samples[1].Value.Float64()
+}
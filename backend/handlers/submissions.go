@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+	"strconv"
+)
+
+// maxSubmissionBatch bounds how many submissions one request may carry
+const maxSubmissionBatch = 1000
+
+// SubmitRatings handles POST /api/submissions: a batch of timestamped ratings from a
+// client's offline queue, applied per user in timestamp order
+func (h *Handler) SubmitRatings(w http.ResponseWriter, r *http.Request) {
+	h.serveSubmissions(w, r, h.defaultBoard(r))
+}
+
+// SubmitBoardRatings handles POST /api/leaderboards/{name}/submissions
+func (h *Handler) SubmitBoardRatings(w http.ResponseWriter, r *http.Request) {
+	if lb, ok := h.board(w, r); ok {
+		h.serveSubmissions(w, r, lb)
+	}
+}
+
+// serveSubmissions implements SubmitRatings against a specific board
+func (h *Handler) serveSubmissions(w http.ResponseWriter, r *http.Request, lb store.Store) {
+	submitter, ok := lb.(store.SubmissionStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not accept queued submissions", http.StatusNotImplemented)
+		return
+	}
+
+	var req struct {
+		Submissions []models.Submission `json:"submissions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Submissions) == 0 {
+		http.Error(w, "Body must contain a non-empty submissions array", http.StatusBadRequest)
+		return
+	}
+	if len(req.Submissions) > maxSubmissionBatch {
+		http.Error(w, "A batch holds at most "+strconv.Itoa(maxSubmissionBatch)+" submissions", http.StatusBadRequest)
+		return
+	}
+
+	// The default board's submissions are routed through its season archive, so ones
+	// dated in a past season land in that season's standings
+	apply := submitter.ApplySubmissions
+	if h.Seasons != nil && lb.Metadata().Name == h.Leaderboard.Metadata().Name {
+		apply = h.Seasons.ApplySubmissions
+	}
+	results := apply(req.Submissions)
+
+	counts := map[string]int{
+		models.SubmissionApplied:    0,
+		models.SubmissionArchived:   0,
+		models.SubmissionSuperseded: 0,
+		models.SubmissionDuplicate:  0,
+		models.SubmissionRejected:   0,
+		models.SubmissionFinalized:  0,
+	}
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	h.audit("submissions.apply", lb.Metadata().Name, "", counts)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": results,
+		"counts":  counts,
+	})
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// streamSubscription is what a live SSE connection is currently receiving
+type streamSubscription struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Query      string `json:"query,omitempty"` // when set, the stream carries search results instead of a page
+	IntervalMs int64  `json:"intervalMs"`
+
+	// When set, the stream carries the standings of one country or tier, and only
+	// series events of its users
+	segment
+}
+
+// interval returns the subscription's refresh cadence
+func (s streamSubscription) interval() time.Duration {
+	return time.Duration(s.IntervalMs) * time.Millisecond
+}
+
+// clamp bounds a subscription to what the caller's access tier allows
+func (s streamSubscription) clamp(access StreamAccess) streamSubscription {
+	if s.Limit <= 0 || s.Limit > access.MaxLimit {
+		s.Limit = access.MaxLimit
+	}
+	if s.Offset < 0 || access.TopOnly {
+		s.Offset = 0
+	}
+	if s.IntervalMs < access.IntervalMs {
+		s.IntervalMs = access.IntervalMs
+	}
+	return s
+}
+
+// streamConn is a registered SSE connection that can be re-targeted without reconnecting
+type streamConn struct {
+	id      string
+	access  StreamAccess
+	changes chan streamSubscription
+	stream  *compressedStream // what the connection has been sent
+}
+
+// streamRegistry tracks live SSE connections by ID
+type streamRegistry struct {
+	mu    sync.RWMutex
+	conns map[string]*streamConn
+}
+
+func newStreamRegistry() *streamRegistry {
+	return &streamRegistry{conns: make(map[string]*streamConn)}
+}
+
+// register adds a connection under a fresh random ID
+func (sr *streamRegistry) register(access StreamAccess, stream *compressedStream) *streamConn {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+
+	conn := &streamConn{
+		id:      hex.EncodeToString(buf),
+		access:  access,
+		changes: make(chan streamSubscription, 1),
+		stream:  stream,
+	}
+
+	sr.mu.Lock()
+	sr.conns[conn.id] = conn
+	sr.mu.Unlock()
+	return conn
+}
+
+// unregister removes a connection once its stream ends
+func (sr *streamRegistry) unregister(id string) {
+	sr.mu.Lock()
+	delete(sr.conns, id)
+	sr.mu.Unlock()
+}
+
+// get looks up a live connection
+func (sr *streamRegistry) get(id string) (*streamConn, bool) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	conn, found := sr.conns[id]
+	return conn, found
+}
+
+// UpdateSubscription handles POST /api/stream/{connectionId}/subscription
+func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	conn, found := h.subscriptions.get(r.PathValue("connectionId"))
+	if !found {
+		http.Error(w, "Stream connection not found", http.StatusNotFound)
+		return
+	}
+
+	var sub streamSubscription
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	var err error
+	if sub.segment, err = sub.segment.normalize(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sub = sub.clamp(conn.access)
+
+	// Only the latest change matters; replace any change the stream hasn't picked up yet
+	select {
+	case <-conn.changes:
+	default:
+	}
+	conn.changes <- sub
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(sub)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// teams returns the team board, writing a 501 when teams aren't kept
+func (h *Handler) teams(w http.ResponseWriter) (*store.TeamLeaderboard, bool) {
+	if h.Teams == nil {
+		http.Error(w, "Teams need the in-memory store", http.StatusNotImplemented)
+		return nil, false
+	}
+	return h.Teams, true
+}
+
+// CreateTeam handles POST /api/teams with a body like {"name": "...", "members": [...]}.
+// Members already on a team move to the new one.
+func (h *Handler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	teams, ok := h.teams(w)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name    string   `json:"name"`
+		Members []string `json:"members"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		http.Error(w, "Body must contain a team name", http.StatusBadRequest)
+		return
+	}
+
+	err := teams.CreateTeam(req.Name, req.Members)
+	if errors.Is(err, store.ErrTeamExists) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.audit("team.create", teams.Metadata().Name, req.Name, req.Members)
+
+	team, _ := teams.GetTeam(req.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(team)
+}
+
+// GetTeam handles GET /api/teams/{team}
+func (h *Handler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	teams, ok := h.teams(w)
+	if !ok {
+		return
+	}
+
+	team, found := teams.GetTeam(r.PathValue("team"))
+	if !found {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+// DeleteTeam handles DELETE /api/teams/{team}
+func (h *Handler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	teams, ok := h.teams(w)
+	if !ok {
+		return
+	}
+
+	name := r.PathValue("team")
+	if !teams.DeleteTeam(name) {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+	h.audit("team.delete", teams.Metadata().Name, name, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JoinTeam handles PUT /api/teams/{team}/members/{username}, moving the user off any
+// team they were on
+func (h *Handler) JoinTeam(w http.ResponseWriter, r *http.Request) {
+	teams, ok := h.teams(w)
+	if !ok {
+		return
+	}
+
+	name, username := r.PathValue("team"), r.PathValue("username")
+	if err := teams.Join(name, username); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	h.audit("team.join", teams.Metadata().Name, username, map[string]string{"team": name})
+
+	team, _ := teams.GetTeam(name)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+// LeaveTeam handles DELETE /api/teams/{team}/members/{username}
+func (h *Handler) LeaveTeam(w http.ResponseWriter, r *http.Request) {
+	teams, ok := h.teams(w)
+	if !ok {
+		return
+	}
+
+	name, username := r.PathValue("team"), r.PathValue("username")
+	if !teams.Leave(name, username) {
+		http.Error(w, "User is not on that team", http.StatusNotFound)
+		return
+	}
+	h.audit("team.leave", teams.Metadata().Name, username, map[string]string{"team": name})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTeamLeaderboard handles GET /api/teams/leaderboard
+func (h *Handler) GetTeamLeaderboard(w http.ResponseWriter, r *http.Request) {
+	teams, ok := h.teams(w)
+	if !ok {
+		return
+	}
+
+	limit, offset := pageParams(r)
+	entries, total := teams.GetLeaderboard(limit, offset)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries":     entries,
+		"scoring":     teams.Scoring().String(),
+		"scoreFormat": teams.Metadata().ScoreFormat,
+		"totalTeams":  total,
+		"limit":       limit,
+		"offset":      offset,
+		"hasMore":     offset+limit < total,
+	})
+}
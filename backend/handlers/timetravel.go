@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"math"
+	"net/http"
+	"time"
+)
+
+// GetUserRankAt handles GET /api/users/{username}/rank?at=2024-06-02T18:00:00Z: where
+// the user stood at a past moment (a timestamp or a date), worked out from rating
+// histories. When the user's history doesn't reach back that far and the moment falls
+// in an archived season, that season's final standings answer instead.
+func (h *Handler) GetUserRankAt(w http.ResponseWriter, r *http.Request) {
+	lb := h.defaultBoard(r)
+	traveller, ok := lb.(store.TimeTravelStore)
+	if !ok {
+		http.Error(w, "This leaderboard does not keep the history past ranks need", http.StatusNotImplemented)
+		return
+	}
+
+	at := time.Now()
+	if s := r.URL.Query().Get("at"); s != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, s); err != nil {
+			if at, err = time.Parse(time.DateOnly, s); err != nil {
+				http.Error(w, "at must be an RFC 3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if at.After(time.Now()) {
+		http.Error(w, "at must not be in the future", http.StatusBadRequest)
+		return
+	}
+
+	// Users who have since left the board may still be in an archived season
+	username := r.PathValue("username")
+	rank, found := lookupUserRankAt(traveller, lb, username, at)
+	if found {
+		username = rank.Username
+	}
+	if (!found || rank.Estimate) && h.Seasons != nil {
+		if archived := h.seasonRankAt(username, at); archived != nil {
+			rank, found = archived, true
+		}
+	}
+	if !found {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rank)
+}
+
+// seasonRankAt is where username finished the archived season covering at, or nil
+func (h *Handler) seasonRankAt(username string, at time.Time) *models.HistoricalRank {
+	season, entry, above, found := h.Seasons.StandingAt(username, at)
+	if !found {
+		return nil
+	}
+	rank := &models.HistoricalRank{
+		Username:        username,
+		At:              at,
+		Rating:          entry.Rating,
+		Rank:            entry.Rank,
+		CompetitionRank: above + 1,
+		TotalUsers:      season.TotalUsers,
+		Source:          models.HistoricalFromSeason,
+		Season:          season.ID,
+	}
+	top := float64(rank.CompetitionRank) / float64(rank.TotalUsers) * 100
+	rank.Percentile = math.Round(top*100) / 100
+	return rank
+}
+
+// lookupUserRankAt is RankAt matching the username regardless of case, like GetUser
+func lookupUserRankAt(traveller store.TimeTravelStore, lb store.Store, username string, at time.Time) (*models.HistoricalRank, bool) {
+	if rank, found := traveller.RankAt(username, at); found {
+		return rank, true
+	}
+	result, found := lookupUser(lb, username)
+	if !found {
+		return nil, false
+	}
+	return traveller.RankAt(result.Username, at)
+}
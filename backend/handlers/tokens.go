@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"leaderboard-api/auth"
+	"net/http"
+	"strings"
+)
+
+// TokenPolicy authenticates callers with JWT bearer tokens carrying role claims.
+// Admin routes need an admin token; writes elsewhere and the caller's own /api/me
+// routes need a user or admin token. A valid token's subject names the caller in place
+// of any X-Username header sent, which is otherwise dropped.
+type TokenPolicy struct {
+	verifier *auth.Verifier
+}
+
+// NewTokenPolicy creates a policy verifying tokens with verifier
+func NewTokenPolicy(verifier *auth.Verifier) *TokenPolicy {
+	return &TokenPolicy{verifier: verifier}
+}
+
+// Subject returns the subject of the valid bearer token a request carries, or "" when
+// it carries none, one that isn't valid or one without a subject
+func (p *TokenPolicy) Subject(r *http.Request) string {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return ""
+	}
+	claims, err := p.verifier.Verify(token)
+	if err != nil {
+		return ""
+	}
+	return claims.Subject
+}
+
+// authenticate verifies the request's bearer token and checks it grants role,
+// writing a 401 or 403 when it doesn't
+func (p *TokenPolicy) authenticate(w http.ResponseWriter, r *http.Request, role string) (auth.Claims, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="leaderboard"`)
+		http.Error(w, "A bearer token with the "+role+" role is required", http.StatusUnauthorized)
+		return auth.Claims{}, false
+	}
+
+	claims, err := p.verifier.Verify(token)
+	switch {
+	case errors.Is(err, auth.ErrNoKey):
+		http.Error(w, "Tokens cannot be verified right now", http.StatusServiceUnavailable)
+		return claims, false
+	case err != nil:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="leaderboard", error="invalid_token"`)
+		http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
+		return claims, false
+	case !claims.HasRole(role):
+		http.Error(w, "This token lacks the "+role+" role", http.StatusForbidden)
+		return claims, false
+	}
+	return claims, true
+}
+
+// RequireUser guards the API's writes, as maintenance mode classifies them, and the
+// /api/me routes behind a user token. Other reads, streams and admin routes pass;
+// RequireAdmin guards the latter.
+func (p *TokenPolicy) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject := ""
+		if writes(r) || (strings.HasPrefix(r.URL.Path, "/api/me/") && r.Method != http.MethodOptions) {
+			claims, ok := p.authenticate(w, r, auth.RoleUser)
+			if !ok {
+				return
+			}
+			subject = claims.Subject
+		} else if !strings.HasPrefix(r.URL.Path, "/api/stream") {
+			// Streams take partner keys as bearer tokens
+			subject = p.Subject(r)
+		}
+
+		if subject != "" {
+			r.Header.Set(UserHeader, subject)
+		} else {
+			r.Header.Del(UserHeader)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAdmin guards every route it wraps behind an admin token, for the admin routes
+func (p *TokenPolicy) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := p.authenticate(w, r, auth.RoleAdmin); ok {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
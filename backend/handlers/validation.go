@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"net/http"
+)
+
+// refused reports whether err is a write breaking one of the board's validation rules,
+// in which case it has answered 422 with the rule and the limit crossed
+func refused(w http.ResponseWriter, err error) bool {
+	var invalid *store.ValidationError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(struct {
+		Error     string               `json:"error"`
+		Violation models.RuleViolation `json:"violation"`
+	}{invalid.Message, invalid.RuleViolation})
+	return true
+}
+
+// allowed checks a rating about to be written for username against lb's validation
+// rules, answering 422 and reporting false if it breaks one. previous is nil for a
+// user joining. Stores without rules allow every rating.
+func allowed(w http.ResponseWriter, lb store.Store, username string, previous *int64, rating int64) bool {
+	validating, ok := lb.(store.ValidatingStore)
+	if !ok {
+		return true
+	}
+	return !refused(w, validating.CheckRating(username, previous, rating))
+}
+
+// updateRating writes a rating sent by a client, checked against the board's validation
+// rules where the store has them
+func updateRating(lb store.Store, username string, rating int64) (bool, error) {
+	if validating, ok := lb.(store.ValidatingStore); ok {
+		return validating.UpdateRatingChecked(username, rating)
+	}
+	return lb.UpdateRating(username, rating), nil
+}
@@ -0,0 +1,106 @@
+// Package ids generates user IDs
+package ids
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Generator hands out unique user IDs
+type Generator interface {
+	NewID() string
+}
+
+// Strategies accepted by New
+const (
+	StrategyUUID      = "uuid"
+	StrategySnowflake = "snowflake"
+)
+
+// New returns the generator for strategy: "uuid" (the default when empty) or
+// "snowflake", which stamps IDs with node so several instances never collide
+func New(strategy string, node int64) (Generator, error) {
+	switch strings.ToLower(strings.TrimSpace(strategy)) {
+	case "", StrategyUUID:
+		return UUID{}, nil
+	case StrategySnowflake:
+		return NewSnowflake(node)
+	default:
+		return nil, fmt.Errorf("unknown ID strategy %q (want uuid or snowflake)", strategy)
+	}
+}
+
+// UUID generates random (version 4) UUIDs
+type UUID struct{}
+
+// NewID returns a new random UUID in its canonical form
+func (UUID) NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("ids: reading random bytes: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Snowflake layout: milliseconds since snowflakeEpoch, then the node, then a sequence
+// number within the millisecond. IDs sort by creation time.
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+	maxNode      = 1<<nodeBits - 1
+	maxSequence  = 1<<sequenceBits - 1
+)
+
+// snowflakeEpoch keeps timestamps small enough to last well past this century
+var snowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates time-ordered 63-bit IDs, unique across up to 1024 nodes
+type Snowflake struct {
+	node int64
+
+	mu       sync.Mutex
+	last     int64 // millisecond of the last ID
+	sequence int64
+}
+
+// NewSnowflake returns a snowflake generator for node, from 0 to 1023
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > maxNode {
+		return nil, fmt.Errorf("snowflake node must be between 0 and %d, got %d", maxNode, node)
+	}
+	return &Snowflake{node: node}, nil
+}
+
+// NewID returns the next ID, waiting for the next millisecond if this one's sequence
+// numbers have run out
+func (s *Snowflake) NewID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Since(snowflakeEpoch).Milliseconds()
+	if now < s.last {
+		// The clock stepped back; keep counting from where it was
+		now = s.last
+	}
+	if now == s.last {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			for now <= s.last {
+				time.Sleep(100 * time.Microsecond)
+				now = time.Since(snowflakeEpoch).Milliseconds()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.last = now
+
+	id := now<<(nodeBits+sequenceBits) | s.node<<sequenceBits | s.sequence
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"context"
+	"leaderboard-api/models"
+	"leaderboard-api/store"
+	"log"
+	"sync"
+	"time"
+)
+
+// fetchTimeout bounds a single fetch from an upstream source
+const fetchTimeout = 2 * time.Minute
+
+// Rating is one user's rating as reported by an upstream source
+type Rating struct {
+	Username string
+	Rating   int64
+}
+
+// Importer fetches the current ratings from an upstream rating system
+type Importer interface {
+	// Source is the tag recorded on users created from this importer, e.g. "codeforces"
+	Source() string
+	Fetch(ctx context.Context) ([]Rating, error)
+}
+
+// Runner mirrors one or more importers into a board, refreshing on an interval.
+// Users new to the board are added with the importer's source tag; existing users
+// have their rating replaced. Users missing upstream are left alone.
+type Runner struct {
+	leaderboard store.Store
+	importers   []Importer
+	stopChan    chan struct{}
+	once        sync.Once
+}
+
+// NewRunner creates a runner that writes into lb
+func NewRunner(lb store.Store, importers ...Importer) *Runner {
+	return &Runner{
+		leaderboard: lb,
+		importers:   importers,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start imports immediately and then every interval until Stop
+func (ru *Runner) Start(interval time.Duration) {
+	go func() {
+		ru.RunOnce()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ru.RunOnce()
+			case <-ru.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic refreshes
+func (ru *Runner) Stop() {
+	ru.once.Do(func() { close(ru.stopChan) })
+}
+
+// RunOnce runs every importer, logging rather than failing on errors
+func (ru *Runner) RunOnce() {
+	for _, imp := range ru.importers {
+		start := time.Now()
+		added, updated, err := ru.run(imp)
+		if err != nil {
+			log.Printf("Import from %s failed: %v", imp.Source(), err)
+			continue
+		}
+		log.Printf("Imported from %s: %d added, %d updated in %v", imp.Source(), added, updated, time.Since(start))
+	}
+}
+
+// run fetches from one importer and applies the ratings
+func (ru *Runner) run(imp Importer) (added, updated int, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	ratings, err := imp.Fetch(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	newUsers := make([]*models.User, 0)
+	for _, r := range ratings {
+		if ru.leaderboard.UpdateRating(r.Username, r.Rating) {
+			updated++
+			continue
+		}
+		newUsers = append(newUsers, &models.User{
+			ID:       r.Username,
+			Username: r.Username,
+			Rating:   r.Rating,
+			Source:   imp.Source(),
+		})
+	}
+	if len(newUsers) > 0 {
+		ru.leaderboard.BulkAddUsers(newUsers)
+	}
+	return len(newUsers), updated, nil
+}
@@ -0,0 +1,196 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Mapping locates users in a JSON document. Paths are dot-separated object keys;
+// an empty List means the document itself is the array of users.
+type Mapping struct {
+	List     string
+	Username string
+	Rating   string
+}
+
+// DefaultMapping reads a top-level array of {"username": ..., "rating": ...} objects
+var DefaultMapping = Mapping{Username: "username", Rating: "rating"}
+
+// JSONSource reads ratings from a JSON document served over HTTP(S) or stored in a file
+type JSONSource struct {
+	Tag     string
+	URL     string // http(s) URL, or a local path
+	Mapping Mapping
+	Client  *http.Client
+}
+
+// CSVSource reads "username,rating" rows from a dump file. A header row is skipped.
+type CSVSource struct {
+	Tag  string
+	Path string
+}
+
+// FromSpec builds an importer from a source spec:
+//
+//	codeforces             Codeforces rated users
+//	lichess:<perf>         Lichess top 200 for a perf type, e.g. lichess:blitz
+//	file:<path>            a .csv dump, or a .json dump read with mapping
+//	http(s)://...          a JSON API read with mapping
+func FromSpec(spec string, mapping Mapping) (Importer, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "codeforces":
+		return &JSONSource{
+			Tag:     "codeforces",
+			URL:     "https://codeforces.com/api/user.ratedList?activeOnly=true",
+			Mapping: Mapping{List: "result", Username: "handle", Rating: "rating"},
+		}, nil
+	case "lichess":
+		if arg == "" {
+			return nil, fmt.Errorf("lichess source needs a perf type, e.g. lichess:blitz")
+		}
+		return &JSONSource{
+			Tag:     "lichess",
+			URL:     "https://lichess.org/api/player/top/200/" + url.PathEscape(arg),
+			Mapping: Mapping{List: "users", Username: "username", Rating: "perfs." + arg + ".rating"},
+		}, nil
+	case "file":
+		if arg == "" {
+			return nil, fmt.Errorf("file source needs a path")
+		}
+		if strings.EqualFold(filepath.Ext(arg), ".csv") {
+			return &CSVSource{Tag: "file", Path: arg}, nil
+		}
+		return &JSONSource{Tag: "file", URL: arg, Mapping: mapping}, nil
+	case "http", "https":
+		parsed, err := url.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSource{Tag: parsed.Hostname(), URL: spec, Mapping: mapping}, nil
+	default:
+		return nil, fmt.Errorf("unknown import source %q", spec)
+	}
+}
+
+// Source returns the tag recorded on imported users
+func (s *JSONSource) Source() string {
+	return s.Tag
+}
+
+// Fetch downloads or reads the document and extracts every mapped user
+func (s *JSONSource) Fetch(ctx context.Context) ([]Rating, error) {
+	body, err := s.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var doc interface{}
+	if err := json.NewDecoder(body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	list, ok := lookup(doc, s.Mapping.List).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no array at %q", s.Mapping.List)
+	}
+
+	ratings := make([]Rating, 0, len(list))
+	for _, item := range list {
+		username, _ := lookup(item, s.Mapping.Username).(string)
+		rating, isNumber := lookup(item, s.Mapping.Rating).(float64)
+		if username == "" || !isNumber {
+			continue
+		}
+		ratings = append(ratings, Rating{Username: username, Rating: int64(math.Round(rating))})
+	}
+	return ratings, nil
+}
+
+// open returns the document body from the URL or local path
+func (s *JSONSource) open(ctx context.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+		return os.Open(s.URL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", s.URL, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// lookup follows a dot-separated path through nested JSON objects
+func lookup(value interface{}, path string) interface{} {
+	if path == "" {
+		return value
+	}
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// Source returns the tag recorded on imported users
+func (s *CSVSource) Source() string {
+	return s.Tag
+}
+
+// Fetch reads every row of the dump; rows without an integer rating are skipped
+func (s *CSVSource) Fetch(ctx context.Context) ([]Rating, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make([]Rating, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		rating, err := strconv.ParseInt(strings.TrimSpace(row[1]), 10, 64)
+		username := strings.TrimSpace(row[0])
+		if err != nil || username == "" {
+			continue
+		}
+		ratings = append(ratings, Rating{Username: username, Rating: rating})
+	}
+	return ratings, nil
+}
@@ -1,89 +1,440 @@
 package main
 
 import (
-	"fmt"
-	"leaderboard-api/handlers"
+	"context"
+	"leaderboard-api/importer"
+	"leaderboard-api/mirror"
+	"leaderboard-api/models"
+	"leaderboard-api/secrets"
 	"leaderboard-api/seed"
+	"leaderboard-api/server"
 	"leaderboard-api/simulator"
 	"leaderboard-api/store"
 	"log"
-	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Allow all origins for development
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+func main() {
+	cfg := server.Config{Addr: ":" + envOr("PORT", "8080")}
+
+	// ADMIN_PORT serves the admin routes only from a separate listener
+	if adminPort := os.Getenv("ADMIN_PORT"); adminPort != "" {
+		cfg.AdminAddr = ":" + adminPort
+	}
 
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+	// Keys and credentials come from Vault, mounted files, an env-file or plain env vars,
+	// reloaded every SECRETS_RELOAD_INTERVAL when set
+	secretStore, err := secrets.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid secrets configuration: %v", err)
+	}
+	cfg.Secrets = secretStore
+	if v := os.Getenv("SECRETS_RELOAD_INTERVAL"); v != "" {
+		if cfg.SecretsReloadInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("Invalid SECRETS_RELOAD_INTERVAL: %v", err)
 		}
+	}
 
-		next.ServeHTTP(w, r)
-	})
-}
+	// Score semantics for the main board: SCORE_UNIT=points|time_ms|currency. Ratings are
+	// 64-bit integers; SCORE_DECIMALS gives points and currency a fixed precision, e.g.
+	// 2 holds a fractional Elo of 1523.25 as 152325
+	if unit := os.Getenv("SCORE_UNIT"); unit != "" {
+		decimals, _ := strconv.Atoi(os.Getenv("SCORE_DECIMALS"))
+		if cfg.Store.ScoreFormat, err = store.NewScoreFormat(unit, decimals, os.Getenv("SCORE_SYMBOL")); err != nil {
+			log.Fatalf("Invalid SCORE_UNIT: %v", err)
+		}
+	}
 
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		next.ServeHTTP(w, r)
-		log.Printf("%s %s %v", r.Method, r.URL.Path, time.Since(start))
-	})
-}
+	// Composite ranking, e.g. SORT_KEYS=wins:desc,losses:asc,rating:desc; games played,
+	// wins and win rate break rating ties with SORT_KEYS=rating,winRate,gamesPlayed
+	if spec := os.Getenv("SORT_KEYS"); spec != "" {
+		if cfg.Store.SortKeys, err = store.ParseSortKeys(spec); err != nil {
+			log.Fatalf("Invalid SORT_KEYS: %v", err)
+		}
+	}
+
+	// How ties are ranked: RANKING_MODE=dense|standard|modified|ordinal
+	if cfg.Store.RankingMode, err = store.ParseRankingMode(os.Getenv("RANKING_MODE")); err != nil {
+		log.Fatalf("Invalid RANKING_MODE: %v", err)
+	}
+
+	// Order within ties: TIE_BREAK=username|achieved (first to reach the rating wins)
+	if cfg.Store.TieBreak, err = store.ParseTieBreak(os.Getenv("TIE_BREAK")); err != nil {
+		log.Fatalf("Invalid TIE_BREAK: %v", err)
+	}
+
+	// MAX_ENTRIES caps the board (e.g. 100000 keeps the top 100k); past it the
+	// lowest-rated user is evicted
+	if capacity, err := strconv.Atoi(os.Getenv("MAX_ENTRIES")); err == nil && capacity > 0 {
+		cfg.Store.Capacity = capacity
+	}
+
+	// BOARD_LAYOUT=columnar suits read-heavy deployments: ratings are also kept as a
+	// parallel slice for scans and snapshots are written column by column
+	if cfg.Store.Layout, err = store.ParseLayout(os.Getenv("BOARD_LAYOUT")); err != nil {
+		log.Fatalf("Invalid BOARD_LAYOUT: %v", err)
+	}
+
+	// Ratings clients write are refused outside RATING_MIN..RATING_MAX, when they move
+	// more than RATING_MAX_DELTA in one write, or with RATING_MONOTONIC=true when they
+	// fall; all in stored units. Background jobs such as decay aren't checked.
+	rules := &models.ValidationRules{Monotonic: os.Getenv("RATING_MONOTONIC") == "true"}
+	for _, bound := range []struct {
+		env  string
+		into **int64
+	}{{"RATING_MIN", &rules.MinRating}, {"RATING_MAX", &rules.MaxRating}} {
+		if v := os.Getenv(bound.env); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				log.Fatalf("Invalid %s: %v", bound.env, err)
+			}
+			*bound.into = &n
+		}
+	}
+	if v := os.Getenv("RATING_MAX_DELTA"); v != "" {
+		if rules.MaxDelta, err = strconv.ParseInt(v, 10, 64); err != nil {
+			log.Fatalf("Invalid RATING_MAX_DELTA: %v", err)
+		}
+	}
+	if *rules != (models.ValidationRules{}) {
+		cfg.Store.Validation = rules
+	}
+
+	// Rank and rating deltas compare against standings from RANK_DELTA_BASELINE ago (default 1h)
+	if d, err := time.ParseDuration(os.Getenv("RANK_DELTA_BASELINE")); err == nil && d > 0 {
+		cfg.RankDeltaBaseline = d
+	}
+
+	// STORE_BACKEND=redis shares the main board between instances through REDIS_URL,
+	// under REDIS_KEY_PREFIX. Snapshots, series and seasons need the in-memory store
+	// and are skipped then.
+	cfg.Store.Backend = os.Getenv("STORE_BACKEND")
+	cfg.Store.RedisKeyPrefix = os.Getenv("REDIS_KEY_PREFIX")
+
+	// STORE_MIGRATE_TO=redis moves the in-memory main board to Redis without downtime:
+	// writes go to both, reads stay in memory until POST /api/admin/migration/cutover,
+	// and one in MIGRATE_VERIFY_EVERY reads (default 10) is compared between the two
+	cfg.Store.MigrateTo = os.Getenv("STORE_MIGRATE_TO")
+	if n, err := strconv.Atoi(os.Getenv("MIGRATE_VERIFY_EVERY")); err == nil && n > 0 {
+		cfg.Store.MigrateVerifyEvery = n
+	}
+
+	// New users get USER_ID_STRATEGY IDs: uuid (the default) or snowflake, stamped with
+	// SNOWFLAKE_NODE (0-1023) so instances sharing a backend never hand out the same one
+	cfg.IDStrategy = os.Getenv("USER_ID_STRATEGY")
+	if n, err := strconv.ParseInt(os.Getenv("SNOWFLAKE_NODE"), 10, 64); err == nil {
+		cfg.SnowflakeNode = n
+	}
+
+	// An empty board is seeded with 10,000 users. Seed ratings follow a bell curve with
+	// SEED_TIE_RATIO (default 0.15) of users on popular ratings: SEED_POPULAR_RATINGS, a
+	// comma-separated list, or every round hundred. SEED_RATING_STEP rounds the rest so
+	// they tie among themselves too. SEED_USERNAMES=unicode seeds Hindi, CJK, emoji and
+	// combining-character usernames instead of ASCII ones.
+	cfg.Seed = &server.SeedConfig{Users: 10000, Distribution: seed.DefaultDistribution}
+	if ratio, err := strconv.ParseFloat(os.Getenv("SEED_TIE_RATIO"), 64); err == nil && ratio >= 0 && ratio <= 1 {
+		cfg.Seed.Distribution.TieRatio = ratio
+	}
+	for _, field := range strings.Split(os.Getenv("SEED_POPULAR_RATINGS"), ",") {
+		if rating, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64); err == nil {
+			cfg.Seed.Distribution.Popular = append(cfg.Seed.Distribution.Popular, rating)
+		}
+	}
+	if step, err := strconv.ParseInt(os.Getenv("SEED_RATING_STEP"), 10, 64); err == nil && step > 0 {
+		cfg.Seed.Distribution.Step = step
+	}
+	switch mode := os.Getenv("SEED_USERNAMES"); mode {
+	case "", "ascii":
+	case "unicode":
+		cfg.Seed.UnicodeNames = true
+	default:
+		log.Fatalf("Invalid SEED_USERNAMES %q: use ascii or unicode", mode)
+	}
+
+	// SNAPSHOT_PATH restores the board from its last snapshot instead of seeding it and
+	// snapshots it every SNAPSHOT_INTERVAL (default 1m). Sampled checks that the view,
+	// rating tree and snapshot agree with the user records run every
+	// CONSISTENCY_CHECK_INTERVAL (default 5m) over CONSISTENCY_SAMPLE users (default 100).
+	if path := os.Getenv("SNAPSHOT_PATH"); path != "" {
+		cfg.Snapshots = &server.SnapshotConfig{Path: path, Interval: time.Minute, CheckInterval: 5 * time.Minute, CheckSample: 100}
+		if d, err := time.ParseDuration(os.Getenv("SNAPSHOT_INTERVAL")); err == nil && d > 0 {
+			cfg.Snapshots.Interval = d
+		}
+		if d, err := time.ParseDuration(os.Getenv("CONSISTENCY_CHECK_INTERVAL")); err == nil && d > 0 {
+			cfg.Snapshots.CheckInterval = d
+		}
+		if n, err := strconv.Atoi(os.Getenv("CONSISTENCY_SAMPLE")); err == nil && n > 0 {
+			cfg.Snapshots.CheckSample = n
+		}
+	}
+
+	// Promotion/demotion series are opt-in: SERIES_BEST_OF=3 requires 2 wins to cross a tier
+	if bestOf, err := strconv.Atoi(os.Getenv("SERIES_BEST_OF")); err == nil && bestOf > 0 {
+		cfg.SeriesBestOf = bestOf
+	}
+
+	// Display names may be shared by default; DISPLAY_NAME_POLICY=unique refuses a name
+	// another user already shows (ignoring case)
+	if cfg.DisplayNames, err = store.ParseDisplayNamePolicy(os.Getenv("DISPLAY_NAME_POLICY")); err != nil {
+		log.Fatalf("Invalid DISPLAY_NAME_POLICY: %v", err)
+	}
+
+	// Rank milestones are recorded as rating increases cross them: entering the top N for
+	// each of MILESTONE_RANKS (default 10,100,1000), taking #1, and reaching each multiple
+	// of MILESTONE_RATING_STEP (default 1000, in display units; 0 disables)
+	cfg.Milestones = &store.MilestonePolicy{RankThresholds: store.DefaultRankThresholds, RatingStep: 1000}
+	if spec := os.Getenv("MILESTONE_RANKS"); spec != "" {
+		if cfg.Milestones.RankThresholds, err = store.ParseRankThresholds(spec); err != nil {
+			log.Fatalf("Invalid MILESTONE_RANKS: %v", err)
+		}
+	}
+	if n, err := strconv.ParseInt(os.Getenv("MILESTONE_RATING_STEP"), 10, 64); err == nil && n >= 0 {
+		cfg.Milestones.RatingStep = n
+	}
+
+	// Inactive users lose DECAY_PER_DAY points per day once DECAY_AFTER_DAYS (default
+	// 14) pass without an update, down to DECAY_FLOOR points, checked every
+	// DECAY_INTERVAL (default 1h); DECAY_EXEMPT lists usernames to skip
+	if perDay, err := strconv.ParseInt(os.Getenv("DECAY_PER_DAY"), 10, 64); err == nil && perDay > 0 {
+		policy := store.DecayPolicy{After: 14 * 24 * time.Hour, PerDay: perDay, Exempt: make(map[string]bool)}
+		if days, err := strconv.Atoi(os.Getenv("DECAY_AFTER_DAYS")); err == nil && days >= 0 {
+			policy.After = time.Duration(days) * 24 * time.Hour
+		}
+		if floor, err := strconv.ParseInt(os.Getenv("DECAY_FLOOR"), 10, 64); err == nil {
+			policy.Floor = floor
+		}
+		for _, username := range strings.Split(os.Getenv("DECAY_EXEMPT"), ",") {
+			if username = strings.TrimSpace(username); username != "" {
+				policy.Exempt[username] = true
+			}
+		}
+		cfg.Decay = &server.DecayConfig{Policy: policy, Interval: time.Hour}
+		if d, err := time.ParseDuration(os.Getenv("DECAY_INTERVAL")); err == nil && d > 0 {
+			cfg.Decay.Interval = d
+		}
+	}
+
+	// The co-op board ranks groups of DUO_GROUP_SIZE (default 2), 2000 seeded at random
+	cfg.DuoSeedGroups = 2000
+	if size, err := strconv.Atoi(os.Getenv("DUO_GROUP_SIZE")); err == nil && size > 1 {
+		cfg.DuoSize = size
+	}
+
+	// Teams rank by an aggregate of their members' ratings on the main board:
+	// TEAM_SCORE=sum|avg|top:N (default sum). TEAM_SEED_COUNT seed teams of
+	// TEAM_SEED_SIZE members (default 200 of 5) are made from freshly seeded users.
+	cfg.Teams = &server.TeamsConfig{SeedCount: 200, SeedSize: 5}
+	if cfg.Teams.Scoring, err = store.ParseTeamScoring(os.Getenv("TEAM_SCORE")); err != nil {
+		log.Fatalf("Invalid TEAM_SCORE: %v", err)
+	}
+	if n, err := strconv.Atoi(os.Getenv("TEAM_SEED_COUNT")); err == nil && n >= 0 {
+		cfg.Teams.SeedCount = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("TEAM_SEED_SIZE")); err == nil && n > 0 {
+		cfg.Teams.SeedSize = n
+	}
+
+	// Matches are kept in memory unless MATCH_LOG_PATH points at an append-only log
+	cfg.MatchLogPath = os.Getenv("MATCH_LOG_PATH")
+
+	// Matches submitted as a winner and loser are rated with Elo, moving a rating by at
+	// most ELO_K points a game (default 32)
+	if k, err := strconv.ParseFloat(os.Getenv("ELO_K"), 64); err == nil && k > 0 {
+		cfg.EloK = k
+	}
+
+	// Past days and seasons accept late submissions for FINALIZE_GRACE_PERIOD (default
+	// 48h) and are then finalized: their standings no longer change
+	cfg.Seasons = &server.SeasonsConfig{GracePeriod: store.DefaultGracePeriod}
+	if d, err := time.ParseDuration(os.Getenv("FINALIZE_GRACE_PERIOD")); err == nil && d >= 0 {
+		cfg.Seasons.GracePeriod = d
+	}
+
+	// Writes made through the API go to a hash-chained audit log, kept in memory
+	// unless AUDIT_LOG_PATH is set; entries are signed when audit_signing_key is set
+	cfg.Audit = &server.AuditConfig{Path: os.Getenv("AUDIT_LOG_PATH")}
+
+	// Every change to the default board, whether from the API, the simulator or a
+	// background job, goes to a change log of the latest CHANGE_LOG_SIZE (default
+	// 10000) changes, and also to the file at CHANGE_LOG_PATH when set
+	cfg.ChangeLog = &server.ChangeLogConfig{Path: os.Getenv("CHANGE_LOG_PATH")}
+	cfg.ChangeLog.Size, _ = strconv.Atoi(os.Getenv("CHANGE_LOG_SIZE"))
+
+	// Streams slow to 2s frames above STREAM_MAX_CONNECTIONS open streams or
+	// STREAM_MAX_CPU (fraction of GOMAXPROCS), recovering once load subsides
+	cfg.StreamMaxConnections, cfg.StreamMaxCPU = 500, 0.8
+	if n, err := strconv.Atoi(os.Getenv("STREAM_MAX_CONNECTIONS")); err == nil && n > 0 {
+		cfg.StreamMaxConnections = n
+	}
+	if f, err := strconv.ParseFloat(os.Getenv("STREAM_MAX_CPU"), 64); err == nil && f > 0 {
+		cfg.StreamMaxCPU = f
+	}
+
+	// Mirror upstream rating systems: IMPORT_SOURCES=codeforces,lichess:blitz,file:/data/ratings.csv
+	// every IMPORT_INTERVAL (default 1h). JSON sources other than the presets are read
+	// with IMPORT_LIST_PATH, IMPORT_USERNAME_FIELD and IMPORT_RATING_FIELD (dot-separated paths)
+	if specs := os.Getenv("IMPORT_SOURCES"); specs != "" {
+		mapping := importer.DefaultMapping
+		mapping.List = os.Getenv("IMPORT_LIST_PATH")
+		if field := os.Getenv("IMPORT_USERNAME_FIELD"); field != "" {
+			mapping.Username = field
+		}
+		if field := os.Getenv("IMPORT_RATING_FIELD"); field != "" {
+			mapping.Rating = field
+		}
+
+		cfg.Import = &server.ImportConfig{Interval: time.Hour}
+		for _, spec := range strings.Split(specs, ",") {
+			imp, err := importer.FromSpec(strings.TrimSpace(spec), mapping)
+			if err != nil {
+				log.Fatalf("Invalid IMPORT_SOURCES: %v", err)
+			}
+			cfg.Import.Importers = append(cfg.Import.Importers, imp)
+		}
+		if d, err := time.ParseDuration(os.Getenv("IMPORT_INTERVAL")); err == nil && d > 0 {
+			cfg.Import.Interval = d
+		}
+	}
+
+	// Push rating changes to an external leaderboard every MIRROR_INTERVAL (default 10s):
+	// MIRROR_URL and MIRROR_BODY are templates over {{.Board}}, {{.Username}},
+	// {{.Rating}}, {{.Previous}} and {{.New}}. MIRROR_CONFLICT=keep-higher|skip-existing
+	// reads the remote rating first through MIRROR_FETCH_URL (field MIRROR_RATING_FIELD);
+	// mirror_token is sent as a bearer token. Backfill pushes every existing user on the
+	// first sync; MIRROR_BACKFILL=false only mirrors changes made from now on.
+	if pushURL := os.Getenv("MIRROR_URL"); pushURL != "" {
+		target, err := mirror.NewTarget(os.Getenv("MIRROR_METHOD"), pushURL, os.Getenv("MIRROR_BODY"),
+			os.Getenv("MIRROR_FETCH_URL"), os.Getenv("MIRROR_RATING_FIELD"))
+		if err != nil {
+			log.Fatalf("Invalid mirror target: %v", err)
+		}
+		cfg.Mirror = &server.MirrorConfig{Target: target, Interval: 10 * time.Second, Backfill: os.Getenv("MIRROR_BACKFILL") != "false"}
+		if cfg.Mirror.Conflict, err = mirror.ParseConflictPolicy(os.Getenv("MIRROR_CONFLICT")); err != nil {
+			log.Fatalf("Invalid MIRROR_CONFLICT: %v", err)
+		}
+		if d, err := time.ParseDuration(os.Getenv("MIRROR_INTERVAL")); err == nil && d > 0 {
+			cfg.Mirror.Interval = d
+		}
+	}
+
+	// POST milestone events on the default board to MILESTONE_WEBHOOK_URL in batches every
+	// MILESTONE_WEBHOOK_INTERVAL (default 5s), and just users reaching their goals to
+	// GOAL_WEBHOOK_URL, signed with webhook_signing_key when set
+	cfg.MilestoneWebhookURL = os.Getenv("MILESTONE_WEBHOOK_URL")
+	cfg.GoalWebhookURL = os.Getenv("GOAL_WEBHOOK_URL")
+	if d, err := time.ParseDuration(os.Getenv("MILESTONE_WEBHOOK_INTERVAL")); err == nil && d > 0 {
+		cfg.WebhookInterval = d
+	}
+
+	// The simulator pauses itself while its writes are unhealthy: a p99 over
+	// SIMULATOR_MAX_WRITE_LATENCY (default 50ms) or more than SIMULATOR_MAX_ERROR_RATE
+	// (default 0.05) of writes failing in a second; both set to 0 turn the kill switch off.
+	// Pauses and resumes are posted to SIMULATOR_ALERT_WEBHOOK_URL when set.
+	cfg.Simulator = &server.SimulatorConfig{
+		UpdatesPerSecond: 3000,
+		Health:           simulator.DefaultHealthPolicy,
+		AlertURL:         os.Getenv("SIMULATOR_ALERT_WEBHOOK_URL"),
+	}
+	if d, err := time.ParseDuration(os.Getenv("SIMULATOR_MAX_WRITE_LATENCY")); err == nil && d >= 0 {
+		cfg.Simulator.Health.MaxLatency = d
+	}
+	if rate, err := strconv.ParseFloat(os.Getenv("SIMULATOR_MAX_ERROR_RATE"), 64); err == nil && rate >= 0 {
+		cfg.Simulator.Health.MaxErrorRate = rate
+	}
+
+	// Stats are sampled every STATS_SAMPLE_INTERVAL (default 10s) and kept for
+	// STATS_HISTORY_RETENTION (default 24h) for GET /api/stats/history
+	cfg.Stats = &server.StatsConfig{Interval: 10 * time.Second, Retention: 24 * time.Hour}
+	if d, err := time.ParseDuration(os.Getenv("STATS_SAMPLE_INTERVAL")); err == nil && d > 0 {
+		cfg.Stats.Interval = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("STATS_HISTORY_RETENTION")); err == nil && d > 0 {
+		cfg.Stats.Retention = d
+	}
+
+	// USER_TTL (e.g. 2160h) evicts users with no update for that long from the main
+	// board; USER_TTL_ARCHIVE=true keeps them on the inactive list so they can be restored
+	if ttl, err := time.ParseDuration(os.Getenv("USER_TTL")); err == nil && ttl > 0 {
+		cfg.UserTTL = ttl
+		cfg.ArchiveExpired = os.Getenv("USER_TTL_ARCHIVE") == "true"
+	}
+
+	// COMPACT_INTERVAL (e.g. 6h) rebuilds the main board's structures periodically so
+	// memory held after deletes is given back; POST /api/admin/compact runs it on demand
+	if d, err := time.ParseDuration(os.Getenv("COMPACT_INTERVAL")); err == nil && d > 0 {
+		cfg.CompactInterval = d
+	}
+
+	// Admin routes are limited to ADMIN_IP_ALLOW, less ADMIN_IP_DENY, and the rules in
+	// ADMIN_IP_RULES_FILE, reloaded as it changes
+	cfg.AdminIPAllow = os.Getenv("ADMIN_IP_ALLOW")
+	cfg.AdminIPDeny = os.Getenv("ADMIN_IP_DENY")
+	cfg.AdminIPRulesFile = os.Getenv("ADMIN_IP_RULES_FILE")
+
+	// With API keys configured (secrets api_keys, read-write, and api_read_keys,
+	// read-only) writes need a read-write key; API_PUBLIC_READS=false gates reads too.
+	// Admin routes need a read-write key too unless bearer tokens guard them.
+	cfg.PrivateReads = os.Getenv("API_PUBLIC_READS") == "false"
+
+	// With a jwt_signing_key secret, admin routes need an HS256 bearer token with the
+	// admin role and writes one with the user role; JWT_ISSUER pins the iss claim
+	cfg.JWTIssuer = os.Getenv("JWT_ISSUER")
+
+	// RATE_LIMIT_RPS (with RATE_LIMIT_BURST, default twice the rate) limits each client
+	// IP to that many requests a second; RATE_LIMIT_PER_KEY=true limits callers with a
+	// valid API or partner key by key, and those with a valid bearer token by its
+	// subject, instead
+	if rps, err := strconv.ParseFloat(os.Getenv("RATE_LIMIT_RPS"), 64); err == nil && rps > 0 {
+		cfg.RateLimit = &server.RateLimitConfig{RPS: rps, PerKey: os.Getenv("RATE_LIMIT_PER_KEY") == "true"}
+		if n, err := strconv.Atoi(os.Getenv("RATE_LIMIT_BURST")); err == nil && n > 0 {
+			cfg.RateLimit.Burst = n
+		}
+	}
+
+	// Once started, the main board's view is rebuilt in the background at most every
+	// VIEW_PUBLISH_INTERVAL (default 100ms), so reads never rebuild it inline and may
+	// trail writes by that much; 0 keeps rebuilding on the first read after a write
+	cfg.ViewPublishInterval = 100 * time.Millisecond
+	if d, err := time.ParseDuration(os.Getenv("VIEW_PUBLISH_INTERVAL")); err == nil && d >= 0 {
+		cfg.ViewPublishInterval = d
+	}
 
-func main() {
 	log.Println("Initializing leaderboard...")
-	leaderboard := store.NewLeaderboard()
-
-	log.Println("Generating 10,000 seed users...")
-	users := seed.GenerateUsersWithTies(10000)
-	leaderboard.BulkAddUsers(users)
-	log.Printf("Loaded %d users into leaderboard", leaderboard.GetTotalUsers())
-
-	h := handlers.NewHandler(leaderboard)
-
-	log.Println("Starting score update simulator...")
-	updater := simulator.NewScoreUpdater(leaderboard)
-	updater.Start(3000)
-
-	// Setup routes
-	mux := http.NewServeMux()
-
-	// API routes
-	mux.HandleFunc("GET /api/leaderboard", h.GetLeaderboard)
-	mux.HandleFunc("GET /api/users/search", h.SearchUsers)
-	mux.HandleFunc("GET /api/users/{username}", h.GetUser)
-	mux.HandleFunc("GET /api/stats", h.GetStats)
-	mux.HandleFunc("GET /api/stream", h.StreamUpdates)
-	mux.HandleFunc("GET /api/stream/search", h.StreamSearchUpdates)
-	mux.HandleFunc("GET /health", h.HealthCheck)
-
-	// Apply middleware
-	handler := corsMiddleware(loggingMiddleware(mux))
-
-	// Get port from environment or default to 8080
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	// Start server
-	addr := fmt.Sprintf(":%s", port)
-	log.Printf("  Leaderboard API server starting on http://localhost%s", addr)
-	log.Printf("  API Endpoints:")
-	log.Printf("   GET /api/leaderboard?limit=50&offset=0")
-	log.Printf("   GET /api/users/search?q=rahul")
-	log.Printf("   GET /api/users/{username}")
-	log.Printf("   GET /api/stats")
-	log.Printf("   GET /health")
-
-	if err := http.ListenAndServe(addr, handler); err != nil {
+	srv, err := server.New(cfg, nil)
+	if err != nil {
+		log.Fatalf("Failed to assemble server: %v", err)
+	}
+	if err := srv.Start(); err != nil {
 		log.Fatalf("Server failed to start: %v", err)
 	}
+
+	// On SIGINT or SIGTERM, finish in-flight requests for up to 10s before closing
+	// streams, stopping background jobs and taking a final snapshot
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Println("Shutting down...")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("Shutdown: %v", err)
+		}
+	}()
+	if err := srv.Wait(); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+// envOr returns the environment variable name, or fallback when it is unset
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
 }
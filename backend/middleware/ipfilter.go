@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IPFilter allows or rejects requests by client IP using CIDR allow/deny lists.
+// Deny rules win; when the allow list is non-empty only matching clients get through.
+type IPFilter struct {
+	mu    sync.RWMutex
+	allow []*net.IPNet
+	deny  []*net.IPNet
+
+	// Optional rules file, re-read when its modification time changes
+	path    string
+	modTime time.Time
+}
+
+// NewIPFilter creates a filter from comma-separated CIDR (or bare IP) lists
+func NewIPFilter(allow, deny string) (*IPFilter, error) {
+	f := &IPFilter{}
+	var err error
+	if f.allow, err = parseCIDRs(strings.Split(allow, ",")); err != nil {
+		return nil, err
+	}
+	if f.deny, err = parseCIDRs(strings.Split(deny, ",")); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// LoadFile replaces the rules with those in path. Each non-empty line is
+// "allow <cidr>" or "deny <cidr>"; lines starting with # are ignored.
+func (f *IPFilter) LoadFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var allow, deny []string
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		action, cidr, _ := strings.Cut(text, " ")
+		switch action {
+		case "allow":
+			allow = append(allow, cidr)
+		case "deny":
+			deny = append(deny, cidr)
+		default:
+			return fmt.Errorf("%s:%d: expected \"allow <cidr>\" or \"deny <cidr>\"", path, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	allowNets, err := parseCIDRs(allow)
+	if err != nil {
+		return err
+	}
+	denyNets, err := parseCIDRs(deny)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.allow, f.deny = allowNets, denyNets
+	f.path, f.modTime = path, info.ModTime()
+	f.mu.Unlock()
+	return nil
+}
+
+// WatchFile polls the rules file and reloads it whenever it changes.
+// A file that fails to parse is logged and the previous rules stay in force.
+func (f *IPFilter) WatchFile(path string, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			f.mu.RLock()
+			changed := !info.ModTime().Equal(f.modTime)
+			f.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := f.LoadFile(path); err != nil {
+				log.Printf("IP filter reload failed, keeping previous rules: %v", err)
+				continue
+			}
+			log.Printf("IP filter rules reloaded from %s", path)
+		}
+	}()
+}
+
+// Allowed reports whether ip passes the filter
+func (f *IPFilter) Allowed(ip net.IP) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, n := range f.deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, n := range f.allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware rejects requests from disallowed client IPs with 403
+func (f *IPFilter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := net.ParseIP(clientHost(r))
+		if ip == nil || !f.Allowed(ip) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseCIDRs parses CIDR blocks, treating bare IPs as single-host networks
+func parseCIDRs(values []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			if ip := net.ParseIP(v); ip != nil && ip.To4() != nil {
+				v += "/32"
+			} else {
+				v += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", v, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that have refilled are forgotten
+const sweepInterval = time.Minute
+
+// RateLimiter limits each client to a sustained rate of requests with a token
+// bucket: a client may burst up to burst requests, and tokens refill at rate per
+// second. Clients are told where they stand with X-RateLimit-* headers and refused
+// with 429 and Retry-After once their bucket is empty.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	// Key names the API key a request is authenticated with, or returns empty. Keyed
+	// requests draw from the key's bucket rather than their IP's, so one key is limited
+	// across every address it calls from. It must only return keys it has verified, or
+	// clients could dodge their IP's limit by inventing keys.
+	Key func(r *http.Request) string
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	at     time.Time // when tokens was last brought up to date
+}
+
+// NewRateLimiter creates a limiter allowing each client rate requests per second with
+// bursts of up to burst
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:      rate,
+		burst:     float64(max(burst, 1)),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// RateDecision is the outcome of taking a token from a client's bucket
+type RateDecision struct {
+	Allowed    bool
+	Limit      int           // the bucket's size
+	Remaining  int           // whole tokens left after this request
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until a refused client may try again; zero when allowed
+}
+
+// Take spends one of client's tokens if it has one
+func (l *RateLimiter) Take(client string) RateDecision {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweepLocked(now)
+	}
+
+	b, exists := l.buckets[client]
+	if !exists {
+		b = &tokenBucket{tokens: l.burst, at: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.at).Seconds()*l.rate)
+	b.at = now
+
+	decision := RateDecision{Limit: int(l.burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = l.refill(1 - b.tokens)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = l.refill(l.burst - b.tokens)
+	return decision
+}
+
+// refill returns how long tokens take to come back
+func (l *RateLimiter) refill(tokens float64) time.Duration {
+	if l.rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweepLocked forgets buckets that would be full by now, which a new bucket is too
+func (l *RateLimiter) sweepLocked(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.at).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// client names the bucket a request draws from: its API key when it has a verified
+// one, else its IP
+func (l *RateLimiter) client(r *http.Request) string {
+	if l.Key != nil {
+		if key := l.Key(r); key != "" {
+			return "key:" + key
+		}
+	}
+	return "ip:" + clientHost(r)
+}
+
+// Middleware refuses requests from clients over their rate with 429, setting
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset (seconds until the
+// bucket is full) on every response
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decision := l.Take(l.client(r))
+
+		header := w.Header()
+		header.Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(ceilSeconds(decision.Reset), 10))
+		if !decision.Allowed {
+			header.Set("Retry-After", strconv.FormatInt(ceilSeconds(decision.RetryAfter), 10))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ceilSeconds rounds d up to whole seconds, as the rate limit headers are given in
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
+
+// clientHost returns the IP a request came from, without its port
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,192 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+// ErrNotFound is returned by Target.Fetch when the external system has no rating for a user
+var ErrNotFound = errors.New("mirror: user not found on target")
+
+// Change is one user's rating as it should appear on the external system. It is the
+// data available to the URL and body templates.
+type Change struct {
+	Board    string
+	Username string
+	Rating   int64
+	Previous int64 // last rating pushed for the user
+	New      bool  // true when the user has never been pushed
+}
+
+// templateFuncs are available in every mirror template: json quotes a value and path
+// escapes a URL path segment
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"path": url.PathEscape,
+}
+
+// Target is a generic HTTP leaderboard service. Ratings are written by rendering the
+// push templates for each change; when a fetch URL is set, the current remote rating
+// is read from the JSON field at RatingField (a dot-separated path).
+type Target struct {
+	method      string
+	pushURL     *template.Template
+	body        *template.Template
+	fetchURL    *template.Template
+	ratingField string
+
+	// Token, when set, is sent as a bearer token; it is called per request so
+	// rotated credentials take effect immediately
+	Token  func() string
+	Client *http.Client
+}
+
+// DefaultBody pushes {"username": ..., "rating": ...}
+const DefaultBody = `{"username":{{json .Username}},"rating":{{.Rating}}}`
+
+// NewTarget parses the templates for a target. fetchURL may be empty when the
+// conflict policy never needs the remote rating.
+func NewTarget(method, pushURL, body, fetchURL, ratingField string) (*Target, error) {
+	if method == "" {
+		method = http.MethodPut
+	}
+	if body == "" {
+		body = DefaultBody
+	}
+	if ratingField == "" {
+		ratingField = "rating"
+	}
+
+	t := &Target{method: strings.ToUpper(method), ratingField: ratingField}
+	var err error
+	if t.pushURL, err = template.New("url").Funcs(templateFuncs).Parse(pushURL); err != nil {
+		return nil, fmt.Errorf("push URL template: %w", err)
+	}
+	if t.body, err = template.New("body").Funcs(templateFuncs).Parse(body); err != nil {
+		return nil, fmt.Errorf("body template: %w", err)
+	}
+	if fetchURL != "" {
+		if t.fetchURL, err = template.New("fetch").Funcs(templateFuncs).Parse(fetchURL); err != nil {
+			return nil, fmt.Errorf("fetch URL template: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// CanFetch reports whether the target can read remote ratings
+func (t *Target) CanFetch() bool {
+	return t.fetchURL != nil
+}
+
+// Push writes one change to the external system
+func (t *Target) Push(ctx context.Context, change Change) error {
+	target, err := render(t.pushURL, change)
+	if err != nil {
+		return err
+	}
+	body, err := render(t.body, change)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.method, target, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s", t.method, target, resp.Status)
+	}
+	return nil
+}
+
+// Fetch reads a user's current rating from the external system
+func (t *Target) Fetch(ctx context.Context, change Change) (int64, error) {
+	if t.fetchURL == nil {
+		return 0, errors.New("mirror: no fetch URL configured")
+	}
+	target, err := render(t.fetchURL, change)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := t.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return 0, fmt.Errorf("GET %s: %s", target, resp.Status)
+	}
+
+	var doc interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return 0, fmt.Errorf("decode %s: %w", target, err)
+	}
+	rating, ok := lookup(doc, t.ratingField).(float64)
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return int64(math.Round(rating)), nil
+}
+
+func (t *Target) do(req *http.Request) (*http.Response, error) {
+	if t.Token != nil {
+		if token := t.Token(); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+func render(tmpl *template.Template, change Change) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, change); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// lookup follows a dot-separated path through nested JSON objects
+func lookup(value interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
@@ -0,0 +1,215 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"leaderboard-api/store"
+	"log"
+	"sync"
+	"time"
+)
+
+// Conflict policies decide what happens when the external system already has a rating
+const (
+	ConflictOverwrite    = "overwrite"     // always push the local rating
+	ConflictKeepHigher   = "keep-higher"   // push only if the local rating is higher than the remote one
+	ConflictSkipExisting = "skip-existing" // only push users the target doesn't have yet
+)
+
+// pageSize is how many entries are read from the board per call while scanning for changes
+const pageSize = 1000
+
+// ParseConflictPolicy validates a conflict policy name; an empty name means overwrite
+func ParseConflictPolicy(name string) (string, error) {
+	switch name {
+	case "":
+		return ConflictOverwrite, nil
+	case ConflictOverwrite, ConflictKeepHigher, ConflictSkipExisting:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q (want overwrite, keep-higher or skip-existing)", name)
+	}
+}
+
+// Stats summarizes the worker's progress
+type Stats struct {
+	Board     string    `json:"board"`
+	Conflict  string    `json:"conflict"`
+	Tracked   int       `json:"tracked"` // users whose current rating the target has been brought in line with
+	Pushed    uint64    `json:"pushed"`
+	Skipped   uint64    `json:"skipped"` // changes the conflict policy kept off the target
+	Failed    uint64    `json:"failed"`
+	LastSync  time.Time `json:"lastSync"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Worker pushes local rating changes on a board to a Target. It scans the board
+// whenever its version moves and pushes every user whose rating differs from what
+// was last pushed; failed pushes are retried on the next scan. With backfill the
+// first scan pushes every user, otherwise it only records the starting ratings.
+// Users removed locally are not removed from the target.
+type Worker struct {
+	leaderboard store.Store
+	target      *Target
+	conflict    string
+	backfill    bool
+
+	// Sync state, guarded by syncMu for the duration of a sync
+	syncMu      sync.Mutex
+	pushed      map[string]int64 // last rating the target was brought in line with, per user
+	primed      bool
+	lastVersion uint64
+	retry       bool
+
+	statsMu sync.Mutex
+	stats   Stats
+
+	stopChan chan struct{}
+	once     sync.Once
+}
+
+// NewWorker creates a worker mirroring lb to target
+func NewWorker(lb store.Store, target *Target, conflict string, backfill bool) *Worker {
+	return &Worker{
+		leaderboard: lb,
+		target:      target,
+		conflict:    conflict,
+		backfill:    backfill,
+		pushed:      make(map[string]int64),
+		stats:       Stats{Board: lb.Metadata().Name, Conflict: conflict},
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start syncs immediately and then every interval until Stop
+func (mw *Worker) Start(interval time.Duration) {
+	go func() {
+		mw.Sync()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				mw.Sync()
+			case <-mw.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic syncs
+func (mw *Worker) Stop() {
+	mw.once.Do(func() { close(mw.stopChan) })
+}
+
+// Stats returns a snapshot of the worker's counters. A nil worker has none.
+func (mw *Worker) Stats() *Stats {
+	if mw == nil {
+		return nil
+	}
+	mw.statsMu.Lock()
+	defer mw.statsMu.Unlock()
+	stats := mw.stats
+	return &stats
+}
+
+// Sync pushes every change since the last sync
+func (mw *Worker) Sync() {
+	mw.syncMu.Lock()
+	defer mw.syncMu.Unlock()
+
+	version := mw.leaderboard.Version()
+	if mw.primed && version == mw.lastVersion && !mw.retry {
+		return
+	}
+	current := mw.scan()
+
+	if !mw.primed && !mw.backfill {
+		mw.pushed = current
+		mw.primed, mw.lastVersion = true, version
+		mw.record(func(stats *Stats) { stats.Tracked = len(mw.pushed) })
+		return
+	}
+
+	mw.retry = false
+	for username, rating := range current {
+		previous, known := mw.pushed[username]
+		if known && previous == rating {
+			continue
+		}
+		change := Change{
+			Board:    mw.leaderboard.Metadata().Name,
+			Username: username,
+			Rating:   rating,
+			Previous: previous,
+			New:      !known,
+		}
+		if err := mw.apply(change); err != nil {
+			mw.record(func(stats *Stats) {
+				stats.Failed++
+				stats.LastError = err.Error()
+			})
+			mw.retry = true
+			continue
+		}
+		mw.pushed[username] = rating
+	}
+
+	mw.primed, mw.lastVersion = true, version
+	mw.record(func(stats *Stats) {
+		stats.Tracked = len(mw.pushed)
+		stats.LastSync = time.Now()
+		if mw.retry {
+			log.Printf("Mirror sync of %s incomplete: %s", stats.Board, stats.LastError)
+		}
+	})
+}
+
+// record updates the stats under their lock
+func (mw *Worker) record(update func(stats *Stats)) {
+	mw.statsMu.Lock()
+	defer mw.statsMu.Unlock()
+	update(&mw.stats)
+}
+
+// apply runs the conflict policy for one change and pushes it if the policy allows
+func (mw *Worker) apply(change Change) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if mw.conflict != ConflictOverwrite {
+		remote, err := mw.target.Fetch(ctx, change)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			return err
+		case mw.conflict == ConflictSkipExisting, remote >= change.Rating:
+			mw.record(func(stats *Stats) { stats.Skipped++ })
+			return nil
+		}
+	}
+
+	if err := mw.target.Push(ctx, change); err != nil {
+		return err
+	}
+	mw.record(func(stats *Stats) { stats.Pushed++ })
+	return nil
+}
+
+// scan reads every user's current rating from the board
+func (mw *Worker) scan() map[string]int64 {
+	ratings := make(map[string]int64, len(mw.pushed))
+	for offset := 0; ; offset += pageSize {
+		entries := mw.leaderboard.GetLeaderboard(pageSize, offset)
+		for _, entry := range entries {
+			ratings[entry.Username] = entry.Rating
+		}
+		if len(entries) < pageSize {
+			return ratings
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry is one record of the hash-chained audit log. Hash covers every other
+// field plus PrevHash, so editing or removing an entry breaks every later link.
+type AuditEntry struct {
+	Seq       uint64          `json:"seq"`
+	At        time.Time       `json:"at"`
+	Action    string          `json:"action"`
+	Board     string          `json:"board,omitempty"`
+	Subject   string          `json:"subject,omitempty"` // usually the affected username
+	Data      json.RawMessage `json:"data,omitempty"`
+	PrevHash  string          `json:"prevHash"`
+	Hash      string          `json:"hash"`
+	KeyID     string          `json:"keyId,omitempty"`     // signing key fingerprint
+	Signature string          `json:"signature,omitempty"` // HMAC-SHA256 of Hash
+}
+
+// AuditVerification is the outcome of re-checking the audit chain
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int    `json:"entries"`
+	HeadHash string `json:"headHash"`
+	// First entry that failed and why; unset when the chain is intact
+	BrokenAt uint64 `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// Entries whose signature could not be checked because they were unsigned or
+	// signed with a key this process doesn't hold
+	Unverified int `json:"unverified"`
+}
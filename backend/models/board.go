@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+// Score units a board can declare
+const (
+	ScoreUnitPoints   = "points"
+	ScoreUnitTimeMs   = "time_ms"
+	ScoreUnitCurrency = "currency"
+)
+
+// Ranking modes, i.e. how tied users are ranked. For ratings 100, 90, 90, 80:
+//
+//	dense     1, 2, 2, 3
+//	standard  1, 2, 2, 4 ("1224" competition ranking)
+//	modified  1, 3, 3, 4 ("1334" competition ranking)
+//	ordinal   1, 2, 3, 4 (ties broken by the board's order)
+const (
+	RankingDense    = "dense"
+	RankingStandard = "standard"
+	RankingModified = "modified"
+	RankingOrdinal  = "ordinal"
+)
+
+// Tie-breaks order users who share a rank. They never change ranks, only the order
+// tied users are listed in (and so their ordinal rank).
+const (
+	TieBreakUsername = "username" // alphabetical
+	TieBreakAchieved = "achieved" // whoever reached the rating first
+)
+
+// ScoreFormat holds rendering hints so generic clients can display scores correctly
+type ScoreFormat struct {
+	Unit     string `json:"unit"`
+	Decimals int    `json:"decimals,omitempty"` // points and currency: scores are held in units of 10^-Decimals
+	Symbol   string `json:"symbol,omitempty"`   // currency: prefix such as "$"
+	Example  string `json:"example"`            // example rendering of a representative score
+}
+
+// Layouts a board's published standings can be held in. Columnar boards also keep
+// usernames and ratings as parallel slices, which range and percentile scans read
+// cache-friendly, and write their snapshots column by column, which is faster to
+// encode and decode than one object per user.
+const (
+	LayoutRows     = "rows"
+	LayoutColumnar = "columnar"
+)
+
+// SortKey is one component of a composite ranking: a field name ("rating" or a
+// plugin score) and its direction
+type SortKey struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// MetricWeight is one term of a composite score: a submitted metric and the weight it
+// carries in the board's rating
+type MetricWeight struct {
+	Metric string  `json:"metric"`
+	Weight float64 `json:"weight"`
+}
+
+// BoardMetadata describes a leaderboard and the semantics of its score
+type BoardMetadata struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	ScoreFormat ScoreFormat `json:"scoreFormat"`
+	SortKeys    []SortKey   `json:"sortKeys,omitempty"`    // empty means rank by rating only
+	RankingMode string      `json:"rankingMode,omitempty"` // empty means dense
+	TieBreak    string      `json:"tieBreak,omitempty"`    // empty means username
+
+	// When set, ratings are the weighted sum of these submitted metrics, rounded to the
+	// nearest integer (e.g. 0.7*points + 0.3*accuracy)
+	Metrics []MetricWeight `json:"metrics,omitempty"`
+
+	// Most users the board holds; past it the lowest-rated are evicted. 0 is unbounded.
+	Capacity int `json:"capacity,omitempty"`
+
+	Layout string `json:"layout,omitempty"` // empty means rows
+
+	// Bounds on the ratings clients may write; nil accepts any rating
+	Validation *ValidationRules `json:"validation,omitempty"`
+}
+
+// BoardSummary is a board's metadata along with its current size
+type BoardSummary struct {
+	BoardMetadata
+	TotalUsers int `json:"totalUsers"`
+}
+
+// FreezeStatus says whether a board's standings are frozen. While frozen, reads serve
+// the standings as of Since and writes accumulate unseen until the board is unfrozen.
+type FreezeStatus struct {
+	Frozen        bool       `json:"frozen"`
+	Since         *time.Time `json:"since,omitempty"`
+	FrozenVersion uint64     `json:"frozenVersion,omitempty"` // store version being served
+	PendingWrites uint64     `json:"pendingWrites,omitempty"` // versions written since the freeze
+}
+
+// MaintenanceStatus reports whether the API is read-only for maintenance. Writes are
+// refused with 503 while reads and streams keep serving the last state.
+type MaintenanceStatus struct {
+	Enabled    bool       `json:"enabled"`
+	Reason     string     `json:"reason,omitempty"`
+	Since      *time.Time `json:"since,omitempty"`
+	RetryAfter int        `json:"retryAfter,omitempty"` // seconds clients are told to wait
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Certificate attests a user's standing on a board at one moment. Digest covers the
+// standing fields; the audit entry the certificate was issued under records the
+// digest, so a certificate is checked against the hash-chained audit log rather than
+// trusted on its own.
+type Certificate struct {
+	Board           string    `json:"board"`
+	Username        string    `json:"username"`
+	DisplayName     string    `json:"displayName,omitempty"`
+	Rank            int       `json:"rank"`
+	CompetitionRank int       `json:"competitionRank"`
+	Rating          int64     `json:"rating"`
+	Display         string    `json:"display"`
+	Percentile      float64   `json:"percentile"`
+	TotalUsers      int       `json:"totalUsers"`
+	AsOf            time.Time `json:"asOf"`
+	BoardVersion    uint64    `json:"boardVersion"`
+
+	// SHA-256 of the fields above
+	Digest string `json:"digest"`
+
+	// Audit entry the certificate was issued under, and that entry's signature when
+	// the log is signed
+	AuditSeq  uint64 `json:"auditSeq"`
+	AuditHash string `json:"auditHash"`
+	KeyID     string `json:"keyId,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// CertificateVerification is the outcome of checking a certificate against the audit log
+type CertificateVerification struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"` // why the certificate was rejected
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Kinds of board change reported to observers
+const (
+	ChangeAdded   = "added"
+	ChangeUpdated = "updated"
+	ChangeRemoved = "removed"
+)
+
+// Where changes come from. Other sources, such as importers, name themselves.
+const (
+	ChangeSourceAPI       = "api"
+	ChangeSourceSimulator = "simulator"
+	ChangeSourceSystem    = "system" // background jobs such as decay and expiry
+)
+
+// ChangeEvent describes one change to a board. Ranks are standard competition ranks
+// (users rated strictly higher, plus one) taken at write time; RankBefore is 0 for an
+// added user and RankAfter 0 for a removed one.
+type ChangeEvent struct {
+	Seq        uint64    `json:"seq"`
+	Type       string    `json:"type"`
+	Username   string    `json:"username"`
+	OldRating  int64     `json:"oldRating"`
+	NewRating  int64     `json:"newRating"`
+	RankBefore int       `json:"rankBefore"`
+	RankAfter  int       `json:"rankAfter"`
+	At         time.Time `json:"at"`
+
+	// Who made the change: the source, and within it the caller where known (for the
+	// API, the X-Username header or the client address)
+	Source string `json:"source"`
+	Actor  string `json:"actor,omitempty"`
+}
+
+// RankCacheRebuild describes one rebuild of the ranked, indexed copy of a board that
+// reads are served from
+type RankCacheRebuild struct {
+	Version    uint64    `json:"version"` // board version the ranks reflect
+	Users      int       `json:"users"`
+	DurationMs float64   `json:"durationMs"`
+	At         time.Time `json:"at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// Trend directions
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendFlat = "flat"
+)
+
+// Comparison puts two users side by side for a versus screen. Gaps are A's lead over
+// B: positive when A is ahead, negative when B is.
+type Comparison struct {
+	A ComparedUser `json:"a"`
+	B ComparedUser `json:"b"`
+
+	RankGap   int    `json:"rankGap"`
+	RatingGap int64  `json:"ratingGap"`
+	Leader    string `json:"leader,omitempty"` // username of whoever ranks higher; empty when tied
+}
+
+// ComparedUser is one side of a Comparison
+type ComparedUser struct {
+	SearchResult
+
+	// Recent rating movement, on boards that keep history
+	Trend *Trend `json:"trend,omitempty"`
+}
+
+// Trend summarizes a user's most recent rating changes
+type Trend struct {
+	Direction string         `json:"direction"`
+	Delta     int64          `json:"delta"` // net change across Recent
+	Recent    []RatingChange `json:"recent"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Kinds of goal a user can set
+const (
+	GoalRating = "rating" // reach a rating of at least Target
+	GoalRank   = "rank"   // reach a standard competition rank of Target or better
+)
+
+// Goal is a target a user has set themselves. Goals are replaced rather than changed
+// in place, so views can share them with the live records.
+type Goal struct {
+	Type   string `json:"type"`
+	Target int64  `json:"target"`
+
+	// Where the user stood when they set the goal, for measuring progress
+	StartRating int64     `json:"startRating"`
+	StartRank   int       `json:"startRank"`
+	SetAt       time.Time `json:"setAt"`
+
+	AchievedAt *time.Time `json:"achievedAt,omitempty"`
+}
+
+// GoalProgress is a goal with how far along the user is
+type GoalProgress struct {
+	Goal
+
+	CurrentRating int64   `json:"currentRating"`
+	CurrentRank   int     `json:"currentRank"`
+	Progress      float64 `json:"progress"` // from 0 at the start to 1 once reached
+	Achieved      bool    `json:"achieved"`
+}
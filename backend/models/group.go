@@ -0,0 +1,20 @@
+package models
+
+// GroupEntry is a ranked entry on a co-op board whose members share a score
+type GroupEntry struct {
+	Rank    int      `json:"rank"`
+	GroupID string   `json:"groupId"`
+	Members []string `json:"members"`
+	Rating  int64    `json:"rating"`
+	Display string   `json:"display"`
+}
+
+// TeamEntry is a ranked team whose score is aggregated from its members' ratings
+type TeamEntry struct {
+	Rank        int      `json:"rank"`
+	Team        string   `json:"team"`
+	MemberCount int      `json:"memberCount"`
+	Members     []string `json:"members,omitempty"` // only on single-team lookups
+	Rating      int64    `json:"rating"`
+	Display     string   `json:"display"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// MatchPlayer is one participant's result within a match
+type MatchPlayer struct {
+	Username     string `json:"username"`
+	Score        int    `json:"score"`
+	RatingBefore int64  `json:"ratingBefore"`
+	RatingAfter  int64  `json:"ratingAfter"`
+	RatingDelta  int64  `json:"ratingDelta"`
+}
+
+// Match is an immutable record of a submitted match
+type Match struct {
+	ID       int64         `json:"id"`
+	Players  []MatchPlayer `json:"players"`
+	PlayedAt time.Time     `json:"playedAt"`
+	Draw     bool          `json:"draw,omitempty"` // rated matches only: the two players drew
+}
@@ -0,0 +1,241 @@
+package models
+
+import "time"
+
+// CacheStats reports the effectiveness of a cache
+type CacheStats struct {
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hitRate"`
+	Entries int     `json:"entries"`
+	Version uint64  `json:"version"` // store version the cached entries belong to
+}
+
+// CoalescingStats reports how many identical concurrent reads shared one computation
+type CoalescingStats struct {
+	Computed uint64 `json:"computed"` // reads that ran the computation
+	Shared   uint64 `json:"shared"`   // reads that waited for one already running
+	InFlight int    `json:"inFlight"`
+}
+
+// PropagationStats summarizes how long writes took to reach stream clients.
+// Percentiles are histogram bucket upper bounds.
+type PropagationStats struct {
+	Deliveries uint64  `json:"deliveries"`
+	AvgMs      float64 `json:"avgMs"`
+	P50Ms      float64 `json:"p50Ms"`
+	P99Ms      float64 `json:"p99Ms"`
+	MaxMs      float64 `json:"maxMs"`
+}
+
+// LatencyEvent is sent on a stream after a frame that carried new writes: when the
+// oldest of them was made, and when the frame went out
+type LatencyEvent struct {
+	Version     uint64    `json:"version"` // version the frame reflects
+	WrittenAt   time.Time `json:"writtenAt"`
+	DeliveredAt time.Time `json:"deliveredAt"`
+	LatencyMs   float64   `json:"latencyMs"`
+}
+
+// LockStats summarizes how long callers waited for and held a store lock.
+// P99 figures are histogram bucket upper bounds.
+type LockStats struct {
+	Acquisitions uint64  `json:"acquisitions"`
+	WaitAvgMs    float64 `json:"waitAvgMs"`
+	WaitP99Ms    float64 `json:"waitP99Ms"`
+	WaitMaxMs    float64 `json:"waitMaxMs"`
+	HoldAvgMs    float64 `json:"holdAvgMs"`
+	HoldP99Ms    float64 `json:"holdP99Ms"`
+	HoldMaxMs    float64 `json:"holdMaxMs"`
+}
+
+// CompactionReport describes one compaction run. Heap figures are process-wide, in
+// bytes, measured after a full garbage collection.
+type CompactionReport struct {
+	Board             string  `json:"board"`
+	Users             int     `json:"users"`
+	UserCapacityFreed int     `json:"userCapacityFreed"` // unused user slice slots dropped
+	DeltaBuckets      int     `json:"deltaBuckets"`
+	HeapBefore        uint64  `json:"heapBefore"`
+	HeapAfter         uint64  `json:"heapAfter"`
+	Reclaimed         uint64  `json:"reclaimed"`
+	ReleasedToOS      uint64  `json:"releasedToOS"`
+	DurationMs        float64 `json:"durationMs"`
+}
+
+// MigrationStats reports how a dual-write backend migration is going
+type MigrationStats struct {
+	ReadFrom        string       `json:"readFrom"` // "primary" or "secondary"
+	Reads           uint64       `json:"reads"`
+	Verified        uint64       `json:"verified"`        // reads repeated against the other store
+	Divergences     uint64       `json:"divergences"`     // verified reads that didn't match
+	WriteMismatches uint64       `json:"writeMismatches"` // writes only one store applied
+	Recent          []Divergence `json:"recent"`
+}
+
+// Divergence is one observed difference between the two stores of a migration
+type Divergence struct {
+	Op     string    `json:"op"`
+	Detail string    `json:"detail"`
+	At     time.Time `json:"at"`
+}
+
+// BackfillReport describes copying a migration's primary store into its secondary
+type BackfillReport struct {
+	Copied     int     `json:"copied"`
+	Corrected  int     `json:"corrected"` // secondary ratings that differed
+	Removed    int     `json:"removed"`   // secondary users the primary doesn't have
+	DurationMs float64 `json:"durationMs"`
+}
+
+// ConsistencyReport is the outcome of one consistency check
+type ConsistencyReport struct {
+	At           time.Time `json:"at"`
+	Sampled      int       `json:"sampled"`
+	ViewDrift    int       `json:"viewDrift"`    // published view disagreed with the records
+	TreeDrift    int       `json:"treeDrift"`    // rating tree ranks disagreed with the records
+	DurableDrift int       `json:"durableDrift"` // snapshot disagreed with the records
+	Details      []string  `json:"details"`
+	Repairs      []string  `json:"repairs"`
+	DurationMs   float64   `json:"durationMs"`
+}
+
+// ConsistencyStats totals every consistency check so far
+type ConsistencyStats struct {
+	Checks       uint64             `json:"checks"`
+	Sampled      uint64             `json:"sampled"`
+	ViewDrift    uint64             `json:"viewDrift"`
+	TreeDrift    uint64             `json:"treeDrift"`
+	DurableDrift uint64             `json:"durableDrift"`
+	Repairs      uint64             `json:"repairs"`
+	Last         *ConsistencyReport `json:"last,omitempty"`
+}
+
+// SnapshotStats describes the periodic snapshots persisting a board. PendingWrites
+// counts versions written since the last snapshot saved, all of them before the first.
+type SnapshotStats struct {
+	Path            string     `json:"path"`
+	IntervalMs      float64    `json:"intervalMs"`
+	Saves           uint64     `json:"saves"`
+	Failures        uint64     `json:"failures"`
+	LastSavedAt     *time.Time `json:"lastSavedAt,omitempty"`
+	LastVersion     uint64     `json:"lastVersion"`
+	LastDurationMs  float64    `json:"lastDurationMs"`
+	LastError       string     `json:"lastError,omitempty"` // error of the latest attempt, if it failed
+	SinceLastSaveMs float64    `json:"sinceLastSaveMs,omitempty"`
+	PendingWrites   uint64     `json:"pendingWrites"`
+}
+
+// Diagnostic check outcomes, worst last
+const (
+	DiagnosticOK      = "ok"
+	DiagnosticWarn    = "warn"
+	DiagnosticFailing = "failing"
+	DiagnosticSkipped = "skipped" // the check doesn't apply to this deployment
+)
+
+// DiagnosticCheck is the outcome of one self-check
+type DiagnosticCheck struct {
+	Name       string      `json:"name"`
+	Status     string      `json:"status"`
+	Message    string      `json:"message"`
+	DurationMs float64     `json:"durationMs"`
+	Details    interface{} `json:"details,omitempty"`
+}
+
+// DiagnosticsReport is a battery of self-checks run together. Status is the worst of
+// the checks'.
+type DiagnosticsReport struct {
+	At         time.Time         `json:"at"`
+	Status     string            `json:"status"`
+	Checks     []DiagnosticCheck `json:"checks"`
+	DurationMs float64           `json:"durationMs"`
+}
+
+// GoroutineSummary groups the process's goroutines by state and by the function
+// they are in
+type GoroutineSummary struct {
+	Total     int              `json:"total"`
+	ByState   map[string]int   `json:"byState"`
+	Functions []GoroutineGroup `json:"functions"` // the busiest, most goroutines first
+}
+
+// GoroutineGroup counts goroutines sitting in one function
+type GoroutineGroup struct {
+	Function string `json:"function"`
+	Count    int    `json:"count"`
+}
+
+// ChangeStats counts a board's changes by kind since startup. Dropped counts changes
+// any subscriber missed by falling behind.
+type ChangeStats struct {
+	Added   uint64 `json:"added"`
+	Updated uint64 `json:"updated"`
+	Removed uint64 `json:"removed"`
+	Dropped uint64 `json:"dropped"`
+}
+
+// BroadcastStats describes the pages fanned out to SSE connections
+type BroadcastStats struct {
+	Topics      int    `json:"topics"`      // distinct pages being streamed
+	Subscribers int    `json:"subscribers"` // connections following them
+	Published   uint64 `json:"published"`   // frames encoded and fanned out
+}
+
+// CompressionStats reports how much compression saved on one stream connection, or
+// on every stream connection since startup. RawBytes is what was written before
+// compression; WireBytes what was sent.
+type CompressionStats struct {
+	Encoding     string  `json:"encoding,omitempty"`     // "gzip" or "identity", on one connection
+	Compressed   uint64  `json:"compressed,omitempty"`   // connections that negotiated gzip, in totals
+	Uncompressed uint64  `json:"uncompressed,omitempty"` // connections that didn't, in totals
+	RawBytes     int64   `json:"rawBytes"`
+	WireBytes    int64   `json:"wireBytes"`
+	SavedPercent float64 `json:"savedPercent"`
+}
+
+// Simulator alert types
+const (
+	SimulatorPaused  = "simulator_paused"
+	SimulatorResumed = "simulator_resumed"
+)
+
+// SimulatorAlert is raised when the load simulator pauses itself because store writes
+// got slow or started failing, and again when it resumes
+type SimulatorAlert struct {
+	Seq    uint64      `json:"seq"`
+	Type   string      `json:"type"`
+	Reason string      `json:"reason"`
+	Window WriteHealth `json:"window"` // the window that triggered the alert
+	At     time.Time   `json:"at"`
+}
+
+// WriteHealth summarizes one window of the simulator's store writes
+type WriteHealth struct {
+	Writes       int     `json:"writes"`
+	Errors       int     `json:"errors"`
+	ErrorRate    float64 `json:"errorRate"`
+	LatencyP99Ms float64 `json:"latencyP99Ms"`
+}
+
+// SimulatorStatus reports whether the load simulator is writing, and the health it
+// last measured
+type SimulatorStatus struct {
+	Running      bool             `json:"running"`
+	Paused       bool             `json:"paused"`
+	PausedSince  *time.Time       `json:"pausedSince,omitempty"`
+	LastWindow   WriteHealth      `json:"lastWindow"`
+	MaxLatencyMs float64          `json:"maxLatencyMs,omitempty"` // pause thresholds; unset when the kill switch is off
+	MaxErrorRate float64          `json:"maxErrorRate,omitempty"`
+	Alerts       []SimulatorAlert `json:"alerts"` // most recent last
+}
+
+// PublisherStats reports on background view rebuilds
+type PublisherStats struct {
+	IntervalMs       float64 `json:"intervalMs"`
+	Rebuilds         uint64  `json:"rebuilds"`
+	Skipped          uint64  `json:"skipped"` // ticks with no writes to publish
+	LastBuildMs      float64 `json:"lastBuildMs"`
+	SinceLastBuildMs float64 `json:"sinceLastBuildMs"`
+	PendingWrites    uint64  `json:"pendingWrites"` // versions written since the published view
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Milestone event types
+const (
+	MilestoneTopN          = "entered_top"    // the user's rank entered the top Threshold
+	MilestoneFirstPlace    = "took_first"     // the user took #1
+	MilestoneRatingReached = "rating_reached" // the user's rating reached the round rating Threshold
+	MilestoneGoalReached   = "goal_reached"   // the user reached the goal they set; Threshold is its target
+)
+
+// MilestoneEvent is emitted when a rating change carries a user across a notable
+// rank or rating. Ranks are standard competition ranks by rating.
+type MilestoneEvent struct {
+	Seq        uint64    `json:"seq"`
+	Type       string    `json:"type"`
+	Username   string    `json:"username"`
+	Threshold  int64     `json:"threshold,omitempty"` // the rank or rating crossed
+	GoalType   string    `json:"goalType,omitempty"`  // for goal_reached, whether the goal was a rating or a rank
+	RankBefore int       `json:"rankBefore"`
+	RankAfter  int       `json:"rankAfter"`
+	OldRating  int64     `json:"oldRating"`
+	NewRating  int64     `json:"newRating"`
+	Timestamp  time.Time `json:"timestamp"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Podium is the top of a board: the best three users with their profiles, and how
+// the leader stands
+type Podium struct {
+	Places []SearchResult `json:"places"`
+
+	// When the current leader took #1 and how many seconds they have held it. A reign
+	// already under way when tracking began counts from then.
+	LeaderSince  *time.Time `json:"leaderSince,omitempty"`
+	ReignSeconds int64      `json:"reignSeconds"`
+
+	// Rating the leader is ahead of #2 by; 0 on a tie or with fewer than two users
+	GapToSecond        int64  `json:"gapToSecond"`
+	GapToSecondDisplay string `json:"gapToSecondDisplay"`
+}
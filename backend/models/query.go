@@ -0,0 +1,50 @@
+package models
+
+import "encoding/json"
+
+// Query is a constrained analytics question over a board's users: keep the users
+// matching every filter, split them into groups and aggregate each group.
+//
+// Numeric fields are "rating", "rank", "peakRating", "lowestRating", "gamesPlayed",
+// "wins", "winRate" (basis points) and "scores.<name>" for plugin scores; text fields
+// are "username", "source", "tier", "country" and "displayName".
+type Query struct {
+	Filters []QueryFilter `json:"filters,omitempty"`
+
+	// GroupBy is "band" (rating bands BandWidth wide), "tier", "source", "country", or
+	// empty for a single group of every matched user
+	GroupBy   string `json:"groupBy,omitempty"`
+	BandWidth int64  `json:"bandWidth,omitempty"` // default 500
+
+	Aggregations []QueryAggregation `json:"aggregations"`
+}
+
+// QueryFilter compares a field against a value. Numeric fields take eq, ne, gt, gte,
+// lt and lte; text fields take eq, ne and prefix; both take in, with an array value.
+type QueryFilter struct {
+	Field string          `json:"field"`
+	Op    string          `json:"op"`
+	Value json.RawMessage `json:"value"`
+}
+
+// QueryAggregation is one figure computed per group: "count", or "sum", "avg", "min"
+// or "max" of a numeric field
+type QueryAggregation struct {
+	Op    string `json:"op"`
+	Field string `json:"field,omitempty"`
+}
+
+// QueryGroup holds one group's aggregates, keyed like "count" or "avg(rating)". Only
+// groups with matched users are reported.
+type QueryGroup struct {
+	Key    string                 `json:"key"`
+	Values map[string]interface{} `json:"values"`
+}
+
+// QueryResult answers a Query against one version of a board
+type QueryResult struct {
+	Board   string       `json:"board"`
+	Version uint64       `json:"version"`
+	Matched int          `json:"matched"`
+	Groups  []QueryGroup `json:"groups"`
+}
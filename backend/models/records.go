@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Records are a board's all-time bests. Each is nil until first set.
+type Records struct {
+	LongestReign      *ReignRecord  `json:"longestReign,omitempty"`
+	HighestRating     *RatingRecord `json:"highestRating,omitempty"`
+	BiggestDailyClimb *RatingRecord `json:"biggestDailyClimb,omitempty"` // most rating gained in 24 hours
+}
+
+// ReignRecord is a spell at #1. Until is when the reign ended, or when it was last
+// seen for one still under way.
+type ReignRecord struct {
+	Username string    `json:"username"`
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+	Seconds  int64     `json:"seconds"`
+	Ongoing  bool      `json:"ongoing,omitempty"`
+}
+
+// RatingRecord is a rating (or gain) record and when it was set
+type RatingRecord struct {
+	Username string    `json:"username"`
+	Value    int64     `json:"value"`
+	Display  string    `json:"display"`
+	At       time.Time `json:"at"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// Batch score outcomes
+const (
+	ScoreApplied          = "applied"
+	ScoreUserNotFound     = "user-not-found"
+	ScoreValidationFailed = "validation-failed" // see Reason and Violation
+	ScoreFailed           = "failed"            // the store couldn't apply it; safe to retry
+)
+
+// ScoreUpdate sets one user's rating as part of a batch
+type ScoreUpdate struct {
+	Username string `json:"username"`
+	Rating   int64  `json:"rating"`
+}
+
+// ScoreResult reports what happened to one entry of a batch, so importers can retry
+// only the ones that failed
+type ScoreResult struct {
+	Username string `json:"username"`
+	Status   string `json:"status"`
+	Rating   int64  `json:"rating,omitempty"` // rating stored, when applied
+	Reason   string `json:"reason,omitempty"`
+
+	// Rule the rating broke, when refused by the board's validation rules
+	Violation *RuleViolation `json:"violation,omitempty"`
+}
+
+// RankPrediction is where a rating would land if submitted now, computed without
+// changing the board
+type RankPrediction struct {
+	Rating          int64 `json:"rating"`
+	CompetitionRank int   `json:"competitionRank"` // users rated strictly higher, plus one
+	TotalUsers      int   `json:"totalUsers"`
+
+	// What it takes to reach a rank, when one was asked for
+	Target *RankTarget `json:"target,omitempty"`
+}
+
+// RankTarget is the rating needed to reach a rank
+type RankTarget struct {
+	Rank         int   `json:"rank"`
+	Rating       int64 `json:"rating"`       // lowest rating ranked this high or better, tying whoever is there now
+	PointsNeeded int64 `json:"pointsNeeded"` // zero when the rating already reaches it
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// SeasonSummary describes an archived season without its standings
+type SeasonSummary struct {
+	ID         int       `json:"id"`
+	Board      string    `json:"board"`
+	StartedAt  time.Time `json:"startedAt"`
+	EndedAt    time.Time `json:"endedAt"`
+	TotalUsers int       `json:"totalUsers"`
+
+	// Submissions dated within the season that arrived after it was archived
+	LateSubmissions int `json:"lateSubmissions,omitempty"`
+
+	// Finalized seasons no longer accept late submissions; their standings are final
+	Finalized   bool       `json:"finalized"`
+	FinalizedAt *time.Time `json:"finalizedAt,omitempty"`
+}
+
+// Season is an immutable snapshot of final standings
+type Season struct {
+	SeasonSummary
+	Standings []LeaderboardEntry `json:"-"`
+}
+
+// ResetPolicy controls how ratings are reset when a season rotates
+type ResetPolicy struct {
+	Mode       string  `json:"mode"`       // "hard" (everyone to baseRating) or "soft"
+	BaseRating int64   `json:"baseRating"` // rating everyone is pulled towards, in stored units; default 1500 points
+	Factor     float64 `json:"factor"`     // soft: fraction of distance from base that is kept
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// SeriesState tracks an in-progress promotion or demotion series for a user
+type SeriesState struct {
+	Type      string    `json:"type"` // "promotion" or "demotion"
+	FromTier  string    `json:"fromTier"`
+	ToTier    string    `json:"toTier"`
+	Wins      int       `json:"wins"`
+	Losses    int       `json:"losses"`
+	BestOf    int       `json:"bestOf"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// SeriesEvent is emitted when a series starts or finishes
+type SeriesEvent struct {
+	Seq       uint64      `json:"seq"`
+	Type      string      `json:"type"` // "series_started", "series_won" or "series_lost"
+	Username  string      `json:"username"`
+	Series    SeriesState `json:"series"`
+	Timestamp time.Time   `json:"timestamp"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Submission outcomes
+const (
+	SubmissionApplied    = "applied"    // the rating was applied
+	SubmissionArchived   = "archived"   // dated in a past season; applied to its archived standings
+	SubmissionSuperseded = "superseded" // a submission made later was already applied
+	SubmissionDuplicate  = "duplicate"  // this submission ID was already applied
+	SubmissionRejected   = "rejected"   // invalid; see Reason
+	SubmissionFinalized  = "finalized"  // dated in a window or season that can no longer change
+)
+
+// Submission is a timestamped rating reported by a client, possibly queued while
+// offline and delivered late or out of order
+type Submission struct {
+	ID       string    `json:"id,omitempty"` // client-chosen; makes retries idempotent
+	Username string    `json:"username"`
+	Rating   int64     `json:"rating"`
+	At       time.Time `json:"at"` // when the client recorded the rating
+}
+
+// SubmissionResult reports what happened to one submission in a batch
+type SubmissionResult struct {
+	ID       string    `json:"id,omitempty"`
+	Username string    `json:"username"`
+	At       time.Time `json:"at"`
+	Status   string    `json:"status"`
+	Rating   int64     `json:"rating,omitempty"` // rating stored after applying, when applied
+	Reason   string    `json:"reason,omitempty"`
+
+	// Rule the rating broke, when rejected by the board's validation rules
+	Violation *RuleViolation `json:"violation,omitempty"`
+
+	// Late submissions belong to a period that had closed when they arrived: an
+	// archived season, or an earlier day whose windows are credited with the gain
+	Late   bool `json:"late,omitempty"`
+	Season int  `json:"season,omitempty"` // archived season the submission was routed to
+}
@@ -1,26 +1,173 @@
 package models
 
+import "time"
+
+// Profile is how a user presents on the board. Leaderboard and search results carry
+// it, so frontends don't need a second profile service.
+type Profile struct {
+	Country     string `json:"country,omitempty"`     // ISO 3166-1 alpha-2 code, e.g. "SE"
+	AvatarURL   string `json:"avatarUrl,omitempty"`   // absolute http(s) URL
+	DisplayName string `json:"displayName,omitempty"` // shown in place of the username
+}
+
+// ProfileUpdate changes a user's profile. Nil fields are left alone; an empty string
+// clears the field.
+type ProfileUpdate struct {
+	Country     *string `json:"country"`
+	AvatarURL   *string `json:"avatarUrl"`
+	DisplayName *string `json:"displayName"`
+}
+
 type User struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Rating   int    `json:"rating"`
-	Rank     int    `json:"rank,omitempty"`
+	ID       string         `json:"id"`
+	Username string         `json:"username"`
+	Rating   int64          `json:"rating"`
+	Rank     int            `json:"rank,omitempty"`
+	Scores   map[string]int `json:"scores,omitempty"` // plugin score fields used by composite sort keys
+	Source   string         `json:"source,omitempty"` // upstream rating system the user was imported from
+
+	// ID the game backend knows the user by, unique on the board
+	ExternalID string `json:"externalId,omitempty"`
+
+	// Country, avatar and display name
+	Profile
+
+	// Raw metrics behind the rating, on boards with a composite score
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+
+	// Game record, usable as secondary sort keys ("gamesPlayed", "wins", "winRate")
+	GamesPlayed int `json:"gamesPlayed,omitempty"`
+	Wins        int `json:"wins,omitempty"`
+
+	// When the user's rating last changed, for "who got there first" tie-breaks
+	RatingUpdatedAt time.Time `json:"ratingUpdatedAt"`
+
+	// Highest and lowest ratings the user has held on this board
+	PeakRating   int64     `json:"peakRating"`
+	PeakRatingAt time.Time `json:"peakRatingAt"`
+	LowestRating int64     `json:"lowestRating"`
+
+	// When the user last reported a rating, whether or not it changed; rating decay
+	// for inactivity runs from here and has been applied up to DecayedThrough
+	LastActiveAt   time.Time `json:"lastActiveAt"`
+	DecayedThrough time.Time `json:"decayedThrough"`
+
+	// Target the user has set themselves, if any
+	Goal *Goal `json:"goal,omitempty"`
 }
 
 type LeaderboardEntry struct {
-	Rank     int    `json:"rank"`
-	Username string `json:"username"`
-	Rating   int    `json:"rating"`
+	Rank     int            `json:"rank"`
+	Username string         `json:"username"`
+	Rating   int64          `json:"rating"`
+	Display  string         `json:"display"` // rating rendered per the board's score format
+	Scores   map[string]int `json:"scores,omitempty"`
+
+	Metrics map[string]float64 `json:"metrics,omitempty"` // raw metrics behind a composite rating
+
+	Profile
+
+	GamesPlayed int `json:"gamesPlayed,omitempty"`
+	Wins        int `json:"wins,omitempty"`
+
+	// Rank on the whole board, on views scoped to a country where Rank is the rank
+	// within the country
+	GlobalRank int `json:"globalRank,omitempty"`
+
+	// Movement since the baseline standings (about an hour ago): positive RankDelta
+	// means the user climbed. New users were not on the board at the baseline.
+	RankDelta   int   `json:"rankDelta"`
+	RatingDelta int64 `json:"ratingDelta"`
+	New         bool  `json:"new,omitempty"`
 }
 
 type SearchResult struct {
-	GlobalRank int    `json:"globalRank"`
-	Username   string `json:"username"`
-	Rating     int    `json:"rating"`
+	ID              string             `json:"id,omitempty"`
+	ExternalID      string             `json:"externalId,omitempty"`
+	GlobalRank      int                `json:"globalRank"`
+	CompetitionRank int                `json:"competitionRank"` // users ranked strictly ahead, plus one ("1224" ranking)
+	Username        string             `json:"username"`
+	Rating          int64              `json:"rating"`
+	Percentile      float64            `json:"percentile"` // "top X%" of all users
+	Display         string             `json:"display"`    // rating rendered per the board's score format
+	Series          *SeriesState       `json:"series,omitempty"`
+	Source          string             `json:"source,omitempty"`  // upstream rating system, for imported users
+	Metrics         map[string]float64 `json:"metrics,omitempty"` // raw metrics behind a composite rating
+	GamesPlayed     int                `json:"gamesPlayed,omitempty"`
+	Wins            int                `json:"wins,omitempty"`
+
+	Profile
+
+	// Rating extremes, on boards that track them
+	PeakRating   *int64     `json:"peakRating,omitempty"`
+	PeakRatingAt *time.Time `json:"peakRatingAt,omitempty"`
+	LowestRating *int64     `json:"lowestRating,omitempty"`
+
+	Goal *GoalProgress `json:"goal,omitempty"`
 }
 
 type StatsResponse struct {
-	TotalUsers int `json:"totalUsers"`
-	MinRating  int `json:"minRating"`
-	MaxRating  int `json:"maxRating"`
+	TotalUsers int   `json:"totalUsers"`
+	MinRating  int64 `json:"minRating"`
+	MaxRating  int64 `json:"maxRating"`
+
+	// Distribution of ratings. Percentiles are nearest-rank: the lowest rating at or
+	// below which that share of users fall.
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"stdDev"` // population standard deviation
+	P50    int64   `json:"p50"`
+	P90    int64   `json:"p90"`
+	P99    int64   `json:"p99"`
+}
+
+// StatsSample is a board's stats at one moment, with the rate it was written to since
+// the sample before
+type StatsSample struct {
+	At            time.Time `json:"at"`
+	TotalUsers    int       `json:"totalUsers"`
+	AvgRating     float64   `json:"avgRating"`
+	MinRating     int64     `json:"minRating"`
+	MaxRating     int64     `json:"maxRating"`
+	UpdatesPerSec float64   `json:"updatesPerSec"`
+}
+
+// RatingChange is one entry in a user's rating history
+type RatingChange struct {
+	Old int64     `json:"old"`
+	New int64     `json:"new"`
+	At  time.Time `json:"at"`
+}
+
+type WindowEntry struct {
+	Rank     int    `json:"rank"`
+	Username string `json:"username"`
+	Rating   int64  `json:"rating"`
+	Gain     int64  `json:"gain"` // rating gained within the window
+}
+
+// Where a historical rank was worked out from
+const (
+	HistoricalFromHistory = "history" // the board's users' rating histories
+	HistoricalFromSeason  = "season"  // an archived season's final standings
+)
+
+// HistoricalRank is where a user stood at a past moment
+type HistoricalRank struct {
+	Username        string    `json:"username"`
+	At              time.Time `json:"at"`
+	Rating          int64     `json:"rating"`
+	Rank            int       `json:"rank"`            // dense
+	CompetitionRank int       `json:"competitionRank"` // users rated strictly higher, plus one
+	Percentile      float64   `json:"percentile"`      // "top X%" of the users counted
+	TotalUsers      int       `json:"totalUsers"`
+	Source          string    `json:"source"`
+	Season          int       `json:"season,omitempty"` // archived season, when from one
+
+	// Set when the user's own history doesn't reach back to At, so their rating is
+	// the oldest one still on record
+	Estimate bool `json:"estimate,omitempty"`
+
+	// How many other users' ratings were estimated the same way
+	Estimated int `json:"estimated,omitempty"`
 }
@@ -0,0 +1,29 @@
+package models
+
+// Validation rules a board can enforce on the ratings clients write
+const (
+	RuleMinRating = "min_rating" // ratings below MinRating are refused
+	RuleMaxRating = "max_rating" // ratings above MaxRating are refused
+	RuleMaxDelta  = "max_delta"  // one write may move a rating by at most MaxDelta
+	RuleMonotonic = "monotonic"  // ratings may only rise
+	RuleCustom    = "custom"     // a check registered by the embedding application
+)
+
+// ValidationRules bound the ratings clients may write to a board, so obviously bogus
+// submissions are refused. Limits are in stored units, as the API takes ratings.
+type ValidationRules struct {
+	MinRating *int64 `json:"minRating,omitempty"`
+	MaxRating *int64 `json:"maxRating,omitempty"`
+	MaxDelta  int64  `json:"maxDelta,omitempty"`  // 0 is unbounded
+	Monotonic bool   `json:"monotonic,omitempty"` // refuse any write that lowers a rating
+}
+
+// RuleViolation explains why a write was refused
+type RuleViolation struct {
+	Rule     string `json:"rule"`
+	Username string `json:"username"`
+	Rating   int64  `json:"rating"`             // rating the write asked for
+	Previous *int64 `json:"previous,omitempty"` // the user's rating before it; nil for a new user
+	Limit    *int64 `json:"limit,omitempty"`    // bound the write crossed, for rules that have one
+	Message  string `json:"message"`
+}
@@ -0,0 +1,58 @@
+// Package rating computes rating changes from match results
+package rating
+
+import "math"
+
+// DefaultK is the K-factor used when none is configured: the most a single game can
+// move a rating, in rating points
+const DefaultK = 32
+
+// Elo rates players with the Elo system. The zero value uses DefaultK on boards that
+// store whole rating points.
+type Elo struct {
+	// Most a single game can move a rating, in rating points
+	K float64
+
+	// Stored units per rating point, for boards that keep ratings with decimals
+	// (e.g. 100 for two decimals); 0 means 1
+	Scale int64
+}
+
+// NewElo returns an Elo engine with the given K-factor
+func NewElo(k float64) Elo {
+	return Elo{K: k}
+}
+
+func (e Elo) k() float64 {
+	if e.K <= 0 {
+		return DefaultK
+	}
+	return e.K
+}
+
+func (e Elo) scale() float64 {
+	if e.Scale <= 0 {
+		return 1
+	}
+	return float64(e.Scale)
+}
+
+// Expected returns the score a player rated a is expected to take against one rated b,
+// from 0 for a certain loss to 1 for a certain win
+func (e Elo) Expected(a, b int64) float64 {
+	diff := float64(b-a) / e.scale()
+	return 1 / (1 + math.Pow(10, diff/400))
+}
+
+// Result returns the rating changes for a game between winner and loser, or a draw
+// between them, in stored units. The changes cancel out, so the game moves no points
+// into or out of the board.
+func (e Elo) Result(winner, loser int64, draw bool) (winnerDelta, loserDelta int64) {
+	score := 1.0
+	if draw {
+		score = 0.5
+	}
+	change := e.k() * (score - e.Expected(winner, loser))
+	winnerDelta = int64(math.Round(change * e.scale()))
+	return winnerDelta, -winnerDelta
+}
@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultGrace is how long a rotated-out value keeps validating
+const DefaultGrace = time.Hour
+
+// FromEnv builds a manager from the SECRETS_* and VAULT_* variables. Providers are
+// consulted in order: Vault, mounted secret files, an env-file, then plain environment
+// variables as the fallback.
+//
+//	VAULT_ADDR, VAULT_TOKEN or VAULT_TOKEN_FILE, VAULT_MOUNT, VAULT_SECRET_PATH
+//	SECRETS_DIR       directory with one file per secret
+//	SECRETS_ENV_FILE  KEY=VALUE file
+//	SECRETS_GRACE     how long rotated-out values stay valid (default 1h)
+func FromEnv() (*Manager, error) {
+	providers := make([]Provider, 0, 4)
+
+	if addr := os.Getenv("VAULT_ADDR"); addr != "" {
+		token := os.Getenv("VAULT_TOKEN")
+		if path := os.Getenv("VAULT_TOKEN_FILE"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read vault token: %w", err)
+			}
+			token = strings.TrimSpace(string(data))
+		}
+		secretPath := os.Getenv("VAULT_SECRET_PATH")
+		if secretPath == "" {
+			secretPath = "leaderboard"
+		}
+		providers = append(providers, VaultProvider{
+			Addr:  addr,
+			Token: token,
+			Mount: os.Getenv("VAULT_MOUNT"),
+			Path:  secretPath,
+		})
+	}
+	if dir := os.Getenv("SECRETS_DIR"); dir != "" {
+		providers = append(providers, FileProvider{Dir: dir})
+	}
+	if path := os.Getenv("SECRETS_ENV_FILE"); path != "" {
+		providers = append(providers, EnvFileProvider{Path: path})
+	}
+	providers = append(providers, EnvProvider{})
+
+	grace := DefaultGrace
+	if v := os.Getenv("SECRETS_GRACE"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SECRETS_GRACE: %w", err)
+		}
+		grace = parsed
+	}
+	return NewManager(grace, providers...), nil
+}
@@ -0,0 +1,265 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotRotatable is returned by Rotate for secrets not registered as key lists
+var ErrNotRotatable = errors.New("secret cannot be rotated")
+
+// ErrKeyNotListed is returned by Rotate when the key to replace isn't in the list
+var ErrKeyNotListed = errors.New("key is not in the list")
+
+// secret is the cached state of one named secret
+type secret struct {
+	current   string
+	previous  string
+	rotatedAt time.Time
+
+	// The value the providers last returned, so a reload only overrides a rotation
+	// once the providers change
+	provided string
+
+	// Whether the value is a comma-separated list of client keys, which Rotate adds to
+	keys bool
+}
+
+// Manager caches secrets from a chain of providers and handles rotation. After a
+// rotation the previous value stays valid for a grace period so clients can roll over.
+type Manager struct {
+	mu        sync.RWMutex
+	providers []Provider
+	secrets   map[string]*secret
+	grace     time.Duration
+	listeners []func(name string)
+}
+
+// NewManager creates a manager that consults providers in order
+func NewManager(grace time.Duration, providers ...Provider) *Manager {
+	return &Manager{
+		providers: providers,
+		secrets:   make(map[string]*secret),
+		grace:     grace,
+	}
+}
+
+// Register loads a secret so it is tracked for reloads.
+// A secret missing from every provider is tracked with an empty value.
+func (m *Manager) Register(name string) error {
+	return m.register(name, false)
+}
+
+// RegisterKeys is Register for a comma-separated list of keys handed out to clients,
+// which may also be rotated with Rotate. Signing keys and credentials for other
+// services are registered with Register, so they are never generated or returned.
+func (m *Manager) RegisterKeys(name string) error {
+	return m.register(name, true)
+}
+
+func (m *Manager) register(name string, keys bool) error {
+	value, err := m.fetch(name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.secrets[name]; !exists {
+		m.secrets[name] = &secret{current: value, provided: value, keys: keys}
+	}
+	return nil
+}
+
+// Get returns the current value of a registered secret
+func (m *Manager) Get(name string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if s, exists := m.secrets[name]; exists {
+		return s.current
+	}
+	return ""
+}
+
+// Valid reports whether candidate matches the current value, or the previous value
+// within the grace period. A value may list several accepted keys separated by commas.
+func (m *Manager) Valid(name, candidate string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, exists := m.secrets[name]
+	if !exists || candidate == "" {
+		return false
+	}
+	if matchesAny(s.current, candidate) {
+		return true
+	}
+	return time.Since(s.rotatedAt) < m.grace && matchesAny(s.previous, candidate)
+}
+
+// matchesAny compares candidate against each comma-separated key in constant time
+func matchesAny(list, candidate string) bool {
+	matched := false
+	for _, key := range strings.Split(list, ",") {
+		key = strings.TrimSpace(key)
+		if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// OnChange registers a callback invoked with the secret name after it rotates
+func (m *Manager) OnChange(fn func(name string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// Reload re-reads every registered secret from the providers and returns the names
+// whose value changed. A secret rotated since the providers last changed keeps its
+// rotated value.
+func (m *Manager) Reload() ([]string, error) {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.secrets))
+	for name := range m.secrets {
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+
+	changed := make([]string, 0)
+	for _, name := range names {
+		value, err := m.fetch(name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return changed, err
+		}
+		if m.provide(name, value) {
+			changed = append(changed, name)
+		}
+	}
+	m.notify(changed)
+	return changed, nil
+}
+
+// provide records a value read from the providers, taking it unless the providers
+// returned it last time too; returns whether the secret changed
+func (m *Manager) provide(name, value string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, exists := m.secrets[name]
+	switch {
+	case !exists:
+		m.secrets[name] = &secret{current: value, provided: value}
+		return true
+	case s.provided == value:
+		return false
+	}
+	s.provided = value
+	return s.setLocked(value)
+}
+
+// Rotate adds a freshly generated key to a key list registered with RegisterKeys and
+// returns it. When replace names a key in the list it is dropped, staying valid for
+// the grace period so its client can roll over; the other keys are kept. The new list
+// only lives in memory, surviving reloads until the providers change; persist it in
+// the provider to survive restarts.
+func (m *Manager) Rotate(name, replace string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	value := hex.EncodeToString(buf)
+
+	m.mu.Lock()
+	s, exists := m.secrets[name]
+	switch {
+	case !exists:
+		m.mu.Unlock()
+		return "", ErrNotFound
+	case !s.keys:
+		m.mu.Unlock()
+		return "", ErrNotRotatable
+	}
+
+	list := make([]string, 0)
+	listed := false
+	for _, key := range strings.Split(s.current, ",") {
+		key = strings.TrimSpace(key)
+		switch {
+		case key == "":
+		case replace != "" && key == replace:
+			listed = true
+		default:
+			list = append(list, key)
+		}
+	}
+	if replace != "" && !listed {
+		m.mu.Unlock()
+		return "", ErrKeyNotListed
+	}
+	s.setLocked(strings.Join(append(list, value), ","))
+	m.mu.Unlock()
+
+	m.notify([]string{name})
+	return value, nil
+}
+
+// WatchReload reloads all secrets every interval until stop is closed
+func (m *Manager) WatchReload(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Reload()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// fetch returns the value from the first provider that has the secret
+func (m *Manager) fetch(name string) (string, error) {
+	for _, p := range m.providers {
+		value, err := p.Get(name)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		return value, err
+	}
+	return "", ErrNotFound
+}
+
+// setLocked stores a new value, keeping the old one as previous; returns whether it
+// changed. Caller must hold the manager's lock.
+func (s *secret) setLocked(value string) bool {
+	if s.current == value {
+		return false
+	}
+	s.previous = s.current
+	s.current = value
+	s.rotatedAt = time.Now()
+	return true
+}
+
+// notify calls change listeners for each rotated secret
+func (m *Manager) notify(names []string) {
+	m.mu.RLock()
+	listeners := append([]func(string){}, m.listeners...)
+	m.mu.RUnlock()
+
+	for _, name := range names {
+		for _, fn := range listeners {
+			fn(name)
+		}
+	}
+}
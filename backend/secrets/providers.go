@@ -0,0 +1,139 @@
+package secrets
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by providers that don't hold the requested secret
+var ErrNotFound = errors.New("secret not found")
+
+// Provider fetches secret values by name
+type Provider interface {
+	Get(name string) (string, error)
+}
+
+// EnvProvider reads secrets from environment variables named after the upper-cased secret
+type EnvProvider struct{}
+
+// Get implements Provider
+func (EnvProvider) Get(name string) (string, error) {
+	value, ok := os.LookupEnv(strings.ToUpper(name))
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// FileProvider reads each secret from a file named after it inside Dir, as with
+// Docker or Kubernetes mounted secrets (e.g. /run/secrets/jwt_signing_key)
+type FileProvider struct {
+	Dir string
+}
+
+// Get implements Provider
+func (p FileProvider) Get(name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(p.Dir, filepath.Base(name)))
+	if os.IsNotExist(err) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// EnvFileProvider reads secrets from a KEY=VALUE file, re-reading it on every lookup so
+// edits are picked up on the next reload
+type EnvFileProvider struct {
+	Path string
+}
+
+// Get implements Provider
+func (p EnvFileProvider) Get(name string) (string, error) {
+	file, err := os.Open(p.Path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	want := strings.ToUpper(name)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if found && strings.ToUpper(strings.TrimSpace(key)) == want {
+			return strings.Trim(strings.TrimSpace(value), `"'`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", ErrNotFound
+}
+
+// VaultProvider reads secrets from a HashiCorp Vault KV v2 secret, where each field
+// of the secret at Path is one named secret
+type VaultProvider struct {
+	Addr   string // e.g. https://vault.internal:8200
+	Token  string
+	Mount  string // KV mount, defaults to "secret"
+	Path   string // secret path within the mount, e.g. "leaderboard"
+	Client *http.Client
+}
+
+// Get implements Provider
+func (p VaultProvider) Get(name string) (string, error) {
+	mount := p.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(p.Addr, "/"), mount, p.Path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault: unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	value, ok := body.Data.Data[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return fmt.Sprint(value), nil
+}
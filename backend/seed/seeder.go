@@ -3,6 +3,7 @@ package seed
 import (
 	"fmt"
 	"leaderboard-api/models"
+	"math"
 	"math/rand"
 )
 
@@ -47,7 +48,7 @@ func GenerateUsers(count int) []*models.User {
 		usedUsernames[username] = true
 
 		// Generate rating between 100 and 5000
-		rating := 100 + rand.Intn(4901) // 100 to 5000 inclusive
+		rating := 100 + rand.Int63n(4901) // 100 to 5000 inclusive
 
 		user := &models.User{
 			ID:       fmt.Sprintf("user_%d", i+1),
@@ -62,16 +63,163 @@ func GenerateUsers(count int) []*models.User {
 	return users
 }
 
+// Distribution shapes the ratings GenerateUsersWithDistribution hands out. Real
+// boards are bell-shaped, with players bunching on round numbers and on the
+// ratings new accounts start at, so ties are common near the middle and rare at
+// the top.
+type Distribution struct {
+	Min, Max int64 // ratings are clamped to [Min, Max]
+
+	// Bell curve the ratings are drawn from
+	Mean   float64
+	StdDev float64
+
+	// Share of users, from 0 to 1, put on a popular rating instead of one drawn from
+	// the curve
+	TieRatio float64
+
+	// Popular ratings users bunch on. Empty means every multiple of 100 within
+	// [Min, Max], each as popular as the curve is dense there.
+	Popular []int64
+
+	// Drawn ratings are rounded to a multiple of Step, so values above 1 also tie
+	// users who aren't on a popular rating
+	Step int64
+
+	// Stored units per point, for boards holding fractional points (see
+	// store.ScaleRating); the fields above are in points. 0 means 1.
+	Scale int64
+}
+
+// DefaultDistribution is the distribution GenerateUsersWithTies seeds from
+var DefaultDistribution = Distribution{
+	Min:      100,
+	Max:      5000,
+	Mean:     1500,
+	StdDev:   700,
+	TieRatio: 0.15,
+	Step:     1,
+}
+
+// GenerateUsersWithTies generates users rated by DefaultDistribution
 func GenerateUsersWithTies(count int) []*models.User {
+	return GenerateUsersWithDistribution(count, DefaultDistribution)
+}
+
+// GenerateUsersWithDistribution generates users whose ratings follow d
+func GenerateUsersWithDistribution(count int, d Distribution) []*models.User {
 	users := GenerateUsers(count)
+	if d.Max < d.Min {
+		d.Min, d.Max = d.Max, d.Min
+	}
+	popular, weights := d.popular()
 
-	commonRatings := []int{4600, 3900, 2500, 1500, 1000}
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	scale := max(d.Scale, 1)
+	for _, user := range users {
+		if len(popular) > 0 && total > 0 && rand.Float64() < d.TieRatio {
+			user.Rating = pick(popular, weights, total) * scale
+			continue
+		}
+		user.Rating = d.draw() * scale
+	}
+	return users
+}
 
-	for i := 0; i < len(users) && i < 50; i++ {
-		if i%10 < 5 {
-			users[i].Rating = commonRatings[i%5]
+// draw returns a rating from the curve, rounded to Step and clamped
+func (d Distribution) draw() int64 {
+	rating := int64(math.Round(d.Mean + rand.NormFloat64()*d.StdDev))
+	if d.Step > 1 {
+		rating = int64(math.Round(float64(rating)/float64(d.Step))) * d.Step
+	}
+	return min(max(rating, d.Min), d.Max)
+}
+
+// popular returns the popular ratings within range and how often each is picked
+func (d Distribution) popular() ([]int64, []float64) {
+	ratings := d.Popular
+	if len(ratings) == 0 {
+		for r := (d.Min + 99) / 100 * 100; r <= d.Max; r += 100 {
+			ratings = append(ratings, r)
 		}
 	}
 
-	return users
+	var kept []int64
+	var weights []float64
+	for _, r := range ratings {
+		if r < d.Min || r > d.Max {
+			continue
+		}
+		w := 1.0
+		if d.StdDev > 0 {
+			z := (float64(r) - d.Mean) / d.StdDev
+			w = math.Exp(-z * z / 2)
+		}
+		kept = append(kept, r)
+		weights = append(weights, w)
+	}
+	return kept, weights
+}
+
+// pick chooses one of ratings with probability proportional to its weight
+func pick(ratings []int64, weights []float64, total float64) int64 {
+	x := rand.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return ratings[i]
+		}
+		x -= w
+	}
+	return ratings[len(ratings)-1]
+}
+
+// DistinctRatings counts the different ratings users hold; the fewer, the more ties
+func DistinctRatings(users []*models.User) int {
+	seen := make(map[int64]bool)
+	for _, user := range users {
+		seen[user.Rating] = true
+	}
+	return len(seen)
+}
+
+// GenerateGroups picks random distinct members from users to form count groups of the given size
+func GenerateGroups(users []*models.User, size, count int) [][]string {
+	groups := make([][]string, 0, count)
+	if len(users) < size {
+		return groups
+	}
+
+	for len(groups) < count {
+		members := make([]string, 0, size)
+		picked := make(map[int]bool)
+		for len(members) < size {
+			idx := rand.Intn(len(users))
+			if picked[idx] {
+				continue
+			}
+			picked[idx] = true
+			members = append(members, users[idx].Username)
+		}
+		groups = append(groups, members)
+	}
+
+	return groups
+}
+
+// GenerateTeams splits a random sample of users into count disjoint teams of size,
+// keyed by team name. Fewer teams are made when there aren't enough users.
+func GenerateTeams(users []*models.User, count, size int) map[string][]string {
+	teams := make(map[string][]string, count)
+	order := rand.Perm(len(users))
+	for i := 0; i < count && (i+1)*size <= len(order); i++ {
+		members := make([]string, 0, size)
+		for _, idx := range order[i*size : (i+1)*size] {
+			members = append(members, users[idx].Username)
+		}
+		teams[fmt.Sprintf("team_%03d", i+1)] = members
+	}
+	return teams
 }
@@ -0,0 +1,53 @@
+package seed
+
+import (
+	"fmt"
+	"leaderboard-api/models"
+	"math/rand"
+)
+
+// unicodeNames stress the parts of the board that compare, lower-case or slice
+// usernames: scripts whose letters take several bytes, pairs that look the same but
+// are spelled with different code points, and letters whose case mapping changes
+// their length.
+var unicodeNames = []string{
+	// Devanagari, with conjuncts and vowel signs
+	"राहुल", "प्रिया", "अमित", "क्षितिज", "श्रीनिवास", "ज्ञानेश",
+	// Chinese, Japanese and Korean
+	"王伟", "李娜", "東京太郎", "さくら", "たけし", "カタカナ", "김민준", "이서연",
+	// Precomposed and decomposed spellings of the same names
+	"Jos\u00e9", "Jose\u0301", "Zo\u00eb", "Zoe\u0308", "\u00d1and\u00fa", "N\u0303andu\u0301",
+	// Case mappings that change length or depend on position
+	"straße", "STRASSE", "İstanbul", "ΣΟΦΙΑΣ", "σοφιας", "ǅemal",
+	// Right-to-left scripts
+	"محمد", "فاطمة", "שרה",
+	// Fullwidth letters and stacked combining marks
+	"ＡＬＥＸ", "z\u0335\u0321a\u0336l\u0337g\u0338o",
+}
+
+// unicodeSuffixes include emoji built from several code points: a ZWJ sequence, a
+// flag and a skin tone modifier
+var unicodeSuffixes = []string{
+	"", "", "_🔥", "🐉", "_\U0001F469\u200D\U0001F4BB", "🇮🇳", "_👍🏽", "_⭐", "_007", "_pro", "_名人", "_खिलाड़ी",
+}
+
+// UseUnicodeNames renames users with Hindi, CJK, emoji and combining-character
+// usernames, keeping them unique, to exercise search, prefix indexes and name
+// normalization in development
+func UseUnicodeNames(users []*models.User) {
+	used := make(map[string]bool, len(users))
+	for _, user := range users {
+		for {
+			username := unicodeNames[rand.Intn(len(unicodeNames))] + unicodeSuffixes[rand.Intn(len(unicodeSuffixes))]
+			if used[username] {
+				username = fmt.Sprintf("%s_%d", username, rand.Intn(10000))
+			}
+			if used[username] {
+				continue
+			}
+			used[username] = true
+			user.Username = username
+			break
+		}
+	}
+}
@@ -0,0 +1,665 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard-api/auth"
+	"leaderboard-api/handlers"
+	"leaderboard-api/ids"
+	"leaderboard-api/importer"
+	"leaderboard-api/middleware"
+	"leaderboard-api/mirror"
+	"leaderboard-api/models"
+	"leaderboard-api/rating"
+	"leaderboard-api/secrets"
+	"leaderboard-api/seed"
+	"leaderboard-api/simulator"
+	"leaderboard-api/store"
+	"leaderboard-api/webhook"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// job is a background task New assembled: Start runs it and Shutdown stops it
+type job struct {
+	start func()
+	stop  func() // nil for jobs that run until the process exits
+}
+
+// assembly is what New builds around the default board before the routes
+type assembly struct {
+	board       store.Store
+	leaderboard *store.Leaderboard // nil when the default board is not in memory
+	migration   *store.DualStore
+	secrets     *secrets.Manager
+	format      models.ScoreFormat
+
+	// Users restored or seeded at startup, from which duos and teams are seeded
+	users []*models.User
+}
+
+// addJob schedules a background task for Start
+func (s *Server) addJob(start, stop func()) {
+	s.jobs = append(s.jobs, job{start: start, stop: stop})
+}
+
+// closeOnShutdown closes c once Shutdown has stopped every job, or when New fails
+func (s *Server) closeOnShutdown(c func() error) {
+	s.closers = append(s.closers, c)
+}
+
+// close releases whatever New opened, newest first
+func (s *Server) close() {
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		if err := s.closers[i](); err != nil {
+			log.Printf("Close: %v", err)
+		}
+	}
+	s.closers = nil
+}
+
+// assemble builds the default board's collaborators and background jobs as cfg
+// describes, opening the board first when New wasn't given one, and returns the
+// handlers serving them
+func (s *Server) assemble(board store.Store, o *options) (*handlers.Handler, error) {
+	cfg := s.cfg
+	a := &assembly{secrets: cfg.Secrets}
+	if a.secrets == nil {
+		a.secrets = secrets.NewManager(secrets.DefaultGrace)
+	}
+	if err := registerSecrets(a.secrets); err != nil {
+		return nil, fmt.Errorf("load secrets: %w", err)
+	}
+	a.secrets.OnChange(func(name string) {
+		log.Printf("Secret %s rotated", name)
+	})
+	if cfg.SecretsReloadInterval > 0 {
+		stop := make(chan struct{})
+		s.addJob(func() { a.secrets.WatchReload(cfg.SecretsReloadInterval, stop) }, func() { close(stop) })
+	}
+
+	if board == nil {
+		if err := s.openBoard(a); err != nil {
+			return nil, err
+		}
+	} else {
+		a.board = board
+		a.leaderboard, _ = board.(*store.Leaderboard)
+		a.migration, _ = board.(*store.DualStore)
+	}
+	a.format = a.board.Metadata().ScoreFormat
+
+	idGen, err := ids.New(cfg.IDStrategy, cfg.SnowflakeNode)
+	if err != nil {
+		return nil, fmt.Errorf("user IDs: %w", err)
+	}
+	if err := s.loadUsers(a, idGen); err != nil {
+		return nil, err
+	}
+	if err := s.configureBoard(a); err != nil {
+		return nil, err
+	}
+
+	if o.duos == nil {
+		o.duos = seedDuos(a.users, cfg.DuoSize, cfg.DuoSeedGroups)
+	}
+	if o.matches == nil && cfg.MatchLogPath != "" {
+		matches, err := store.OpenMatchStore(cfg.MatchLogPath)
+		if err != nil {
+			return nil, fmt.Errorf("open match log: %w", err)
+		}
+		s.closeOnShutdown(matches.Close)
+		o.matches = matches
+		log.Printf("Match log opened at %s", cfg.MatchLogPath)
+	}
+	if o.matches == nil {
+		o.matches = store.NewMatchStore()
+	}
+	if o.seasons == nil && cfg.Seasons != nil && a.leaderboard != nil {
+		o.seasons = store.NewSeasonArchive(a.leaderboard)
+		a.leaderboard.SetGracePeriod(cfg.Seasons.GracePeriod)
+		o.seasons.SetGracePeriod(cfg.Seasons.GracePeriod)
+		finalizer := store.NewFinalizer(a.leaderboard, o.seasons)
+		s.addJob(func() { finalizer.Start(10 * time.Minute) }, finalizer.Stop)
+	}
+
+	h := handlers.NewHandler(store.NewManager(a.board), o.duos, o.matches, o.seasons)
+	h.Secrets = a.secrets
+	h.IDs = idGen
+	h.Migration = a.migration
+	h.Elo = rating.NewElo(rating.DefaultK)
+	if cfg.EloK > 0 {
+		h.Elo.K = cfg.EloK
+	}
+	if err := s.assembleLogs(a, h); err != nil {
+		return nil, err
+	}
+	if err := s.assembleJobs(a, h); err != nil {
+		return nil, err
+	}
+	if err := s.assembleAccess(a, o); err != nil {
+		return nil, err
+	}
+
+	if o.views == nil && a.leaderboard != nil && cfg.ViewPublishInterval > 0 {
+		o.views, o.publishInterval = a.leaderboard, cfg.ViewPublishInterval
+	}
+	return h, nil
+}
+
+// registerSecrets loads the secrets the server uses. Only the client key lists may be
+// rotated through the admin API.
+func registerSecrets(m *secrets.Manager) error {
+	for _, name := range []string{"stream_partner_keys", "api_keys", "api_read_keys"} {
+		if err := m.RegisterKeys(name); err != nil {
+			return err
+		}
+	}
+	for _, name := range []string{"jwt_signing_key", "redis_url", "audit_signing_key", "mirror_token", "webhook_signing_key"} {
+		if err := m.Register(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openBoard opens the default board cfg.Store describes. Snapshots, series and
+// seasons need the in-memory store and are skipped on Redis.
+func (s *Server) openBoard(a *assembly) error {
+	cfg := s.cfg.Store
+	leaderboard := store.NewLeaderboard()
+
+	meta := leaderboard.Metadata()
+	if cfg.ScoreFormat.Unit != "" {
+		meta.ScoreFormat = cfg.ScoreFormat
+	}
+	meta.SortKeys = cfg.SortKeys
+	if cfg.RankingMode != "" {
+		meta.RankingMode = cfg.RankingMode
+	}
+	if cfg.TieBreak != "" {
+		meta.TieBreak = cfg.TieBreak
+	}
+	meta.Capacity = cfg.Capacity
+	if cfg.Layout != "" {
+		meta.Layout = cfg.Layout
+	}
+	if cfg.Validation != nil {
+		if err := store.ValidateRules(cfg.Validation); err != nil {
+			return fmt.Errorf("rating validation: %w", err)
+		}
+		meta.Validation = cfg.Validation
+	}
+	leaderboard.SetMetadata(meta)
+	if s.cfg.RankDeltaBaseline > 0 {
+		leaderboard.SetBaselineInterval(s.cfg.RankDeltaBaseline)
+	}
+
+	a.board, a.leaderboard = leaderboard, leaderboard
+	switch cfg.Backend {
+	case "", "memory":
+	case "redis":
+		redisBoard, err := s.openRedisBoard(a.secrets.Get("redis_url"), meta)
+		if err != nil {
+			return err
+		}
+		a.board, a.leaderboard = redisBoard, nil
+	default:
+		return fmt.Errorf("unknown store backend %q", cfg.Backend)
+	}
+
+	switch {
+	case cfg.MigrateTo == "":
+	case cfg.MigrateTo == "redis" && a.leaderboard != nil:
+		redisBoard, err := s.openRedisBoard(a.secrets.Get("redis_url"), meta)
+		if err != nil {
+			return err
+		}
+		verifyEvery := cfg.MigrateVerifyEvery
+		if verifyEvery <= 0 {
+			verifyEvery = 10
+		}
+		a.migration = store.NewDualStore(a.leaderboard, redisBoard, verifyEvery)
+		a.board = a.migration
+		log.Printf("Migrating to Redis: dual-writing, verifying 1 in %d reads", verifyEvery)
+	default:
+		return fmt.Errorf("cannot migrate to %q from store backend %q", cfg.MigrateTo, cfg.Backend)
+	}
+	return nil
+}
+
+// openRedisBoard connects to the Redis board under the configured key prefix,
+// refusing metadata that asks for anything the Redis store can't do
+func (s *Server) openRedisBoard(url string, meta models.BoardMetadata) (*store.RedisLeaderboard, error) {
+	switch {
+	case len(meta.SortKeys) > 0:
+		return nil, errors.New("sort keys are not supported by the redis store")
+	case meta.RankingMode != models.RankingDense:
+		return nil, errors.New("ranking modes other than dense are not supported by the redis store")
+	case meta.TieBreak != models.TieBreakUsername:
+		return nil, errors.New("tie breaks other than username are not supported by the redis store")
+	case meta.Capacity > 0:
+		return nil, errors.New("a capacity is not supported by the redis store")
+	case meta.Layout == models.LayoutColumnar:
+		return nil, errors.New("the columnar layout is not supported by the redis store")
+	case meta.Validation != nil:
+		return nil, errors.New("rating validation is not supported by the redis store")
+	}
+	prefix := s.cfg.Store.RedisKeyPrefix
+	if prefix == "" {
+		prefix = "leaderboard"
+	}
+	redisBoard, err := store.NewRedisLeaderboard(url, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("connect to Redis: %w", err)
+	}
+	s.closeOnShutdown(redisBoard.Close)
+	redisBoard.SetMetadata(meta)
+	log.Printf("Using Redis store under key prefix %q", prefix)
+	return redisBoard, nil
+}
+
+// loadUsers restores the board from the last snapshot when one exists, otherwise
+// seeds it, and schedules the migration backfill of whatever was loaded
+func (s *Server) loadUsers(a *assembly, idGen ids.Generator) error {
+	cfg := s.cfg
+	if cfg.Snapshots != nil && a.leaderboard != nil {
+		restored, err := a.leaderboard.LoadSnapshot(cfg.Snapshots.Path)
+		switch {
+		case err == nil:
+			a.users = restored
+			log.Printf("Restored %d users from snapshot %s", len(a.users), cfg.Snapshots.Path)
+		case os.IsNotExist(err):
+			log.Printf("No snapshot at %s yet", cfg.Snapshots.Path)
+		default:
+			return fmt.Errorf("restore snapshot: %w", err)
+		}
+	}
+	// A shared Redis board is only seeded the first time
+	if a.users == nil && cfg.Seed != nil && a.board.GetTotalUsers() == 0 {
+		dist := cfg.Seed.Distribution
+		dist.Scale = store.ScaleRating(a.format, 1)
+		log.Printf("Generating %d seed users...", cfg.Seed.Users)
+		a.users = seed.GenerateUsersWithDistribution(cfg.Seed.Users, dist)
+		if cfg.Seed.UnicodeNames {
+			seed.UseUnicodeNames(a.users)
+		}
+		for _, user := range a.users {
+			user.ID = idGen.NewID()
+		}
+		log.Printf("Seeded ratings take %d distinct values", seed.DistinctRatings(a.users))
+		a.board.BulkAddUsers(a.users)
+	}
+	log.Printf("Loaded %d users into leaderboard", a.board.GetTotalUsers())
+
+	// Bring the migration target up to date with whatever was restored or seeded
+	if migration := a.migration; migration != nil {
+		s.addJob(func() {
+			go func() {
+				report := migration.Backfill()
+				log.Printf("Migration backfill: copied %d, corrected %d, removed %d in %.0fms",
+					report.Copied, report.Corrected, report.Removed, report.DurationMs)
+			}()
+		}, nil)
+	}
+	return nil
+}
+
+// configureBoard turns on the in-memory board's optional behaviour
+func (s *Server) configureBoard(a *assembly) error {
+	cfg, leaderboard := s.cfg, a.leaderboard
+	if leaderboard == nil {
+		return nil
+	}
+
+	if cfg.SeriesBestOf > 0 {
+		leaderboard.EnableSeries(cfg.SeriesBestOf)
+		log.Printf("Promotion/demotion series enabled (best of %d)", cfg.SeriesBestOf)
+	}
+	if cfg.DisplayNames != "" {
+		leaderboard.SetDisplayNamePolicy(cfg.DisplayNames)
+	}
+	if cfg.Milestones != nil {
+		policy := *cfg.Milestones
+		policy.RatingStep = store.ScaleRating(a.format, policy.RatingStep)
+		leaderboard.EnableMilestones(policy)
+	}
+	if cfg.Decay != nil {
+		policy := cfg.Decay.Policy
+		policy.PerDay = store.ScaleRating(a.format, policy.PerDay)
+		policy.Floor = store.ScaleRating(a.format, policy.Floor)
+		decayer := store.NewDecayer(leaderboard, policy)
+		interval := cfg.Decay.Interval
+		if interval <= 0 {
+			interval = time.Hour
+		}
+		s.addJob(func() { decayer.Start(interval) }, decayer.Stop)
+		log.Printf("Rating decay enabled: -%d/day after %v inactive, floor %d, %d exempt",
+			cfg.Decay.Policy.PerDay, policy.After, policy.Floor, len(policy.Exempt))
+	}
+	return nil
+}
+
+// seedDuos creates the co-op board for groups of size and seeds it with up to count
+// random groups of users
+func seedDuos(users []*models.User, size, count int) *store.GroupLeaderboard {
+	if size < 2 {
+		size = 2
+	}
+	duos := store.NewGroupLeaderboard(size)
+	for _, members := range seed.GenerateGroups(users, size, count) {
+		// Random picks can repeat a group; duplicates are simply skipped
+		duos.AddGroup(members, 100+rand.Int63n(4901))
+	}
+	log.Printf("Loaded %d groups of %d into co-op leaderboard", duos.GetTotalGroups(), size)
+	return duos
+}
+
+// assembleLogs opens the audit and change logs
+func (s *Server) assembleLogs(a *assembly, h *handlers.Handler) error {
+	cfg := s.cfg
+
+	if cfg.Audit != nil {
+		audit := store.NewAuditLog()
+		if path := cfg.Audit.Path; path != "" {
+			var err error
+			audit, err = store.OpenAuditLog(path)
+			if err != nil {
+				return fmt.Errorf("open audit log: %w", err)
+			}
+			s.closeOnShutdown(audit.Close)
+			log.Printf("Audit log opened at %s", path)
+		}
+		audit.SetSigningKey([]byte(a.secrets.Get("audit_signing_key")))
+		a.secrets.OnChange(func(name string) {
+			if name == "audit_signing_key" {
+				audit.SetSigningKey([]byte(a.secrets.Get(name)))
+			}
+		})
+		if check := audit.Verify(); !check.Valid {
+			log.Printf("WARNING: audit log chain broken at entry %d: %s", check.BrokenAt, check.Reason)
+		}
+		h.Audit = audit
+	}
+
+	// Every change to the default board, whether from the API, the simulator or a
+	// background job, goes to the change log
+	if cfg.ChangeLog != nil && a.leaderboard != nil {
+		changeLog := store.NewChangeLog(cfg.ChangeLog.Size)
+		if path := cfg.ChangeLog.Path; path != "" {
+			var err error
+			changeLog, err = store.OpenChangeLog(cfg.ChangeLog.Size, path)
+			if err != nil {
+				return fmt.Errorf("open change log: %w", err)
+			}
+			s.closeOnShutdown(changeLog.Close)
+			log.Printf("Change log opened at %s", path)
+		}
+		a.leaderboard.Subscribe(changeLog.Record)
+		h.ChangeLog = changeLog
+	}
+	return nil
+}
+
+// assembleJobs builds the background jobs around the default board: teams and the
+// podium, streams, imports, the mirror, webhooks, the simulator, persistence, stats,
+// expiry and compaction
+func (s *Server) assembleJobs(a *assembly, h *handlers.Handler) error {
+	cfg, leaderboard := s.cfg, a.leaderboard
+
+	// The podium dates the leader's reign from took_first milestones, polled often
+	// enough that none age out unseen; the same polls keep the all-time records
+	if leaderboard != nil && cfg.Milestones != nil {
+		reign := store.NewReignTracker(leaderboard)
+		s.addJob(func() { reign.Start(5 * time.Second) }, reign.Stop)
+		h.Reign = reign
+	}
+
+	if leaderboard != nil && cfg.Teams != nil {
+		teams := store.NewTeamLeaderboard(leaderboard, cfg.Teams.Scoring)
+		seeded := seed.GenerateTeams(a.users, cfg.Teams.SeedCount, cfg.Teams.SeedSize)
+		for name, members := range seeded {
+			teams.CreateTeam(name, members)
+		}
+		log.Printf("Loaded %d teams scored by %s", len(seeded), cfg.Teams.Scoring)
+		h.Teams = teams
+	}
+
+	// With partner keys configured, anonymous stream subscribers are limited to the
+	// top 10 every 2s
+	if a.secrets.Get("stream_partner_keys") != "" {
+		h.Streams = handlers.NewSecretStreamPolicy(a.secrets, "stream_partner_keys")
+		log.Println("Stream access tiers enabled")
+	}
+	if cfg.StreamMaxConnections > 0 || cfg.StreamMaxCPU > 0 {
+		load := handlers.NewStreamLoadMonitor(cfg.StreamMaxConnections, cfg.StreamMaxCPU)
+		s.addJob(func() { load.Start(time.Second) }, nil)
+		h.Load = load
+	}
+
+	if cfg.Import != nil {
+		runner := importer.NewRunner(withSource(a.board, "import"), cfg.Import.Importers...)
+		s.addJob(func() { runner.Start(cfg.Import.Interval) }, runner.Stop)
+		log.Printf("Importing from %d sources every %v", len(cfg.Import.Importers), cfg.Import.Interval)
+	}
+
+	if cfg.Mirror != nil {
+		target := cfg.Mirror.Target
+		if cfg.Mirror.Conflict != mirror.ConflictOverwrite && !target.CanFetch() {
+			return fmt.Errorf("mirror conflict policy %s needs a fetch URL", cfg.Mirror.Conflict)
+		}
+		target.Token = func() string { return a.secrets.Get("mirror_token") }
+		worker := mirror.NewWorker(a.board, target, cfg.Mirror.Conflict, cfg.Mirror.Backfill)
+		s.addJob(func() { worker.Start(cfg.Mirror.Interval) }, worker.Stop)
+		h.Mirror = worker
+		log.Printf("Mirroring every %v (conflict policy %s, backfill %v)", cfg.Mirror.Interval, cfg.Mirror.Conflict, cfg.Mirror.Backfill)
+	}
+
+	// Milestone events on the default board, and just users reaching their goals, are
+	// posted in batches signed with webhook_signing_key when set
+	interval := cfg.WebhookInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	for _, hook := range []struct {
+		url   string
+		kind  string
+		types []string
+		into  **webhook.Dispatcher
+	}{
+		{cfg.MilestoneWebhookURL, "milestone", nil, &h.Milestones},
+		{cfg.GoalWebhookURL, "goal", []string{models.MilestoneGoalReached}, &h.GoalHooks},
+	} {
+		if hook.url == "" {
+			continue
+		}
+		milestones, ok := a.board.(store.MilestoneStore)
+		if !ok {
+			return fmt.Errorf("%s webhooks need an in-memory default board", hook.kind)
+		}
+		dispatcher := s.dispatcher(a, hook.url, hook.kind, milestoneFeed(milestones, hook.types...), interval)
+		*hook.into = dispatcher
+		log.Printf("Posting %s events to %s every %v", hook.kind, hook.url, interval)
+	}
+
+	if cfg.Simulator != nil {
+		log.Println("Starting score update simulator...")
+		updater := simulator.NewScoreUpdater(withSource(a.board, models.ChangeSourceSimulator))
+		if health := cfg.Simulator.Health; health.MaxLatency > 0 || health.MaxErrorRate > 0 {
+			updater.EnableHealthChecks(health)
+		}
+		s.addJob(func() { updater.Start(cfg.Simulator.UpdatesPerSecond) }, updater.Stop)
+		h.Simulator = updater
+		if hookURL := cfg.Simulator.AlertURL; hookURL != "" {
+			h.SimulatorAlerts = s.dispatcher(a, hookURL, "simulator", func(since uint64) ([]interface{}, uint64) {
+				alerts, latest := updater.AlertsSince(since)
+				batch := make([]interface{}, len(alerts))
+				for i, alert := range alerts {
+					batch[i] = alert
+				}
+				return batch, latest
+			}, 5*time.Second)
+			log.Printf("Posting simulator alerts to %s", hookURL)
+		}
+	}
+
+	// Stopped after the simulator, so the final snapshot holds its last writes
+	if snapshots := cfg.Snapshots; snapshots != nil && leaderboard != nil {
+		snapshotter := store.NewSnapshotter(leaderboard, snapshots.Path)
+		s.addJob(func() { snapshotter.Start(snapshots.Interval) }, snapshotter.Stop)
+		h.Snapshots = snapshotter
+		log.Printf("Snapshotting to %s every %v", snapshots.Path, snapshots.Interval)
+
+		consistency := store.NewConsistencyChecker(leaderboard, snapshots.Path, snapshots.CheckSample)
+		s.addJob(func() { consistency.Start(snapshots.CheckInterval) }, consistency.Stop)
+		h.Consistency = consistency
+	}
+
+	if stats := cfg.Stats; stats != nil && stats.Interval > 0 {
+		recorder := store.NewStatsRecorder(a.board, int(stats.Retention/stats.Interval))
+		s.addJob(func() { recorder.Start(stats.Interval) }, recorder.Stop)
+		h.StatsHistory = recorder
+	}
+
+	if cfg.UserTTL > 0 && leaderboard != nil {
+		expirer := store.NewExpirer(leaderboard, cfg.UserTTL, cfg.ArchiveExpired)
+		s.addJob(func() { expirer.Start(time.Hour) }, expirer.Stop)
+		log.Printf("Expiring users inactive for %v (archive: %v)", cfg.UserTTL, cfg.ArchiveExpired)
+	}
+
+	if cfg.CompactInterval > 0 && leaderboard != nil {
+		compactor := store.NewCompactor(leaderboard)
+		s.addJob(func() { compactor.Start(cfg.CompactInterval) }, compactor.Stop)
+		log.Printf("Compacting every %v", cfg.CompactInterval)
+	}
+	return nil
+}
+
+// dispatcher creates a webhook dispatcher signed with webhook_signing_key, posting
+// every interval once the server starts
+func (s *Server) dispatcher(a *assembly, url, kind string, feed webhook.Feed, interval time.Duration) *webhook.Dispatcher {
+	dispatcher := webhook.NewDispatcher(url, kind, feed)
+	dispatcher.Key = func() []byte { return []byte(a.secrets.Get("webhook_signing_key")) }
+	s.addJob(func() { dispatcher.Start(interval) }, dispatcher.Stop)
+	return dispatcher
+}
+
+// assembleAccess adds the middleware guarding the API: the admin IP filter, rate
+// limits, API keys and bearer tokens
+func (s *Server) assembleAccess(a *assembly, o *options) error {
+	cfg := s.cfg
+	var access []Option
+
+	ipFilter, err := middleware.NewIPFilter(cfg.AdminIPAllow, cfg.AdminIPDeny)
+	if err != nil {
+		return fmt.Errorf("admin IP lists: %w", err)
+	}
+	if path := cfg.AdminIPRulesFile; path != "" {
+		if err := ipFilter.LoadFile(path); err != nil {
+			return fmt.Errorf("load admin IP rules: %w", err)
+		}
+		s.addJob(func() { ipFilter.WatchFile(path, 5*time.Second) }, nil)
+	}
+	access = append(access, WithAdminMiddleware(ipFilter.Middleware))
+
+	// With API keys configured (secrets api_keys, read-write, and api_read_keys,
+	// read-only) writes need a read-write key, and reads too with PrivateReads.
+	// Admin routes need a read-write key too unless bearer tokens guard them.
+	var apiKeys *handlers.APIKeyPolicy
+	if a.secrets.Get("api_keys") != "" || a.secrets.Get("api_read_keys") != "" {
+		apiKeys = handlers.NewSecretAPIKeyPolicy(a.secrets, "api_keys", "api_read_keys", !cfg.PrivateReads)
+		log.Printf("API keys required for writes (public reads: %v)", !cfg.PrivateReads)
+	}
+
+	// With a jwt_signing_key secret, admin routes need an HS256 bearer token with the
+	// admin role and writes one with the user role
+	var tokens *handlers.TokenPolicy
+	if a.secrets.Get("jwt_signing_key") != "" {
+		tokens = handlers.NewTokenPolicy(&auth.Verifier{
+			Key:    func() []byte { return []byte(a.secrets.Get("jwt_signing_key")) },
+			Issuer: cfg.JWTIssuer,
+		})
+		log.Println("Bearer tokens required for writes (user role) and admin routes (admin role)")
+	}
+
+	if limits := cfg.RateLimit; limits != nil && limits.RPS > 0 {
+		burst := limits.Burst
+		if burst <= 0 {
+			burst = int(math.Ceil(2 * limits.RPS))
+		}
+		limiter := middleware.NewRateLimiter(limits.RPS, burst)
+		if limits.PerKey {
+			limiter.Key = func(r *http.Request) string {
+				if apiKeys != nil {
+					if key := apiKeys.Key(r); key != "" {
+						return key
+					}
+				}
+				if tokens != nil {
+					if subject := tokens.Subject(r); subject != "" {
+						return "sub:" + subject
+					}
+				}
+				key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if key == "" {
+					key = r.URL.Query().Get("key")
+				}
+				if !a.secrets.Valid("stream_partner_keys", key) {
+					return ""
+				}
+				return key
+			}
+		}
+		access = append(access, WithMiddleware(limiter.Middleware))
+		log.Printf("Rate limiting clients to %v requests/s, bursts of %d", limits.RPS, burst)
+	}
+
+	if apiKeys != nil {
+		access = append(access, WithMiddleware(apiKeys.Middleware))
+	}
+	switch {
+	case tokens != nil:
+		access = append(access, WithMiddleware(tokens.RequireUser), WithAdminAuth(tokens.RequireAdmin))
+	case apiKeys != nil:
+		access = append(access, WithAdminAuth(apiKeys.RequireAdmin))
+	}
+
+	// Access checks run before any middleware the caller added
+	routes := o.routes
+	o.routes = nil
+	for _, opt := range access {
+		opt(o)
+	}
+	o.routes = append(o.routes, routes...)
+	return nil
+}
+
+// milestoneFeed feeds a webhook the milestone events of the given types, or all of
+// them when none are given
+func milestoneFeed(milestones store.MilestoneStore, types ...string) webhook.Feed {
+	return func(since uint64) ([]interface{}, uint64) {
+		events, latest := milestones.MilestoneEventsSince(since)
+		batch := make([]interface{}, 0, len(events))
+		for _, event := range events {
+			if len(types) == 0 || slices.Contains(types, event.Type) {
+				batch = append(batch, event)
+			}
+		}
+		return batch, latest
+	}
+}
+
+// withSource attributes changes made through board to source, where the store
+// reports changes
+func withSource(board store.Store, source string) store.Store {
+	if observable, ok := board.(store.ObservableStore); ok {
+		return observable.WithSource(source, "")
+	}
+	return board
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Middleware wraps a handler, e.g. to authenticate or trace requests
+type Middleware func(http.Handler) http.Handler
+
+// Builder collects the API's routes and middleware before the server starts. Public
+// routes go on the main listener; admin routes go on their own mux, mounted under
+// /api/admin/ on the main listener or served from a separate one.
+type Builder struct {
+	mux      *http.ServeMux
+	adminMux *http.ServeMux
+
+	// Public routes that also pass the admin middleware, registered on mux by Handler
+	privileged map[string]http.Handler
+
+	// Outermost first
+	middleware      []Middleware
+	adminMiddleware []Middleware
+}
+
+// NewBuilder creates a builder with no routes
+func NewBuilder() *Builder {
+	return &Builder{
+		mux:        http.NewServeMux(),
+		adminMux:   http.NewServeMux(),
+		privileged: make(map[string]http.Handler),
+	}
+}
+
+// Use wraps every route on the main listener, admin routes mounted there included.
+// Middleware registered earlier runs first.
+func (b *Builder) Use(middleware ...Middleware) {
+	b.middleware = append(b.middleware, middleware...)
+}
+
+// UseAdmin wraps the admin routes wherever they are served, inside any middleware
+// from Use. Middleware registered earlier runs first.
+func (b *Builder) UseAdmin(middleware ...Middleware) {
+	b.adminMiddleware = append(b.adminMiddleware, middleware...)
+}
+
+// Handle registers a public route, with a ServeMux pattern such as "GET /api/things"
+func (b *Builder) Handle(pattern string, handler http.Handler) {
+	b.mux.Handle(pattern, handler)
+}
+
+// HandleFunc registers a public route served by fn
+func (b *Builder) HandleFunc(pattern string, fn func(http.ResponseWriter, *http.Request)) {
+	b.mux.HandleFunc(pattern, fn)
+}
+
+// HandleAdmin registers an admin route; its pattern must fall under /api/admin/
+func (b *Builder) HandleAdmin(pattern string, handler http.Handler) {
+	b.adminMux.Handle(pattern, handler)
+}
+
+// HandleAdminFunc registers an admin route served by fn
+func (b *Builder) HandleAdminFunc(pattern string, fn func(http.ResponseWriter, *http.Request)) {
+	b.adminMux.HandleFunc(pattern, fn)
+}
+
+// HandlePrivilegedFunc registers a route on the main listener that only admins may
+// call, such as deleting a board: it keeps its public path but passes the admin
+// middleware too
+func (b *Builder) HandlePrivilegedFunc(pattern string, fn func(http.ResponseWriter, *http.Request)) {
+	b.privileged[pattern] = http.HandlerFunc(fn)
+}
+
+// AdminHandler returns the admin routes wrapped in the admin middleware
+func (b *Builder) AdminHandler() http.Handler {
+	return chain(b.adminMux, b.adminMiddleware)
+}
+
+// Handler returns the main listener's handler. With mountAdmin the admin routes are
+// served under /api/admin/ too. Call it once all routes are registered.
+func (b *Builder) Handler(mountAdmin bool) http.Handler {
+	if mountAdmin {
+		b.mux.Handle("/api/admin/", b.AdminHandler())
+	}
+	for pattern, handler := range b.privileged {
+		b.mux.Handle(pattern, chain(handler, b.adminMiddleware))
+	}
+	return chain(b.mux, b.middleware)
+}
+
+// chain wraps handler so the first middleware runs first
+func chain(handler http.Handler, middleware []Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+var (
+	extensionsMu sync.Mutex
+	extensions   []func(*Builder)
+)
+
+// Extend registers fn to add routes or middleware to every server built afterwards.
+// Embedders call it from an init function, so their auth or telemetry is wired in
+// without changing the API's own route setup.
+func Extend(fn func(*Builder)) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+	extensions = append(extensions, fn)
+}
+
+// ApplyExtensions runs every function registered with Extend against b, in the order
+// they were registered
+func (b *Builder) ApplyExtensions() {
+	extensionsMu.Lock()
+	registered := append([]func(*Builder){}, extensions...)
+	extensionsMu.Unlock()
+
+	for _, fn := range registered {
+		fn(b)
+	}
+}
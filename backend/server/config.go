@@ -0,0 +1,264 @@
+package server
+
+import (
+	"leaderboard-api/importer"
+	"leaderboard-api/mirror"
+	"leaderboard-api/models"
+	"leaderboard-api/secrets"
+	"leaderboard-api/seed"
+	"leaderboard-api/simulator"
+	"leaderboard-api/store"
+	"time"
+)
+
+// Config describes a Server: where it listens, the board it opens when New isn't
+// given one, and the collaborators and background jobs it runs around that board.
+// The zero value serves the API over the board alone; main fills it in from the
+// environment. Rating amounts are in points and scaled by the board's score format.
+type Config struct {
+	// Address of the API, e.g. ":8080"
+	Addr string
+
+	// Address of a separate admin listener; empty serves the admin routes under
+	// /api/admin/ on Addr
+	AdminAddr string
+
+	// Where keys and credentials come from; nil runs without any. The client key
+	// lists are registered for rotation, everything else as plain secrets.
+	Secrets *secrets.Manager
+
+	// How often secrets are reloaded from their providers; 0 never reloads them
+	SecretsReloadInterval time.Duration
+
+	// The default board New opens when it isn't given one
+	Store StoreConfig
+
+	// How new users get IDs: "uuid" (the default) or "snowflake" stamped with
+	// SnowflakeNode (0-1023), so instances sharing a backend never hand out the same one
+	IDStrategy    string
+	SnowflakeNode int64
+
+	// Users generated when the board starts empty and nothing was restored; nil
+	// seeds nothing
+	Seed *SeedConfig
+
+	// Persistence of an in-memory board; nil keeps it in memory only
+	Snapshots *SnapshotConfig
+
+	// How far back rank and rating deltas look; 0 keeps the store's default
+	RankDeltaBaseline time.Duration
+
+	// Promotion/demotion series across tiers, e.g. 3 requires 2 wins out of 3; 0
+	// disables them
+	SeriesBestOf int
+
+	// Whether display names may be shared (store.DisplayNamesShared, the default) or
+	// must be unique
+	DisplayNames string
+
+	// Milestones recorded as ratings rise, RatingStep in points; nil records none.
+	// The podium's reign tracking reads them and runs with them.
+	Milestones *store.MilestonePolicy
+
+	// Rating decay for inactive users; nil disables it
+	Decay *DecayConfig
+
+	// Members per co-op group; 0 means duos
+	DuoSize int
+
+	// Seed groups formed from the loaded users for the co-op board
+	DuoSeedGroups int
+
+	// Team leaderboard over the in-memory board; nil serves no teams
+	Teams *TeamsConfig
+
+	// Append-only log matches are kept in; empty keeps them in memory
+	MatchLogPath string
+
+	// How far Elo moves a rating per rated match, in points; 0 means rating.DefaultK
+	EloK float64
+
+	// Seasons and late submissions on an in-memory board; nil serves no seasons
+	Seasons *SeasonsConfig
+
+	// Hash-chained log of API writes; nil disables auditing
+	Audit *AuditConfig
+
+	// Log of every change to an in-memory board; nil keeps none
+	ChangeLog *ChangeLogConfig
+
+	// Streams slow to 2s frames above this many open streams or this fraction of
+	// GOMAXPROCS busy; 0 for both keeps stream cadence fixed
+	StreamMaxConnections int
+	StreamMaxCPU         float64
+
+	// Upstream rating systems mirrored into the board; nil imports nothing
+	Import *ImportConfig
+
+	// External leaderboard rating changes are pushed to; nil mirrors nothing
+	Mirror *MirrorConfig
+
+	// Where milestone events, and separately goals reached, are posted every
+	// WebhookInterval (default 5s); empty posts nothing
+	MilestoneWebhookURL string
+	GoalWebhookURL      string
+	WebhookInterval     time.Duration
+
+	// Load simulator writing to the board; nil leaves it off
+	Simulator *SimulatorConfig
+
+	// Stats sampled over time for GET /api/stats/history; nil keeps no history
+	Stats *StatsConfig
+
+	// Users with no update for this long are evicted from an in-memory board, kept
+	// on the inactive list with ArchiveExpired; 0 never expires them
+	UserTTL        time.Duration
+	ArchiveExpired bool
+
+	// How often an in-memory board's structures are rebuilt to give memory back; 0
+	// only compacts on demand
+	CompactInterval time.Duration
+
+	// Admin route IP lists, and a file of rules watched for changes. Everyone is
+	// allowed when all are empty.
+	AdminIPAllow     string
+	AdminIPDeny      string
+	AdminIPRulesFile string
+
+	// With API keys configured, whether reads need one too
+	PrivateReads bool
+
+	// Required iss claim of bearer tokens, when the jwt_signing_key secret is set
+	JWTIssuer string
+
+	// Per-client request limits; nil serves everyone without limits
+	RateLimit *RateLimitConfig
+
+	// How often an in-memory board's view is rebuilt in the background; 0 rebuilds
+	// it on the first read after a write
+	ViewPublishInterval time.Duration
+}
+
+// StoreConfig describes the default board
+type StoreConfig struct {
+	// "memory" (or empty) or "redis", shared between instances through the
+	// redis_url secret under RedisKeyPrefix (default "leaderboard")
+	Backend        string
+	RedisKeyPrefix string
+
+	// "redis" moves an in-memory board to Redis without downtime: writes go to both
+	// and one in MigrateVerifyEvery reads (default 10) is compared between them
+	MigrateTo          string
+	MigrateVerifyEvery int
+
+	// Score semantics; a zero format keeps whole points
+	ScoreFormat models.ScoreFormat
+
+	// Composite ranking keys, ranking mode, tie break, entry cap, layout and rating
+	// validation; zero values keep the board's defaults
+	SortKeys    []models.SortKey
+	RankingMode string
+	TieBreak    string
+	Capacity    int
+	Layout      string
+	Validation  *models.ValidationRules
+}
+
+// SeedConfig describes the users generated for an empty board
+type SeedConfig struct {
+	Users        int
+	Distribution seed.Distribution // in points
+	UnicodeNames bool              // Hindi, CJK, emoji and combining-character usernames
+}
+
+// SnapshotConfig describes where an in-memory board is restored from and saved to,
+// and how its consistency with the snapshot is checked
+type SnapshotConfig struct {
+	Path     string
+	Interval time.Duration
+
+	// Sampled checks that the view, rating tree and snapshot agree with the user
+	// records, every CheckInterval over CheckSample users
+	CheckInterval time.Duration
+	CheckSample   int
+}
+
+// DecayConfig describes rating decay; PerDay and Floor in the policy are in points
+type DecayConfig struct {
+	Policy   store.DecayPolicy
+	Interval time.Duration
+}
+
+// TeamsConfig describes the team leaderboard and the teams seeded from new users
+type TeamsConfig struct {
+	Scoring   store.TeamScoring
+	SeedCount int
+	SeedSize  int
+}
+
+// SeasonsConfig describes seasons on an in-memory board
+type SeasonsConfig struct {
+	// How long past days and seasons accept late submissions before they are final
+	GracePeriod time.Duration
+}
+
+// AuditConfig describes the audit log, signed with the audit_signing_key secret
+// when it is set
+type AuditConfig struct {
+	// File the log is appended to; empty keeps it in memory
+	Path string
+}
+
+// ChangeLogConfig describes the change log
+type ChangeLogConfig struct {
+	// Latest changes kept; 0 keeps the store's default
+	Size int
+
+	// File every change is also appended to; empty writes none
+	Path string
+}
+
+// ImportConfig describes the upstream sources imported from
+type ImportConfig struct {
+	Importers []importer.Importer
+	Interval  time.Duration
+}
+
+// MirrorConfig describes the outbound mirror; the mirror_token secret is sent as
+// its bearer token
+type MirrorConfig struct {
+	Target   *mirror.Target
+	Conflict string
+	Interval time.Duration
+
+	// Whether every existing user is pushed on the first sync
+	Backfill bool
+}
+
+// SimulatorConfig describes the load simulator and its kill switch
+type SimulatorConfig struct {
+	UpdatesPerSecond int
+
+	// Write health the simulator pauses itself outside of; zero limits leave the
+	// kill switch off
+	Health simulator.HealthPolicy
+
+	// Where pauses and resumes are posted; empty posts nothing
+	AlertURL string
+}
+
+// StatsConfig describes how stats are sampled
+type StatsConfig struct {
+	Interval  time.Duration
+	Retention time.Duration
+}
+
+// RateLimitConfig describes per-client request limits
+type RateLimitConfig struct {
+	RPS   float64
+	Burst int
+
+	// Limit callers with a valid API or partner key by key, and those with a valid
+	// bearer token by its subject, instead of by IP
+	PerKey bool
+}
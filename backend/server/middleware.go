@@ -0,0 +1,39 @@
+package server
+
+import (
+	"leaderboard-api/store"
+	"log"
+	"net/http"
+	"time"
+)
+
+func corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Allow all origins for development
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Username, X-API-Key, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset")
+
+		// Handle preflight requests
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Store lock timings are attributed to the request and logged with it
+		trace := store.NewLockTrace()
+		next.ServeHTTP(w, r.WithContext(store.ContextWithLockTrace(r.Context(), trace)))
+
+		locks, wait, hold := trace.Totals()
+		log.Printf("%s %s %v locks=%d lock_wait=%v lock_hold=%v", r.Method, r.URL.Path, time.Since(start), locks, wait, hold)
+	})
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"leaderboard-api/handlers"
+	"net/http"
+)
+
+// registerRoutes registers the API's own routes, served by h
+func registerRoutes(routes *Builder, h *handlers.Handler) {
+	// API routes
+	routes.HandleFunc("GET /api/leaderboard", h.GetLeaderboard)
+	routes.HandleFunc("GET /api/leaderboard/poll", h.PollLeaderboard)
+	routes.HandleFunc("GET /api/leaderboard/range", h.GetRankRange)
+	routes.HandleFunc("GET /api/leaderboard/rank", h.GetRatingRank)
+	routes.HandleFunc("GET /api/leaderboard/rank/{rank}", h.GetUsersAtRank)
+	routes.HandleFunc("GET /api/leaderboard/movers", h.GetMovers)
+	routes.HandleFunc("GET /api/leaderboard/predict", h.PredictRank)
+	routes.HandleFunc("GET /api/leaderboard/meta", h.GetBoardMetadata)
+	routes.HandleFunc("GET /api/leaderboard/podium", h.GetPodium)
+	routes.HandleFunc("POST /api/leaderboard/subset", h.GetSubsetLeaderboard)
+	routes.HandleFunc("POST /api/users", h.AddUser)
+	routes.HandleFunc("GET /api/users/compare", h.CompareUsers)
+	routes.HandleFunc("GET /api/users/search", h.SearchUsers)
+	routes.HandleFunc("GET /api/users/suggest", h.SuggestUsers)
+	routes.HandleFunc("GET /api/users/{username}", h.GetUser)
+	// A user's views share one pattern: /api/users/{username}/history and the like
+	// would each overlap /api/users/by-external/{id} with neither more specific
+	routes.HandleFunc("GET /api/users/{username}/{view}", userViews(map[string]http.HandlerFunc{
+		"history": h.GetUserHistory,
+		"rank":    h.GetUserRankAt,
+		"friends": h.GetFriends,
+	}))
+	routes.HandleFunc("GET /api/users/by-external/{id}", h.GetUserByExternalID)
+	routes.HandleFunc("GET /api/external-ids/{id}", h.GetUserByExternalID)
+	routes.HandleFunc("PATCH /api/users/{username}/rating", h.IncrementUserRating)
+	routes.HandleFunc("PUT /api/users/{username}/scores", h.UpdateUserScores)
+	routes.HandleFunc("PUT /api/users/{username}/profile", h.UpdateUserProfile)
+	routes.HandleFunc("PUT /api/users/{username}/friends", h.SetFriends)
+	routes.HandleFunc("GET /api/me/goal", h.GetMyGoal)
+	routes.HandleFunc("PUT /api/me/goal", h.SetMyGoal)
+	routes.HandleFunc("DELETE /api/me/goal", h.ClearMyGoal)
+	routes.HandleFunc("GET /api/stats", h.GetStats)
+	routes.HandleFunc("GET /api/stats/history", h.GetStatsHistory)
+	routes.HandleFunc("GET /api/records", h.GetRecords)
+	routes.HandleFunc("GET /api/leaderboard/freeze", h.GetFreezeStatus)
+	routes.HandleFunc("GET /api/maintenance", h.GetMaintenance)
+	routes.HandleFunc("POST /api/certificates/verify", h.VerifyCertificate)
+	routes.HandleFunc("GET /api/stream", h.StreamUpdates)
+	routes.HandleFunc("GET /api/stream/search", h.StreamSearchUpdates)
+	routes.HandleFunc("POST /api/stream/{connectionId}/subscription", h.UpdateSubscription)
+	routes.HandleFunc("GET /api/stream/{connectionId}/stats", h.GetStreamStats)
+	routes.HandleFunc("GET /api/metrics", h.GetMetrics)
+	routes.HandleFunc("GET /health", h.HealthCheck)
+	routes.HandleFunc("GET /ready", h.ReadyCheck)
+
+	routes.HandleFunc("POST /api/matches", h.SubmitMatch)
+	routes.HandleFunc("POST /api/submissions", h.SubmitRatings)
+	routes.HandleFunc("POST /api/scores/batch", h.SubmitScores)
+	routes.HandleFunc("GET /api/matches", h.ListMatches)
+
+	// Season routes
+	routes.HandleFunc("GET /api/seasons", h.ListSeasons)
+	routes.HandleFunc("GET /api/seasons/{id}/leaderboard", h.GetSeasonLeaderboard)
+
+	// Named leaderboard registry routes
+	routes.HandleFunc("GET /api/leaderboards", h.ListBoards)
+	routes.HandlePrivilegedFunc("POST /api/leaderboards", h.CreateBoard)
+	routes.HandleFunc("GET /api/leaderboards/{name}", h.GetBoard)
+	routes.HandlePrivilegedFunc("DELETE /api/leaderboards/{name}", h.DeleteBoard)
+	routes.HandleFunc("GET /api/leaderboards/{name}/leaderboard", h.GetBoardLeaderboard)
+	routes.HandleFunc("GET /api/leaderboards/{name}/leaderboard/poll", h.PollBoardLeaderboard)
+	routes.HandleFunc("GET /api/leaderboards/{name}/leaderboard/range", h.GetBoardRankRange)
+	routes.HandleFunc("GET /api/leaderboards/{name}/leaderboard/rank", h.GetBoardRatingRank)
+	routes.HandleFunc("GET /api/leaderboards/{name}/leaderboard/rank/{rank}", h.GetBoardUsersAtRank)
+	routes.HandleFunc("GET /api/leaderboards/{name}/leaderboard/movers", h.GetBoardMovers)
+	routes.HandleFunc("GET /api/leaderboards/{name}/leaderboard/predict", h.PredictBoardRank)
+	routes.HandleFunc("POST /api/leaderboards/{name}/leaderboard/subset", h.GetBoardSubsetLeaderboard)
+	routes.HandleFunc("GET /api/leaderboards/{name}/stats", h.GetBoardStats)
+	routes.HandleFunc("GET /api/leaderboards/{name}/users/search", h.SearchBoardUsers)
+	routes.HandleFunc("GET /api/leaderboards/{name}/users/compare", h.CompareBoardUsers)
+	routes.HandleFunc("GET /api/leaderboards/{name}/users/{username}", h.GetBoardUser)
+	routes.HandleFunc("GET /api/leaderboards/{name}/users/{username}/history", h.GetBoardUserHistory)
+	routes.HandleFunc("POST /api/leaderboards/{name}/users", h.AddBoardUser)
+	routes.HandleFunc("PUT /api/leaderboards/{name}/users/{username}/rating", h.UpdateBoardUserRating)
+	routes.HandleFunc("PATCH /api/leaderboards/{name}/users/{username}/rating", h.IncrementBoardUserRating)
+	routes.HandleFunc("PUT /api/leaderboards/{name}/users/{username}/scores", h.UpdateBoardUserScores)
+	routes.HandleFunc("PUT /api/leaderboards/{name}/users/{username}/metrics", h.UpdateBoardUserMetrics)
+	routes.HandleFunc("PUT /api/leaderboards/{name}/users/{username}/profile", h.UpdateBoardUserProfile)
+	routes.HandleFunc("POST /api/leaderboards/{name}/submissions", h.SubmitBoardRatings)
+	routes.HandleFunc("POST /api/leaderboards/{name}/scores/batch", h.SubmitBoardScores)
+
+	// Co-op (duo) leaderboard routes
+	routes.HandleFunc("POST /api/duos", h.CreateDuo)
+	routes.HandleFunc("GET /api/duos/leaderboard", h.GetDuoLeaderboard)
+	routes.HandleFunc("GET /api/duos/search", h.SearchDuos)
+	routes.HandleFunc("GET /api/duos/members/{username}", h.GetMemberDuos)
+	routes.HandleFunc("PUT /api/duos/{groupId}/rating", h.UpdateDuoRating)
+	routes.HandlePrivilegedFunc("DELETE /api/duos/{groupId}", h.DeleteDuo)
+
+	// Team (clan) leaderboard routes
+	routes.HandleFunc("POST /api/teams", h.CreateTeam)
+	routes.HandleFunc("GET /api/teams/leaderboard", h.GetTeamLeaderboard)
+	routes.HandleFunc("GET /api/teams/{team}", h.GetTeam)
+	routes.HandlePrivilegedFunc("DELETE /api/teams/{team}", h.DeleteTeam)
+	routes.HandleFunc("PUT /api/teams/{team}/members/{username}", h.JoinTeam)
+	routes.HandleFunc("DELETE /api/teams/{team}/members/{username}", h.LeaveTeam)
+
+	// Admin routes live on their own mux so they can be IP-filtered and, when
+	// Config.AdminAddr is set, served only from a separate listener
+	routes.HandleAdminFunc("POST /api/admin/seasons/rotate", h.RotateSeason)
+	routes.HandleAdminFunc("POST /api/admin/seasons/{id}/finalize", h.FinalizeSeason)
+	routes.HandleAdminFunc("POST /api/admin/compact", h.CompactBoard)
+	routes.HandleAdminFunc("PUT /api/admin/layout", h.SetBoardLayout)
+	routes.HandleAdminFunc("POST /api/admin/query", h.QueryBoard)
+	routes.HandleAdminFunc("GET /api/admin/export", h.Export)
+	routes.HandleAdminFunc("POST /api/admin/consistency/check", h.CheckConsistency)
+	routes.HandleAdminFunc("GET /api/admin/diagnostics", h.GetDiagnostics)
+	routes.HandleAdminFunc("GET /api/admin/migration", h.GetMigration)
+	routes.HandleAdminFunc("POST /api/admin/migration/cutover", h.CutoverMigration)
+	routes.HandleAdminFunc("POST /api/admin/migration/backfill", h.BackfillMigration)
+	routes.HandleAdminFunc("GET /api/admin/inactive", h.ListInactiveUsers)
+	routes.HandleAdminFunc("POST /api/admin/inactive/{username}/restore", h.RestoreInactiveUser)
+	routes.HandleAdminFunc("GET /api/admin/audit", h.ListAuditLog)
+	routes.HandleAdminFunc("GET /api/admin/audit/verify", h.VerifyAuditLog)
+	routes.HandleAdminFunc("POST /api/admin/certificates", h.IssueCertificates)
+	routes.HandleAdminFunc("POST /api/admin/reset", h.ResetBoard)
+	routes.HandleAdminFunc("POST /api/admin/reseed", h.ReseedBoard)
+	routes.HandleAdminFunc("POST /api/admin/freeze", h.FreezeBoard)
+	routes.HandleAdminFunc("POST /api/admin/unfreeze", h.UnfreezeBoard)
+	routes.HandleAdminFunc("PUT /api/admin/maintenance", h.SetMaintenance)
+	routes.HandleAdminFunc("GET /api/admin/simulator", h.GetSimulatorStatus)
+}
+
+// userViews serves GET /api/users/{username}/{view} with the handler for the view
+func userViews(views map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if serve, found := views[r.PathValue("view")]; found {
+			serve(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,293 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"leaderboard-api/handlers"
+	"leaderboard-api/store"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Option customizes a Server built by New
+type Option func(*options)
+
+type options struct {
+	duos    *store.GroupLeaderboard
+	matches *store.MatchStore
+	seasons *store.SeasonArchive
+
+	configure []func(*handlers.Handler)
+	routes    []func(*Builder)
+
+	// Whether admin callers must present a credential (see WithAdminAuth)
+	adminAuth bool
+
+	// Board whose views are published in the background once the server starts
+	views           *store.Leaderboard
+	publishInterval time.Duration
+}
+
+// WithDuos serves the co-op routes from duos instead of a board seeded as Config
+// describes
+func WithDuos(duos *store.GroupLeaderboard) Option {
+	return func(o *options) { o.duos = duos }
+}
+
+// WithMatches records matches in matches instead of the store Config describes
+func WithMatches(matches *store.MatchStore) Option {
+	return func(o *options) { o.matches = matches }
+}
+
+// WithSeasons serves the season routes from seasons instead of the archive Config
+// describes; without either they report no seasons
+func WithSeasons(seasons *store.SeasonArchive) Option {
+	return func(o *options) { o.seasons = seasons }
+}
+
+// WithHandlers calls fn on the API's handlers before any route is served, to attach
+// optional collaborators such as the audit log, teams or webhooks
+func WithHandlers(fn func(*handlers.Handler)) Option {
+	return func(o *options) { o.configure = append(o.configure, fn) }
+}
+
+// WithMiddleware wraps every route on the main listener, inside CORS and logging
+func WithMiddleware(middleware ...Middleware) Option {
+	return WithRoutes(func(b *Builder) { b.Use(middleware...) })
+}
+
+// WithAdminMiddleware wraps the admin routes wherever they are served, e.g. with an
+// IP filter
+func WithAdminMiddleware(middleware ...Middleware) Option {
+	return WithRoutes(func(b *Builder) { b.UseAdmin(middleware...) })
+}
+
+// WithAdminAuth wraps the admin routes with middleware that authenticates callers,
+// e.g. by admin token. Routes that hand out credentials, such as key rotation, are only
+// served with it, since the IP filter alone lets everyone in by default.
+func WithAdminAuth(middleware ...Middleware) Option {
+	return func(o *options) {
+		o.adminAuth = true
+		WithAdminMiddleware(middleware...)(o)
+	}
+}
+
+// WithRoutes calls fn to add routes or middleware after the API's own are registered.
+// Functions registered with Extend run after every option.
+func WithRoutes(fn func(*Builder)) Option {
+	return func(o *options) { o.routes = append(o.routes, fn) }
+}
+
+// WithBackgroundViews rebuilds lb's view in the background every interval once the
+// server starts, so reads never rebuild it inline and may trail writes by that much.
+// It overrides Config.ViewPublishInterval.
+func WithBackgroundViews(lb *store.Leaderboard, interval time.Duration) Option {
+	return func(o *options) {
+		o.views = lb
+		o.publishInterval = interval
+	}
+}
+
+// Server is the leaderboard API over one default board. It is an http.Handler for the
+// main listener, so it can be mounted in a larger application or driven by httptest
+// without Start; Start and Shutdown run it on its own listeners.
+type Server struct {
+	cfg      Config
+	handlers *handlers.Handler
+	handler  http.Handler
+	admin    http.Handler // nil when the admin routes are mounted on the main listener
+
+	views           *store.Leaderboard
+	publishInterval time.Duration
+
+	// Background jobs run from Start to Shutdown, and what New opened, closed once
+	// they have stopped
+	jobs     []job
+	closers  []func() error
+	stopOnce sync.Once
+
+	mu        sync.Mutex
+	started   bool
+	listeners []*http.Server
+	addrs     []net.Addr
+	serving   sync.WaitGroup
+	draining  sync.WaitGroup // running Shutdowns, which outlast the listeners they stop
+	err       error          // first error a listener stopped with
+}
+
+// New assembles the API over board with the collaborators, background jobs, routes
+// and middleware cfg and opts describe. With a nil board it opens the one cfg.Store
+// describes, restored from a snapshot or seeded as configured. Nothing listens or
+// runs in the background until Start.
+func New(cfg Config, board store.Store, opts ...Option) (*Server, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := &Server{cfg: cfg}
+	h, err := s.assemble(board, &o)
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+	for _, fn := range o.configure {
+		fn(h)
+	}
+
+	routes := NewBuilder()
+	routes.Use(corsMiddleware, loggingMiddleware, h.ReadOnlyDuringMaintenance)
+	registerRoutes(routes, h)
+	if o.adminAuth {
+		routes.HandleAdminFunc("POST /api/admin/keys/rotate", h.RotateKeys)
+	}
+	for _, fn := range o.routes {
+		fn(routes)
+	}
+	routes.ApplyExtensions()
+
+	s.handlers = h
+	s.handler = routes.Handler(cfg.AdminAddr == "")
+	s.views, s.publishInterval = o.views, o.publishInterval
+	if cfg.AdminAddr != "" {
+		s.admin = loggingMiddleware(routes.AdminHandler())
+	}
+	return s, nil
+}
+
+// ServeHTTP serves the main listener's routes
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Start warms up the board's views, starts the background jobs and the view
+// publisher when one is configured, and serves on the configured addresses. It
+// returns once listening; Wait reports when serving stops.
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return errors.New("server already started")
+	}
+
+	// Bind every address first so a taken port fails Start without serving anything
+	addrs, served := []string{s.cfg.Addr}, []http.Handler{s.handler}
+	if s.admin != nil {
+		addrs, served = append(addrs, s.cfg.AdminAddr), append(served, s.admin)
+	}
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, bound := range listeners {
+				bound.Close()
+			}
+			return err
+		}
+		listeners = append(listeners, listener)
+	}
+	s.started = true
+
+	// Build views, indexes and the top pages before accepting traffic
+	warmStart := time.Now()
+	s.handlers.WarmUp()
+	log.Printf("Warmed up in %v", time.Since(warmStart))
+
+	for _, job := range s.jobs {
+		job.start()
+	}
+	if s.views != nil && s.publishInterval > 0 {
+		s.handlers.Publisher = store.NewPublisher(s.views)
+		s.handlers.Publisher.Start(s.publishInterval)
+		log.Printf("Publishing views in the background every %v", s.publishInterval)
+	}
+
+	for i, listener := range listeners {
+		srv := &http.Server{Handler: served[i]}
+		s.listeners = append(s.listeners, srv)
+		s.addrs = append(s.addrs, listener.Addr())
+		s.serving.Add(1)
+		go s.serve(srv, listener)
+	}
+	log.Printf("  Leaderboard API server listening on http://%s", s.addrs[0])
+	if s.admin != nil {
+		log.Printf("  Admin API listening on http://%s", s.addrs[1])
+	}
+	return nil
+}
+
+// serve runs one listener until it is shut down. A listener failing on its own takes
+// the others down with it, so Wait returns rather than leave the API half served.
+func (s *Server) serve(srv *http.Server, listener net.Listener) {
+	defer s.serving.Done()
+	err := srv.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+	for _, other := range s.listeners {
+		other.Close()
+	}
+}
+
+// Addrs returns the addresses the server is listening on, the API's first; empty
+// before Start. Useful when Config asked for port 0.
+func (s *Server) Addrs() []net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]net.Addr(nil), s.addrs...)
+}
+
+// Wait blocks until every listener has stopped and any Shutdown has finished,
+// returning the error that stopped the first listener to fail, or nil after Shutdown
+func (s *Server) Wait() error {
+	s.serving.Wait()
+	s.draining.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests until ctx
+// is done, then closes whatever is still open, such as streams. It then stops the
+// background jobs, newest first, so the final snapshot follows the simulator's last
+// write, and closes the logs and stores New opened.
+func (s *Server) Shutdown(ctx context.Context) error {
+	// Listeners stop serving as soon as Shutdown begins; Wait holds on until it ends
+	s.draining.Add(1)
+	defer s.draining.Done()
+
+	s.mu.Lock()
+	listeners, publisher, started := s.listeners, s.handlers.Publisher, s.started
+	s.mu.Unlock()
+
+	var errs []error
+	for _, srv := range listeners {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+			srv.Close()
+		}
+	}
+	if publisher != nil {
+		publisher.Stop()
+	}
+	s.stopOnce.Do(func() {
+		if started {
+			for i := len(s.jobs) - 1; i >= 0; i-- {
+				if stop := s.jobs[i].stop; stop != nil {
+					stop()
+				}
+			}
+		}
+		s.close()
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,144 @@
+package simulator
+
+import (
+	"fmt"
+	"leaderboard-api/models"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxAlerts bounds how many recent alerts are kept for status and webhooks
+const maxAlerts = 100
+
+// HealthPolicy is when the simulator stops loading the store. Writes are measured in
+// windows; a window whose p99 write latency or error rate crosses a threshold pauses
+// the simulator, and it resumes after RecoverWindows healthy windows in a row. While
+// paused it only sends a trickle of probe writes to measure recovery.
+type HealthPolicy struct {
+	MaxLatency     time.Duration // 0 disables the latency check
+	MaxErrorRate   float64       // fraction of writes that failed; 0 disables the check
+	Window         time.Duration
+	RecoverWindows int
+}
+
+// DefaultHealthPolicy pauses on a p99 write over 50ms or more than 5% failed writes in
+// a second, and resumes after five healthy seconds
+var DefaultHealthPolicy = HealthPolicy{
+	MaxLatency:     50 * time.Millisecond,
+	MaxErrorRate:   0.05,
+	Window:         time.Second,
+	RecoverWindows: 5,
+}
+
+// healthGuard measures the simulator's writes and decides when to pause
+type healthGuard struct {
+	policy HealthPolicy
+
+	mu          sync.Mutex
+	latencies   []time.Duration // current window
+	errors      int
+	paused      bool
+	pausedSince time.Time
+	healthy     int // consecutive healthy windows while paused
+	last        models.WriteHealth
+	alerts      []models.SimulatorAlert
+	alertSeq    uint64
+}
+
+func newHealthGuard(policy HealthPolicy) *healthGuard {
+	if policy.Window <= 0 {
+		policy.Window = DefaultHealthPolicy.Window
+	}
+	if policy.RecoverWindows <= 0 {
+		policy.RecoverWindows = 1
+	}
+	return &healthGuard{policy: policy}
+}
+
+// record notes one write
+func (g *healthGuard) record(latency time.Duration, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.latencies = append(g.latencies, latency)
+	if !ok {
+		g.errors++
+	}
+}
+
+// isPaused reports whether the simulator should hold off
+func (g *healthGuard) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// evaluate closes the current window, pausing or resuming on its health
+func (g *healthGuard) evaluate(now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.latencies) == 0 {
+		return
+	}
+	window := models.WriteHealth{
+		Writes:       len(g.latencies),
+		Errors:       g.errors,
+		ErrorRate:    float64(g.errors) / float64(len(g.latencies)),
+		LatencyP99Ms: milliseconds(p99(g.latencies)),
+	}
+	g.last = window
+	g.latencies = g.latencies[:0]
+	g.errors = 0
+
+	reason := g.unhealthy(window)
+	switch {
+	case reason != "" && !g.paused:
+		g.paused, g.pausedSince = true, now
+		g.alertLocked(models.SimulatorPaused, reason, window, now)
+	case reason != "":
+		g.healthy = 0
+	case g.paused:
+		if g.healthy++; g.healthy >= g.policy.RecoverWindows {
+			g.paused, g.healthy = false, 0
+			g.alertLocked(models.SimulatorResumed,
+				fmt.Sprintf("healthy for %d windows after %v paused", g.policy.RecoverWindows, now.Sub(g.pausedSince).Round(time.Second)),
+				window, now)
+		}
+	}
+}
+
+// unhealthy says which threshold a window crossed, or "" for none
+func (g *healthGuard) unhealthy(window models.WriteHealth) string {
+	if g.policy.MaxLatency > 0 && window.LatencyP99Ms > milliseconds(g.policy.MaxLatency) {
+		return fmt.Sprintf("p99 write latency %.1fms is over %v", window.LatencyP99Ms, g.policy.MaxLatency)
+	}
+	if g.policy.MaxErrorRate > 0 && window.ErrorRate > g.policy.MaxErrorRate {
+		return fmt.Sprintf("%.1f%% of writes failed, over %.1f%%", window.ErrorRate*100, g.policy.MaxErrorRate*100)
+	}
+	return ""
+}
+
+// alertLocked records and logs an alert; caller must hold g.mu
+func (g *healthGuard) alertLocked(kind, reason string, window models.WriteHealth, at time.Time) {
+	g.alertSeq++
+	alert := models.SimulatorAlert{Seq: g.alertSeq, Type: kind, Reason: reason, Window: window, At: at}
+	g.alerts = append(g.alerts, alert)
+	if len(g.alerts) > maxAlerts {
+		g.alerts = g.alerts[len(g.alerts)-maxAlerts:]
+	}
+	log.Printf("Simulator alert %s: %s", kind, reason)
+}
+
+// milliseconds converts d for reporting
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// p99 returns the 99th percentile of latencies, reordering them
+func p99(latencies []time.Duration) time.Duration {
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies[(len(latencies)-1)*99/100]
+}
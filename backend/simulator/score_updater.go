@@ -2,6 +2,7 @@ package simulator
 
 import (
 	
+	"leaderboard-api/models"
 	"leaderboard-api/store"
 	"math/rand"
 	"time"
@@ -9,21 +10,33 @@ import (
 
 // ScoreUpdater simulates random score updates
 type ScoreUpdater struct {
-	leaderboard *store.Leaderboard
+	leaderboard store.Store
+	scale       int64 // stored units per point on the board (see store.ScaleRating)
 	stopChan    chan struct{}
 	running     bool
+
+	// Pauses the simulator while store writes are unhealthy; nil when the kill switch
+	// is off (see health.go)
+	health *healthGuard
 }
 
 // NewScoreUpdater creates a new score updater
-func NewScoreUpdater(lb *store.Leaderboard) *ScoreUpdater {
+func NewScoreUpdater(lb store.Store) *ScoreUpdater {
 
 	return &ScoreUpdater{
 		leaderboard: lb,
+		scale:       store.ScaleRating(lb.Metadata().ScoreFormat, 1),
 		stopChan:    make(chan struct{}),
 		running:     false,
 	}
 }
 
+// EnableHealthChecks pauses the simulator whenever its writes cross policy's
+// thresholds, so it can't take down a shared store; call before Start
+func (su *ScoreUpdater) EnableHealthChecks(policy HealthPolicy) {
+	su.health = newHealthGuard(policy)
+}
+
 // Start begins the score update simulation
 func (su *ScoreUpdater) Start(updatesPerSecond int) {
 	if su.running {
@@ -31,16 +44,33 @@ func (su *ScoreUpdater) Start(updatesPerSecond int) {
 	}
 	su.running = true
 
+	// While paused, one write in a hundred probes whether the store has recovered
+	probeEvery := updatesPerSecond / 100
+	if probeEvery < 1 {
+		probeEvery = 1
+	}
+
 	go func() {
 		ticker := time.NewTicker(time.Second / time.Duration(updatesPerSecond))
 		defer ticker.Stop()
 
+		var windows <-chan time.Time
+		if su.health != nil {
+			windowTicker := time.NewTicker(su.health.policy.Window)
+			defer windowTicker.Stop()
+			windows = windowTicker.C
+		}
+
 		counter := 0
 		for {
 			select {
 			case <-ticker.C:
-				su.performRandomUpdate(counter)
+				if su.health == nil || !su.health.isPaused() || counter%probeEvery == 0 {
+					su.performRandomUpdate(counter)
+				}
 				counter++
+			case now := <-windows:
+				su.health.evaluate(now)
 			case <-su.stopChan:
 				return
 			}
@@ -48,6 +78,49 @@ func (su *ScoreUpdater) Start(updatesPerSecond int) {
 	}()
 }
 
+// Status reports whether the simulator is writing and, with health checks on, the
+// write health it last measured and recent alerts
+func (su *ScoreUpdater) Status() models.SimulatorStatus {
+	status := models.SimulatorStatus{Running: su.running, Alerts: make([]models.SimulatorAlert, 0)}
+	if su.health == nil {
+		return status
+	}
+
+	g := su.health
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	status.Paused = g.paused
+	if g.paused {
+		since := g.pausedSince
+		status.PausedSince = &since
+	}
+	status.LastWindow = g.last
+	status.MaxLatencyMs = milliseconds(g.policy.MaxLatency)
+	status.MaxErrorRate = g.policy.MaxErrorRate
+	status.Alerts = append(status.Alerts, g.alerts...)
+	return status
+}
+
+// AlertsSince returns alerts after seq and the latest alert's sequence number
+func (su *ScoreUpdater) AlertsSince(seq uint64) ([]models.SimulatorAlert, uint64) {
+	alerts := make([]models.SimulatorAlert, 0)
+	if su.health == nil {
+		return alerts, 0
+	}
+
+	g := su.health
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, alert := range g.alerts {
+		if alert.Seq > seq {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, g.alertSeq
+}
+
 // Stop stops the score update simulation
 func (su *ScoreUpdater) Stop() {
 	if !su.running {
@@ -73,26 +146,30 @@ func (su *ScoreUpdater) performRandomUpdate(seed int) {
 
 	// Calculate new rating with mean reversion to maintain average
 	// Target average rating around 1500
-	targetRating := 1500
+	targetRating := 1500 * su.scale
 
 	// 1. Mean Reversion: Pull towards target (approx 1-2% of difference)
 	// If rating is 2500 (1000 above target), drift is -10
 	drift := (targetRating - user.Rating) / 100
 
 	// 2. Random Volatility: +/- 25 points
-	fluctuation := rand.Intn(51) - 25
+	fluctuation := (rand.Int63n(51) - 25) * su.scale
 
 	change := drift + fluctuation
 	newRating := user.Rating + change
 
 	// Clamp to valid range
-	if newRating < 100 {
-		newRating = 100
+	if newRating < 100*su.scale {
+		newRating = 100 * su.scale
 	}
-	if newRating > 5000 {
-		newRating = 5000
+	if newRating > 5000*su.scale {
+		newRating = 5000 * su.scale
 	}
 
-	su.leaderboard.UpdateRating(user.Username, newRating)
+	start := time.Now()
+	ok := su.leaderboard.UpdateRating(user.Username, newRating)
+	if su.health != nil {
+		su.health.record(time.Since(start), ok)
+	}
 	// fmt.Printf("[UPDATE] %s: %d → %d (change: %+d)\n", user.Username, user.Rating, newRating, change)
 }
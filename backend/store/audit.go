@@ -0,0 +1,253 @@
+package store
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"leaderboard-api/models"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// genesisHash is the PrevHash of the first audit entry
+var genesisHash = strings.Repeat("0", 64)
+
+// AuditLog is an append-only, hash-chained record of write operations, optionally
+// backed by a JSON-lines file. Each entry's hash includes the previous entry's hash,
+// and entries are HMAC-signed when a signing key is set.
+type AuditLog struct {
+	mu sync.RWMutex
+
+	entries []models.AuditEntry
+
+	// Optional append-only file sink
+	file *os.File
+
+	// Signing keys by fingerprint, including rotated-out keys so older entries verify
+	keys  map[string][]byte
+	keyID string
+}
+
+// NewAuditLog creates an in-memory audit log
+func NewAuditLog() *AuditLog {
+	return &AuditLog{
+		entries: make([]models.AuditEntry, 0),
+		keys:    make(map[string][]byte),
+	}
+}
+
+// OpenAuditLog creates an audit log backed by the file at path, replaying existing
+// entries as-is; call Verify to check them
+func OpenAuditLog(path string) (*AuditLog, error) {
+	al := NewAuditLog()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry models.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			file.Close()
+			return nil, err
+		}
+		al.entries = append(al.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	al.file = file
+	return al, nil
+}
+
+// SetSigningKey signs subsequent entries with key; an empty key disables signing.
+// Previous keys are remembered so entries they signed still verify.
+func (al *AuditLog) SetSigningKey(key []byte) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if len(key) == 0 {
+		al.keyID = ""
+		return
+	}
+	sum := sha256.Sum256(key)
+	al.keyID = hex.EncodeToString(sum[:4])
+	al.keys[al.keyID] = key
+}
+
+// Append records an action. data is stored as JSON and must not contain secrets.
+func (al *AuditLog) Append(action, board, subject string, data interface{}) (models.AuditEntry, error) {
+	var raw json.RawMessage
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return models.AuditEntry{}, err
+		}
+		raw = encoded
+	}
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	entry := models.AuditEntry{
+		Seq:      uint64(len(al.entries) + 1),
+		At:       time.Now().UTC(),
+		Action:   action,
+		Board:    board,
+		Subject:  subject,
+		Data:     raw,
+		PrevHash: genesisHash,
+	}
+	if n := len(al.entries); n > 0 {
+		entry.PrevHash = al.entries[n-1].Hash
+	}
+	entry.Hash = auditHash(entry)
+	if al.keyID != "" {
+		entry.KeyID = al.keyID
+		entry.Signature = auditSignature(al.keys[al.keyID], entry.Hash)
+	}
+
+	if al.file != nil {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return models.AuditEntry{}, err
+		}
+		if _, err := al.file.Write(append(line, '\n')); err != nil {
+			return models.AuditEntry{}, err
+		}
+	}
+
+	al.entries = append(al.entries, entry)
+	return entry, nil
+}
+
+// List returns up to limit entries with Seq greater than afterSeq, oldest first
+func (al *AuditLog) List(afterSeq uint64, limit int) []models.AuditEntry {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+
+	// Seq is the 1-based position in the log
+	start := int(afterSeq)
+	if start > len(al.entries) {
+		start = len(al.entries)
+	}
+	end := start + limit
+	if end > len(al.entries) {
+		end = len(al.entries)
+	}
+
+	results := make([]models.AuditEntry, end-start)
+	copy(results, al.entries[start:end])
+	return results
+}
+
+// Since returns up to limit entries recorded after since, oldest first
+func (al *AuditLog) Since(since time.Time, limit int) []models.AuditEntry {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+
+	// Entries are appended in time order
+	start := sort.Search(len(al.entries), func(i int) bool { return al.entries[i].At.After(since) })
+	end := start + limit
+	if end > len(al.entries) {
+		end = len(al.entries)
+	}
+
+	results := make([]models.AuditEntry, end-start)
+	copy(results, al.entries[start:end])
+	return results
+}
+
+// Verify recomputes the hash chain and checks every signature it holds a key for.
+// Truncating the newest entries can't be detected from the chain alone; compare
+// HeadHash against a previously published value for that.
+func (al *AuditLog) Verify() models.AuditVerification {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+
+	result := models.AuditVerification{
+		Valid:    true,
+		Entries:  len(al.entries),
+		HeadHash: genesisHash,
+	}
+
+	prev := genesisHash
+	for i, entry := range al.entries {
+		reason := ""
+		switch {
+		case entry.Seq != uint64(i+1):
+			reason = fmt.Sprintf("expected seq %d", i+1)
+		case entry.PrevHash != prev:
+			reason = "previous hash does not match"
+		case auditHash(entry) != entry.Hash:
+			reason = "entry hash does not match its contents"
+		}
+
+		if reason == "" {
+			key, known := al.keys[entry.KeyID]
+			switch {
+			case entry.Signature == "" || !known:
+				result.Unverified++
+			case !hmac.Equal([]byte(auditSignature(key, entry.Hash)), []byte(entry.Signature)):
+				reason = "signature does not match"
+			}
+		}
+
+		if reason != "" {
+			result.Valid = false
+			result.BrokenAt = entry.Seq
+			result.Reason = reason
+			return result
+		}
+		prev = entry.Hash
+	}
+
+	result.HeadHash = prev
+	return result
+}
+
+// Close closes the backing file, if any
+func (al *AuditLog) Close() error {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if al.file == nil {
+		return nil
+	}
+	err := al.file.Close()
+	al.file = nil
+	return err
+}
+
+// auditHash hashes every field of an entry except Hash, KeyID and Signature
+func auditHash(entry models.AuditEntry) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n%s\n%s\n%s\n%s\n%s",
+		entry.Seq,
+		entry.At.UTC().Format(time.RFC3339Nano),
+		entry.Action,
+		entry.Board,
+		entry.Subject,
+		entry.Data,
+		entry.PrevHash,
+	)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// auditSignature returns the hex HMAC-SHA256 of hash under key
+func auditSignature(key []byte, hash string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
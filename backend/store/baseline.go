@@ -0,0 +1,97 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"time"
+)
+
+// DefaultBaselineInterval is how far back rank and rating deltas look by default
+const DefaultBaselineInterval = time.Hour
+
+// SetBaselineInterval sets how far back rank and rating deltas look. Deltas compare
+// against standings captured between one and two intervals ago.
+func (lb *Leaderboard) SetBaselineInterval(interval time.Duration) {
+	lb.baselineMu.Lock()
+	defer lb.baselineMu.Unlock()
+	lb.baselineInterval = interval
+}
+
+// baselineFor returns the view deltas are measured against, or nil until one interval
+// has passed. Views are captured lazily as reads arrive: every interval the pending
+// candidate becomes the baseline and v becomes the new candidate, so the baseline is
+// always at least one interval old.
+func (lb *Leaderboard) baselineFor(v *view) *view {
+	lb.baselineMu.Lock()
+	defer lb.baselineMu.Unlock()
+
+	now := time.Now()
+	switch {
+	case lb.candidate == nil:
+		lb.candidate, lb.candidateAt = v, now
+	case lb.rotationDueLocked(now):
+		lb.baseline = lb.candidate
+		lb.candidate, lb.candidateAt = v, now
+		lb.baselineGen++
+	}
+	return lb.baseline
+}
+
+// BaselineGeneration counts how many times the baseline has rotated, rotating it first
+// if it is due. Rotation doesn't change Version, so deltas served at one version are
+// only current while the generation is unchanged too.
+func (lb *Leaderboard) BaselineGeneration() uint64 {
+	lb.baselineMu.Lock()
+	due := lb.candidate == nil || lb.rotationDueLocked(time.Now())
+	lb.baselineMu.Unlock()
+
+	if due {
+		lb.baselineFor(lb.current())
+	}
+	lb.baselineMu.Lock()
+	defer lb.baselineMu.Unlock()
+	return lb.baselineGen
+}
+
+// liveBaseline is baselineFor for reads served without a view (see topk.go). It only
+// builds the latest view when one is due to become the candidate, once an interval.
+func (lb *Leaderboard) liveBaseline() *view {
+	lb.baselineMu.Lock()
+	due := lb.candidate == nil || lb.rotationDueLocked(time.Now())
+	base := lb.baseline
+	lb.baselineMu.Unlock()
+
+	if due {
+		return lb.baselineFor(lb.latest())
+	}
+	return base
+}
+
+// rotationDueLocked reports whether the candidate is an interval old; caller must hold
+// baselineMu
+func (lb *Leaderboard) rotationDueLocked(now time.Time) bool {
+	interval := lb.baselineInterval
+	if interval <= 0 {
+		interval = DefaultBaselineInterval
+	}
+	return now.Sub(lb.candidateAt) >= interval
+}
+
+// withDeltas fills in how an entry has moved since the baseline view
+func (v *view) withDeltas(entry models.LeaderboardEntry, i int, mode string, base *view) models.LeaderboardEntry {
+	return deltasSince(base, entry, v.rank(i, mode), mode)
+}
+
+// deltasSince fills in how an entry now at rank under mode has moved since base
+func deltasSince(base *view, entry models.LeaderboardEntry, rank int, mode string) models.LeaderboardEntry {
+	if base == nil {
+		return entry
+	}
+	j, existed := base.index[entry.Username]
+	if !existed {
+		entry.New = true
+		return entry
+	}
+	entry.RankDelta = base.rank(j, mode) - rank
+	entry.RatingDelta = entry.Rating - base.users[j].Rating
+	return entry
+}
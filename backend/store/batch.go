@@ -0,0 +1,53 @@
+package store
+
+import (
+	"errors"
+	"leaderboard-api/models"
+	"time"
+)
+
+// UpdateRatings applies a batch of ratings in one step: no view is built while it
+// runs, so readers see all of the batch or none of it. Entries for unknown users or
+// breaking a validation rule are skipped and reported; the rest are applied in order.
+func (lb *Leaderboard) UpdateRatings(updates []models.ScoreUpdate) []models.ScoreResult {
+	// Rules are read before the board lock is taken for writing
+	rules := lb.Metadata().Validation
+
+	unlock := lb.lockWrite()
+	defer unlock()
+
+	results := make([]models.ScoreResult, len(updates))
+	now := time.Now()
+	for i, update := range updates {
+		results[i] = lb.applyScoreLocked(update, rules, now)
+	}
+	return results
+}
+
+// applyScoreLocked applies one entry of a batch under its user's shard lock; caller
+// must hold the write lock
+func (lb *Leaderboard) applyScoreLocked(update models.ScoreUpdate, rules *models.ValidationRules, now time.Time) models.ScoreResult {
+	result := models.ScoreResult{Username: update.Username}
+
+	shard := lb.shardFor(update.Username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[update.Username]
+	if !exists {
+		result.Status = models.ScoreUserNotFound
+		return result
+	}
+
+	previous := user.Rating
+	var invalid *ValidationError
+	if err := lb.validate(rules, update.Username, &previous, update.Rating); errors.As(err, &invalid) {
+		result.Status, result.Reason = models.ScoreValidationFailed, invalid.Message
+		result.Violation = &invalid.RuleViolation
+		return result
+	}
+
+	lb.setRatingLocked(shard, user, update.Rating, now)
+	result.Status, result.Rating = models.ScoreApplied, user.Rating
+	return result
+}
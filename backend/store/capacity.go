@@ -0,0 +1,66 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"sort"
+)
+
+// enforceCapacityLocked evicts the lowest-ranked users until the board holds no more
+// than its capacity, returning how many were evicted. A newcomer rated below everyone
+// on a full board is evicted straight away. Caller must hold the write lock.
+func (lb *Leaderboard) enforceCapacityLocked() int {
+	excess := len(lb.users) - lb.meta.Capacity
+	if lb.meta.Capacity <= 0 || excess <= 0 {
+		return 0
+	}
+
+	unlockShards := lb.lockAllShards()
+	defer unlockShards()
+
+	// ranksBelow reports whether a sits below b in the standings
+	achieved := lb.meta.TieBreak == models.TieBreakAchieved
+	ranksBelow := func(a, b *models.User) bool {
+		if a.Rating != b.Rating {
+			return a.Rating < b.Rating
+		}
+		if achieved && !a.RatingUpdatedAt.Equal(b.RatingUpdatedAt) {
+			return a.RatingUpdatedAt.After(b.RatingUpdatedAt)
+		}
+		return a.Username > b.Username
+	}
+
+	var victims []*models.User
+	if excess == 1 {
+		// The common case of one add past capacity needs only a scan
+		lowest := lb.users[0]
+		for _, user := range lb.users[1:] {
+			if ranksBelow(user, lowest) {
+				lowest = user
+			}
+		}
+		victims = []*models.User{lowest}
+	} else {
+		sorted := make([]*models.User, len(lb.users))
+		copy(sorted, lb.users)
+		sort.Slice(sorted, func(i, j int) bool { return ranksBelow(sorted[i], sorted[j]) })
+		victims = sorted[:excess]
+	}
+
+	evicted := make(map[*models.User]bool, len(victims))
+	for _, user := range victims {
+		lb.dropLocked(lb.shardFor(user.Username), user)
+		evicted[user] = true
+	}
+	kept := lb.users[:0]
+	for _, user := range lb.users {
+		if !evicted[user] {
+			kept = append(kept, user)
+		}
+	}
+	clear(lb.users[len(kept):])
+	lb.users = kept
+
+	lb.members++
+	lb.version.Add(1)
+	return len(victims)
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"leaderboard-api/models"
+	"strconv"
+	"time"
+)
+
+// certificateAction is the audit action certificates are issued under
+const certificateAction = "certificates.issue"
+
+// Certify builds a certificate of each named user's current standing, all from one
+// view of the board so a batch never mixes standings from different moments. Usernames
+// not on the board are returned separately; duplicates count once. The certificates
+// are not valid until attested in an audit log.
+func (lb *Leaderboard) Certify(usernames []string) ([]models.Certificate, []string) {
+	v := lb.current()
+	mode := lb.rankingMode(v)
+	asOf := time.Now().UTC()
+
+	certs := make([]models.Certificate, 0, len(usernames))
+	missing := make([]string, 0)
+	seen := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		i, exists := v.index[username]
+		if !exists {
+			missing = append(missing, username)
+			continue
+		}
+
+		result := v.result(i, mode)
+		cert := models.Certificate{
+			Board:           v.meta.Name,
+			Username:        username,
+			DisplayName:     result.DisplayName,
+			Rank:            result.GlobalRank,
+			CompetitionRank: result.CompetitionRank,
+			Rating:          result.Rating,
+			Display:         result.Display,
+			Percentile:      result.Percentile,
+			TotalUsers:      len(v.users),
+			AsOf:            asOf,
+			BoardVersion:    v.version,
+		}
+		cert.Digest = CertificateDigest(cert)
+		certs = append(certs, cert)
+	}
+	return certs, missing
+}
+
+// CertificateDigest hashes the standing fields of a certificate
+func CertificateDigest(cert models.Certificate) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%d\n%d\n%d\n%s\n%s\n%d\n%s\n%d",
+		cert.Board,
+		cert.Username,
+		cert.DisplayName,
+		cert.Rank,
+		cert.CompetitionRank,
+		cert.Rating,
+		cert.Display,
+		strconv.FormatFloat(cert.Percentile, 'f', -1, 64),
+		cert.TotalUsers,
+		cert.AsOf.UTC().Format(time.RFC3339Nano),
+		cert.BoardVersion,
+	)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// certificateRecord is the audit data of an issued batch: each user's digest
+type certificateRecord struct {
+	Digests map[string]string `json:"digests"`
+}
+
+// Attest records a batch of certificates from one board in the log as a single entry
+// and stamps each with that entry's sequence number, hash and signature
+func (al *AuditLog) Attest(certs []models.Certificate) error {
+	if len(certs) == 0 {
+		return nil
+	}
+	record := certificateRecord{Digests: make(map[string]string, len(certs))}
+	for _, cert := range certs {
+		record.Digests[cert.Username] = cert.Digest
+	}
+
+	entry, err := al.Append(certificateAction, certs[0].Board, "", record)
+	if err != nil {
+		return err
+	}
+	for i := range certs {
+		certs[i].AuditSeq = entry.Seq
+		certs[i].AuditHash = entry.Hash
+		certs[i].KeyID = entry.KeyID
+		certs[i].Signature = entry.Signature
+	}
+	return nil
+}
+
+// VerifyCertificate checks that a certificate is unaltered and was issued under an
+// intact entry of this log
+func (al *AuditLog) VerifyCertificate(cert models.Certificate) models.CertificateVerification {
+	reject := func(format string, args ...interface{}) models.CertificateVerification {
+		return models.CertificateVerification{Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if CertificateDigest(cert) != cert.Digest {
+		return reject("certificate does not match its digest")
+	}
+
+	var entries []models.AuditEntry
+	if cert.AuditSeq > 0 {
+		entries = al.List(cert.AuditSeq-1, 1)
+	}
+	if len(entries) == 0 {
+		return reject("audit entry %d does not exist", cert.AuditSeq)
+	}
+	entry := entries[0]
+	if entry.Hash != cert.AuditHash {
+		return reject("audit entry %d has a different hash", entry.Seq)
+	}
+	var record certificateRecord
+	if entry.Action != certificateAction || entry.Board != cert.Board || json.Unmarshal(entry.Data, &record) != nil {
+		return reject("audit entry %d did not issue certificates for board %s", entry.Seq, cert.Board)
+	}
+	if record.Digests[cert.Username] != cert.Digest {
+		return reject("audit entry %d does not record this certificate", entry.Seq)
+	}
+
+	// Later breaks don't touch the entry, but a break at or before it does
+	if chain := al.Verify(); !chain.Valid && chain.BrokenAt <= entry.Seq {
+		return reject("audit chain is broken at entry %d: %s", chain.BrokenAt, chain.Reason)
+	}
+	return models.CertificateVerification{Valid: true}
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"encoding/json"
+	"leaderboard-api/models"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultChangeLogSize is how many recent changes a change log keeps unless configured
+// otherwise
+const DefaultChangeLogSize = 10000
+
+// ChangeLog keeps the most recent changes to a board in a ring buffer, optionally
+// copying every change to a JSON-lines file. Unlike the audit log it records every
+// write, including the simulator's and background jobs', so it is bounded rather than
+// hash-chained. Feed it by subscribing Record to an observable board.
+type ChangeLog struct {
+	mu sync.Mutex
+
+	ring []models.ChangeEvent
+	next int  // slot the next change goes in
+	full bool // whether the ring has wrapped
+
+	// Optional append-only file sink
+	file *os.File
+}
+
+// NewChangeLog creates an in-memory change log holding the latest size changes
+func NewChangeLog(size int) *ChangeLog {
+	if size <= 0 {
+		size = DefaultChangeLogSize
+	}
+	return &ChangeLog{ring: make([]models.ChangeEvent, size)}
+}
+
+// OpenChangeLog creates a change log that also appends every change to the file at
+// path. Earlier contents of the file are kept but not loaded.
+func OpenChangeLog(size int, path string) (*ChangeLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	cl := NewChangeLog(size)
+	cl.file = file
+	return cl, nil
+}
+
+// Record adds a change, overwriting the oldest once the ring is full. File errors
+// are logged; the change is still kept in memory.
+func (cl *ChangeLog) Record(event models.ChangeEvent) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.ring[cl.next] = event
+	cl.next = (cl.next + 1) % len(cl.ring)
+	if cl.next == 0 {
+		cl.full = true
+	}
+
+	if cl.file != nil {
+		line, _ := json.Marshal(event)
+		if _, err := cl.file.Write(append(line, '\n')); err != nil {
+			log.Printf("Change log write failed: %v", err)
+		}
+	}
+}
+
+// Since returns up to limit changes made after since, oldest first, optionally only
+// those to one user
+func (cl *ChangeLog) Since(since time.Time, username string, limit int) []models.ChangeEvent {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	start, count := 0, cl.next
+	if cl.full {
+		start, count = cl.next, len(cl.ring)
+	}
+
+	results := make([]models.ChangeEvent, 0)
+	for k := 0; k < count && len(results) < limit; k++ {
+		event := cl.ring[(start+k)%len(cl.ring)]
+		if !event.At.After(since) || (username != "" && event.Username != username) {
+			continue
+		}
+		results = append(results, event)
+	}
+	return results
+}
+
+// Close closes the backing file, if any
+func (cl *ChangeLog) Close() error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.file == nil {
+		return nil
+	}
+	err := cl.file.Close()
+	cl.file = nil
+	return err
+}
@@ -0,0 +1,245 @@
+package store
+
+import (
+	"fmt"
+	"leaderboard-api/models"
+	"strings"
+	"time"
+)
+
+// ParseLayout validates a layout name; an empty name means rows
+func ParseLayout(name string) (string, error) {
+	switch layout := strings.ToLower(strings.TrimSpace(name)); layout {
+	case "":
+		return models.LayoutRows, nil
+	case models.LayoutRows, models.LayoutColumnar:
+		return layout, nil
+	default:
+		return "", fmt.Errorf("unknown layout %q (want rows or columnar)", name)
+	}
+}
+
+// SetLayout switches the board between row and columnar layouts. The next view is
+// built in the new layout and the next snapshot written in it.
+func (lb *Leaderboard) SetLayout(layout string) error {
+	layout, err := ParseLayout(layout)
+	if err != nil {
+		return err
+	}
+
+	unlock := lb.lockWrite()
+	defer unlock()
+	lb.meta.Layout = layout
+	lb.version.Add(1)
+	return nil
+}
+
+// viewColumns holds a view's usernames and ratings as parallel slices in ranking
+// order, alongside its ranks. Scans over ratings then walk one contiguous slice
+// instead of striding across whole user records.
+type viewColumns struct {
+	usernames []string
+	ratings   []int64
+}
+
+// buildColumns fills the columns from the sorted users
+func (v *view) buildColumns() {
+	v.cols = &viewColumns{
+		usernames: make([]string, len(v.users)),
+		ratings:   make([]int64, len(v.users)),
+	}
+	for i := range v.users {
+		v.cols.usernames[i] = v.users[i].Username
+		v.cols.ratings[i] = v.users[i].Rating
+	}
+}
+
+// ratingAt returns the rating at position i, from the columns when the view has them
+func (v *view) ratingAt(i int) int64 {
+	if v.cols != nil {
+		return v.cols.ratings[i]
+	}
+	return v.users[i].Rating
+}
+
+// usernameAt returns the username at position i, from the columns when the view has them
+func (v *view) usernameAt(i int) string {
+	if v.cols != nil {
+		return v.cols.usernames[i]
+	}
+	return v.users[i].Username
+}
+
+// snapshotColumns is the columnar snapshot encoding: one array per user field, all
+// the same length. Times are Unix nanoseconds, 0 for unset. Columns that are empty
+// for every user are left out.
+type snapshotColumns struct {
+	Usernames       []string `json:"usernames"`
+	IDs             []string `json:"ids"`
+	Ratings         []int64  `json:"ratings"`
+	GamesPlayed     []int    `json:"gamesPlayed"`
+	Wins            []int    `json:"wins"`
+	RatingUpdatedAt []int64  `json:"ratingUpdatedAt"`
+	PeakRatings     []int64  `json:"peakRatings"`
+	PeakRatingAt    []int64  `json:"peakRatingAt"`
+	LowestRatings   []int64  `json:"lowestRatings"`
+	LastActiveAt    []int64  `json:"lastActiveAt"`
+	DecayedThrough  []int64  `json:"decayedThrough"`
+
+	Sources      []string             `json:"sources,omitempty"`
+	Countries    []string             `json:"countries,omitempty"`
+	AvatarURLs   []string             `json:"avatarUrls,omitempty"`
+	DisplayNames []string             `json:"displayNames,omitempty"`
+	Scores       []map[string]int     `json:"scores,omitempty"`
+	Metrics      []map[string]float64 `json:"metrics,omitempty"`
+	Goals        []*models.Goal       `json:"goals,omitempty"`
+}
+
+// encodeColumns splits users into columns
+func encodeColumns(users []models.User) *snapshotColumns {
+	n := len(users)
+	cols := &snapshotColumns{
+		Usernames:       make([]string, n),
+		IDs:             make([]string, n),
+		Ratings:         make([]int64, n),
+		GamesPlayed:     make([]int, n),
+		Wins:            make([]int, n),
+		RatingUpdatedAt: make([]int64, n),
+		PeakRatings:     make([]int64, n),
+		PeakRatingAt:    make([]int64, n),
+		LowestRatings:   make([]int64, n),
+		LastActiveAt:    make([]int64, n),
+		DecayedThrough:  make([]int64, n),
+	}
+
+	// Sparse columns are only allocated once some user has a value
+	sparse := func(column *[]string, i int, value string) {
+		if value == "" {
+			return
+		}
+		if *column == nil {
+			*column = make([]string, n)
+		}
+		(*column)[i] = value
+	}
+
+	for i := range users {
+		user := &users[i]
+		cols.Usernames[i] = user.Username
+		cols.IDs[i] = user.ID
+		cols.Ratings[i] = user.Rating
+		cols.GamesPlayed[i] = user.GamesPlayed
+		cols.Wins[i] = user.Wins
+		cols.RatingUpdatedAt[i] = unixNanos(user.RatingUpdatedAt)
+		cols.PeakRatings[i] = user.PeakRating
+		cols.PeakRatingAt[i] = unixNanos(user.PeakRatingAt)
+		cols.LowestRatings[i] = user.LowestRating
+		cols.LastActiveAt[i] = unixNanos(user.LastActiveAt)
+		cols.DecayedThrough[i] = unixNanos(user.DecayedThrough)
+
+		sparse(&cols.Sources, i, user.Source)
+		sparse(&cols.Countries, i, user.Country)
+		sparse(&cols.AvatarURLs, i, user.AvatarURL)
+		sparse(&cols.DisplayNames, i, user.DisplayName)
+		if len(user.Scores) > 0 {
+			if cols.Scores == nil {
+				cols.Scores = make([]map[string]int, n)
+			}
+			cols.Scores[i] = user.Scores
+		}
+		if len(user.Metrics) > 0 {
+			if cols.Metrics == nil {
+				cols.Metrics = make([]map[string]float64, n)
+			}
+			cols.Metrics[i] = user.Metrics
+		}
+		if user.Goal != nil {
+			if cols.Goals == nil {
+				cols.Goals = make([]*models.Goal, n)
+			}
+			cols.Goals[i] = user.Goal
+		}
+	}
+	return cols
+}
+
+// decodeColumns reassembles users from columns, rejecting columns of mismatched length
+func decodeColumns(cols *snapshotColumns) ([]models.User, error) {
+	n := len(cols.Usernames)
+	lengths := map[string]int{
+		"ids": len(cols.IDs), "ratings": len(cols.Ratings), "gamesPlayed": len(cols.GamesPlayed),
+		"wins": len(cols.Wins), "ratingUpdatedAt": len(cols.RatingUpdatedAt),
+		"peakRatings": len(cols.PeakRatings), "peakRatingAt": len(cols.PeakRatingAt),
+		"lowestRatings": len(cols.LowestRatings), "lastActiveAt": len(cols.LastActiveAt),
+		"decayedThrough": len(cols.DecayedThrough),
+	}
+	optional := map[string]int{
+		"sources": len(cols.Sources), "countries": len(cols.Countries), "avatarUrls": len(cols.AvatarURLs),
+		"displayNames": len(cols.DisplayNames), "scores": len(cols.Scores), "metrics": len(cols.Metrics),
+		"goals": len(cols.Goals),
+	}
+	for name, length := range optional {
+		if length > 0 {
+			lengths[name] = length
+		}
+	}
+	for name, length := range lengths {
+		if length != n {
+			return nil, fmt.Errorf("snapshot column %s has %d values for %d users", name, length, n)
+		}
+	}
+
+	users := make([]models.User, n)
+	for i := range users {
+		user := &users[i]
+		user.Username = cols.Usernames[i]
+		user.ID = cols.IDs[i]
+		user.Rating = cols.Ratings[i]
+		user.GamesPlayed = cols.GamesPlayed[i]
+		user.Wins = cols.Wins[i]
+		user.RatingUpdatedAt = fromUnixNanos(cols.RatingUpdatedAt[i])
+		user.PeakRating = cols.PeakRatings[i]
+		user.PeakRatingAt = fromUnixNanos(cols.PeakRatingAt[i])
+		user.LowestRating = cols.LowestRatings[i]
+		user.LastActiveAt = fromUnixNanos(cols.LastActiveAt[i])
+		user.DecayedThrough = fromUnixNanos(cols.DecayedThrough[i])
+
+		if cols.Sources != nil {
+			user.Source = cols.Sources[i]
+		}
+		if cols.Countries != nil {
+			user.Country = cols.Countries[i]
+		}
+		if cols.AvatarURLs != nil {
+			user.AvatarURL = cols.AvatarURLs[i]
+		}
+		if cols.DisplayNames != nil {
+			user.DisplayName = cols.DisplayNames[i]
+		}
+		if cols.Scores != nil {
+			user.Scores = cols.Scores[i]
+		}
+		if cols.Metrics != nil {
+			user.Metrics = cols.Metrics[i]
+		}
+		if cols.Goals != nil {
+			user.Goal = cols.Goals[i]
+		}
+	}
+	return users, nil
+}
+
+// unixNanos encodes a time for a snapshot column, keeping the zero time distinct
+func unixNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNanos(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Compact rebuilds the board's slices and maps at their current size. Go maps never
+// shrink and removing users leaves capacity behind in the user slice, so long-running
+// instances with heavy churn hold on to memory that compaction gives back. Memory
+// figures in the report are process-wide, measured around a forced GC.
+func (lb *Leaderboard) Compact() models.CompactionReport {
+	start := time.Now()
+	before := heapStats()
+
+	report := models.CompactionReport{Board: lb.Metadata().Name}
+	func() {
+		unlock := lb.lockWrite()
+		defer unlock()
+		unlockShards := lb.lockAllShards()
+		defer unlockShards()
+
+		report.UserCapacityFreed = cap(lb.users) - len(lb.users)
+		users := make([]*models.User, len(lb.users))
+		copy(users, lb.users)
+		lb.users = users
+		report.Users = len(users)
+		lb.inactive = compactMap(lb.inactive)
+
+		for _, shard := range lb.shards {
+			shard.users = compactMap(shard.users)
+			shard.series = compactMap(shard.series)
+			shard.history = compactMap(shard.history)
+			shard.submitted = compactMap(shard.submitted)
+		}
+	}()
+
+	lb.deltaMu.Lock()
+	lb.compactDeltas(time.Now())
+	buckets := make([]*deltaBucket, len(lb.deltaBuckets))
+	for i, bucket := range lb.deltaBuckets {
+		bucket.gains = compactMap(bucket.gains)
+		buckets[i] = bucket
+	}
+	lb.deltaBuckets = buckets
+	report.DeltaBuckets = len(buckets)
+	lb.moverBuckets = append([]*deltaBucket(nil), lb.moverBuckets...)
+	lb.deltaMu.Unlock()
+
+	lb.seriesMu.Lock()
+	events := make([]models.SeriesEvent, len(lb.seriesEvents))
+	copy(events, lb.seriesEvents)
+	lb.seriesEvents = events
+	lb.seriesMu.Unlock()
+
+	lb.searchCache.clear()
+
+	// Hand freed pages back to the OS so the reclaimed memory shows up outside the process too
+	debug.FreeOSMemory()
+	after := heapStats()
+
+	report.HeapBefore = before.HeapInuse
+	report.HeapAfter = after.HeapInuse
+	if before.HeapInuse > after.HeapInuse {
+		report.Reclaimed = before.HeapInuse - after.HeapInuse
+	}
+	report.ReleasedToOS = after.HeapReleased - min(before.HeapReleased, after.HeapReleased)
+	report.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	return report
+}
+
+// compactMap returns a copy of m allocated for exactly its current size
+func compactMap[K comparable, V any](m map[K]V) map[K]V {
+	compacted := make(map[K]V, len(m))
+	for k, v := range m {
+		compacted[k] = v
+	}
+	return compacted
+}
+
+// heapStats reads memory statistics after a full collection
+func heapStats() runtime.MemStats {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats
+}
+
+// Compactor periodically compacts a board
+type Compactor struct {
+	leaderboard *Leaderboard
+	stopChan    chan struct{}
+	done        chan struct{}
+	once        sync.Once
+}
+
+// NewCompactor creates a compactor for lb
+func NewCompactor(lb *Leaderboard) *Compactor {
+	return &Compactor{
+		leaderboard: lb,
+		stopChan:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start compacts the board every interval
+func (c *Compactor) Start(interval time.Duration) {
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				report := c.leaderboard.Compact()
+				log.Printf("Compacted %s in %.1fms: reclaimed %d bytes, released %d bytes to the OS",
+					report.Board, report.DurationMs, report.Reclaimed, report.ReleasedToOS)
+			case <-c.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic compaction
+func (c *Compactor) Stop() {
+	c.once.Do(func() {
+		close(c.stopChan)
+		<-c.done
+	})
+}
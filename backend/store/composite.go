@@ -0,0 +1,124 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"math"
+	"time"
+)
+
+var (
+	ErrNotComposite  = errors.New("leaderboard does not compute its rating from metrics")
+	ErrUnknownMetric = errors.New("metric is not part of the leaderboard's score")
+)
+
+// ValidateMetricWeights checks a composite score definition: metric names must be
+// non-empty and distinct, and weights finite
+func ValidateMetricWeights(weights []models.MetricWeight) error {
+	seen := make(map[string]bool, len(weights))
+	for _, w := range weights {
+		if w.Metric == "" {
+			return errors.New("metric names must not be empty")
+		}
+		if seen[w.Metric] {
+			return fmt.Errorf("metric %q is listed twice", w.Metric)
+		}
+		if math.IsNaN(w.Weight) || math.IsInf(w.Weight, 0) {
+			return fmt.Errorf("metric %q has an invalid weight", w.Metric)
+		}
+		seen[w.Metric] = true
+	}
+	return nil
+}
+
+// CompositeRating returns the weighted sum of metrics per the board's weights, as a
+// rating at the board's precision (rounded to the nearest unit). Metrics without a
+// value count as 0.
+func CompositeRating(meta models.BoardMetadata, metrics map[string]float64) int64 {
+	sum := 0.0
+	for _, w := range meta.Metrics {
+		sum += w.Weight * metrics[w.Metric]
+	}
+	return int64(math.Round(sum * float64(ScaleRating(meta.ScoreFormat, 1))))
+}
+
+// CheckMetrics rejects submitted metrics that aren't part of weights or aren't finite
+func CheckMetrics(weights []models.MetricWeight, metrics map[string]float64) error {
+	for metric, value := range metrics {
+		known := false
+		for _, w := range weights {
+			known = known || w.Metric == metric
+		}
+		if !known {
+			return fmt.Errorf("%w: %q", ErrUnknownMetric, metric)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("metric %q must be a finite number", metric)
+		}
+	}
+	return nil
+}
+
+// UpdateMetrics merges submitted metrics into a user's record and recomputes their
+// rating from the board's weights. Reports false if the user doesn't exist.
+func (lb *Leaderboard) UpdateMetrics(username string, metrics map[string]float64) (bool, error) {
+	// The weights are read under the board lock, which is taken before the shard's
+	unlock := lb.lockRead()
+	defer unlock()
+
+	meta := lb.meta
+	weights := meta.Metrics
+	if len(weights) == 0 {
+		return false, ErrNotComposite
+	}
+	if err := CheckMetrics(weights, metrics); err != nil {
+		return false, err
+	}
+
+	shard := lb.shardFor(username)
+	unlockShard := lb.lockShard(shard)
+	defer unlockShard()
+
+	user, exists := shard.users[username]
+	if !exists {
+		return false, nil
+	}
+
+	// The rating the merged metrics come to must pass the board's rules
+	merged := copyMetrics(user.Metrics)
+	if merged == nil {
+		merged = make(map[string]float64, len(metrics))
+	}
+	for metric, value := range metrics {
+		merged[metric] = value
+	}
+	previous := user.Rating
+	if err := lb.validate(meta.Validation, username, &previous, CompositeRating(meta, merged)); err != nil {
+		return true, err
+	}
+
+	if user.Metrics == nil {
+		user.Metrics = make(map[string]float64, len(metrics))
+	}
+	for metric, value := range metrics {
+		user.Metrics[metric] = value
+	}
+
+	lb.setRatingLocked(shard, user, CompositeRating(meta, user.Metrics), time.Now())
+	// The raw metrics are part of the record even when the rating doesn't move
+	lb.version.Add(1)
+	return true, nil
+}
+
+// copyMetrics returns a copy of a raw metric map safe to hand out after unlocking
+func copyMetrics(metrics map[string]float64) map[string]float64 {
+	if len(metrics) == 0 {
+		return nil
+	}
+	copied := make(map[string]float64, len(metrics))
+	for metric, value := range metrics {
+		copied[metric] = value
+	}
+	return copied
+}
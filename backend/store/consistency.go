@@ -0,0 +1,249 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard-api/models"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxDriftDetails is how many drift descriptions a consistency report keeps
+const maxDriftDetails = 20
+
+// ConsistencyChecker samples users and verifies that the layers derived from the
+// board's user records agree with them: the published view ranks are served from,
+// the rating tree behind competition ranks, and the snapshot on disk. Drift is
+// counted and repaired: the view is republished, the tree rebuilt from the records,
+// or a fresh snapshot written.
+type ConsistencyChecker struct {
+	leaderboard  *Leaderboard
+	snapshotPath string // empty skips the durable check
+	sampleSize   int
+
+	mu    sync.Mutex // serializes checks and guards stats
+	stats models.ConsistencyStats
+
+	stopChan chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewConsistencyChecker creates a checker sampling sampleSize users of lb per check,
+// comparing them against the snapshot at snapshotPath when it is set
+func NewConsistencyChecker(lb *Leaderboard, snapshotPath string, sampleSize int) *ConsistencyChecker {
+	return &ConsistencyChecker{
+		leaderboard:  lb,
+		snapshotPath: snapshotPath,
+		sampleSize:   sampleSize,
+		stopChan:     make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Stats returns the totals across every check so far and the last report
+func (c *ConsistencyChecker) Stats() models.ConsistencyStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Check runs one sampled comparison and repairs any drift it finds
+func (c *ConsistencyChecker) Check() models.ConsistencyReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lb := c.leaderboard
+	start := time.Now()
+	report := models.ConsistencyReport{At: start, Details: make([]string, 0), Repairs: make([]string, 0)}
+	drift := func(layer *int, format string, args ...interface{}) {
+		*layer++
+		if len(report.Details) < maxDriftDetails {
+			report.Details = append(report.Details, fmt.Sprintf(format, args...))
+		}
+	}
+
+	// Freeze the records and the rating tree while sampling them
+	unlock := lb.lockRead()
+	unlockShards := lb.rlockAllShards()
+	truth := lb.copyAllLocked()
+	published := lb.published.Load()
+	picks := rand.Perm(len(truth.users))
+	if len(picks) > c.sampleSize {
+		picks = picks[:c.sampleSize]
+	}
+	sampled := make([]string, len(picks))
+	treeRanks := make([]int, len(picks))
+	for j, i := range picks {
+		sampled[j] = truth.users[i].Username
+		treeRanks[j] = lb.ratings.countAbove(truth.users[i].Rating) + 1
+	}
+	unlockShards()
+	unlock()
+
+	// Building sorts the users, so sampled users are found again by name
+	truth.build(published)
+	sample := make([]int, len(sampled))
+	for j, username := range sampled {
+		sample[j] = truth.index[username]
+	}
+	report.Sampled = len(sample)
+
+	// Ratings in descending order give each rating's competition rank by binary search
+	ratings := make([]int64, len(truth.users))
+	for i, user := range truth.users {
+		ratings[i] = user.Rating
+	}
+	sort.Slice(ratings, func(i, j int) bool { return ratings[i] > ratings[j] })
+
+	// The published view may lag the records; it is only compared at the same version
+	compareView := published != nil && published.version == truth.version
+	for j, i := range sample {
+		user := &truth.users[i]
+
+		above := sort.Search(len(ratings), func(k int) bool { return ratings[k] <= user.Rating })
+		if treeRanks[j] != above+1 {
+			drift(&report.TreeDrift, "%s: rating tree rank %d, records rank %d", user.Username, treeRanks[j], above+1)
+		}
+
+		if !compareView {
+			continue
+		}
+		k, found := published.index[user.Username]
+		switch {
+		case !found:
+			drift(&report.ViewDrift, "%s: missing from the published view", user.Username)
+		case published.users[k].Rating != user.Rating || published.ranks[k] != truth.ranks[i]:
+			drift(&report.ViewDrift, "%s: view rating %d rank %d, records rating %d rank %d", user.Username,
+				published.users[k].Rating, published.ranks[k], user.Rating, truth.ranks[i])
+		}
+	}
+
+	if c.snapshotPath != "" {
+		c.checkSnapshot(truth, sample, &report, drift)
+	}
+
+	if report.ViewDrift > 0 {
+		unlockPublish := lb.lockPublish()
+		if current := lb.published.Load(); current == nil || current.version <= truth.version {
+			lb.published.Store(truth)
+			lb.notifyRebuilt(truth, 0)
+		}
+		unlockPublish()
+		report.Repairs = append(report.Repairs, "view republished from the records")
+	}
+	if report.TreeDrift > 0 {
+		lb.rebuildRatingTree()
+		report.Repairs = append(report.Repairs, "rating tree rebuilt from the records")
+	}
+	if report.DurableDrift > 0 {
+		if err := lb.SaveSnapshot(c.snapshotPath); err != nil {
+			report.Repairs = append(report.Repairs, "snapshot rewrite failed: "+err.Error())
+		} else {
+			report.Repairs = append(report.Repairs, "snapshot rewritten")
+		}
+	}
+	report.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+
+	c.stats.Checks++
+	c.stats.Sampled += uint64(report.Sampled)
+	c.stats.ViewDrift += uint64(report.ViewDrift)
+	c.stats.TreeDrift += uint64(report.TreeDrift)
+	c.stats.DurableDrift += uint64(report.DurableDrift)
+	c.stats.Repairs += uint64(len(report.Repairs))
+	c.stats.Last = &report
+	return report
+}
+
+// checkSnapshot compares sampled users against the snapshot on disk. A user whose
+// rating last changed before the snapshot was taken must have that rating in it.
+// Late submissions are dated when they were made, so one applied after the snapshot
+// reads as drift; the repair, an early snapshot, is harmless.
+func (c *ConsistencyChecker) checkSnapshot(truth *view, sample []int, report *models.ConsistencyReport,
+	drift func(*int, string, ...interface{})) {
+	file, err := os.Open(c.snapshotPath)
+	if err != nil {
+		// Nothing has been written yet
+		return
+	}
+	defer file.Close()
+
+	var snapshot snapshotFile
+	if err := json.NewDecoder(file).Decode(&snapshot); err != nil {
+		drift(&report.DurableDrift, "snapshot unreadable: %v", err)
+		return
+	}
+	users, err := snapshot.users()
+	if err != nil {
+		drift(&report.DurableDrift, "snapshot unreadable: %v", err)
+		return
+	}
+	stored := make(map[string]int64, len(users))
+	for _, user := range users {
+		stored[user.Username] = user.Rating
+	}
+
+	for _, i := range sample {
+		user := &truth.users[i]
+		if !user.RatingUpdatedAt.Before(snapshot.TakenAt) {
+			continue
+		}
+		rating, found := stored[user.Username]
+		switch {
+		case !found:
+			// Added since the snapshot, with a rating dated earlier (e.g. imported)
+		case rating != user.Rating:
+			drift(&report.DurableDrift, "%s: snapshot rating %d, records rating %d", user.Username, rating, user.Rating)
+		}
+	}
+}
+
+// rebuildRatingTree resets the rating tree from the user records, and leaves the
+// top list to be refilled from them
+func (lb *Leaderboard) rebuildRatingTree() {
+	unlock := lb.lockWrite()
+	defer unlock()
+	unlockShards := lb.lockAllShards()
+	defer unlockShards()
+
+	ratings := make([]int64, 0, len(lb.users))
+	for _, user := range lb.users {
+		ratings = append(ratings, user.Rating)
+	}
+	lb.ratings.reset(ratings)
+	lb.top.invalidate()
+}
+
+// Start runs a check every interval
+func (c *ConsistencyChecker) Start(interval time.Duration) {
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if report := c.Check(); len(report.Repairs) > 0 {
+					log.Printf("Consistency check found drift (view %d, tree %d, snapshot %d): %v",
+						report.ViewDrift, report.TreeDrift, report.DurableDrift, report.Repairs)
+				}
+			case <-c.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic checks
+func (c *ConsistencyChecker) Stop() {
+	c.once.Do(func() {
+		close(c.stopChan)
+		<-c.done
+	})
+}
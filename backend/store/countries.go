@@ -0,0 +1,10 @@
+package store
+
+import "leaderboard-api/models"
+
+// GetCountryLeaderboard returns a page of the standings among users from country
+// (an ISO 3166-1 alpha-2 code) and how many users the country has. Entries are
+// ranked within the country; GlobalRank keeps their rank on the whole board.
+func (lb *Leaderboard) GetCountryLeaderboard(country string, limit, offset int) ([]models.LeaderboardEntry, int) {
+	return lb.segmentPage(func(v *view) map[string]*segmentIndex { return v.countries }, country, limit, offset)
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"leaderboard-api/models"
+	"sort"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is the sort position of the last entry a client has seen. Paging resumes
+// at the first user after that position in the current view, so ratings moving
+// between requests don't repeat or skip users that kept their place.
+type pageCursor struct {
+	Rating   int64   `json:"r"`
+	Username string  `json:"u"`
+	Achieved int64   `json:"a,omitempty"` // Unix nanoseconds, on boards breaking ties by achievement time
+	Keys     []int64 `json:"k,omitempty"` // sort key values, on boards with composite sort keys
+}
+
+// cursorAt encodes the sort position of the user at position i as an opaque token
+func (v *view) cursorAt(i int) string {
+	user := &v.users[i]
+	c := pageCursor{Rating: user.Rating, Username: user.Username}
+	if v.meta.TieBreak == models.TieBreakAchieved {
+		c.Achieved = user.RatingUpdatedAt.UnixNano()
+	}
+	for _, key := range v.meta.SortKeys {
+		c.Keys = append(c.Keys, fieldValue(user, key.Field))
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseCursor decodes a token from cursorAt, rejecting ones that don't fit v's sort keys
+func (v *view) parseCursor(token string) (pageCursor, error) {
+	var c pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.Username == "" {
+		return pageCursor{}, ErrInvalidCursor
+	}
+	if len(c.Keys) != len(v.meta.SortKeys) {
+		return pageCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// precedes reports whether the cursor position sorts ahead of user, by the same order
+// views are built in
+func (c pageCursor) precedes(v *view, user *models.User) bool {
+	if keys := v.meta.SortKeys; len(keys) > 0 {
+		for k, key := range keys {
+			if value := fieldValue(user, key.Field); c.Keys[k] != value {
+				return (c.Keys[k] > value) == key.Desc
+			}
+		}
+	} else if c.Rating != user.Rating {
+		return c.Rating > user.Rating
+	}
+	if v.meta.TieBreak == models.TieBreakAchieved {
+		if achieved := user.RatingUpdatedAt.UnixNano(); c.Achieved != achieved {
+			return c.Achieved < achieved
+		}
+	}
+	return c.Username < user.Username
+}
+
+// GetLeaderboardAfter returns up to limit entries following the cursor, or from the
+// top with an empty cursor, along with the cursor for the next page. The next cursor
+// is empty once the end of the board is reached.
+func (lb *Leaderboard) GetLeaderboardAfter(cursor string, limit int) ([]models.LeaderboardEntry, string, error) {
+	v := lb.current()
+	mode := lb.rankingMode(v)
+	base := lb.baselineFor(v)
+
+	start := 0
+	if cursor != "" {
+		c, err := v.parseCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(v.users), func(i int) bool { return c.precedes(v, &v.users[i]) })
+	}
+
+	end := start + limit
+	if end > len(v.users) {
+		end = len(v.users)
+	}
+
+	entries := make([]models.LeaderboardEntry, 0, end-start)
+	for i := start; i < end; i++ {
+		entries = append(entries, v.withDeltas(v.entry(i, mode), i, mode, base))
+	}
+
+	next := ""
+	if end < len(v.users) {
+		next = v.cursorAt(end - 1)
+	}
+	return entries, next, nil
+}
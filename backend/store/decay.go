@@ -0,0 +1,110 @@
+package store
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// DecayPolicy configures how the ratings of inactive users decay
+type DecayPolicy struct {
+	After  time.Duration   // inactivity before decay starts
+	PerDay int64           // rating lost per full day of inactivity after that
+	Floor  int64           // decay never takes a rating below this
+	Exempt map[string]bool // usernames that never decay
+}
+
+// ApplyDecay lowers the rating of every user inactive for longer than policy.After
+// by PerDay for each whole day of decay owed as of now, stopping at the floor.
+// Decay is tracked per user, so repeated runs never charge the same day twice, and
+// it doesn't count as activity. Returns the number of users whose rating changed.
+func (lb *Leaderboard) ApplyDecay(policy DecayPolicy, now time.Time) int {
+	if policy.PerDay <= 0 {
+		return 0
+	}
+
+	decayed := 0
+	for _, shard := range lb.shards {
+		unlock := lb.lockShard(shard)
+		for username, user := range shard.users {
+			if policy.Exempt[username] {
+				continue
+			}
+
+			from := user.LastActiveAt.Add(policy.After)
+			if user.DecayedThrough.After(from) {
+				from = user.DecayedThrough
+			}
+			days := int(now.Sub(from) / (24 * time.Hour))
+			if days <= 0 {
+				continue
+			}
+			user.DecayedThrough = from.Add(time.Duration(days) * 24 * time.Hour)
+
+			if user.Rating <= policy.Floor {
+				continue
+			}
+			rating := user.Rating - int64(days)*policy.PerDay
+			if rating < policy.Floor {
+				rating = policy.Floor
+			}
+			lb.moveRatingLocked(shard, user, rating, now)
+			decayed++
+		}
+		unlock()
+	}
+	return decayed
+}
+
+// Decayer periodically applies a decay policy to a board
+type Decayer struct {
+	leaderboard *Leaderboard
+	policy      DecayPolicy
+	stopChan    chan struct{}
+	done        chan struct{}
+	once        sync.Once
+}
+
+// NewDecayer creates a decayer applying policy to lb
+func NewDecayer(lb *Leaderboard, policy DecayPolicy) *Decayer {
+	return &Decayer{
+		leaderboard: lb,
+		policy:      policy,
+		stopChan:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start applies decay now and then every interval
+func (d *Decayer) Start(interval time.Duration) {
+	d.run()
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				d.run()
+			case <-d.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic decay
+func (d *Decayer) Stop() {
+	d.once.Do(func() {
+		close(d.stopChan)
+		<-d.done
+	})
+}
+
+func (d *Decayer) run() {
+	if n := d.leaderboard.ApplyDecay(d.policy, time.Now()); n > 0 {
+		log.Printf("Rating decay applied to %d inactive users", n)
+	}
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrDisplayNameTaken is returned when a display name is already in use on a board
+// that requires them to be unique
+var ErrDisplayNameTaken = errors.New("display name is taken")
+
+// Display name policies. Usernames are the immutable key every API reference uses;
+// display names are only what boards show, so whether two users may share one is a
+// per-deployment choice.
+const (
+	DisplayNamesShared = "shared" // any number of users may show the same name
+	DisplayNamesUnique = "unique" // no two users show the same name, ignoring case
+)
+
+// ParseDisplayNamePolicy validates a display name policy; an empty name means shared
+func ParseDisplayNamePolicy(name string) (string, error) {
+	switch policy := strings.ToLower(strings.TrimSpace(name)); policy {
+	case "":
+		return DisplayNamesShared, nil
+	case DisplayNamesShared, DisplayNamesUnique:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown display name policy %q (want shared or unique)", name)
+	}
+}
+
+// SetDisplayNamePolicy sets whether display names must be unique. Names already shared
+// when uniqueness is switched on are left alone; only new claims are refused.
+func (lb *Leaderboard) SetDisplayNamePolicy(policy string) {
+	lb.uniqueDisplayNames.Store(policy == DisplayNamesUnique)
+}
+
+// DisplayNamePolicy returns the board's display name policy
+func (lb *Leaderboard) DisplayNamePolicy() string {
+	if lb.uniqueDisplayNames.Load() {
+		return DisplayNamesUnique
+	}
+	return DisplayNamesShared
+}
+
+// foldDisplayName is the form display names are compared and searched in
+func foldDisplayName(name string) string {
+	return strings.ToLower(name)
+}
+
+// renameDisplay moves username's display name from old to new, reporting false if new
+// is taken under the unique policy. Either name may be empty. Names are counted rather
+// than owned so the counts stay right while duplicates are allowed.
+func (lb *Leaderboard) renameDisplay(old, new string) bool {
+	oldKey, newKey := foldDisplayName(old), foldDisplayName(new)
+	if oldKey == newKey && old != "" && new != "" {
+		// A change of case only
+		lb.displayNamesGen.Add(1)
+		return true
+	}
+
+	lb.displayNamesMu.Lock()
+	defer lb.displayNamesMu.Unlock()
+
+	if new != "" && lb.uniqueDisplayNames.Load() && lb.displayNames[newKey] > 0 {
+		return false
+	}
+	if old != "" {
+		if lb.displayNames[oldKey]--; lb.displayNames[oldKey] <= 0 {
+			delete(lb.displayNames, oldKey)
+		}
+	}
+	if new != "" {
+		lb.displayNames[newKey]++
+	}
+	lb.displayNamesGen.Add(1)
+	return true
+}
+
+// buildDisplayIndex indexes every prefix of every lowercase display name, with the
+// usernames showing it in alphabetical order
+func (v *view) buildDisplayIndex() {
+	v.displayIndex = make(map[string][]string)
+	for _, username := range v.sortedUsernames() {
+		name := foldDisplayName(v.users[v.index[username]].DisplayName)
+		// Prefixes end on rune boundaries so none splits a character
+		for i, r := range name {
+			prefix := name[:i+utf8.RuneLen(r)]
+			v.displayIndex[prefix] = append(v.displayIndex[prefix], username)
+		}
+	}
+}
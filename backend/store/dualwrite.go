@@ -0,0 +1,360 @@
+package store
+
+import (
+	"fmt"
+	"leaderboard-api/models"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxDivergences is how many recent divergences DualStore keeps for reporting
+const maxDivergences = 50
+
+// DualStore drives a migration between two backends. Every write goes to both stores;
+// reads are served by the primary (the old backend) until Cutover switches them to
+// the secondary, and a sample of reads is repeated against the other store and
+// compared. Mismatches are counted and the latest kept for reporting.
+//
+// Only the Store interface is mirrored: anything that writes to the in-memory board
+// directly, such as season rotation or rating decay, reaches the primary alone and
+// will show up as divergence. Divergences seen while writes are in flight can be
+// transient; ones that persist point at a real difference between the backends.
+type DualStore struct {
+	primary   Store
+	secondary Store
+
+	// Reads are served by the secondary once set
+	cutover atomic.Bool
+
+	// Every verifyEvery-th read is compared
+	verifyEvery uint64
+	reads       atomic.Uint64
+
+	mu    sync.Mutex
+	stats models.MigrationStats
+}
+
+// NewDualStore mirrors writes to primary and secondary and compares one in every
+// verifyEvery reads (every read when verifyEvery <= 1)
+func NewDualStore(primary, secondary Store, verifyEvery int) *DualStore {
+	if verifyEvery < 1 {
+		verifyEvery = 1
+	}
+	return &DualStore{
+		primary:     primary,
+		secondary:   secondary,
+		verifyEvery: uint64(verifyEvery),
+		stats:       models.MigrationStats{Recent: make([]models.Divergence, 0)},
+	}
+}
+
+// Cutover switches reads to the secondary store (or back, with toSecondary false).
+// Writes keep going to both either way.
+func (ds *DualStore) Cutover(toSecondary bool) {
+	ds.cutover.Store(toSecondary)
+}
+
+// Stats returns the migration counters and the most recent divergences
+func (ds *DualStore) Stats() models.MigrationStats {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	stats := ds.stats
+	stats.ReadFrom = "primary"
+	if ds.cutover.Load() {
+		stats.ReadFrom = "secondary"
+	}
+	stats.Reads = ds.reads.Load()
+	stats.Recent = append([]models.Divergence(nil), ds.stats.Recent...)
+	return stats
+}
+
+// Backfill copies the primary's users into the secondary, correcting ratings that
+// differ and removing users the primary doesn't have, so verification starts from
+// matching stores. Writes made meanwhile still go to both.
+func (ds *DualStore) Backfill() models.BackfillReport {
+	const page = 1000
+	start := time.Now()
+	var report models.BackfillReport
+
+	// The primary is read in one snapshot and copied from it in pages: paging the live
+	// ranking by offset would skip users whose rank moved between pages
+	snapshot := ds.primary.GetLeaderboard(ds.primary.GetTotalUsers(), 0)
+	present := make(map[string]bool, len(snapshot))
+	for from := 0; from < len(snapshot); from += page {
+		entries := snapshot[from:min(from+page, len(snapshot))]
+		users := make([]*models.User, 0, len(entries))
+		for _, entry := range entries {
+			present[entry.Username] = true
+			users = append(users, &models.User{ID: entry.Username, Username: entry.Username, Rating: entry.Rating, Scores: entry.Scores})
+		}
+		ds.secondary.BulkAddUsers(users)
+
+		for _, user := range users {
+			result, found := ds.secondary.GetUserRank(user.Username)
+			if found && result.Rating == user.Rating {
+				continue
+			}
+			// Re-read the primary so a write made since the snapshot isn't undone
+			if current, found := ds.primary.GetUserRank(user.Username); found {
+				ds.secondary.UpdateRating(user.Username, current.Rating)
+				report.Corrected++
+			}
+		}
+		report.Copied += len(users)
+	}
+
+	for offset := 0; ; {
+		entries := ds.secondary.GetLeaderboard(page, offset)
+		if len(entries) == 0 {
+			break
+		}
+		removed := 0
+		for _, entry := range entries {
+			if present[entry.Username] {
+				continue
+			}
+			// Users added since the snapshot went to both stores and stay
+			if _, found := ds.primary.GetUserRank(entry.Username); found {
+				continue
+			}
+			if ds.secondary.RemoveUser(entry.Username) {
+				removed++
+			}
+		}
+		report.Removed += removed
+		// Removals shift later users up into this page's range
+		offset += len(entries) - removed
+	}
+
+	report.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	return report
+}
+
+// reader returns the store serving reads and the one they are verified against
+func (ds *DualStore) reader() (Store, Store) {
+	if ds.cutover.Load() {
+		return ds.secondary, ds.primary
+	}
+	return ds.primary, ds.secondary
+}
+
+// sample reports whether this read should be verified
+func (ds *DualStore) sample() bool {
+	return ds.reads.Add(1)%ds.verifyEvery == 0
+}
+
+// verify runs check against the other store and records a divergence if it returns
+// a description of one. It runs inline, straight after the read it checks, so
+// writes landing in between are rare; sampled reads pay for the second read.
+func (ds *DualStore) verify(op string, check func(other Store) string) {
+	_, other := ds.reader()
+	detail := check(other)
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.stats.Verified++
+	if detail != "" {
+		ds.stats.Divergences++
+		ds.record(op, detail)
+	}
+}
+
+// writeResult records a divergence when the two stores disagree on whether a write
+// applied
+func (ds *DualStore) writeResult(op, username string, primary, secondary bool) bool {
+	if primary != secondary {
+		ds.mu.Lock()
+		ds.stats.WriteMismatches++
+		ds.record(op, fmt.Sprintf("%s: primary applied=%v, secondary applied=%v", username, primary, secondary))
+		ds.mu.Unlock()
+	}
+	if ds.cutover.Load() {
+		return secondary
+	}
+	return primary
+}
+
+// record keeps a divergence in the recent list; caller must hold mu
+func (ds *DualStore) record(op, detail string) {
+	ds.stats.Recent = append(ds.stats.Recent, models.Divergence{Op: op, Detail: detail, At: time.Now()})
+	if len(ds.stats.Recent) > maxDivergences {
+		ds.stats.Recent = ds.stats.Recent[len(ds.stats.Recent)-maxDivergences:]
+	}
+}
+
+// Metadata returns the primary's metadata
+func (ds *DualStore) Metadata() models.BoardMetadata {
+	return ds.primary.Metadata()
+}
+
+// AddUser adds the user to both stores; each gets its own copy
+func (ds *DualStore) AddUser(user *models.User) error {
+	copied := *user
+	primary, secondary := ds.primary.AddUser(user), ds.secondary.AddUser(&copied)
+	ds.writeResult("AddUser", user.Username, primary == nil, secondary == nil)
+	if ds.cutover.Load() {
+		return secondary
+	}
+	return primary
+}
+
+// BulkAddUsers adds the users to both stores; each gets its own copies
+func (ds *DualStore) BulkAddUsers(users []*models.User) {
+	copies := make([]*models.User, len(users))
+	for i, user := range users {
+		copied := *user
+		copies[i] = &copied
+	}
+	ds.primary.BulkAddUsers(users)
+	ds.secondary.BulkAddUsers(copies)
+}
+
+// UpdateRating updates the rating in both stores
+func (ds *DualStore) UpdateRating(username string, newRating int64) bool {
+	return ds.writeResult("UpdateRating", username,
+		ds.primary.UpdateRating(username, newRating), ds.secondary.UpdateRating(username, newRating))
+}
+
+// UpdateScores merges plugin scores in both stores
+func (ds *DualStore) UpdateScores(username string, scores map[string]int) bool {
+	return ds.writeResult("UpdateScores", username,
+		ds.primary.UpdateScores(username, scores), ds.secondary.UpdateScores(username, scores))
+}
+
+// RemoveUser deletes the user from both stores
+func (ds *DualStore) RemoveUser(username string) bool {
+	return ds.writeResult("RemoveUser", username,
+		ds.primary.RemoveUser(username), ds.secondary.RemoveUser(username))
+}
+
+// GetLeaderboard returns a page of standings
+func (ds *DualStore) GetLeaderboard(limit, offset int) []models.LeaderboardEntry {
+	store, _ := ds.reader()
+	entries := store.GetLeaderboard(limit, offset)
+	if ds.sample() {
+		ds.verify("GetLeaderboard", func(other Store) string {
+			return compareEntries(entries, other.GetLeaderboard(limit, offset))
+		})
+	}
+	return entries
+}
+
+// GetRankRange returns the entries ranked within [fromRank, toRank]
+func (ds *DualStore) GetRankRange(fromRank, toRank int) []models.LeaderboardEntry {
+	store, _ := ds.reader()
+	entries := store.GetRankRange(fromRank, toRank)
+	if ds.sample() {
+		ds.verify("GetRankRange", func(other Store) string {
+			return compareEntries(entries, other.GetRankRange(fromRank, toRank))
+		})
+	}
+	return entries
+}
+
+// GetUserRank returns a user's rank
+func (ds *DualStore) GetUserRank(username string) (*models.SearchResult, bool) {
+	store, _ := ds.reader()
+	result, found := store.GetUserRank(username)
+	if ds.sample() {
+		ds.verify("GetUserRank", func(other Store) string {
+			otherResult, otherFound := other.GetUserRank(username)
+			switch {
+			case found != otherFound:
+				return fmt.Sprintf("%s: found=%v, other found=%v", username, found, otherFound)
+			case found && (result.Rating != otherResult.Rating || result.GlobalRank != otherResult.GlobalRank):
+				return fmt.Sprintf("%s: rating %d rank %d, other rating %d rank %d",
+					username, result.Rating, result.GlobalRank, otherResult.Rating, otherResult.GlobalRank)
+			}
+			return ""
+		})
+	}
+	return result, found
+}
+
+// CompetitionRank returns the standard competition rank for a rating
+func (ds *DualStore) CompetitionRank(rating int64) int {
+	store, _ := ds.reader()
+	rank := store.CompetitionRank(rating)
+	if ds.sample() {
+		ds.verify("CompetitionRank", func(other Store) string {
+			if otherRank := other.CompetitionRank(rating); otherRank != rank {
+				return fmt.Sprintf("rating %d: rank %d, other rank %d", rating, rank, otherRank)
+			}
+			return ""
+		})
+	}
+	return rank
+}
+
+// SearchUsers is served without verification: the backends match names differently
+func (ds *DualStore) SearchUsers(query string, limit int) []models.SearchResult {
+	store, _ := ds.reader()
+	return store.SearchUsers(query, limit)
+}
+
+// SuggestUsernames is served without verification, like SearchUsers
+func (ds *DualStore) SuggestUsernames(prefix string, limit int) []string {
+	store, _ := ds.reader()
+	return store.SuggestUsernames(prefix, limit)
+}
+
+// GetRandomUser returns a user for score updates
+func (ds *DualStore) GetRandomUser(index int) *models.User {
+	store, _ := ds.reader()
+	return store.GetRandomUser(index)
+}
+
+// GetTotalUsers returns the number of users
+func (ds *DualStore) GetTotalUsers() int {
+	store, _ := ds.reader()
+	total := store.GetTotalUsers()
+	if ds.sample() {
+		ds.verify("GetTotalUsers", func(other Store) string {
+			if otherTotal := other.GetTotalUsers(); otherTotal != total {
+				return fmt.Sprintf("%d users, other %d", total, otherTotal)
+			}
+			return ""
+		})
+	}
+	return total
+}
+
+// GetStats returns board statistics
+func (ds *DualStore) GetStats() models.StatsResponse {
+	store, _ := ds.reader()
+	stats := store.GetStats()
+	if ds.sample() {
+		ds.verify("GetStats", func(other Store) string {
+			if otherStats := other.GetStats(); otherStats != stats {
+				return fmt.Sprintf("%+v, other %+v", stats, otherStats)
+			}
+			return ""
+		})
+	}
+	return stats
+}
+
+// Version returns the version of the store serving reads
+func (ds *DualStore) Version() uint64 {
+	store, _ := ds.reader()
+	return store.Version()
+}
+
+// compareEntries describes the first difference between two pages of standings.
+// Backends may list tied users in different orders, so only each position's rank and
+// rating are compared.
+func compareEntries(entries, other []models.LeaderboardEntry) string {
+	if len(entries) != len(other) {
+		return fmt.Sprintf("%d entries, other %d", len(entries), len(other))
+	}
+	for i := range entries {
+		if entries[i].Rank != other[i].Rank || entries[i].Rating != other[i].Rating {
+			return fmt.Sprintf("position %d: %s rank %d rating %d, other %s rank %d rating %d", i,
+				entries[i].Username, entries[i].Rank, entries[i].Rating,
+				other[i].Username, other[i].Rank, other[i].Rating)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ExpireInactive evicts every user with no rating update since now-ttl, keeping the
+// hot structures small. With archive set, evicted users are kept on the inactive
+// list, from which RestoreUser brings them back. Returns the number evicted.
+func (lb *Leaderboard) ExpireInactive(ttl time.Duration, archive bool, now time.Time) int {
+	cutoff := now.Add(-ttl)
+
+	unlock := lb.lockWrite()
+	defer unlock()
+	unlockShards := lb.lockAllShards()
+	defer unlockShards()
+
+	kept := lb.users[:0]
+	expired := 0
+	for _, user := range lb.users {
+		if !user.LastActiveAt.Before(cutoff) {
+			kept = append(kept, user)
+			continue
+		}
+
+		lb.dropLocked(lb.shardFor(user.Username), user)
+		if archive {
+			lb.inactive[user.Username] = user
+		}
+		expired++
+	}
+	// Clear the tail so evicted users aren't kept alive by the backing array
+	clear(lb.users[len(kept):])
+	lb.users = kept
+
+	if expired > 0 {
+		lb.members++
+		lb.version.Add(1)
+	}
+	return expired
+}
+
+// InactiveUsers returns a page of archived inactive users, most recently active first,
+// and how many there are in total
+func (lb *Leaderboard) InactiveUsers(limit, offset int) ([]models.User, int) {
+	unlock := lb.lockRead()
+	users := make([]models.User, 0, len(lb.inactive))
+	for _, user := range lb.inactive {
+		users = append(users, *user)
+	}
+	unlock()
+
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].LastActiveAt.Equal(users[j].LastActiveAt) {
+			return users[i].LastActiveAt.After(users[j].LastActiveAt)
+		}
+		return users[i].Username < users[j].Username
+	})
+
+	total := len(users)
+	if offset >= total {
+		return []models.User{}, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return users[offset:end], total
+}
+
+// RestoreUser moves a user from the inactive list back onto the board with the rating
+// they left with; restoring counts as activity
+func (lb *Leaderboard) RestoreUser(username string) bool {
+	unlock := lb.lockWrite()
+	defer unlock()
+
+	user, exists := lb.inactive[username]
+	if !exists {
+		return false
+	}
+	user.LastActiveAt = time.Now()
+	if !lb.insertLocked(user) {
+		// A new user has taken the name since
+		delete(lb.inactive, username)
+		return false
+	}
+
+	lb.members++
+	lb.version.Add(1)
+	lb.enforceCapacityLocked()
+	return true
+}
+
+// Expirer periodically evicts inactive users from a board
+type Expirer struct {
+	leaderboard *Leaderboard
+	ttl         time.Duration
+	archive     bool
+	stopChan    chan struct{}
+	done        chan struct{}
+	once        sync.Once
+}
+
+// NewExpirer creates an expirer evicting users of lb inactive for longer than ttl,
+// archiving them to the inactive list if archive is set
+func NewExpirer(lb *Leaderboard, ttl time.Duration, archive bool) *Expirer {
+	return &Expirer{
+		leaderboard: lb,
+		ttl:         ttl,
+		archive:     archive,
+		stopChan:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start evicts inactive users now and then every interval
+func (e *Expirer) Start(interval time.Duration) {
+	e.run()
+	go func() {
+		defer close(e.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				e.run()
+			case <-e.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic expiry
+func (e *Expirer) Stop() {
+	e.once.Do(func() {
+		close(e.stopChan)
+		<-e.done
+	})
+}
+
+func (e *Expirer) run() {
+	if n := e.leaderboard.ExpireInactive(e.ttl, e.archive, time.Now()); n > 0 {
+		log.Printf("Expired %d users inactive for over %v", n, e.ttl)
+	}
+}
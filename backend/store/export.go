@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"iter"
+	"leaderboard-api/models"
+)
+
+// Export walks every user in standings order, with Rank set by the board's ranking
+// mode. Users are copied from one point in time when iteration starts: all shards are
+// read-locked together just long enough to copy them, then sorted and handed out with
+// no lock held, so live updates carry on however long the consumer takes. Iteration
+// stops with ctx's error once ctx is done.
+func (lb *Leaderboard) Export(ctx context.Context) iter.Seq2[models.User, error] {
+	return func(yield func(models.User, error) bool) {
+		v := lb.pointInTime()
+		mode := lb.rankingMode(v)
+
+		for i := range v.users {
+			if err := ctx.Err(); err != nil {
+				yield(models.User{}, err)
+				return
+			}
+			// The view is private to this export, so its users can be handed out as-is
+			user := v.users[i]
+			user.Rank = v.rank(i, mode)
+			if !yield(user, nil) {
+				return
+			}
+		}
+	}
+}
+
+// pointInTime builds a private view of the board copied under every shard lock at
+// once, unlike published views whose shards may be copied moments apart
+func (lb *Leaderboard) pointInTime() *view {
+	unlock := lb.lockRead()
+	unlockShards := lb.rlockAllShards()
+	v := lb.copyAllLocked()
+	unlockShards()
+	unlock()
+
+	v.build(lb.published.Load())
+	return v
+}
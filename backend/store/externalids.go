@@ -0,0 +1,53 @@
+package store
+
+import (
+	"errors"
+	"leaderboard-api/models"
+)
+
+// ErrExternalIDTaken is returned when another user already holds an external ID
+var ErrExternalIDTaken = errors.New("external ID is taken")
+
+// claimExternalID records that username holds externalID, reporting false if another
+// user does. Empty IDs are never recorded.
+func (lb *Leaderboard) claimExternalID(externalID, username string) bool {
+	if externalID == "" {
+		return true
+	}
+	lb.externalIDsMu.Lock()
+	defer lb.externalIDsMu.Unlock()
+	if holder, taken := lb.externalIDs[externalID]; taken && holder != username {
+		return false
+	}
+	lb.externalIDs[externalID] = username
+	return true
+}
+
+// releaseExternalID forgets username's external ID as it leaves the board
+func (lb *Leaderboard) releaseExternalID(externalID, username string) {
+	if externalID == "" {
+		return
+	}
+	lb.externalIDsMu.Lock()
+	defer lb.externalIDsMu.Unlock()
+	if lb.externalIDs[externalID] == username {
+		delete(lb.externalIDs, externalID)
+	}
+}
+
+// ExternalIDHolder returns the username holding externalID, if any
+func (lb *Leaderboard) ExternalIDHolder(externalID string) (string, bool) {
+	lb.externalIDsMu.Lock()
+	defer lb.externalIDsMu.Unlock()
+	username, found := lb.externalIDs[externalID]
+	return username, found
+}
+
+// UserByExternalID looks a user up by the ID a game backend knows them by
+func (lb *Leaderboard) UserByExternalID(externalID string) (*models.SearchResult, bool) {
+	username, found := lb.ExternalIDHolder(externalID)
+	if !found {
+		return nil, false
+	}
+	return lb.GetUserRank(username)
+}
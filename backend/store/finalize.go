@@ -0,0 +1,156 @@
+package store
+
+import (
+	"errors"
+	"leaderboard-api/models"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultGracePeriod is how long a closed day or season keeps accepting late
+// submissions before it is finalized
+const DefaultGracePeriod = 48 * time.Hour
+
+var (
+	ErrPeriodFinalized = errors.New("period has been finalized")
+	ErrGracePeriod     = errors.New("period is still within its grace period")
+	ErrSeasonNotFound  = errors.New("season not found")
+)
+
+// SetGracePeriod sets how long past days stay open to late submissions
+func (lb *Leaderboard) SetGracePeriod(d time.Duration) {
+	lb.deltaMu.Lock()
+	defer lb.deltaMu.Unlock()
+	lb.gracePeriod = d
+}
+
+// FinalizedBefore returns the start of the oldest day still open to late submissions
+func (lb *Leaderboard) FinalizedBefore() time.Time {
+	lb.deltaMu.Lock()
+	defer lb.deltaMu.Unlock()
+	return lb.finalizedBefore
+}
+
+// FinalizeWindows closes every day whose grace period has ended by now. Their
+// rating gains become immutable and submissions dated in them are refused with
+// ErrPeriodFinalized. It returns the new finalization point, which never moves back.
+func (lb *Leaderboard) FinalizeWindows(now time.Time) time.Time {
+	lb.deltaMu.Lock()
+	defer lb.deltaMu.Unlock()
+
+	if before := now.Add(-lb.gracePeriod).Truncate(24 * time.Hour); before.After(lb.finalizedBefore) {
+		lb.finalizedBefore = before
+	}
+	return lb.finalizedBefore
+}
+
+// SetGracePeriod sets how long archived seasons keep accepting late submissions
+func (sa *SeasonArchive) SetGracePeriod(d time.Duration) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	sa.gracePeriod = d
+}
+
+// Finalize makes an archived season's standings final once its grace period has
+// ended. Later submissions dated in it are refused with ErrPeriodFinalized.
+func (sa *SeasonArchive) Finalize(id int, now time.Time) (models.SeasonSummary, error) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if id < 1 || id > len(sa.seasons) {
+		return models.SeasonSummary{}, ErrSeasonNotFound
+	}
+	season := sa.seasons[id-1]
+	switch {
+	case season.Finalized:
+		return season.SeasonSummary, ErrPeriodFinalized
+	case now.Before(season.EndedAt.Add(sa.gracePeriod)):
+		return season.SeasonSummary, ErrGracePeriod
+	}
+	season.finalize(now)
+	return season.SeasonSummary, nil
+}
+
+// FinalizeDue finalizes every archived season whose grace period has ended and
+// returns the ones it finalized
+func (sa *SeasonArchive) FinalizeDue(now time.Time) []models.SeasonSummary {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	finalized := make([]models.SeasonSummary, 0)
+	for _, season := range sa.seasons {
+		if !season.Finalized && !now.Before(season.EndedAt.Add(sa.gracePeriod)) {
+			season.finalize(now)
+			finalized = append(finalized, season.SeasonSummary)
+		}
+	}
+	return finalized
+}
+
+// finalize marks the season final; its submission marks are no longer needed
+func (season *archivedSeason) finalize(now time.Time) {
+	season.Finalized = true
+	season.FinalizedAt = &now
+	season.submitted = nil
+	season.achieved = nil
+}
+
+// Finalizer periodically finalizes the days and seasons of a board whose grace
+// period has ended
+type Finalizer struct {
+	leaderboard *Leaderboard
+	seasons     *SeasonArchive // may be nil
+	stopChan    chan struct{}
+	done        chan struct{}
+	once        sync.Once
+}
+
+// NewFinalizer creates a finalizer for lb and its season archive
+func NewFinalizer(lb *Leaderboard, seasons *SeasonArchive) *Finalizer {
+	return &Finalizer{
+		leaderboard: lb,
+		seasons:     seasons,
+		stopChan:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start finalizes whatever is due now and then every interval
+func (f *Finalizer) Start(interval time.Duration) {
+	f.run()
+	go func() {
+		defer close(f.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				f.run()
+			case <-f.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic finalization
+func (f *Finalizer) Stop() {
+	f.once.Do(func() {
+		close(f.stopChan)
+		<-f.done
+	})
+}
+
+func (f *Finalizer) run() {
+	now := time.Now()
+	f.leaderboard.FinalizeWindows(now)
+	if f.seasons == nil {
+		return
+	}
+	for _, season := range f.seasons.FinalizeDue(now) {
+		log.Printf("Season %d finalized", season.ID)
+	}
+}
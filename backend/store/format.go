@@ -0,0 +1,109 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"strconv"
+	"strings"
+)
+
+// MaxScoreDecimals bounds a score format's precision. Scores are 64-bit integers in
+// units of 10^-Decimals, so 9 decimals still leaves room for values past 10^9.
+const MaxScoreDecimals = 9
+
+var ErrUnknownScoreUnit = errors.New("score unit must be points, time_ms or currency")
+
+// FormatScore renders a raw score according to a board's score format
+func FormatScore(format models.ScoreFormat, score int64) string {
+	switch format.Unit {
+	case models.ScoreUnitTimeMs:
+		return formatDuration(score)
+	case models.ScoreUnitCurrency:
+		return formatFixed(score, format.Decimals, format.Symbol)
+	default:
+		return formatFixed(score, format.Decimals, "")
+	}
+}
+
+// formatDuration renders milliseconds as m:ss.mmm, or h:mm:ss.mmm past an hour
+func formatDuration(ms int64) string {
+	sign := ""
+	if ms < 0 {
+		sign = "-"
+		ms = -ms
+	}
+	hours := ms / 3600000
+	minutes := ms / 60000 % 60
+	seconds := ms / 1000 % 60
+	millis := ms % 1000
+	if hours > 0 {
+		return fmt.Sprintf("%s%d:%02d:%02d.%03d", sign, hours, minutes, seconds, millis)
+	}
+	return fmt.Sprintf("%s%d:%02d.%03d", sign, minutes, seconds, millis)
+}
+
+// formatFixed renders a fixed-point amount held in units of 10^-decimals (e.g. cents,
+// or hundredths of a rating point) after an optional symbol such as "$"
+func formatFixed(amount int64, decimals int, symbol string) string {
+	// Formatting before dropping the sign keeps the most negative int64 intact
+	digits, negative := strings.CutPrefix(strconv.FormatInt(amount, 10), "-")
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+	if decimals <= 0 {
+		return sign + symbol + digits
+	}
+
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	split := len(digits) - decimals
+	return sign + symbol + digits[:split] + "." + digits[split:]
+}
+
+// NewScoreFormat builds a score format for a unit, filling in the example rendering.
+// An empty unit means points. Points and currency keep decimals, clamped to
+// [0, MaxScoreDecimals]; any other unit fails with ErrUnknownScoreUnit.
+func NewScoreFormat(unit string, decimals int, symbol string) (models.ScoreFormat, error) {
+	decimals = min(max(decimals, 0), MaxScoreDecimals)
+	format := models.ScoreFormat{Unit: unit, Decimals: decimals, Symbol: symbol}
+	switch unit {
+	case models.ScoreUnitTimeMs:
+		format.Decimals = 0
+		format.Symbol = ""
+		format.Example = FormatScore(format, 83456)
+	case models.ScoreUnitCurrency:
+		format.Example = FormatScore(format, 123456)
+	case "", models.ScoreUnitPoints:
+		format.Unit = models.ScoreUnitPoints
+		format.Symbol = ""
+		format.Example = FormatScore(format, ScaleRating(format, 1500))
+	default:
+		return models.ScoreFormat{}, fmt.Errorf("%w, not %q", ErrUnknownScoreUnit, unit)
+	}
+	return format, nil
+}
+
+// defaultScoreFormat is whole points, the format of boards not configured otherwise
+func defaultScoreFormat() models.ScoreFormat {
+	format, _ := NewScoreFormat(models.ScoreUnitPoints, 0, "")
+	return format
+}
+
+// ScaleRating converts n whole points into the units a board with format holds
+// ratings in. Rating constants such as tier thresholds, defaults and step sizes are
+// given in points and scaled through here, so they mean the same on every board.
+func ScaleRating(format models.ScoreFormat, n int64) int64 {
+	return n * pow10(format.Decimals)
+}
+
+// pow10 returns 10^n for the small exponents score formats use
+func pow10(n int) int64 {
+	p := int64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
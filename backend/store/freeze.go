@@ -0,0 +1,59 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"time"
+)
+
+// freezeState is the view a frozen board keeps serving, and how far the milestone feed
+// had got when it froze
+type freezeState struct {
+	view         *view
+	at           time.Time
+	milestoneSeq uint64
+}
+
+// Freeze pins the standings readers see to the current view, as contests do near
+// their end. Writes keep being applied but stay out of rankings, searches and stats,
+// and milestones they cross are held back, until Unfreeze. It reports false if the
+// board was already frozen.
+func (lb *Leaderboard) Freeze() bool {
+	v := lb.latest()
+
+	lb.milestoneMu.Lock()
+	seq := lb.milestoneSeq
+	lb.milestoneMu.Unlock()
+
+	return lb.frozen.CompareAndSwap(nil, &freezeState{view: v, at: time.Now(), milestoneSeq: seq})
+}
+
+// Unfreeze publishes everything written since Freeze as the final standings. It
+// reports false if the board wasn't frozen.
+func (lb *Leaderboard) Unfreeze() bool {
+	return lb.frozen.Swap(nil) != nil
+}
+
+// Live returns a handle onto the same board that reads the latest standings even while
+// it is frozen or published in the background, for writers checking and reporting the
+// state they change
+func (lb *Leaderboard) Live() Store {
+	handle := *lb
+	handle.live = true
+	return &handle
+}
+
+// FreezeStatus reports whether the board is frozen and how far writes have moved past
+// the standings being served
+func (lb *Leaderboard) FreezeStatus() models.FreezeStatus {
+	state := lb.frozen.Load()
+	if state == nil {
+		return models.FreezeStatus{}
+	}
+	at := state.at
+	return models.FreezeStatus{
+		Frozen:        true,
+		Since:         &at,
+		FrozenVersion: state.view.version,
+		PendingWrites: lb.version.Load() - state.view.version,
+	}
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// FriendLists stores each user's friends list, for friends-scoped leaderboards. Lists
+// are one-way: naming someone as a friend doesn't add you to their list.
+type FriendLists struct {
+	mu      sync.RWMutex
+	friends map[string][]string
+}
+
+// NewFriendLists creates an empty friends list store
+func NewFriendLists() *FriendLists {
+	return &FriendLists{friends: make(map[string][]string)}
+}
+
+// Set replaces a user's friends list. The list is deduplicated and sorted; the user
+// themselves is left out. An empty list deletes it.
+func (fl *FriendLists) Set(username string, friends []string) ([]string, error) {
+	seen := make(map[string]bool, len(friends))
+	list := make([]string, 0, len(friends))
+	for _, friend := range friends {
+		if friend == "" || friend == username || seen[friend] {
+			continue
+		}
+		seen[friend] = true
+		list = append(list, friend)
+	}
+	// The user is ranked alongside their friends, so leave room for them
+	if len(list) >= MaxSubsetSize {
+		return nil, fmt.Errorf("a friends list holds at most %d users", MaxSubsetSize-1)
+	}
+	sort.Strings(list)
+
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	if len(list) == 0 {
+		delete(fl.friends, username)
+	} else {
+		fl.friends[username] = list
+	}
+	return list, nil
+}
+
+// Get returns a copy of a user's friends list, empty if they have none
+func (fl *FriendLists) Get(username string) []string {
+	fl.mu.RLock()
+	defer fl.mu.RUnlock()
+	return append([]string{}, fl.friends[username]...)
+}
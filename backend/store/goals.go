@@ -0,0 +1,147 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"time"
+)
+
+// ErrInvalidGoal wraps every reason a goal is rejected
+var ErrInvalidGoal = errors.New("invalid goal")
+
+// SetGoal gives a user a rating or rank target, replacing any goal they had. Goals
+// the user has already reached are rejected. It reports false if the user isn't on
+// the board.
+func (lb *Leaderboard) SetGoal(username, kind string, target int64) (*models.GoalProgress, bool, error) {
+	shard := lb.shardFor(username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
+	if !exists {
+		return nil, false, nil
+	}
+
+	rank := lb.CompetitionRank(user.Rating)
+	switch kind {
+	case models.GoalRating:
+		if target <= user.Rating {
+			return nil, true, fmt.Errorf("%w: rating %d is already reached", ErrInvalidGoal, target)
+		}
+	case models.GoalRank:
+		if target < 1 {
+			return nil, true, fmt.Errorf("%w: rank must be at least 1", ErrInvalidGoal)
+		}
+		if target >= int64(rank) {
+			return nil, true, fmt.Errorf("%w: rank %d is already reached", ErrInvalidGoal, target)
+		}
+	default:
+		return nil, true, fmt.Errorf("%w: type must be %q or %q", ErrInvalidGoal, models.GoalRating, models.GoalRank)
+	}
+
+	user.Goal = &models.Goal{
+		Type:        kind,
+		Target:      target,
+		StartRating: user.Rating,
+		StartRank:   rank,
+		SetAt:       time.Now(),
+	}
+	lb.version.Add(1)
+
+	progress := goalProgress(user.Goal, user.Rating, rank)
+	return &progress, true, nil
+}
+
+// ClearGoal removes a user's goal, reporting false if they had none
+func (lb *Leaderboard) ClearGoal(username string) bool {
+	shard := lb.shardFor(username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
+	if !exists || user.Goal == nil {
+		return false
+	}
+	user.Goal = nil
+	lb.version.Add(1)
+	return true
+}
+
+// GetGoal returns a user's goal and their progress towards it as of now. found is
+// false if the user isn't on the board or has no goal.
+func (lb *Leaderboard) GetGoal(username string) (*models.GoalProgress, bool) {
+	shard := lb.shardFor(username)
+	unlock := lb.rlockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
+	if !exists || user.Goal == nil {
+		return nil, false
+	}
+	progress := goalProgress(user.Goal, user.Rating, lb.CompetitionRank(user.Rating))
+	return &progress, true
+}
+
+// checkGoalLocked marks a user's goal reached, recording a goal_reached milestone,
+// once a rating change carries them to it. Rank goals are checked here too, so one
+// reached only through others dropping is noticed at the user's next rating change.
+// Called after the rating tree is moved; caller must hold the user's shard lock.
+func (lb *Leaderboard) checkGoalLocked(user *models.User, oldRating int64, rankBefore int, at time.Time) {
+	goal := user.Goal
+	if goal == nil || goal.AchievedAt != nil {
+		return
+	}
+	rankAfter := lb.CompetitionRank(user.Rating)
+	if !goalMet(goal, user.Rating, rankAfter) {
+		return
+	}
+
+	achieved := *goal
+	achieved.AchievedAt = &at
+	user.Goal = &achieved
+
+	lb.appendMilestones(models.MilestoneEvent{
+		Type:       models.MilestoneGoalReached,
+		Username:   user.Username,
+		Threshold:  goal.Target,
+		GoalType:   goal.Type,
+		RankBefore: rankBefore,
+		RankAfter:  rankAfter,
+		OldRating:  oldRating,
+		NewRating:  user.Rating,
+		Timestamp:  at,
+	})
+}
+
+// goalMet reports whether a rating and rank satisfy a goal
+func goalMet(goal *models.Goal, rating int64, rank int) bool {
+	if goal.Type == models.GoalRank {
+		return int64(rank) <= goal.Target
+	}
+	return rating >= goal.Target
+}
+
+// goalProgress measures how far a user at rating and rank has come towards a goal
+func goalProgress(goal *models.Goal, rating int64, rank int) models.GoalProgress {
+	progress := models.GoalProgress{
+		Goal:          *goal,
+		CurrentRating: rating,
+		CurrentRank:   rank,
+		Achieved:      goal.AchievedAt != nil || goalMet(goal, rating, rank),
+	}
+
+	var done, total float64
+	if goal.Type == models.GoalRank {
+		done, total = float64(goal.StartRank-rank), float64(int64(goal.StartRank)-goal.Target)
+	} else {
+		done, total = float64(rating-goal.StartRating), float64(goal.Target-goal.StartRating)
+	}
+	switch {
+	case progress.Achieved:
+		progress.Progress = 1
+	case total > 0 && done > 0:
+		progress.Progress = done / total
+	}
+	return progress
+}
@@ -0,0 +1,249 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrGroupSize       = errors.New("wrong number of group members")
+	ErrDuplicateMember = errors.New("group members must be distinct")
+	ErrGroupExists     = errors.New("group already exists")
+	ErrMemberSeparator = errors.New("group member names can't contain " + groupSeparator)
+)
+
+// groupSeparator joins member names in a group ID. Members may not contain it, so
+// every ID names exactly one set of members.
+const groupSeparator = "+"
+
+// GroupLeaderboard ranks fixed-size groups of users (duos, trios, ...) that share a score.
+// Groups are stored as entries of an inner Leaderboard keyed by a canonical group ID.
+type GroupLeaderboard struct {
+	mu sync.RWMutex
+
+	// Number of members per group
+	size int
+
+	// Inner board keyed by group ID
+	board *Leaderboard
+
+	// Members of each group, sorted
+	members map[string][]string
+
+	// Group IDs each user belongs to
+	groupsByMember map[string][]string
+}
+
+// NewGroupLeaderboard creates a co-op leaderboard for groups of the given size
+func NewGroupLeaderboard(size int) *GroupLeaderboard {
+	board := NewLeaderboard()
+	board.SetMetadata(models.BoardMetadata{
+		Name:        "duos",
+		Description: fmt.Sprintf("Co-op groups of %d sharing a score", size),
+		ScoreFormat: defaultScoreFormat(),
+	})
+
+	return &GroupLeaderboard{
+		size:           size,
+		board:          board,
+		members:        make(map[string][]string),
+		groupsByMember: make(map[string][]string),
+	}
+}
+
+// GroupID returns the canonical identity of a group, independent of member order
+func GroupID(members []string) string {
+	sorted := append([]string(nil), members...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, groupSeparator)
+}
+
+// Metadata returns the co-op board's name and score semantics
+func (gl *GroupLeaderboard) Metadata() models.BoardMetadata {
+	return gl.board.Metadata()
+}
+
+// Size returns the number of members per group
+func (gl *GroupLeaderboard) Size() int {
+	return gl.size
+}
+
+// AddGroup registers a new group with an initial shared rating
+func (gl *GroupLeaderboard) AddGroup(members []string, rating int64) (string, error) {
+	if len(members) != gl.size {
+		return "", fmt.Errorf("%w: expected %d, got %d", ErrGroupSize, gl.size, len(members))
+	}
+	seen := make(map[string]bool)
+	for _, m := range members {
+		if m == "" || seen[m] {
+			return "", ErrDuplicateMember
+		}
+		if strings.Contains(m, groupSeparator) {
+			return "", fmt.Errorf("%w: %q", ErrMemberSeparator, m)
+		}
+		seen[m] = true
+	}
+
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+
+	id := GroupID(members)
+	if _, exists := gl.members[id]; exists {
+		return "", ErrGroupExists
+	}
+
+	sorted := append([]string(nil), members...)
+	sort.Strings(sorted)
+	gl.members[id] = sorted
+	for _, m := range sorted {
+		gl.groupsByMember[m] = append(gl.groupsByMember[m], id)
+	}
+	gl.board.AddUser(&models.User{ID: id, Username: id, Rating: rating})
+
+	return id, nil
+}
+
+// RemoveGroup deletes a group and its member links
+func (gl *GroupLeaderboard) RemoveGroup(id string) bool {
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+
+	members, exists := gl.members[id]
+	if !exists {
+		return false
+	}
+
+	delete(gl.members, id)
+	for _, m := range members {
+		ids := gl.groupsByMember[m]
+		for i, g := range ids {
+			if g == id {
+				gl.groupsByMember[m] = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(gl.groupsByMember[m]) == 0 {
+			delete(gl.groupsByMember, m)
+		}
+	}
+	gl.board.RemoveUser(id)
+
+	return true
+}
+
+// UpdateGroupRating updates a group's shared rating
+func (gl *GroupLeaderboard) UpdateGroupRating(id string, rating int64) bool {
+	return gl.board.UpdateRating(id, rating)
+}
+
+// GetLeaderboard returns paginated group entries with tie-aware ranking
+func (gl *GroupLeaderboard) GetLeaderboard(limit, offset int) []models.GroupEntry {
+	gl.mu.RLock()
+	defer gl.mu.RUnlock()
+
+	entries := gl.board.GetLeaderboard(limit, offset)
+	results := make([]models.GroupEntry, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, models.GroupEntry{
+			Rank:    entry.Rank,
+			GroupID: entry.Username,
+			Members: gl.members[entry.Username],
+			Rating:  entry.Rating,
+			Display: entry.Display,
+		})
+	}
+	return results
+}
+
+// GetGroup returns a single group with its rank
+func (gl *GroupLeaderboard) GetGroup(id string) (*models.GroupEntry, bool) {
+	gl.mu.RLock()
+	defer gl.mu.RUnlock()
+
+	return gl.groupEntry(id)
+}
+
+// GetMemberGroups returns every group a user belongs to, ranked from that member's perspective
+func (gl *GroupLeaderboard) GetMemberGroups(username string) []models.GroupEntry {
+	gl.mu.RLock()
+	defer gl.mu.RUnlock()
+
+	results := make([]models.GroupEntry, 0)
+	for _, id := range gl.groupsByMember[username] {
+		if entry, found := gl.groupEntry(id); found {
+			results = append(results, *entry)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Rating > results[j].Rating
+	})
+	return results
+}
+
+// SearchByMember finds groups having any member whose username starts with query (case-insensitive)
+func (gl *GroupLeaderboard) SearchByMember(query string, limit int) []models.GroupEntry {
+	gl.mu.RLock()
+	defer gl.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	results := make([]models.GroupEntry, 0)
+	if query == "" {
+		return results
+	}
+
+	seen := make(map[string]bool)
+	for member, ids := range gl.groupsByMember {
+		if !strings.HasPrefix(strings.ToLower(member), query) {
+			continue
+		}
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if entry, found := gl.groupEntry(id); found {
+				results = append(results, *entry)
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rating != results[j].Rating {
+			return results[i].Rating > results[j].Rating
+		}
+		return results[i].GroupID < results[j].GroupID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// GetTotalGroups returns the number of registered groups
+func (gl *GroupLeaderboard) GetTotalGroups() int {
+	return gl.board.GetTotalUsers()
+}
+
+// groupEntry builds a ranked entry for a group; caller must hold gl.mu
+func (gl *GroupLeaderboard) groupEntry(id string) (*models.GroupEntry, bool) {
+	members, exists := gl.members[id]
+	if !exists {
+		return nil, false
+	}
+	rank, found := gl.board.GetUserRank(id)
+	if !found {
+		return nil, false
+	}
+	return &models.GroupEntry{
+		Rank:    rank.GlobalRank,
+		GroupID: id,
+		Members: members,
+		Rating:  rank.Rating,
+		Display: rank.Display,
+	}, true
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"time"
+)
+
+// historySize is how many rating changes are kept per user
+const historySize = 500
+
+// ratingHistory is a fixed-size ring buffer of a user's most recent rating changes
+type ratingHistory struct {
+	changes [historySize]models.RatingChange
+	next    int // slot the next change is written to
+	count   int
+}
+
+// record appends a change, overwriting the oldest once the buffer is full
+func (rh *ratingHistory) record(change models.RatingChange) {
+	rh.changes[rh.next] = change
+	rh.next = (rh.next + 1) % historySize
+	if rh.count < historySize {
+		rh.count++
+	}
+}
+
+// latest returns up to limit of the most recent changes, oldest first
+func (rh *ratingHistory) latest(limit int) []models.RatingChange {
+	if limit > rh.count {
+		limit = rh.count
+	}
+	changes := make([]models.RatingChange, 0, limit)
+	for i := limit; i > 0; i-- {
+		changes = append(changes, rh.changes[(rh.next-i+historySize)%historySize])
+	}
+	return changes
+}
+
+// recordHistory notes a rating change; caller must hold the user's shard lock
+func (shard *userShard) recordHistory(username string, oldRating, newRating int64, at time.Time) {
+	if oldRating == newRating {
+		return
+	}
+	history, exists := shard.history[username]
+	if !exists {
+		history = &ratingHistory{}
+		shard.history[username] = history
+	}
+	history.record(models.RatingChange{Old: oldRating, New: newRating, At: at})
+}
+
+// GetHistory returns up to limit of a user's most recent rating changes, oldest first
+func (lb *Leaderboard) GetHistory(username string, limit int) ([]models.RatingChange, bool) {
+	shard := lb.shardFor(username)
+	unlock := lb.rlockShard(shard)
+	defer unlock()
+
+	if _, exists := shard.users[username]; !exists {
+		return nil, false
+	}
+	history, exists := shard.history[username]
+	if !exists {
+		return []models.RatingChange{}, true
+	}
+	return history.latest(limit), true
+}
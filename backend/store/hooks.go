@@ -0,0 +1,115 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"time"
+)
+
+// rebuildHookBuffer is how many rebuilds a hook may fall behind by before further ones
+// are dropped for it
+const rebuildHookBuffer = 64
+
+// OnRatingChanged calls fn whenever a user's rating changes, until the returned
+// function is called. It is Subscribe narrowed to updates, delivered the same way.
+func (lb *Leaderboard) OnRatingChanged(fn func(models.ChangeEvent)) (unsubscribe func()) {
+	return lb.Subscribe(func(event models.ChangeEvent) {
+		if event.Type == models.ChangeUpdated {
+			fn(event)
+		}
+	})
+}
+
+// OnUserAdded calls fn whenever a user joins the board, until the returned function
+// is called. It is Subscribe narrowed to additions, delivered the same way.
+func (lb *Leaderboard) OnUserAdded(fn func(models.ChangeEvent)) (unsubscribe func()) {
+	return lb.Subscribe(func(event models.ChangeEvent) {
+		if event.Type == models.ChangeAdded {
+			fn(event)
+		}
+	})
+}
+
+// rebuildHook delivers rebuilds to one OnRankCacheRebuilt callback on its own goroutine
+type rebuildHook struct {
+	fn     func(models.RankCacheRebuild)
+	events chan models.RankCacheRebuild
+	stop   chan struct{}
+}
+
+// OnRankCacheRebuilt calls fn each time the ranks reads are served from are rebuilt,
+// until the returned function is called: after writes once a reader needs them, or
+// on every tick of a background Publisher. Embedders can use it to refresh caches of
+// their own derived from ranks. Calls come on a goroutine of the hook's own, so fn may
+// read the board; a hook too far behind misses rebuilds.
+func (lb *Leaderboard) OnRankCacheRebuilt(fn func(models.RankCacheRebuild)) (unsubscribe func()) {
+	hook := &rebuildHook{
+		fn:     fn,
+		events: make(chan models.RankCacheRebuild, rebuildHookBuffer),
+		stop:   make(chan struct{}),
+	}
+	go hook.run()
+
+	lb.rebuildHooksMu.Lock()
+	hooks := append(lb.loadRebuildHooks(), hook)
+	lb.rebuildHooks.Store(&hooks)
+	lb.rebuildHooksMu.Unlock()
+
+	return func() {
+		lb.rebuildHooksMu.Lock()
+		defer lb.rebuildHooksMu.Unlock()
+
+		current := lb.loadRebuildHooks()
+		remaining := make([]*rebuildHook, 0, len(current))
+		for _, h := range current {
+			if h != hook {
+				remaining = append(remaining, h)
+			}
+		}
+		if len(remaining) == len(current) {
+			return
+		}
+		lb.rebuildHooks.Store(&remaining)
+		close(hook.stop)
+	}
+}
+
+// run delivers queued rebuilds until the hook is removed
+func (hook *rebuildHook) run() {
+	for {
+		select {
+		case event := <-hook.events:
+			hook.fn(event)
+		case <-hook.stop:
+			return
+		}
+	}
+}
+
+// loadRebuildHooks returns the current hooks; the slice must not be modified
+func (lb *Leaderboard) loadRebuildHooks() []*rebuildHook {
+	if hooks := lb.rebuildHooks.Load(); hooks != nil {
+		return *hooks
+	}
+	return nil
+}
+
+// notifyRebuilt hands a freshly published view's rebuild to every hook without blocking
+func (lb *Leaderboard) notifyRebuilt(v *view, took time.Duration) {
+	hooks := lb.loadRebuildHooks()
+	if len(hooks) == 0 {
+		return
+	}
+
+	event := models.RankCacheRebuild{
+		Version:    v.version,
+		Users:      len(v.users),
+		DurationMs: float64(took.Microseconds()) / 1000,
+		At:         time.Now(),
+	}
+	for _, hook := range hooks {
+		select {
+		case hook.events <- event:
+		default:
+		}
+	}
+}
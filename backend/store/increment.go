@@ -0,0 +1,131 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrRatingOverflow is returned when a relative change would take a rating past
+	// the range ratings can hold
+	ErrRatingOverflow = errors.New("rating change overflows the rating range")
+
+	// ErrUserNotFound is wrapped with the username when a change names a user the
+	// board doesn't have
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrUserExists is returned when adding a user whose username is taken
+	ErrUserExists = errors.New("user already exists")
+)
+
+// addRating returns rating+delta, or false if the sum overflows
+func addRating(rating, delta int64) (int64, bool) {
+	sum := rating + delta
+	if (delta > 0 && sum < rating) || (delta < 0 && sum > rating) {
+		return 0, false
+	}
+	return sum, true
+}
+
+// AddDeltas returns ratings with the matching delta added to each, or nil and
+// ErrRatingOverflow if any sum leaves the int64 range
+func AddDeltas(ratings, deltas []int64) ([]int64, error) {
+	sums := make([]int64, len(ratings))
+	for i, rating := range ratings {
+		sum, ok := addRating(rating, deltas[i])
+		if !ok {
+			return nil, ErrRatingOverflow
+		}
+		sums[i] = sum
+	}
+	return sums, nil
+}
+
+// IncrementRating adds delta to a user's rating in one step, so concurrent changes
+// never lose one another. The result is checked against the board's validation rules
+// like any rating a client writes. It returns the rating stored, and reports false if
+// the user doesn't exist.
+func (lb *Leaderboard) IncrementRating(username string, delta int64) (int64, bool, error) {
+	// Rules are read under the board lock, which is taken before the shard's
+	rules := lb.Metadata().Validation
+
+	shard := lb.shardFor(username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
+	if !exists {
+		return 0, false, nil
+	}
+	previous := user.Rating
+	rating, ok := addRating(previous, delta)
+	if !ok {
+		return previous, true, ErrRatingOverflow
+	}
+	if err := lb.validate(rules, username, &previous, rating); err != nil {
+		return previous, true, err
+	}
+
+	lb.setRatingLocked(shard, user, rating, time.Now())
+	// Series rules may hold the rating back
+	return user.Rating, true, nil
+}
+
+// TransformRatings changes several users' ratings together, as for the players of a
+// match: transform gets their current ratings, in the order of usernames, and returns
+// the new ones. Every new rating is checked against the board's validation rules before
+// any is written, and no view is built meanwhile, so readers see all of the change or
+// none of it. It returns the ratings before and as stored after.
+func (lb *Leaderboard) TransformRatings(usernames []string, transform func(ratings []int64) []int64) (before, after []int64, err error) {
+	// Rules are read before the board lock is taken for writing
+	rules := lb.Metadata().Validation
+
+	unlock := lb.lockWrite()
+	defer unlock()
+
+	// Shards are locked in index order, as lockAllShards does, so two transforms can't
+	// wait on each other
+	var indexes []int
+	for _, username := range usernames {
+		indexes = append(indexes, shardIndex(username))
+	}
+	sort.Ints(indexes)
+	for i, index := range indexes {
+		if i > 0 && index == indexes[i-1] {
+			continue
+		}
+		unlockShard := lb.lockShard(lb.shards[index])
+		defer unlockShard()
+	}
+
+	users := make([]*models.User, len(usernames))
+	before = make([]int64, len(usernames))
+	for i, username := range usernames {
+		user, exists := lb.shardFor(username).users[username]
+		if !exists {
+			return nil, nil, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+		}
+		users[i], before[i] = user, user.Rating
+	}
+
+	ratings := transform(append([]int64(nil), before...))
+	if len(ratings) != len(usernames) {
+		return nil, nil, fmt.Errorf("transform returned %d ratings for %d users", len(ratings), len(usernames))
+	}
+	for i, username := range usernames {
+		if err := lb.validate(rules, username, &before[i], ratings[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := time.Now()
+	after = make([]int64, len(usernames))
+	for i, user := range users {
+		lb.setRatingLocked(lb.shardFor(user.Username), user, ratings[i], now)
+		after[i] = user.Rating
+	}
+	return before, after, nil
+}
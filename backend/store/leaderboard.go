@@ -5,354 +5,666 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// Leaderboard manages users and their rankings efficiently
+// Leaderboard manages users and their rankings efficiently. Users are spread over
+// per-shard locks (see shards.go) so rating updates for different users proceed in
+// parallel; mu only guards metadata and membership. Readers are served from an
+// immutable view (see view.go) that is swapped in atomically, so reads never contend
+// with writers. Locks are always taken in the order mu, then shards by index.
 type Leaderboard struct {
-	mu sync.RWMutex
-
-	// All users indexed by username for O(1) lookup
-	usersByUsername map[string]*models.User
+	*leaderboardState
 
-	// Users sorted by rating (descending) for leaderboard display
-	sortedUsers []*models.User
+	// Request-scoped lock trace; nil on the shared handle (see WithLockTrace)
+	trace *LockTrace
 
-	// Rating to list of usernames for tie-aware ranking
-	ratingToUsers map[int][]string
+	// Ranking mode overriding the board's; empty on the shared handle (see WithRanking)
+	ranking string
 
-	// Cache for rank lookup - maps rating to rank
-	rankCache map[int]int
+	// Who changes made through this handle are attributed to; empty on the shared
+	// handle (see WithSource)
+	source string
+	actor  string
 
-	// Flag to indicate if rankCache needs rebuild
-	rankCacheDirty bool
+	// Reads the latest standings, through a freeze and without waiting for the
+	// background publisher; false on the shared handle (see Live)
+	live bool
+}
 
-	// Prefix index for fast user search - maps lowercase prefix to list of usernames
-	prefixIndex map[string][]string
+// leaderboardState is the board data shared by every handle onto a Leaderboard
+type leaderboardState struct {
+	// Guards meta, users, members and inactive
+	mu sync.RWMutex
 
-	// Flag to indicate if prefixIndex needs rebuild
-	prefixIndexDirty bool
+	// Board name and score semantics
+	meta models.BoardMetadata
+
+	// Users and active series, partitioned by username hash
+	shards [shardCount]*userShard
+
+	// All users in insertion order
+	users []*models.User
+
+	// Bumped whenever a user joins or leaves, so views can reuse the prefix index
+	members uint64
+
+	// Display names in use, folded, with how many users show each; the generation is
+	// bumped on every rename so views know to reindex (see displaynames.go)
+	displayNamesMu     sync.Mutex
+	displayNames       map[string]int
+	displayNamesGen    atomic.Uint64
+	uniqueDisplayNames atomic.Bool
+
+	// Username by the ID a game backend knows the user by (see externalids.go)
+	externalIDsMu sync.Mutex
+	externalIDs   map[string]string
+
+	// Users evicted for inactivity and archived, by username (see expiry.go)
+	inactive map[string]*models.User
+
+	// Every user's rating, for competition ranks without a view rebuild
+	ratings *ratingTree
+
+	// Best users in ranking order, kept up on every write so the first pages are
+	// served without a view (see topk.go)
+	top *topList
+
+	// Checks registered by the embedding application, run on every rating clients
+	// write; replaced copy-on-write under validatorsMu (see validation.go)
+	validatorsMu sync.Mutex
+	validators   atomic.Pointer[[]Validator]
+
+	// Promotion/demotion series length (0 = series disabled)
+	seriesBestOf atomic.Int64
+
+	// Stored units per point under meta's score format, for writers that hold only a
+	// shard lock (see ScaleRating)
+	pointScale atomic.Int64
+
+	// Monotonically increasing version, bumped on every mutation and stamped with
+	// when it was reached (see versionclock.go)
+	version versionClock
+
+	// Latest view published for readers; publishMu serializes rebuilding it
+	published atomic.Pointer[view]
+	publishMu sync.Mutex
+
+	// Standings served instead of the published view while the board is frozen
+	// (see freeze.go)
+	frozen atomic.Pointer[freezeState]
+
+	// Set while a Publisher rebuilds views in the background, so readers take the
+	// published view as it is rather than building one inline
+	background atomic.Bool
+
+	// Standings deltas are measured against (see baseline.go)
+	baselineMu       sync.Mutex
+	baselineInterval time.Duration
+	baseline         *view
+	candidate        *view
+	candidateAt      time.Time
+	baselineGen      uint64 // rotations so far
+
+	// Cached search results, invalidated whenever version changes
+	searchCache *searchCache
+
+	// Time-indexed log of rating gains for windowed leaderboards, oldest first
+	deltaMu      sync.Mutex
+	deltaBuckets []*deltaBucket
+
+	// The last hour of gains again by the minute, for movers (see movers.go). Guarded
+	// by deltaMu.
+	moverBuckets []*deltaBucket
+
+	// Days before finalizedBefore are final: their gains no longer change and
+	// submissions dated in them are refused (see finalize.go). Guarded by deltaMu.
+	gracePeriod     time.Duration
+	finalizedBefore time.Time
+
+	// Recent series events for streaming, with a monotonically increasing sequence
+	seriesMu     sync.Mutex
+	seriesEvents []models.SeriesEvent
+	seriesSeq    uint64
+
+	// Milestone policy (nil = milestones disabled) and recent milestone events for
+	// streaming, with a monotonically increasing sequence (see milestones.go)
+	milestones      atomic.Pointer[MilestonePolicy]
+	milestoneMu     sync.Mutex
+	milestoneEvents []models.MilestoneEvent
+	milestoneSeq    uint64
+
+	// Change subscribers, replaced copy-on-write under observersMu so writers read them
+	// without locking (see observers.go)
+	observersMu    sync.Mutex
+	observers      atomic.Pointer[[]*observer]
+	changeSeq      atomic.Uint64
+	droppedChanges atomic.Uint64
+
+	// OnRankCacheRebuilt callbacks, replaced copy-on-write like observers (see hooks.go)
+	rebuildHooksMu sync.Mutex
+	rebuildHooks   atomic.Pointer[[]*rebuildHook]
+
+	// All-time records, kept up by a ReignTracker and saved with snapshots (see records.go)
+	recordsMu sync.Mutex
+	records   models.Records
+
+	// Wait/hold histograms for mu, the shards and publishMu
+	locks lockStats
 }
 
 // NewLeaderboard creates a new leaderboard instance
 func NewLeaderboard() *Leaderboard {
-	return &Leaderboard{
-		usersByUsername:  make(map[string]*models.User),
-		sortedUsers:      make([]*models.User, 0),
-		ratingToUsers:    make(map[int][]string),
-		rankCache:        make(map[int]int),
-		rankCacheDirty:   true,
-		prefixIndex:      make(map[string][]string),
-		prefixIndexDirty: true,
+	meta := models.BoardMetadata{
+		Name:        "global",
+		ScoreFormat: defaultScoreFormat(),
 	}
+	lb := &Leaderboard{leaderboardState: &leaderboardState{
+		meta:         meta,
+		shards:       newUserShards(),
+		users:        make([]*models.User, 0),
+		inactive:     make(map[string]*models.User),
+		displayNames: make(map[string]int),
+		externalIDs:  make(map[string]string),
+		ratings:      newRatingTree(),
+		top:          newTopList(meta),
+		searchCache:  newSearchCache(),
+		gracePeriod:  DefaultGracePeriod,
+	}}
+	lb.pointScale.Store(ScaleRating(meta.ScoreFormat, 1))
+	return lb
 }
 
-// AddUser adds a new user to the leaderboard
-func (lb *Leaderboard) AddUser(user *models.User) {
-	lb.mu.Lock()
-	defer lb.mu.Unlock()
+// SetMetadata replaces the board's name and score semantics
+func (lb *Leaderboard) SetMetadata(meta models.BoardMetadata) {
+	unlock := lb.lockWrite()
+	defer unlock()
+	lb.meta = meta
+	lb.pointScale.Store(ScaleRating(meta.ScoreFormat, 1))
+	lb.top.setMeta(meta)
+	lb.version.Add(1)
+	lb.enforceCapacityLocked()
+}
 
-	// Check if user already exists
-	if _, exists := lb.usersByUsername[user.Username]; exists {
-		return
-	}
+// Metadata returns the board's name and score semantics
+func (lb *Leaderboard) Metadata() models.BoardMetadata {
+	unlock := lb.lockRead()
+	defer unlock()
+	return lb.meta
+}
 
-	lb.usersByUsername[user.Username] = user
+// Version returns the store version, which changes on every mutation. It is the version
+// of the standings readers are served, so while the board is frozen it stays at the
+// frozen standings' version, and while a background publisher runs it moves only as
+// views are published.
+func (lb *Leaderboard) Version() uint64 {
+	if v := lb.served(); v != nil {
+		return v.version
+	}
+	return lb.version.Load()
+}
 
-	// Add to sorted list (will be sorted in batch later or use binary insert)
-	lb.sortedUsers = append(lb.sortedUsers, user)
+// SearchCacheStats returns hit/miss counters for the search result cache
+func (lb *Leaderboard) SearchCacheStats() models.CacheStats {
+	return lb.searchCache.stats()
+}
 
-	// Add to rating map
-	lb.ratingToUsers[user.Rating] = append(lb.ratingToUsers[user.Rating], user.Username)
+// AddUser adds a new user to the leaderboard, evicting the lowest-rated user if that
+// takes the board past its capacity. It returns ErrUserExists or ErrExternalIDTaken,
+// adding nothing, if the username or the user's external ID is taken.
+func (lb *Leaderboard) AddUser(user *models.User) error {
+	unlock := lb.lockWrite()
+	defer unlock()
+
+	// External IDs are only claimed and released under the write lock, so the check
+	// holds until the insert
+	if holder, taken := lb.ExternalIDHolder(user.ExternalID); taken && holder != user.Username {
+		return ErrExternalIDTaken
+	}
+	if !lb.insertLocked(user) {
+		return ErrUserExists
+	}
 
-	lb.rankCacheDirty = true
-	lb.prefixIndexDirty = true
+	lb.members++
+	lb.version.Add(1)
+	lb.enforceCapacityLocked()
+	return nil
 }
 
-// BulkAddUsers adds multiple users efficiently
+// BulkAddUsers adds multiple users efficiently. On a board with a capacity, the
+// lowest-rated users past it are evicted once all have been added.
 func (lb *Leaderboard) BulkAddUsers(users []*models.User) {
-	lb.mu.Lock()
-	defer lb.mu.Unlock()
+	unlock := lb.lockWrite()
+	defer unlock()
 
 	for _, user := range users {
-		if _, exists := lb.usersByUsername[user.Username]; exists {
-			continue
-		}
-
-		lb.usersByUsername[user.Username] = user
-		lb.sortedUsers = append(lb.sortedUsers, user)
-		lb.ratingToUsers[user.Rating] = append(lb.ratingToUsers[user.Rating], user.Username)
+		lb.insertLocked(user)
 	}
 
-	// Sort all users by rating descending after bulk add
-	sort.Slice(lb.sortedUsers, func(i, j int) bool {
-		return lb.sortedUsers[i].Rating > lb.sortedUsers[j].Rating
-	})
-
-	lb.rankCacheDirty = true
-	lb.prefixIndexDirty = true
+	lb.members++
+	lb.version.Add(1)
+	lb.enforceCapacityLocked()
 }
 
-// rebuildRankCache rebuilds the rank cache for tie-aware ranking
-func (lb *Leaderboard) rebuildRankCache() {
-	if !lb.rankCacheDirty {
+// insertLocked adds a user to its shard and the membership list, reporting false if the
+// username is taken; caller must hold the write lock
+func (lb *Leaderboard) insertLocked(user *models.User) bool {
+	shard := lb.shardFor(user.Username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
 
-		return
+	if _, exists := shard.users[user.Username]; exists {
+		return false
 	}
-
-	lb.rankCache = make(map[int]int)
-
-	// Get unique ratings sorted descending
-	ratings := make([]int, 0, len(lb.ratingToUsers))
-	for rating := range lb.ratingToUsers {
-		ratings = append(ratings, rating)
+	if user.RatingUpdatedAt.IsZero() {
+		user.RatingUpdatedAt = time.Now()
 	}
-	sort.Sort(sort.Reverse(sort.IntSlice(ratings)))
-
-	// Assign ranks - same rating gets same rank (dense ranking)
-	rank := 1
-	for _, rating := range ratings {
-		lb.rankCache[rating] = rank
-		rank++
+	if user.LastActiveAt.IsZero() {
+		user.LastActiveAt = user.RatingUpdatedAt
 	}
-
-	lb.rankCacheDirty = false
-}
-
-// rebuildPrefixIndex rebuilds the prefix index from current users
-func (lb *Leaderboard) rebuildPrefixIndex() {
-	if !lb.prefixIndexDirty {
-		return
+	if user.PeakRatingAt.IsZero() {
+		user.PeakRating = user.Rating
+		user.PeakRatingAt = user.RatingUpdatedAt
+		user.LowestRating = user.Rating
 	}
-
-	lb.prefixIndex = make(map[string][]string)
-	for username := range lb.usersByUsername {
-		usernameL := strings.ToLower(username)
-		// Add all prefixes of the username
-		for i := 1; i <= len(usernameL); i++ {
-			prefix := usernameL[:i]
-			lb.prefixIndex[prefix] = append(lb.prefixIndex[prefix], username)
-		}
+	// A user joining with a display name someone else holds joins without one, and
+	// likewise an external ID when bulk added or restored; AddUser refuses it first
+	if user.DisplayName != "" && !lb.renameDisplay("", user.DisplayName) {
+		user.DisplayName = ""
+	}
+	if !lb.claimExternalID(user.ExternalID, user.Username) {
+		user.ExternalID = ""
 	}
-	lb.prefixIndexDirty = false
-}
 
-// ensureSorted makes sure the sortedUsers slice is sorted
-func (lb *Leaderboard) ensureSorted() {
-	sort.Slice(lb.sortedUsers, func(i, j int) bool {
-		if lb.sortedUsers[i].Rating != lb.sortedUsers[j].Rating {
-			return lb.sortedUsers[i].Rating > lb.sortedUsers[j].Rating
-		}
-		return lb.sortedUsers[i].Username < lb.sortedUsers[j].Username
-	})
+	shard.users[user.Username] = user
+	lb.users = append(lb.users, user)
+	delete(lb.inactive, user.Username)
+	lb.ratings.add(user.Rating, 1)
+	lb.top.update(user)
+	if lb.observed() {
+		lb.notifyChange(models.ChangeAdded, user.Username, 0, user.Rating, 0, lb.CompetitionRank(user.Rating), user.RatingUpdatedAt)
+	}
+	return true
 }
 
 // GetLeaderboard returns paginated leaderboard entries with tie-aware ranking
 func (lb *Leaderboard) GetLeaderboard(limit, offset int) []models.LeaderboardEntry {
-	lb.mu.RLock()
-	defer lb.mu.RUnlock()
-
-	if lb.rankCacheDirty {
-		lb.mu.RUnlock()
-		lb.mu.Lock()
-		lb.rebuildRankCache()
-		lb.ensureSorted()
-		lb.mu.Unlock()
-		lb.mu.RLock()
+	if entries, ok := lb.topPage(limit, offset); ok {
+		return entries
 	}
 
-	if offset >= len(lb.sortedUsers) {
+	v := lb.current()
+	mode := lb.rankingMode(v)
+	base := lb.baselineFor(v)
+
+	if offset >= len(v.users) {
 		return []models.LeaderboardEntry{}
 	}
 
 	end := offset + limit
-	if end > len(lb.sortedUsers) {
-		end = len(lb.sortedUsers)
+	if end > len(v.users) {
+		end = len(v.users)
 	}
 
 	entries := make([]models.LeaderboardEntry, 0, end-offset)
 	for i := offset; i < end; i++ {
-		user := lb.sortedUsers[i]
-		entries = append(entries, models.LeaderboardEntry{
-			Rank:     lb.rankCache[user.Rating],
-			Username: user.Username,
-			Rating:   user.Rating,
-		})
+		entries = append(entries, v.withDeltas(v.entry(i, mode), i, mode, base))
 	}
 
 	return entries
 }
 
-// SearchUsers searches for users by username using prefix index (case-insensitive)
-func (lb *Leaderboard) SearchUsers(query string, limit int) []models.SearchResult {
-	lb.mu.RLock()
-	defer lb.mu.RUnlock()
+// GetRankRange returns all entries whose rank falls within [fromRank, toRank].
+// Tied users share a rank, so the result may hold more entries than toRank-fromRank+1.
+func (lb *Leaderboard) GetRankRange(fromRank, toRank int) []models.LeaderboardEntry {
+	v := lb.current()
+	mode := lb.rankingMode(v)
+	base := lb.baselineFor(v)
+
+	// Ranks are non-decreasing along the view in every mode, so binary search for the first match
+	start := sort.Search(len(v.users), func(i int) bool { return v.rank(i, mode) >= fromRank })
 
-	if lb.rankCacheDirty {
-		lb.mu.RUnlock()
-		lb.mu.Lock()
-		lb.rebuildRankCache()
-		lb.ensureSorted()
-		lb.mu.Unlock()
-		lb.mu.RLock()
+	entries := make([]models.LeaderboardEntry, 0)
+	for i := start; i < len(v.users) && v.rank(i, mode) <= toRank; i++ {
+		entries = append(entries, v.withDeltas(v.entry(i, mode), i, mode, base))
 	}
 
-	if lb.prefixIndexDirty {
-		lb.mu.RUnlock()
-		lb.mu.Lock()
-		lb.rebuildPrefixIndex()
-		lb.mu.Unlock()
-		lb.mu.RLock()
+	return entries
+}
+
+// SearchUsers searches for users by username or display name using the prefix indexes
+// (case-insensitive). Results may be shared with the search cache and must be treated
+// as read-only.
+func (lb *Leaderboard) SearchUsers(query string, limit int) []models.SearchResult {
+	v := lb.current()
+	mode := lb.rankingMode(v)
+
+	if cached, found := lb.searchCache.get(query, mode, limit, v.version); found {
+		return cached
 	}
 
 	query = strings.ToLower(query)
 	results := make([]models.SearchResult, 0)
 
-	// Use prefix index for fast lookup
+	// Use the prefix indexes for fast lookup
 	matchingUsernames := make([]string, 0)
+	seenMap := make(map[string]bool)
+	collect := func(usernames []string) {
+		for _, u := range usernames {
+			if !seenMap[u] {
+				matchingUsernames = append(matchingUsernames, u)
+				seenMap[u] = true
+			}
+		}
+	}
 	if len(query) > 0 {
-		if prefixMatches, exists := lb.prefixIndex[query]; exists {
-			matchingUsernames = prefixMatches
-		} else {
+		for _, index := range []map[string][]string{v.prefixIndex, v.displayIndex} {
+			if prefixMatches, exists := index[query]; exists {
+				collect(prefixMatches)
+				continue
+			}
 			// Fall back to substring search
-			seenMap := make(map[string]bool)
-			for prefix, usernames := range lb.prefixIndex {
+			for prefix, usernames := range index {
 				if strings.Contains(prefix, query) {
-					for _, u := range usernames {
-						if !seenMap[u] {
-							matchingUsernames = append(matchingUsernames, u)
-							seenMap[u] = true
-						}
-					}
+					collect(usernames)
 				}
 			}
 		}
 	}
 
-	// Sort matching usernames by rating (descending)
-	sort.Slice(matchingUsernames, func(i, j int) bool {
-		userI := lb.usersByUsername[matchingUsernames[i]]
-		userJ := lb.usersByUsername[matchingUsernames[j]]
-		return userI.Rating > userJ.Rating
-	})
+	// Order matches by position in the view (best rank first)
+	positions := make([]int, 0, len(matchingUsernames))
+	for _, username := range matchingUsernames {
+		positions = append(positions, v.index[username])
+	}
+	sort.Ints(positions)
 
 	// Build results up to limit
-	for _, username := range matchingUsernames {
+	for _, i := range positions {
 		if len(results) >= limit {
 			break
 		}
-		user := lb.usersByUsername[username]
-		results = append(results, models.SearchResult{
-			GlobalRank: lb.rankCache[user.Rating],
-			Username:   user.Username,
-			Rating:     user.Rating,
-		})
+		results = append(results, v.result(i, mode))
 	}
 
+	lb.searchCache.put(query, mode, limit, v.version, results)
 	return results
 }
 
+// SuggestUsernames returns up to limit usernames starting with prefix (case-insensitive),
+// in alphabetical order. It only consults the prefix index and never touches ranks.
+func (lb *Leaderboard) SuggestUsernames(prefix string, limit int) []string {
+	matches := lb.current().prefixIndex[strings.ToLower(prefix)]
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	suggestions := make([]string, len(matches))
+	copy(suggestions, matches)
+	return suggestions
+}
+
 // GetUserRank gets a specific user's rank by username
 func (lb *Leaderboard) GetUserRank(username string) (*models.SearchResult, bool) {
-	lb.mu.RLock()
-	defer lb.mu.RUnlock()
-
-	if lb.rankCacheDirty {
-		lb.mu.RUnlock()
-		lb.mu.Lock()
-		lb.rebuildRankCache()
-		lb.mu.Unlock()
-		lb.mu.RLock()
-	}
+	v := lb.current()
 
-	user, exists := lb.usersByUsername[username]
+	i, exists := v.index[username]
 	if !exists {
 		return nil, false
 	}
 
-	return &models.SearchResult{
-		GlobalRank: lb.rankCache[user.Rating],
-		Username:   user.Username,
-		Rating:     user.Rating,
-	}, true
+	result := v.result(i, lb.rankingMode(v))
+	if state, inSeries := v.series[username]; inSeries {
+		result.Series = &state
+	}
+
+	return &result, true
 }
 
-// UpdateRating updates a user's rating
-func (lb *Leaderboard) UpdateRating(username string, newRating int) bool {
-	lb.mu.Lock()
-	defer lb.mu.Unlock()
+// LookupUser is GetUserRank ignoring case, the way search matches usernames: an exact
+// match wins, otherwise the one user whose username differs only in case. The result
+// carries the username as stored. Usernames that differ only in case from each other
+// but not exactly from username are ambiguous and not found.
+func (lb *Leaderboard) LookupUser(username string) (*models.SearchResult, bool) {
+	if result, found := lb.GetUserRank(username); found {
+		return result, true
+	}
+	v := lb.current()
+	if matches := v.folded[strings.ToLower(username)]; len(matches) == 1 {
+		i := v.index[matches[0]]
+		result := v.result(i, lb.rankingMode(v))
+		if state, inSeries := v.series[matches[0]]; inSeries {
+			result.Series = &state
+		}
+		return &result, true
+	}
+	return nil, false
+}
 
-	user, exists := lb.usersByUsername[username]
+// UpdateRating updates a user's rating. Only the user's shard is locked, so updates
+// for users in different shards don't wait on each other.
+func (lb *Leaderboard) UpdateRating(username string, newRating int64) bool {
+	shard := lb.shardFor(username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
 	if !exists {
 		return false
 	}
 
+	lb.setRatingLocked(shard, user, newRating, time.Now())
+	return true
+}
+
+// setRatingLocked moves a user to newRating (subject to series rules) as of at;
+// caller must hold the user's shard lock
+func (lb *Leaderboard) setRatingLocked(shard *userShard, user *models.User, newRating int64, at time.Time) {
+	if at.After(user.LastActiveAt) {
+		user.LastActiveAt = at
+	}
+	lb.moveRatingLocked(shard, user, lb.applySeries(shard, user.Username, user.Rating, newRating), at)
+}
+
+// moveRatingLocked stores newRating as of at without applying series rules or
+// counting as activity; caller must hold the user's shard lock
+func (lb *Leaderboard) moveRatingLocked(shard *userShard, user *models.User, newRating int64, at time.Time) {
 	oldRating := user.Rating
+	if newRating != oldRating {
+		user.RatingUpdatedAt = at
+	}
+	if newRating > user.PeakRating {
+		user.PeakRating = newRating
+		user.PeakRatingAt = at
+	}
+	if newRating < user.LowestRating {
+		user.LowestRating = newRating
+	}
+	shard.recordHistory(user.Username, oldRating, newRating, at)
+	user.Rating = newRating
+	policy, rankBefore := lb.rankBeforeMilestone(oldRating, newRating)
+	observed := lb.observed() && newRating != oldRating
+	pursuing := user.Goal != nil && user.Goal.AchievedAt == nil && newRating != oldRating
+	if (observed || pursuing) && policy == nil {
+		rankBefore = lb.CompetitionRank(oldRating)
+	}
+	lb.ratings.move(oldRating, newRating)
+	if newRating != oldRating {
+		lb.top.update(user)
+	}
+	if policy != nil {
+		lb.recordMilestones(policy, user.Username, oldRating, newRating, rankBefore, at)
+	}
+	if pursuing {
+		lb.checkGoalLocked(user, oldRating, rankBefore, at)
+	}
+	if observed {
+		lb.notifyChange(models.ChangeUpdated, user.Username, oldRating, newRating, rankBefore, lb.CompetitionRank(newRating), at)
+	}
+	lb.recordDelta(user.Username, newRating-oldRating, at)
 
-	// Remove from old rating group
-	users := lb.ratingToUsers[oldRating]
-	for i, u := range users {
-		if u == username {
-			lb.ratingToUsers[oldRating] = append(users[:i], users[i+1:]...)
-			break
+	lb.version.Add(1)
+}
+
+// ResetRatings applies reset to every user's rating in one step and returns the
+// full standings as they were immediately before the reset. On boards that break
+// ties by who got there first it also returns when each user reached their rating.
+func (lb *Leaderboard) ResetRatings(reset func(int64) int64) ([]models.LeaderboardEntry, map[string]time.Time) {
+	unlock := lb.lockWrite()
+	defer unlock()
+	unlockShards := lb.lockAllShards()
+	defer unlockShards()
+
+	v := lb.copyAllLocked()
+	v.build(lb.published.Load())
+
+	mode := lb.rankingMode(v)
+	standings := make([]models.LeaderboardEntry, 0, len(v.users))
+	var achieved map[string]time.Time
+	if v.meta.TieBreak == models.TieBreakAchieved {
+		achieved = make(map[string]time.Time, len(v.users))
+	}
+	for i := range v.users {
+		standings = append(standings, v.entry(i, mode))
+		if achieved != nil {
+			achieved[v.users[i].Username] = v.users[i].RatingUpdatedAt
 		}
 	}
-	if len(lb.ratingToUsers[oldRating]) == 0 {
-		delete(lb.ratingToUsers, oldRating)
+
+	now := time.Now()
+	ratings := make([]int64, 0, len(lb.users))
+	changed := make([]models.ChangeEvent, 0)
+	observed := lb.observed()
+	for _, user := range lb.users {
+		if rating := reset(user.Rating); rating != user.Rating {
+			lb.shardFor(user.Username).recordHistory(user.Username, user.Rating, rating, now)
+			if observed {
+				changed = append(changed, models.ChangeEvent{
+					Username:   user.Username,
+					OldRating:  user.Rating,
+					NewRating:  rating,
+					RankBefore: v.above[v.index[user.Username]] + 1,
+				})
+			}
+			user.Rating = rating
+			user.RatingUpdatedAt = now
+		}
+		ratings = append(ratings, user.Rating)
+	}
+	lb.ratings.reset(ratings)
+	lb.top.invalidate()
+	for _, change := range changed {
+		lb.notifyChange(models.ChangeUpdated, change.Username, change.OldRating, change.NewRating,
+			change.RankBefore, lb.CompetitionRank(change.NewRating), now)
+	}
+	for _, shard := range lb.shards {
+		shard.series = make(map[string]*models.SeriesState)
 	}
 
-	// Update user rating
-	user.Rating = newRating
+	lb.version.Add(1)
+	return standings, achieved
+}
 
-	// Add to new rating group
-	lb.ratingToUsers[newRating] = append(lb.ratingToUsers[newRating], username)
+// RemoveUser deletes a user from the leaderboard
+func (lb *Leaderboard) RemoveUser(username string) bool {
+	unlock := lb.lockWrite()
+	defer unlock()
 
-	lb.rankCacheDirty = true
+	shard := lb.shardFor(username)
+	unlockShard := lb.lockShard(shard)
+	user, exists := shard.users[username]
+	if !exists {
+		unlockShard()
+		return false
+	}
+	lb.dropLocked(shard, user)
+	unlockShard()
+
+	for i, u := range lb.users {
+		if u.Username == username {
+			lb.users = append(lb.users[:i], lb.users[i+1:]...)
+			break
+		}
+	}
+
+	lb.members++
+	lb.version.Add(1)
 	return true
 }
 
-// GetRandomUser returns a random user for score updates
+// dropLocked removes user from its shard and the rating tree; caller must hold the
+// write lock and the shard lock, and remove the user from lb.users
+func (lb *Leaderboard) dropLocked(shard *userShard, user *models.User) {
+	if lb.observed() {
+		lb.notifyChange(models.ChangeRemoved, user.Username, user.Rating, 0, lb.CompetitionRank(user.Rating), 0, time.Now())
+	}
+	lb.ratings.add(user.Rating, -1)
+	lb.top.remove(user)
+	lb.renameDisplay(user.DisplayName, "")
+	lb.releaseExternalID(user.ExternalID, user.Username)
+	delete(shard.users, user.Username)
+	delete(shard.series, user.Username)
+	delete(shard.history, user.Username)
+	delete(shard.submitted, user.Username)
+}
+
+// CompetitionRank returns the standard competition ("1224") rank a user with the given
+// rating holds, or would hold: the number of users rated strictly higher, plus one.
+// It reads the live rating tree, so it costs O(log n) even while the view is stale.
+func (lb *Leaderboard) CompetitionRank(rating int64) int {
+	return lb.ratings.countAbove(rating) + 1
+}
+
+// RatingAtRank returns the rating of the user in position rank (from 1), the lowest a
+// rating can be and still hold that competition rank; false past the last user. It
+// reads the live rating tree like CompetitionRank.
+func (lb *Leaderboard) RatingAtRank(rank int) (int64, bool) {
+	return lb.ratings.nthHighest(rank)
+}
+
+// GetRandomUser returns a copy of a random user for score updates
 func (lb *Leaderboard) GetRandomUser(index int) *models.User {
-	lb.mu.RLock()
-	defer lb.mu.RUnlock()
+	unlock := lb.lockRead()
+	defer unlock()
 
-	if len(lb.sortedUsers) == 0 {
+	if len(lb.users) == 0 {
 		return nil
 	}
 
-	return lb.sortedUsers[index%len(lb.sortedUsers)]
+	user := lb.users[index%len(lb.users)]
+	unlockShard := lb.rlockShard(lb.shardFor(user.Username))
+	defer unlockShard()
+
+	copied := *user
+	copied.Scores = copyScores(user.Scores)
+	copied.Metrics = copyMetrics(user.Metrics)
+	return &copied
 }
 
 // GetTotalUsers returns total number of users
 func (lb *Leaderboard) GetTotalUsers() int {
-	lb.mu.RLock()
-	defer lb.mu.RUnlock()
-	return len(lb.sortedUsers)
+	unlock := lb.lockRead()
+	defer unlock()
+	return len(lb.users)
 }
 
 // GetStats returns leaderboard statistics
 func (lb *Leaderboard) GetStats() models.StatsResponse {
-	lb.mu.RLock()
-	defer lb.mu.RUnlock()
+	v := lb.current()
 
 	stats := models.StatsResponse{
-		TotalUsers: len(lb.sortedUsers),
-		MinRating:  5000,
-		MaxRating:  100,
+		TotalUsers: len(v.users),
+		MinRating:  v.minRating,
+		MaxRating:  v.maxRating,
 	}
-
-	for rating := range lb.ratingToUsers {
-		if rating < stats.MinRating {
-			stats.MinRating = rating
-		}
-		if rating > stats.MaxRating {
-			stats.MaxRating = rating
-		}
+	if lb.served() == nil {
+		// The live rating tree keeps the distribution up to date
+		lb.ratings.describe(&stats)
+	} else {
+		// Frozen or background-published standings trail the tree, so describe them
+		// from the view's own ratings
+		v.describe(&stats)
 	}
-
-	if len(lb.sortedUsers) == 0 {
-		stats.MinRating = 0
-		stats.MaxRating = 0
-	}
-
 	return stats
 }
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"leaderboard-api/models"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// lockBuckets are the upper bounds of the lock timing histograms
+var lockBuckets = []time.Duration{
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// lockHistogram accumulates wait and hold times for one kind of lock acquisition
+type lockHistogram struct {
+	mu       sync.Mutex
+	count    uint64
+	waitSum  time.Duration
+	waitMax  time.Duration
+	holdSum  time.Duration
+	holdMax  time.Duration
+	waitHist [7]uint64 // one bucket per lockBuckets entry, plus overflow
+	holdHist [7]uint64
+}
+
+// observe records one acquisition
+func (h *lockHistogram) observe(wait, hold time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count++
+	h.waitSum += wait
+	h.holdSum += hold
+	if wait > h.waitMax {
+		h.waitMax = wait
+	}
+	if hold > h.holdMax {
+		h.holdMax = hold
+	}
+	h.waitHist[bucketOf(wait)]++
+	h.holdHist[bucketOf(hold)]++
+}
+
+// stats summarizes the histogram
+func (h *lockHistogram) stats() models.LockStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := models.LockStats{
+		Acquisitions: h.count,
+		WaitMaxMs:    millis(h.waitMax),
+		WaitP99Ms:    millis(quantile(h.waitHist[:], h.count, 0.99, h.waitMax)),
+		HoldMaxMs:    millis(h.holdMax),
+		HoldP99Ms:    millis(quantile(h.holdHist[:], h.count, 0.99, h.holdMax)),
+	}
+	if h.count > 0 {
+		stats.WaitAvgMs = millis(h.waitSum / time.Duration(h.count))
+		stats.HoldAvgMs = millis(h.holdSum / time.Duration(h.count))
+	}
+	return stats
+}
+
+// bucketOf returns the histogram bucket for d
+func bucketOf(d time.Duration) int {
+	for i, bound := range lockBuckets {
+		if d <= bound {
+			return i
+		}
+	}
+	return len(lockBuckets)
+}
+
+// quantile returns the upper bound of the bucket holding quantile q; the overflow
+// bucket reports the observed maximum
+func quantile(hist []uint64, count uint64, q float64, max time.Duration) time.Duration {
+	if count == 0 {
+		return 0
+	}
+	target := uint64(float64(count)*q + 0.5)
+	var seen uint64
+	for i, n := range hist {
+		seen += n
+		if seen >= target && i < len(lockBuckets) {
+			if lockBuckets[i] > max {
+				return max
+			}
+			return lockBuckets[i]
+		}
+	}
+	return max
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// lockStats holds the histograms for every lock the store takes
+type lockStats struct {
+	read       lockHistogram // shared lock on metadata and membership
+	write      lockHistogram // exclusive lock on metadata and membership
+	shardRead  lockHistogram // shared lock on a user shard
+	shardWrite lockHistogram // exclusive lock on a user shard
+	publish    lockHistogram // serializes rebuilding the published view
+}
+
+// LockTrace accumulates the store lock timings of a single request and is safe for
+// concurrent use. Rebuilding a view takes the read lock inside the publish lock, so
+// their hold times overlap.
+type LockTrace struct {
+	acquisitions atomic.Int64
+	wait         atomic.Int64
+	hold         atomic.Int64
+}
+
+// NewLockTrace creates an empty trace
+func NewLockTrace() *LockTrace {
+	return &LockTrace{}
+}
+
+// Totals returns the number of lock acquisitions and the total wait and hold times
+func (t *LockTrace) Totals() (int64, time.Duration, time.Duration) {
+	return t.acquisitions.Load(), time.Duration(t.wait.Load()), time.Duration(t.hold.Load())
+}
+
+func (t *LockTrace) record(wait, hold time.Duration) {
+	t.acquisitions.Add(1)
+	t.wait.Add(int64(wait))
+	t.hold.Add(int64(hold))
+}
+
+type lockTraceKey struct{}
+
+// ContextWithLockTrace returns a context carrying t
+func ContextWithLockTrace(ctx context.Context, t *LockTrace) context.Context {
+	return context.WithValue(ctx, lockTraceKey{}, t)
+}
+
+// LockTraceFrom returns the trace carried by ctx, or nil
+func LockTraceFrom(ctx context.Context) *LockTrace {
+	t, _ := ctx.Value(lockTraceKey{}).(*LockTrace)
+	return t
+}
+
+// WithLockTrace returns a handle onto the same board whose lock timings are also
+// recorded into t
+func (lb *Leaderboard) WithLockTrace(t *LockTrace) Store {
+	handle := *lb
+	handle.trace = t
+	return &handle
+}
+
+// LockStats returns wait/hold summaries for each of the store's locks
+func (lb *Leaderboard) LockStats() map[string]models.LockStats {
+	return map[string]models.LockStats{
+		"read":       lb.locks.read.stats(),
+		"write":      lb.locks.write.stats(),
+		"shardRead":  lb.locks.shardRead.stats(),
+		"shardWrite": lb.locks.shardWrite.stats(),
+		"publish":    lb.locks.publish.stats(),
+	}
+}
+
+// lockRead takes the shared metadata/membership lock and returns the function that releases it
+func (lb *Leaderboard) lockRead() func() {
+	return lb.timedLock(&lb.locks.read, lb.mu.RLock, lb.mu.RUnlock)
+}
+
+// lockWrite takes the exclusive metadata/membership lock and returns the function that releases it
+func (lb *Leaderboard) lockWrite() func() {
+	return lb.timedLock(&lb.locks.write, lb.mu.Lock, lb.mu.Unlock)
+}
+
+// lockPublish takes the view publishing lock and returns the function that releases it
+func (lb *Leaderboard) lockPublish() func() {
+	return lb.timedLock(&lb.locks.publish, lb.publishMu.Lock, lb.publishMu.Unlock)
+}
+
+// timedLock calls lock, recording how long it waited, and returns a release function
+// that records how long the lock was held
+func (lb *Leaderboard) timedLock(h *lockHistogram, lock, unlock func()) func() {
+	start := time.Now()
+	lock()
+	acquired := time.Now()
+	return func() {
+		hold := time.Since(acquired)
+		unlock()
+		lb.observe(h, acquired.Sub(start), hold)
+	}
+}
+
+// observe records a lock acquisition in the store histogram and the request trace
+func (lb *Leaderboard) observe(h *lockHistogram, wait, hold time.Duration) {
+	h.observe(wait, hold)
+	if lb.trace != nil {
+		lb.trace.record(wait, hold)
+	}
+}
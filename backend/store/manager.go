@@ -0,0 +1,111 @@
+package store
+
+import (
+	"errors"
+	"leaderboard-api/models"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrBoardExists      = errors.New("leaderboard already exists")
+	ErrBoardNotFound    = errors.New("leaderboard not found")
+	ErrInvalidBoardName = errors.New("leaderboard name must be 1-64 characters of a-z, 0-9, '-' or '_'")
+	ErrDefaultBoard     = errors.New("the default leaderboard cannot be deleted")
+)
+
+// boardNamePattern restricts board names to URL-safe identifiers
+var boardNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+// Manager hosts many independent leaderboards keyed by name
+type Manager struct {
+	mu sync.RWMutex
+
+	// Boards by name
+	boards map[string]Store
+
+	// Name of the board served by the legacy single-board routes
+	defaultName string
+}
+
+// NewManager creates a registry whose default board is lb
+func NewManager(lb Store) *Manager {
+	name := lb.Metadata().Name
+	return &Manager{
+		boards:      map[string]Store{name: lb},
+		defaultName: name,
+	}
+}
+
+// Create registers a new empty in-memory board described by meta
+func (m *Manager) Create(meta models.BoardMetadata) (Store, error) {
+	if !boardNamePattern.MatchString(meta.Name) {
+		return nil, ErrInvalidBoardName
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.boards[meta.Name]; exists {
+		return nil, ErrBoardExists
+	}
+
+	lb := NewLeaderboard()
+	lb.SetMetadata(meta)
+	m.boards[meta.Name] = lb
+	return lb, nil
+}
+
+// Get returns the board with the given name
+func (m *Manager) Get(name string) (Store, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	lb, exists := m.boards[name]
+	return lb, exists
+}
+
+// Default returns the board served by the legacy single-board routes
+func (m *Manager) Default() Store {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.boards[m.defaultName]
+}
+
+// Delete removes a board; the default board cannot be removed
+func (m *Manager) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == m.defaultName {
+		return ErrDefaultBoard
+	}
+	if _, exists := m.boards[name]; !exists {
+		return ErrBoardNotFound
+	}
+	delete(m.boards, name)
+	return nil
+}
+
+// List returns a summary of every board, sorted by name
+func (m *Manager) List() []models.BoardSummary {
+	m.mu.RLock()
+	boards := make([]Store, 0, len(m.boards))
+	for _, lb := range m.boards {
+		boards = append(boards, lb)
+	}
+	m.mu.RUnlock()
+
+	summaries := make([]models.BoardSummary, 0, len(boards))
+	for _, lb := range boards {
+		summaries = append(summaries, models.BoardSummary{
+			BoardMetadata: lb.Metadata(),
+			TotalUsers:    lb.GetTotalUsers(),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
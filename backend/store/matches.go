@@ -0,0 +1,142 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"leaderboard-api/models"
+	"os"
+	"sync"
+	"time"
+)
+
+// MatchStore is an append-only log of submitted matches, optionally backed by a
+// JSON-lines file that is replayed on open
+type MatchStore struct {
+	mu sync.RWMutex
+
+	// All matches in submission order; IDs are assigned sequentially
+	matches []models.Match
+
+	// Indexes into matches for each participating user
+	byUser map[string][]int
+
+	// Optional append-only file sink
+	file *os.File
+}
+
+// NewMatchStore creates an in-memory match store
+func NewMatchStore() *MatchStore {
+	return &MatchStore{
+		matches: make([]models.Match, 0),
+		byUser:  make(map[string][]int),
+	}
+}
+
+// OpenMatchStore creates a match store backed by the file at path, replaying any
+// matches already recorded there
+func OpenMatchStore(path string) (*MatchStore, error) {
+	ms := NewMatchStore()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var match models.Match
+		if err := json.Unmarshal(scanner.Bytes(), &match); err != nil {
+			file.Close()
+			return nil, err
+		}
+		ms.index(match)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	ms.file = file
+	return ms, nil
+}
+
+// Append records a match, assigning its ID and timestamp if unset
+func (ms *MatchStore) Append(match models.Match) (models.Match, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	match.ID = int64(len(ms.matches) + 1)
+	if match.PlayedAt.IsZero() {
+		match.PlayedAt = time.Now()
+	}
+
+	if ms.file != nil {
+		data, err := json.Marshal(match)
+		if err != nil {
+			return models.Match{}, err
+		}
+		if _, err := ms.file.Write(append(data, '\n')); err != nil {
+			return models.Match{}, err
+		}
+	}
+
+	ms.index(match)
+	return match, nil
+}
+
+// List returns matches newest first, optionally filtered to a single user.
+// Pass beforeID = 0 to start from the newest match; the returned cursor is the ID to
+// pass next, or 0 when there are no more matches.
+func (ms *MatchStore) List(username string, beforeID int64, limit int) ([]models.Match, int64) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	results := make([]models.Match, 0, limit)
+
+	if username == "" {
+		start := len(ms.matches) - 1
+		if beforeID > 0 && beforeID-2 < int64(start) {
+			start = int(beforeID - 2)
+		}
+		for i := start; i >= 0 && len(results) < limit; i-- {
+			results = append(results, ms.matches[i])
+		}
+	} else {
+		indexes := ms.byUser[username]
+		for i := len(indexes) - 1; i >= 0 && len(results) < limit; i-- {
+			match := ms.matches[indexes[i]]
+			if beforeID > 0 && match.ID >= beforeID {
+				continue
+			}
+			results = append(results, match)
+		}
+	}
+
+	if len(results) < limit || len(results) == 0 || results[len(results)-1].ID == 1 {
+		return results, 0
+	}
+	return results, results[len(results)-1].ID
+}
+
+// Close releases the backing file, if any
+func (ms *MatchStore) Close() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.file == nil {
+		return nil
+	}
+	err := ms.file.Close()
+	ms.file = nil
+	return err
+}
+
+// index adds a match to the in-memory log; caller must hold ms.mu
+func (ms *MatchStore) index(match models.Match) {
+	idx := len(ms.matches)
+	ms.matches = append(ms.matches, match)
+	for _, player := range match.Players {
+		ms.byUser[player.Username] = append(ms.byUser[player.Username], idx)
+	}
+}
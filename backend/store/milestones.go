@@ -0,0 +1,145 @@
+package store
+
+import (
+	"fmt"
+	"leaderboard-api/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxMilestoneEvents bounds the number of milestone events kept for streaming
+const maxMilestoneEvents = 1000
+
+// MilestonePolicy says which crossings are milestones. Taking #1 always is.
+type MilestonePolicy struct {
+	// Entering the top N for each N, e.g. 10, 100 and 1000. A jump across several
+	// reports only the tightest one.
+	RankThresholds []int
+
+	// Reaching each multiple of RatingStep, in rating units; 0 disables
+	RatingStep int64
+}
+
+// DefaultRankThresholds are the rank milestones used unless configured otherwise
+var DefaultRankThresholds = []int{10, 100, 1000}
+
+// ParseRankThresholds parses a comma-separated list of positive ranks such as
+// "10,100,1000"
+func ParseRankThresholds(spec string) ([]int, error) {
+	thresholds := make([]int, 0)
+	for _, part := range strings.Split(spec, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("rank threshold %q is not a positive integer", part)
+		}
+		thresholds = append(thresholds, n)
+	}
+	return thresholds, nil
+}
+
+// EnableMilestones starts recording milestone events for rating increases. Each one
+// costs two rank lookups in the rating tree at write time.
+func (lb *Leaderboard) EnableMilestones(policy MilestonePolicy) {
+	thresholds := append([]int(nil), policy.RankThresholds...)
+	sort.Ints(thresholds)
+	policy.RankThresholds = thresholds
+	lb.milestones.Store(&policy)
+}
+
+// MilestoneEventsSince returns milestone events with a sequence number greater than
+// seq, along with the latest sequence number
+func (lb *Leaderboard) MilestoneEventsSince(seq uint64) ([]models.MilestoneEvent, uint64) {
+	lb.milestoneMu.Lock()
+	defer lb.milestoneMu.Unlock()
+
+	// Milestones crossed while the board is frozen would give the standings away
+	latest := lb.milestoneSeq
+	if state := lb.frozen.Load(); state != nil {
+		latest = state.milestoneSeq
+	}
+
+	events := make([]models.MilestoneEvent, 0)
+	for _, event := range lb.milestoneEvents {
+		if event.Seq > seq && event.Seq <= latest {
+			events = append(events, event)
+		}
+	}
+	return events, latest
+}
+
+// rankBeforeMilestone returns the user's rank before a rating change when the change
+// could cross a milestone, or 0; called before the rating tree is moved
+func (lb *Leaderboard) rankBeforeMilestone(oldRating, newRating int64) (*MilestonePolicy, int) {
+	policy := lb.milestones.Load()
+	if policy == nil || newRating <= oldRating {
+		return nil, 0
+	}
+	return policy, lb.ratings.countAbove(oldRating) + 1
+}
+
+// recordMilestones compares ranks and ratings around a rating increase and records
+// any milestone crossed; called after the rating tree is moved
+func (lb *Leaderboard) recordMilestones(policy *MilestonePolicy, username string, oldRating, newRating int64, rankBefore int, at time.Time) {
+	rankAfter := lb.ratings.countAbove(newRating) + 1
+	event := models.MilestoneEvent{
+		Username:   username,
+		RankBefore: rankBefore,
+		RankAfter:  rankAfter,
+		OldRating:  oldRating,
+		NewRating:  newRating,
+		Timestamp:  at,
+	}
+
+	events := make([]models.MilestoneEvent, 0, 2)
+	if rankAfter == 1 && rankBefore > 1 {
+		event.Type, event.Threshold = models.MilestoneFirstPlace, 1
+		events = append(events, event)
+	} else {
+		for _, n := range policy.RankThresholds {
+			if rankAfter <= n && rankBefore > n {
+				event.Type, event.Threshold = models.MilestoneTopN, int64(n)
+				events = append(events, event)
+				break
+			}
+		}
+	}
+	if step := policy.RatingStep; step > 0 {
+		if reached := floorDiv(newRating, step); reached > floorDiv(oldRating, step) {
+			event.Type, event.Threshold = models.MilestoneRatingReached, reached*step
+			events = append(events, event)
+		}
+	}
+	lb.appendMilestones(events...)
+}
+
+// appendMilestones adds events to the log, numbering them in order
+func (lb *Leaderboard) appendMilestones(events ...models.MilestoneEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	lb.milestoneMu.Lock()
+	defer lb.milestoneMu.Unlock()
+	for _, event := range events {
+		lb.milestoneSeq++
+		event.Seq = lb.milestoneSeq
+		lb.milestoneEvents = append(lb.milestoneEvents, event)
+	}
+	if len(lb.milestoneEvents) > maxMilestoneEvents {
+		lb.milestoneEvents = lb.milestoneEvents[len(lb.milestoneEvents)-maxMilestoneEvents:]
+	}
+}
+
+// floorDiv divides rounding toward negative infinity
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
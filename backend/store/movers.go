@@ -0,0 +1,121 @@
+package store
+
+import (
+	"fmt"
+	"leaderboard-api/models"
+	"sort"
+	"time"
+)
+
+// Movers over windows up to moverRetention are measured from minute buckets; longer
+// ones fall back to the hourly delta log
+const (
+	moverBucketSpan = time.Minute
+	moverRetention  = time.Hour
+	maxMoverWindow  = 7 * 24 * time.Hour
+)
+
+// moverBucketFor returns the minute bucket covering at, creating it in order and
+// dropping buckets past moverRetention. Caller must hold deltaMu.
+func (lb *Leaderboard) moverBucketFor(at, now time.Time) *deltaBucket {
+	start := at.Truncate(moverBucketSpan)
+	i := len(lb.moverBuckets)
+	for i > 0 && lb.moverBuckets[i-1].start.After(start) {
+		i--
+	}
+	if i > 0 && lb.moverBuckets[i-1].start.Equal(start) {
+		return lb.moverBuckets[i-1]
+	}
+
+	bucket := &deltaBucket{start: start, span: moverBucketSpan, gains: make(map[string]int64)}
+	lb.moverBuckets = append(lb.moverBuckets, nil)
+	copy(lb.moverBuckets[i+1:], lb.moverBuckets[i:])
+	lb.moverBuckets[i] = bucket
+
+	expired := 0
+	for expired < len(lb.moverBuckets) && now.Sub(lb.moverBuckets[expired].start) > moverRetention+moverBucketSpan {
+		expired++
+	}
+	lb.moverBuckets = lb.moverBuckets[expired:]
+	return bucket
+}
+
+// ParseMoverWindow parses a movers window: a duration such as "15m" or "2h", or one of
+// the named Windows, up to a week
+func ParseMoverWindow(window string) (time.Duration, error) {
+	if span, ok := Windows[window]; ok && span <= maxMoverWindow {
+		return span, nil
+	}
+	span, err := time.ParseDuration(window)
+	if err != nil || span <= 0 || span > maxMoverWindow {
+		return 0, fmt.Errorf("window must be a duration such as 15m, up to %v", maxMoverWindow)
+	}
+	return span, nil
+}
+
+// Movers returns up to limit of the users who gained the most rating within the
+// trailing window, and up to limit of those who lost the most. Gainers are ranked by
+// gain and losers by loss, ties sharing a dense rank; losers carry negative gains.
+// Windows up to an hour are exact to the minute at their far edge, longer ones to
+// the hour.
+func (lb *Leaderboard) Movers(window time.Duration, limit int) (gainers, losers []models.WindowEntry) {
+	now := time.Now()
+	cutoff := now.Add(-window)
+
+	var gains map[string]int64
+	if window <= moverRetention {
+		gains = make(map[string]int64)
+		lb.deltaMu.Lock()
+		for _, bucket := range lb.moverBuckets {
+			if bucket.start.Add(bucket.span).Before(cutoff) {
+				continue
+			}
+			for username, gain := range bucket.gains {
+				gains[username] += gain
+			}
+		}
+		lb.deltaMu.Unlock()
+	} else {
+		gains = lb.gainsSince(cutoff)
+	}
+
+	gainers, losers = []models.WindowEntry{}, []models.WindowEntry{}
+	for username, gain := range gains {
+		if gain == 0 {
+			continue
+		}
+		rating, exists := lb.ratingOf(username)
+		if !exists {
+			continue
+		}
+		entry := models.WindowEntry{Username: username, Rating: rating, Gain: gain}
+		if gain > 0 {
+			gainers = append(gainers, entry)
+		} else {
+			losers = append(losers, entry)
+		}
+	}
+	return rankMovers(gainers, 1, limit), rankMovers(losers, -1, limit)
+}
+
+// rankMovers orders entries by gain times sign, largest first, ranks them densely and
+// keeps the first limit
+func rankMovers(entries []models.WindowEntry, sign int64, limit int) []models.WindowEntry {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Gain != entries[j].Gain {
+			return entries[i].Gain*sign > entries[j].Gain*sign
+		}
+		return entries[i].Username < entries[j].Username
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	rank := 0
+	for i := range entries {
+		if i == 0 || entries[i].Gain != entries[i-1].Gain {
+			rank++
+		}
+		entries[i].Rank = rank
+	}
+	return entries
+}
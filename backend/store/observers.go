@@ -0,0 +1,124 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"time"
+)
+
+// observerBuffer is how many changes an observer may fall behind by before further
+// changes are dropped for it
+const observerBuffer = 4096
+
+// observer delivers changes to one subscriber on its own goroutine
+type observer struct {
+	fn     func(models.ChangeEvent)
+	events chan models.ChangeEvent
+	stop   chan struct{}
+}
+
+// Subscribe calls fn for every user added, removed or whose rating changes on the
+// board, until the returned function is called. Changes are delivered in write order
+// on a goroutine of the subscriber's own, so fn may call back into the board; a
+// subscriber more than observerBuffer changes behind misses changes rather than
+// slowing writers down.
+func (lb *Leaderboard) Subscribe(fn func(models.ChangeEvent)) (unsubscribe func()) {
+	obs := &observer{
+		fn:     fn,
+		events: make(chan models.ChangeEvent, observerBuffer),
+		stop:   make(chan struct{}),
+	}
+	go obs.run()
+
+	lb.observersMu.Lock()
+	observers := append(lb.loadObservers(), obs)
+	lb.observers.Store(&observers)
+	lb.observersMu.Unlock()
+
+	return func() {
+		lb.observersMu.Lock()
+		defer lb.observersMu.Unlock()
+
+		current := lb.loadObservers()
+		remaining := make([]*observer, 0, len(current))
+		for _, o := range current {
+			if o != obs {
+				remaining = append(remaining, o)
+			}
+		}
+		if len(remaining) == len(current) {
+			return
+		}
+		lb.observers.Store(&remaining)
+		close(obs.stop)
+	}
+}
+
+// run delivers queued changes until the observer is unsubscribed
+func (obs *observer) run() {
+	for {
+		select {
+		case event := <-obs.events:
+			obs.fn(event)
+		case <-obs.stop:
+			return
+		}
+	}
+}
+
+// WithSource returns a handle onto the same board whose changes are attributed to
+// source and actor. Changes through the shared handle come from the system.
+func (lb *Leaderboard) WithSource(source, actor string) Store {
+	handle := *lb
+	handle.source, handle.actor = source, actor
+	return &handle
+}
+
+// DroppedChanges returns how many changes were dropped for subscribers that fell behind
+func (lb *Leaderboard) DroppedChanges() uint64 {
+	return lb.droppedChanges.Load()
+}
+
+// loadObservers returns the current subscribers; the slice must not be modified
+func (lb *Leaderboard) loadObservers() []*observer {
+	if observers := lb.observers.Load(); observers != nil {
+		return *observers
+	}
+	return nil
+}
+
+// observed reports whether anyone is subscribed, so writers can skip computing ranks
+func (lb *Leaderboard) observed() bool {
+	return len(lb.loadObservers()) > 0
+}
+
+// notifyChange hands a change to every subscriber without blocking. Callers hold the
+// lock serializing writes to the user, which keeps each user's changes in order.
+func (lb *Leaderboard) notifyChange(kind, username string, oldRating, newRating int64, rankBefore, rankAfter int, at time.Time) {
+	observers := lb.loadObservers()
+	if len(observers) == 0 {
+		return
+	}
+
+	event := models.ChangeEvent{
+		Seq:        lb.changeSeq.Add(1),
+		Type:       kind,
+		Username:   username,
+		OldRating:  oldRating,
+		NewRating:  newRating,
+		RankBefore: rankBefore,
+		RankAfter:  rankAfter,
+		At:         at,
+		Source:     lb.source,
+		Actor:      lb.actor,
+	}
+	if event.Source == "" {
+		event.Source = models.ChangeSourceSystem
+	}
+	for _, obs := range observers {
+		select {
+		case obs.events <- event:
+		default:
+			lb.droppedChanges.Add(1)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"math"
+	"sync"
+)
+
+// ratingTree is an order-statistic tree over every user's rating: a treap keyed by
+// rating whose nodes also hold how many users share that rating and how many users
+// their subtree covers. Counting the users above a rating walks one root-to-leaf
+// path, so standard competition ranks are O(log n) without building a view.
+type ratingTree struct {
+	mu   sync.Mutex
+	root *ratingNode
+	seed uint64
+
+	// Running totals of every rating and its square, for the mean and spread. Ratings
+	// are whole numbers, so the float sums stay exact up to 2^53.
+	sum        float64
+	sumSquares float64
+}
+
+type ratingNode struct {
+	rating   int64
+	count    int // users holding exactly this rating
+	size     int // users in this subtree, including count
+	priority uint64
+
+	left, right *ratingNode
+}
+
+func newRatingTree() *ratingTree {
+	return &ratingTree{seed: 0x9e3779b97f4a7c15}
+}
+
+// add adjusts the number of users holding rating by delta
+func (t *ratingTree) add(rating int64, delta int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.addLocked(rating, delta)
+}
+
+// move records a user's rating changing from oldRating to newRating
+func (t *ratingTree) move(oldRating, newRating int64) {
+	if oldRating == newRating {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.addLocked(oldRating, -1)
+	t.addLocked(newRating, 1)
+}
+
+// reset replaces the tree's contents with ratings
+func (t *ratingTree) reset(ratings []int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.root = nil
+	t.sum, t.sumSquares = 0, 0
+	for _, rating := range ratings {
+		t.addLocked(rating, 1)
+	}
+}
+
+// countAbove returns the number of users with a rating strictly greater than rating
+func (t *ratingTree) countAbove(rating int64) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	above := 0
+	for n := t.root; n != nil; {
+		if rating < n.rating {
+			above += n.count + n.right.total()
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return above
+}
+
+// nthHighest returns the rating held by the nth best user (from 1), counting every user
+// sharing a rating; false if fewer than n users are rated
+func (t *ratingTree) nthHighest(n int) (int64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.nthHighestLocked(n)
+}
+
+func (t *ratingTree) nthHighestLocked(n int) (int64, bool) {
+	if n < 1 || n > t.root.total() {
+		return 0, false
+	}
+	for node := t.root; node != nil; {
+		above := node.right.total()
+		switch {
+		case n <= above:
+			node = node.right
+		case n <= above+node.count:
+			return node.rating, true
+		default:
+			n -= above + node.count
+			node = node.left
+		}
+	}
+	return 0, false
+}
+
+// describe fills in the rating distribution of stats from the tree, in O(log n)
+func (t *ratingTree) describe(stats *models.StatsResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := t.root.total()
+	if n == 0 {
+		return
+	}
+	// kth lowest rating, from 1
+	lowest := func(k int) int64 {
+		rating, _ := t.nthHighestLocked(n - k + 1)
+		return rating
+	}
+	describeRatings(stats, n, t.sum, t.sumSquares, lowest)
+}
+
+// describeRatings fills in the distribution of n ratings from their sum, the sum of
+// their squares and a way to find the kth lowest
+func describeRatings(stats *models.StatsResponse, n int, sum, sumSquares float64, lowest func(k int) int64) {
+	round := func(x float64) float64 { return math.Round(x*100) / 100 }
+	mean := sum / float64(n)
+	stats.Mean = round(mean)
+	stats.StdDev = round(math.Sqrt(max(sumSquares/float64(n)-mean*mean, 0)))
+
+	if n%2 == 1 {
+		stats.Median = float64(lowest(n/2 + 1))
+	} else {
+		stats.Median = (float64(lowest(n/2)) + float64(lowest(n/2+1))) / 2
+	}
+	percentile := func(p float64) int64 {
+		return lowest(max(int(math.Ceil(p/100*float64(n))), 1))
+	}
+	stats.P50, stats.P90, stats.P99 = percentile(50), percentile(90), percentile(99)
+}
+
+func (t *ratingTree) addLocked(rating int64, delta int) {
+	below, node := splitNodes(t.root, rating)
+	var above *ratingNode
+	if rating < math.MaxInt64 {
+		node, above = splitNodes(node, rating+1)
+	}
+	if node == nil {
+		node = &ratingNode{rating: rating, priority: t.nextPriority()}
+	}
+	// Counts never go negative, and the sums only change by what the count does
+	if node.count+delta < 0 {
+		delta = -node.count
+	}
+	t.sum += float64(rating) * float64(delta)
+	t.sumSquares += float64(rating) * float64(rating) * float64(delta)
+	node.count += delta
+	if node.count <= 0 {
+		node = nil
+	} else {
+		node.update()
+	}
+	t.root = mergeNodes(mergeNodes(below, node), above)
+}
+
+// nextPriority returns a pseudo-random heap priority (xorshift64)
+func (t *ratingTree) nextPriority() uint64 {
+	t.seed ^= t.seed << 13
+	t.seed ^= t.seed >> 7
+	t.seed ^= t.seed << 17
+	return t.seed
+}
+
+func (n *ratingNode) total() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *ratingNode) update() {
+	n.size = n.count + n.left.total() + n.right.total()
+}
+
+// splitNodes divides a subtree into nodes rated below rating and nodes rated at or above it
+func splitNodes(n *ratingNode, rating int64) (*ratingNode, *ratingNode) {
+	if n == nil {
+		return nil, nil
+	}
+	if n.rating < rating {
+		left, right := splitNodes(n.right, rating)
+		n.right = left
+		n.update()
+		return n, right
+	}
+	left, right := splitNodes(n.left, rating)
+	n.left = right
+	n.update()
+	return left, n
+}
+
+// mergeNodes joins two subtrees where every rating in a is below every rating in b
+func mergeNodes(a, b *ratingNode) *ratingNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		a.right = mergeNodes(a.right, b)
+		a.update()
+		return a
+	}
+	b.left = mergeNodes(a, b.left)
+	b.update()
+	return b
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"sync"
+	"time"
+)
+
+// podiumSize is how many places a podium shows
+const podiumSize = 3
+
+// ReignTracker follows who holds #1 on a board and since when. Reigns start at the
+// took_first milestone that began them; a change at the top no event explains, such
+// as the leader dropping or being removed, is noticed on the next poll and dated then.
+// Polling also keeps the board's records (see records.go) up to date.
+type ReignTracker struct {
+	leaderboard *Leaderboard
+
+	mu     sync.Mutex
+	seq    uint64 // last milestone event consumed
+	leader string
+	since  time.Time
+
+	stopChan chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewReignTracker creates a tracker for lb. Milestones must be enabled on lb for
+// reigns to be dated from the write that began them.
+func NewReignTracker(lb *Leaderboard) *ReignTracker {
+	t := &ReignTracker{
+		leaderboard: lb,
+		stopChan:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	_, t.seq = lb.MilestoneEventsSince(0)
+	return t
+}
+
+// Start polls for changes at the top every interval, so events are consumed before
+// they age out of the milestone log
+func (t *ReignTracker) Start(interval time.Duration) {
+	t.poll()
+	go func() {
+		defer close(t.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.poll()
+			case <-t.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts polling
+func (t *ReignTracker) Stop() {
+	t.once.Do(func() {
+		close(t.stopChan)
+		<-t.done
+	})
+}
+
+func (t *ReignTracker) poll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.updateLocked(t.leaderboard.current(), now)
+
+	entries, _, _ := t.leaderboard.GetWindowLeaderboard("daily", 1, 0)
+	if len(entries) > 0 && entries[0].Gain > 0 {
+		t.leaderboard.noteClimb(entries[0].Username, entries[0].Gain, now)
+	}
+}
+
+// updateLocked applies new milestone events, checks the leader against v and offers
+// the results as records; caller must hold t.mu
+func (t *ReignTracker) updateLocked(v *view, now time.Time) {
+	lb := t.leaderboard
+
+	var events []models.MilestoneEvent
+	events, t.seq = lb.MilestoneEventsSince(t.seq)
+	for _, event := range events {
+		lb.noteRating(event.Username, event.NewRating, event.Timestamp)
+		if event.Type == models.MilestoneFirstPlace && event.Username != t.leader {
+			t.crownLocked(event.Username, event.Timestamp)
+		}
+	}
+
+	if len(v.users) == 0 {
+		t.crownLocked("", now)
+		return
+	}
+	if top := v.usernameAt(0); top != t.leader {
+		t.crownLocked(top, now)
+	}
+	lb.noteReign(t.leader, t.since, now)
+
+	// Rating-ordered boards have their highest rating on top
+	if len(v.meta.SortKeys) == 0 {
+		lb.noteRating(v.users[0].Username, v.users[0].Rating, v.users[0].RatingUpdatedAt)
+	}
+}
+
+// crownLocked ends the current reign at and starts username's; caller must hold t.mu
+func (t *ReignTracker) crownLocked(username string, at time.Time) {
+	if t.leader != "" {
+		t.leaderboard.noteReign(t.leader, t.since, at)
+	}
+	t.leader, t.since = username, at
+	if username == "" {
+		t.since = time.Time{}
+	}
+}
+
+// Records returns the board's records, marking the longest reign ongoing when its
+// holder is still at #1
+func (t *ReignTracker) Records() models.Records {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.updateLocked(t.leaderboard.current(), time.Now())
+	records := t.leaderboard.Records()
+	if r := records.LongestReign; r != nil && r.Username == t.leader && r.Since.Equal(t.since) {
+		r.Ongoing = true
+	}
+	return records
+}
+
+// Podium returns the top three users and the leader's reign and lead over #2
+func (t *ReignTracker) Podium() models.Podium {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lb := t.leaderboard
+	v := lb.current()
+	now := time.Now()
+	t.updateLocked(v, now)
+
+	mode := lb.rankingMode(v)
+	podium := models.Podium{Places: make([]models.SearchResult, 0, podiumSize)}
+	for i := 0; i < podiumSize && i < len(v.users); i++ {
+		podium.Places = append(podium.Places, v.result(i, mode))
+	}
+
+	if t.leader != "" {
+		since := t.since
+		podium.LeaderSince = &since
+		podium.ReignSeconds = int64(now.Sub(since) / time.Second)
+	}
+	if len(v.users) > 1 {
+		podium.GapToSecond = v.ratingAt(0) - v.ratingAt(1)
+	}
+	podium.GapToSecondDisplay = FormatScore(v.meta.ScoreFormat, podium.GapToSecond)
+	return podium
+}
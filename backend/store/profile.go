@@ -0,0 +1,116 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidProfile wraps every reason a profile field is rejected
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// Profile fields are rendered on every leaderboard row, so they are kept short
+const (
+	maxDisplayNameLength = 64 // in characters
+	maxAvatarURLLength   = 2048
+)
+
+// NormalizeProfile validates a profile and returns it in canonical form: country
+// codes upper-cased and surrounding whitespace trimmed. Empty fields are allowed.
+func NormalizeProfile(p models.Profile) (models.Profile, error) {
+	var err error
+	if p.Country, err = normalizeCountry(p.Country); err != nil {
+		return models.Profile{}, err
+	}
+	if p.AvatarURL, err = normalizeAvatarURL(p.AvatarURL); err != nil {
+		return models.Profile{}, err
+	}
+	if p.DisplayName, err = normalizeDisplayName(p.DisplayName); err != nil {
+		return models.Profile{}, err
+	}
+	return p, nil
+}
+
+func normalizeCountry(country string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return "", nil
+	}
+	if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return "", fmt.Errorf("%w: country must be a two-letter ISO 3166-1 code, got %q", ErrInvalidProfile, country)
+	}
+	return country, nil
+}
+
+func normalizeAvatarURL(avatar string) (string, error) {
+	avatar = strings.TrimSpace(avatar)
+	if avatar == "" {
+		return "", nil
+	}
+	if len(avatar) > maxAvatarURLLength {
+		return "", fmt.Errorf("%w: avatarUrl is longer than %d bytes", ErrInvalidProfile, maxAvatarURLLength)
+	}
+	parsed, err := url.Parse(avatar)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("%w: avatarUrl must be an absolute http or https URL", ErrInvalidProfile)
+	}
+	return avatar, nil
+}
+
+func normalizeDisplayName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if !utf8.ValidString(name) {
+		return "", fmt.Errorf("%w: displayName is not valid UTF-8", ErrInvalidProfile)
+	}
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		return "", fmt.Errorf("%w: displayName is longer than %d characters", ErrInvalidProfile, maxDisplayNameLength)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", fmt.Errorf("%w: displayName contains control characters", ErrInvalidProfile)
+	}
+	return name, nil
+}
+
+// ApplyProfileUpdate returns p with the fields set in update replaced, normalized
+func ApplyProfileUpdate(p models.Profile, update models.ProfileUpdate) (models.Profile, error) {
+	if update.Country != nil {
+		p.Country = *update.Country
+	}
+	if update.AvatarURL != nil {
+		p.AvatarURL = *update.AvatarURL
+	}
+	if update.DisplayName != nil {
+		p.DisplayName = *update.DisplayName
+	}
+	return NormalizeProfile(p)
+}
+
+// UpdateProfile changes a user's country, avatar or display name. It reports false if
+// the user doesn't exist, and ErrDisplayNameTaken if the board's policy keeps the new
+// display name from being shared.
+func (lb *Leaderboard) UpdateProfile(username string, update models.ProfileUpdate) (bool, error) {
+	shard := lb.shardFor(username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
+	if !exists {
+		return false, nil
+	}
+
+	profile, err := ApplyProfileUpdate(user.Profile, update)
+	if err != nil {
+		return false, err
+	}
+	if profile.DisplayName != user.DisplayName && !lb.renameDisplay(user.DisplayName, profile.DisplayName) {
+		return false, ErrDisplayNameTaken
+	}
+	user.Profile = profile
+
+	lb.version.Add(1)
+	return true, nil
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"sync"
+	"time"
+)
+
+// Publisher rebuilds a board's view in the background, at most once per interval and
+// only when writes have moved the board since the last one, so no request pays for a
+// rebuild inline. Any number of writes between ticks cost one rebuild. While it runs,
+// readers are served the last published view, at most about one interval behind; live
+// handles still build the latest on demand.
+type Publisher struct {
+	leaderboard *Leaderboard
+
+	statsMu     sync.Mutex
+	stats       models.PublisherStats
+	lastBuiltAt time.Time
+
+	stopChan chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewPublisher creates a publisher for lb
+func NewPublisher(lb *Leaderboard) *Publisher {
+	return &Publisher{
+		leaderboard: lb,
+		stopChan:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start publishes a view now and then checks every interval. Readers stop building
+// views inline from here on.
+func (p *Publisher) Start(interval time.Duration) {
+	p.statsMu.Lock()
+	p.stats.IntervalMs = float64(interval) / float64(time.Millisecond)
+	p.statsMu.Unlock()
+
+	p.publish()
+	p.leaderboard.background.Store(true)
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.publish()
+			case <-p.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts background publishing; readers go back to building views on demand
+func (p *Publisher) Stop() {
+	p.once.Do(func() {
+		close(p.stopChan)
+		<-p.done
+		p.leaderboard.background.Store(false)
+	})
+}
+
+// Stats reports how often views are rebuilt and how far behind readers may be
+func (p *Publisher) Stats() models.PublisherStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	stats := p.stats
+	if v := p.leaderboard.published.Load(); v != nil {
+		stats.PendingWrites = p.leaderboard.version.Load() - v.version
+	}
+	if !p.lastBuiltAt.IsZero() {
+		stats.SinceLastBuildMs = float64(time.Since(p.lastBuiltAt)) / float64(time.Millisecond)
+	}
+	return stats
+}
+
+// publish rebuilds the view if the board has moved past it
+func (p *Publisher) publish() {
+	lb := p.leaderboard
+	if v := lb.published.Load(); v != nil && v.version >= lb.version.Load() {
+		p.statsMu.Lock()
+		p.stats.Skipped++
+		p.statsMu.Unlock()
+		return
+	}
+
+	start := time.Now()
+	lb.latest()
+	took := time.Since(start)
+
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	p.stats.Rebuilds++
+	p.stats.LastBuildMs = float64(took) / float64(time.Millisecond)
+	p.lastBuiltAt = start
+}
@@ -0,0 +1,329 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidQuery wraps every reason a query is rejected
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Queries are kept small so one request can't tie up the server
+const (
+	maxQueryFilters      = 16
+	maxQueryAggregations = 16
+	defaultBandWidth     = 500
+)
+
+// queryField reads one field of the user at position i of a view. Exactly one of
+// numeric and text is set.
+type queryField struct {
+	numeric func(v *view, i int) int64
+	text    func(v *view, i int) string
+}
+
+// lookupQueryField resolves a field name; rank is read in the given ranking mode
+func lookupQueryField(name, mode string) (queryField, bool) {
+	switch name {
+	case "rank":
+		return queryField{numeric: func(v *view, i int) int64 { return int64(v.rank(i, mode)) }}, true
+	case "peakRating":
+		return queryField{numeric: func(v *view, i int) int64 { return v.users[i].PeakRating }}, true
+	case "lowestRating":
+		return queryField{numeric: func(v *view, i int) int64 { return v.users[i].LowestRating }}, true
+	case "rating":
+		return queryField{numeric: func(v *view, i int) int64 { return v.ratingAt(i) }}, true
+	case "gamesPlayed", "wins", "winRate":
+		return queryField{numeric: func(v *view, i int) int64 { return fieldValue(&v.users[i], name) }}, true
+	case "username":
+		return queryField{text: func(v *view, i int) string { return v.usernameAt(i) }}, true
+	case "source":
+		return queryField{text: func(v *view, i int) string { return v.users[i].Source }}, true
+	case "tier":
+		return queryField{text: func(v *view, i int) string { return TierForRating(v.meta.ScoreFormat, v.ratingAt(i)) }}, true
+	case "country":
+		return queryField{text: func(v *view, i int) string { return v.users[i].Country }}, true
+	case "displayName":
+		return queryField{text: func(v *view, i int) string { return v.users[i].DisplayName }}, true
+	}
+	if score, ok := strings.CutPrefix(name, "scores."); ok && score != "" {
+		return queryField{numeric: func(v *view, i int) int64 { return int64(v.users[i].Scores[score]) }}, true
+	}
+	return queryField{}, false
+}
+
+// compileFilter turns a filter into a predicate, checking its value has the field's type
+func compileFilter(f models.QueryFilter, mode string) (func(v *view, i int) bool, error) {
+	field, ok := lookupQueryField(f.Field, mode)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, f.Field)
+	}
+
+	if field.numeric != nil {
+		if f.Op == "in" {
+			var values []int64
+			if err := json.Unmarshal(f.Value, &values); err != nil {
+				return nil, fmt.Errorf("%w: %s in needs an array of integers", ErrInvalidQuery, f.Field)
+			}
+			return func(v *view, i int) bool {
+				n := field.numeric(v, i)
+				for _, value := range values {
+					if n == value {
+						return true
+					}
+				}
+				return false
+			}, nil
+		}
+
+		var value int64
+		if err := json.Unmarshal(f.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %s needs an integer value", ErrInvalidQuery, f.Field)
+		}
+		var compare func(n int64) bool
+		switch f.Op {
+		case "eq":
+			compare = func(n int64) bool { return n == value }
+		case "ne":
+			compare = func(n int64) bool { return n != value }
+		case "gt":
+			compare = func(n int64) bool { return n > value }
+		case "gte":
+			compare = func(n int64) bool { return n >= value }
+		case "lt":
+			compare = func(n int64) bool { return n < value }
+		case "lte":
+			compare = func(n int64) bool { return n <= value }
+		default:
+			return nil, fmt.Errorf("%w: operator %q does not apply to numeric field %s", ErrInvalidQuery, f.Op, f.Field)
+		}
+		return func(v *view, i int) bool { return compare(field.numeric(v, i)) }, nil
+	}
+
+	if f.Op == "in" {
+		var values []string
+		if err := json.Unmarshal(f.Value, &values); err != nil {
+			return nil, fmt.Errorf("%w: %s in needs an array of strings", ErrInvalidQuery, f.Field)
+		}
+		return func(v *view, i int) bool {
+			s := field.text(v, i)
+			for _, value := range values {
+				if s == value {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	var value string
+	if err := json.Unmarshal(f.Value, &value); err != nil {
+		return nil, fmt.Errorf("%w: %s needs a string value", ErrInvalidQuery, f.Field)
+	}
+	switch f.Op {
+	case "eq":
+		return func(v *view, i int) bool { return field.text(v, i) == value }, nil
+	case "ne":
+		return func(v *view, i int) bool { return field.text(v, i) != value }, nil
+	case "prefix":
+		return func(v *view, i int) bool { return strings.HasPrefix(field.text(v, i), value) }, nil
+	}
+	return nil, fmt.Errorf("%w: operator %q does not apply to text field %s", ErrInvalidQuery, f.Op, f.Field)
+}
+
+// queryGrouping names each user's group and orders the group keys
+type queryGrouping struct {
+	key  func(v *view, i int) string
+	less func(a, b string) bool
+}
+
+func compileGrouping(q models.Query) (queryGrouping, error) {
+	switch q.GroupBy {
+	case "":
+		return queryGrouping{
+			key:  func(*view, int) string { return "all" },
+			less: func(a, b string) bool { return a < b },
+		}, nil
+	case "band":
+		width := q.BandWidth
+		if width == 0 {
+			width = defaultBandWidth
+		}
+		if width < 0 {
+			return queryGrouping{}, fmt.Errorf("%w: bandWidth must be positive", ErrInvalidQuery)
+		}
+		// Band keys are "from-to"; the floor keeps negative ratings in the right band
+		floor := func(rating int64) int64 {
+			band := rating / width
+			if rating%width != 0 && rating < 0 {
+				band--
+			}
+			return band * width
+		}
+		return queryGrouping{
+			key: func(v *view, i int) string {
+				from := floor(v.ratingAt(i))
+				return fmt.Sprintf("%d-%d", from, from+width-1)
+			},
+			less: func(a, b string) bool { return bandStart(a) < bandStart(b) },
+		}, nil
+	case "tier":
+		return queryGrouping{
+			key:  func(v *view, i int) string { return TierForRating(v.meta.ScoreFormat, v.ratingAt(i)) },
+			less: func(a, b string) bool { return tierIndexByName(a) < tierIndexByName(b) },
+		}, nil
+	case "source":
+		return queryGrouping{
+			key:  func(v *view, i int) string { return v.users[i].Source },
+			less: func(a, b string) bool { return a < b },
+		}, nil
+	case "country":
+		// Users without a country share the empty key, listed first
+		return queryGrouping{
+			key:  func(v *view, i int) string { return v.users[i].Country },
+			less: func(a, b string) bool { return a < b },
+		}, nil
+	}
+	return queryGrouping{}, fmt.Errorf("%w: cannot group by %q (band, tier, source or country)", ErrInvalidQuery, q.GroupBy)
+}
+
+// bandStart parses the lower bound back out of a band key
+func bandStart(key string) int64 {
+	var from int64
+	fmt.Sscanf(key, "%d", &from)
+	return from
+}
+
+// queryAggregate accumulates one aggregation over a group
+type queryAggregate struct {
+	name  string
+	op    string
+	field func(v *view, i int) int64
+}
+
+func compileAggregation(a models.QueryAggregation, mode string) (queryAggregate, error) {
+	if a.Op == "count" {
+		return queryAggregate{name: "count", op: a.Op}, nil
+	}
+	switch a.Op {
+	case "sum", "avg", "min", "max":
+	default:
+		return queryAggregate{}, fmt.Errorf("%w: unknown aggregation %q (count, sum, avg, min or max)", ErrInvalidQuery, a.Op)
+	}
+	field, ok := lookupQueryField(a.Field, mode)
+	if !ok || field.numeric == nil {
+		return queryAggregate{}, fmt.Errorf("%w: %s needs a numeric field, got %q", ErrInvalidQuery, a.Op, a.Field)
+	}
+	return queryAggregate{name: a.Op + "(" + a.Field + ")", op: a.Op, field: field.numeric}, nil
+}
+
+// groupTotals holds a group's running figures, one entry per aggregation
+type groupTotals struct {
+	count int
+	sums  []float64 // float so sums of large ratings can't overflow
+	mins  []int64
+	maxes []int64
+}
+
+// Query evaluates q against the board's current view, so every figure comes from one
+// consistent version of the standings
+func (lb *Leaderboard) Query(q models.Query) (models.QueryResult, error) {
+	if len(q.Filters) > maxQueryFilters {
+		return models.QueryResult{}, fmt.Errorf("%w: at most %d filters", ErrInvalidQuery, maxQueryFilters)
+	}
+	if len(q.Aggregations) == 0 || len(q.Aggregations) > maxQueryAggregations {
+		return models.QueryResult{}, fmt.Errorf("%w: between 1 and %d aggregations", ErrInvalidQuery, maxQueryAggregations)
+	}
+
+	v := lb.current()
+	mode := lb.rankingMode(v)
+
+	filters := make([]func(v *view, i int) bool, 0, len(q.Filters))
+	for _, f := range q.Filters {
+		filter, err := compileFilter(f, mode)
+		if err != nil {
+			return models.QueryResult{}, err
+		}
+		filters = append(filters, filter)
+	}
+	grouping, err := compileGrouping(q)
+	if err != nil {
+		return models.QueryResult{}, err
+	}
+	aggregates := make([]queryAggregate, 0, len(q.Aggregations))
+	for _, a := range q.Aggregations {
+		aggregate, err := compileAggregation(a, mode)
+		if err != nil {
+			return models.QueryResult{}, err
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+
+	result := models.QueryResult{Board: v.meta.Name, Version: v.version, Groups: make([]models.QueryGroup, 0)}
+	groups := make(map[string]*groupTotals)
+next:
+	for i := range v.users {
+		for _, filter := range filters {
+			if !filter(v, i) {
+				continue next
+			}
+		}
+		result.Matched++
+
+		key := grouping.key(v, i)
+		totals, exists := groups[key]
+		if !exists {
+			totals = &groupTotals{
+				sums:  make([]float64, len(aggregates)),
+				mins:  make([]int64, len(aggregates)),
+				maxes: make([]int64, len(aggregates)),
+			}
+			groups[key] = totals
+		}
+		totals.count++
+		for j, aggregate := range aggregates {
+			if aggregate.field == nil {
+				continue
+			}
+			n := aggregate.field(v, i)
+			totals.sums[j] += float64(n)
+			if totals.count == 1 || n < totals.mins[j] {
+				totals.mins[j] = n
+			}
+			if totals.count == 1 || n > totals.maxes[j] {
+				totals.maxes[j] = n
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return grouping.less(keys[i], keys[j]) })
+
+	for _, key := range keys {
+		totals := groups[key]
+		values := make(map[string]interface{}, len(aggregates))
+		for j, aggregate := range aggregates {
+			switch aggregate.op {
+			case "count":
+				values[aggregate.name] = totals.count
+			case "sum":
+				values[aggregate.name] = totals.sums[j]
+			case "avg":
+				values[aggregate.name] = totals.sums[j] / float64(totals.count)
+			case "min":
+				values[aggregate.name] = totals.mins[j]
+			case "max":
+				values[aggregate.name] = totals.maxes[j]
+			}
+		}
+		result.Groups = append(result.Groups, models.QueryGroup{Key: key, Values: values})
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"fmt"
+	"leaderboard-api/models"
+	"strings"
+)
+
+// ParseRankingMode validates a ranking mode name; an empty name means dense
+func ParseRankingMode(name string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(name)); mode {
+	case "":
+		return models.RankingDense, nil
+	case models.RankingDense, models.RankingStandard, models.RankingModified, models.RankingOrdinal:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown ranking mode %q (want dense, standard, modified or ordinal)", name)
+	}
+}
+
+// ParseTieBreak validates a tie-break name; an empty name means username
+func ParseTieBreak(name string) (string, error) {
+	switch tieBreak := strings.ToLower(strings.TrimSpace(name)); tieBreak {
+	case "":
+		return models.TieBreakUsername, nil
+	case models.TieBreakUsername, models.TieBreakAchieved:
+		return tieBreak, nil
+	default:
+		return "", fmt.Errorf("unknown tie-break %q (want username or achieved)", name)
+	}
+}
+
+// WithRanking returns a handle onto the same board that ranks users by mode instead
+// of the board's configured ranking mode
+func (lb *Leaderboard) WithRanking(mode string) Store {
+	handle := *lb
+	handle.ranking = mode
+	return &handle
+}
+
+// rankingMode returns the mode this handle ranks by for the given view
+func (lb *Leaderboard) rankingMode(v *view) string {
+	return lb.rankingModeFor(v.meta)
+}
+
+// rankingModeFor is rankingMode for a board described by meta
+func (lb *Leaderboard) rankingModeFor(meta models.BoardMetadata) string {
+	if lb.ranking != "" {
+		return lb.ranking
+	}
+	if meta.RankingMode != "" {
+		return meta.RankingMode
+	}
+	return models.RankingDense
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"time"
+)
+
+// Records returns the board's all-time records, with values rendered per its score
+// format
+func (lb *Leaderboard) Records() models.Records {
+	format := lb.Metadata().ScoreFormat
+
+	lb.recordsMu.Lock()
+	defer lb.recordsMu.Unlock()
+
+	records := models.Records{
+		HighestRating:     displayRecord(lb.records.HighestRating, format),
+		BiggestDailyClimb: displayRecord(lb.records.BiggestDailyClimb, format),
+	}
+	if r := lb.records.LongestReign; r != nil {
+		reign := *r
+		records.LongestReign = &reign
+	}
+	return records
+}
+
+// displayRecord copies a rating record with its value rendered per format
+func displayRecord(record *models.RatingRecord, format models.ScoreFormat) *models.RatingRecord {
+	if record == nil {
+		return nil
+	}
+	copied := *record
+	copied.Display = FormatScore(format, copied.Value)
+	return &copied
+}
+
+// restoreRecords replaces the records with ones loaded from a snapshot
+func (lb *Leaderboard) restoreRecords(records models.Records) {
+	lb.recordsMu.Lock()
+	defer lb.recordsMu.Unlock()
+	lb.records = records
+}
+
+// noteReign offers a reign at #1 from since until now as the longest
+func (lb *Leaderboard) noteReign(username string, since, now time.Time) {
+	seconds := int64(now.Sub(since) / time.Second)
+
+	lb.recordsMu.Lock()
+	defer lb.recordsMu.Unlock()
+
+	if r := lb.records.LongestReign; r != nil && r.Seconds >= seconds {
+		return
+	}
+	lb.records.LongestReign = &models.ReignRecord{Username: username, Since: since, Until: now, Seconds: seconds}
+}
+
+// noteRating offers a rating as the highest ever
+func (lb *Leaderboard) noteRating(username string, rating int64, at time.Time) {
+	lb.recordsMu.Lock()
+	defer lb.recordsMu.Unlock()
+	noteRecord(&lb.records.HighestRating, username, rating, at)
+}
+
+// noteClimb offers a 24-hour rating gain as the biggest
+func (lb *Leaderboard) noteClimb(username string, gain int64, at time.Time) {
+	lb.recordsMu.Lock()
+	defer lb.recordsMu.Unlock()
+	noteRecord(&lb.records.BiggestDailyClimb, username, gain, at)
+}
+
+// noteRecord replaces *record if value beats it; caller must hold recordsMu
+func noteRecord(record **models.RatingRecord, username string, value int64, at time.Time) {
+	if *record != nil && (*record).Value >= value {
+		return
+	}
+	*record = &models.RatingRecord{Username: username, Value: value, At: at}
+}
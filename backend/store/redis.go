@@ -0,0 +1,886 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds every Redis round trip made by RedisLeaderboard
+const redisTimeout = 2 * time.Second
+
+// MaxRedisRating bounds the ratings a Redis board accepts: sorted set scores are
+// doubles, which hold every integer up to 2^53 exactly but round beyond it, which
+// would merge distinct ratings into false ties
+const MaxRedisRating = 1 << 53
+
+// redisRatingOK reports whether a rating is held exactly by Redis, logging if not
+func redisRatingOK(username string, rating int64) bool {
+	if rating > MaxRedisRating || rating < -MaxRedisRating {
+		log.Printf("redis: rating %d for %s is beyond ±2^53 and was not stored", rating, username)
+		return false
+	}
+	return true
+}
+
+// RedisLeaderboard implements Store on Redis sorted sets so several API instances can
+// share one board. It keeps these keys under its prefix:
+//
+//	<prefix>:users   ZSET username -> rating
+//	<prefix>:ratings ZSET of distinct ratings, used for dense ranks
+//	<prefix>:counts  HASH rating -> number of users holding it
+//	<prefix>:names   ZSET (score 0) of "lowercase\x00username" for prefix search
+//	<prefix>:scores  HASH username -> JSON object of plugin scores
+//	<prefix>:version counter bumped by every mutation
+//
+// Redis breaks score ties by member in reverse lexical order on descending ranges, so
+// tied users are listed Z..A rather than A..Z as in the in-memory store.
+type RedisLeaderboard struct {
+	client *redis.Client
+	meta   models.BoardMetadata
+
+	usersKey   string
+	ratingsKey string
+	countsKey  string
+	namesKey   string
+	scoresKey  string
+	versionKey string
+}
+
+// NewRedisLeaderboard connects to the Redis server at redisURL (e.g. redis://localhost:6379/0)
+// and stores the board under keyPrefix
+func NewRedisLeaderboard(redisURL, keyPrefix string) (*RedisLeaderboard, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &RedisLeaderboard{
+		client: client,
+		meta: models.BoardMetadata{
+			Name:        "global",
+			ScoreFormat: defaultScoreFormat(),
+		},
+		usersKey:   keyPrefix + ":users",
+		ratingsKey: keyPrefix + ":ratings",
+		countsKey:  keyPrefix + ":counts",
+		namesKey:   keyPrefix + ":names",
+		scoresKey:  keyPrefix + ":scores",
+		versionKey: keyPrefix + ":version",
+	}, nil
+}
+
+// Close releases the Redis connection pool
+func (rl *RedisLeaderboard) Close() error {
+	return rl.client.Close()
+}
+
+// Metadata returns the board's name and score semantics
+func (rl *RedisLeaderboard) Metadata() models.BoardMetadata {
+	return rl.meta
+}
+
+// SetMetadata replaces the board's name and score semantics. Sort keys, ranking mode,
+// tie-break and capacity are ignored; Redis boards always rank every user by rating
+// with dense ranks.
+func (rl *RedisLeaderboard) SetMetadata(meta models.BoardMetadata) {
+	meta.SortKeys = nil
+	meta.RankingMode = ""
+	meta.TieBreak = ""
+	meta.Capacity = 0
+	rl.meta = meta
+}
+
+// keys returns the key list passed to every mutation script
+func (rl *RedisLeaderboard) keys() []string {
+	return []string{rl.usersKey, rl.ratingsKey, rl.countsKey, rl.namesKey, rl.versionKey, rl.scoresKey}
+}
+
+// redisAddUser adds a user unless it already exists, keeping the rating buckets in sync
+var redisAddUser = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('HINCRBY', KEYS[3], ARGV[2], 1)
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[2])
+redis.call('ZADD', KEYS[4], 0, ARGV[3] .. '\0' .. ARGV[1])
+redis.call('INCR', KEYS[5])
+return 1
+`)
+
+// redisUpdateRating moves a user between rating buckets atomically
+var redisUpdateRating = redis.NewScript(`
+local old = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not old then
+	return 0
+end
+if tonumber(old) == tonumber(ARGV[2]) then
+	return 1
+end
+old = string.format('%.0f', tonumber(old))
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+if redis.call('HINCRBY', KEYS[3], old, -1) <= 0 then
+	redis.call('HDEL', KEYS[3], old)
+	redis.call('ZREM', KEYS[2], old)
+end
+redis.call('HINCRBY', KEYS[3], ARGV[2], 1)
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[2])
+redis.call('INCR', KEYS[5])
+return 1
+`)
+
+// redisIncrementRating adds ARGV[2] to a user's rating, moving it between rating
+// buckets, and returns the new rating; false if the user doesn't exist
+var redisIncrementRating = redis.NewScript(`
+local old = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not old then
+	return false
+end
+local new = tonumber(old) + tonumber(ARGV[2])
+if new > tonumber(ARGV[3]) or new < -tonumber(ARGV[3]) then
+	return redis.error_reply('overflow')
+end
+old = string.format('%.0f', tonumber(old))
+new = string.format('%.0f', new)
+if new ~= old then
+	redis.call('ZADD', KEYS[1], new, ARGV[1])
+	if redis.call('HINCRBY', KEYS[3], old, -1) <= 0 then
+		redis.call('HDEL', KEYS[3], old)
+		redis.call('ZREM', KEYS[2], old)
+	end
+	redis.call('HINCRBY', KEYS[3], new, 1)
+	redis.call('ZADD', KEYS[2], new, new)
+	redis.call('INCR', KEYS[5])
+end
+return new
+`)
+
+// redisUpdateRatings applies a batch of username, rating pairs in ARGV as one script,
+// moving each user between rating buckets, and returns 1 per applied pair and 0 per
+// unknown user
+var redisUpdateRatings = redis.NewScript(`
+local applied = {}
+local changed = false
+for i = 1, #ARGV, 2 do
+	local old = redis.call('ZSCORE', KEYS[1], ARGV[i])
+	if not old then
+		applied[#applied + 1] = 0
+	else
+		applied[#applied + 1] = 1
+		if tonumber(old) ~= tonumber(ARGV[i + 1]) then
+			old = string.format('%.0f', tonumber(old))
+			redis.call('ZADD', KEYS[1], ARGV[i + 1], ARGV[i])
+			if redis.call('HINCRBY', KEYS[3], old, -1) <= 0 then
+				redis.call('HDEL', KEYS[3], old)
+				redis.call('ZREM', KEYS[2], old)
+			end
+			redis.call('HINCRBY', KEYS[3], ARGV[i + 1], 1)
+			redis.call('ZADD', KEYS[2], ARGV[i + 1], ARGV[i + 1])
+			changed = true
+		end
+	end
+end
+if changed then
+	redis.call('INCR', KEYS[5])
+end
+return applied
+`)
+
+// redisRemoveUser deletes a user and its bucket/name entries
+var redisRemoveUser = redis.NewScript(`
+local old = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not old then
+	return 0
+end
+old = string.format('%.0f', tonumber(old))
+redis.call('ZREM', KEYS[1], ARGV[1])
+if redis.call('HINCRBY', KEYS[3], old, -1) <= 0 then
+	redis.call('HDEL', KEYS[3], old)
+	redis.call('ZREM', KEYS[2], old)
+end
+redis.call('ZREM', KEYS[4], ARGV[2] .. '\0' .. ARGV[1])
+redis.call('HDEL', KEYS[6], ARGV[1])
+redis.call('INCR', KEYS[5])
+return 1
+`)
+
+// redisUpdateScores merges a JSON object of plugin scores into a user's stored scores
+var redisUpdateScores = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return 0
+end
+local stored = redis.call('HGET', KEYS[6], ARGV[1])
+local scores = stored and cjson.decode(stored) or {}
+for field, value in pairs(cjson.decode(ARGV[2])) do
+	scores[field] = value
+end
+redis.call('HSET', KEYS[6], ARGV[1], cjson.encode(scores))
+redis.call('INCR', KEYS[5])
+return 1
+`)
+
+// AddUser adds a new user to the leaderboard, returning ErrUserExists if the username
+// is taken. The check and the insert run in one script, so concurrent adds from several
+// API instances see exactly one succeed.
+func (rl *RedisLeaderboard) AddUser(user *models.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if !redisRatingOK(user.Username, user.Rating) {
+		return ErrRatingOverflow
+	}
+	added, err := redisAddUser.Run(ctx, rl.client, rl.keys(), user.Username, user.Rating, strings.ToLower(user.Username)).Int()
+	switch {
+	case err != nil:
+		log.Printf("redis: add user %s: %v", user.Username, err)
+		return err
+	case added == 0:
+		return ErrUserExists
+	}
+	return nil
+}
+
+// BulkAddUsers adds multiple users in a single pipeline
+func (rl *RedisLeaderboard) BulkAddUsers(users []*models.User) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*redisTimeout)
+	defer cancel()
+
+	// Pipelined commands can't fall back from EVALSHA, so send the script body
+	pipe := rl.client.Pipeline()
+	for _, user := range users {
+		if !redisRatingOK(user.Username, user.Rating) {
+			continue
+		}
+		redisAddUser.Eval(ctx, pipe, rl.keys(), user.Username, user.Rating, strings.ToLower(user.Username))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("redis: bulk add: %v", err)
+	}
+}
+
+// UpdateRating updates a user's rating
+func (rl *RedisLeaderboard) UpdateRating(username string, newRating int64) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if !redisRatingOK(username, newRating) {
+		return false
+	}
+	updated, err := redisUpdateRating.Run(ctx, rl.client, rl.keys(), username, newRating).Int()
+	if err != nil {
+		log.Printf("redis: update %s: %v", username, err)
+		return false
+	}
+	return updated == 1
+}
+
+// IncrementRating adds delta to a user's rating in one script, so concurrent changes
+// from several API instances never lose one another. Ratings may not leave ±2^53.
+func (rl *RedisLeaderboard) IncrementRating(username string, delta int64) (int64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if delta > MaxRedisRating || delta < -MaxRedisRating {
+		return 0, false, ErrRatingOverflow
+	}
+	rating, err := redisIncrementRating.Run(ctx, rl.client, rl.keys(), username, delta, int64(MaxRedisRating)).Int64()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return 0, false, nil
+	case err != nil && strings.Contains(err.Error(), "overflow"):
+		return 0, true, ErrRatingOverflow
+	case err != nil:
+		log.Printf("redis: increment %s: %v", username, err)
+		return 0, false, err
+	}
+	return rating, true, nil
+}
+
+// UpdateRatings applies a batch of ratings in one script, which Redis runs without
+// interleaving other commands. Ratings beyond ±2^53 are refused.
+func (rl *RedisLeaderboard) UpdateRatings(updates []models.ScoreUpdate) []models.ScoreResult {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*redisTimeout)
+	defer cancel()
+
+	results := make([]models.ScoreResult, len(updates))
+	args := make([]interface{}, 0, 2*len(updates))
+	sent := make([]int, 0, len(updates)) // index in updates of each pair in args
+	for i, update := range updates {
+		results[i].Username = update.Username
+		if update.Rating > MaxRedisRating || update.Rating < -MaxRedisRating {
+			results[i].Status = models.ScoreValidationFailed
+			results[i].Reason = "rating is beyond the ±2^53 a Redis board holds exactly"
+			continue
+		}
+		args = append(args, update.Username, update.Rating)
+		sent = append(sent, i)
+	}
+	if len(sent) == 0 {
+		return results
+	}
+
+	applied, err := redisUpdateRatings.Run(ctx, rl.client, rl.keys(), args...).Int64Slice()
+	if err != nil {
+		log.Printf("redis: batch update: %v", err)
+	}
+	for j, i := range sent {
+		switch {
+		case err != nil:
+			results[i].Status, results[i].Reason = models.ScoreFailed, "store unavailable"
+		case applied[j] == 1:
+			results[i].Status, results[i].Rating = models.ScoreApplied, updates[i].Rating
+		default:
+			results[i].Status = models.ScoreUserNotFound
+		}
+	}
+	return results
+}
+
+// redisTransformAttempts bounds how often TransformRatings retries after another write
+// to the board got in between its read and its write
+const redisTransformAttempts = 10
+
+// TransformRatings reads the users' ratings under WATCH and writes the new ones in a
+// MULTI transaction, retrying if any write to the board got in between, so concurrent
+// instances never apply a change computed from ratings that had moved on
+func (rl *RedisLeaderboard) TransformRatings(usernames []string, transform func(ratings []int64) []int64) (before, after []int64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*redisTimeout)
+	defer cancel()
+
+	txn := func(tx *redis.Tx) error {
+		before = make([]int64, len(usernames))
+		for i, username := range usernames {
+			score, err := tx.ZScore(ctx, rl.usersKey, username).Result()
+			if errors.Is(err, redis.Nil) {
+				return fmt.Errorf("%w: %s", ErrUserNotFound, username)
+			}
+			if err != nil {
+				return err
+			}
+			before[i] = int64(score)
+		}
+
+		ratings := transform(append([]int64(nil), before...))
+		if len(ratings) != len(usernames) {
+			return fmt.Errorf("transform returned %d ratings for %d users", len(ratings), len(usernames))
+		}
+		for _, rating := range ratings {
+			if rating > MaxRedisRating || rating < -MaxRedisRating {
+				return ErrRatingOverflow
+			}
+		}
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, username := range usernames {
+				// Scripts queued in MULTI can't fall back from EVALSHA, so send the body
+				redisUpdateRating.Eval(ctx, pipe, rl.keys(), username, ratings[i])
+			}
+			return nil
+		})
+		after = ratings
+		return err
+	}
+
+	for attempt := 0; attempt < redisTransformAttempts; attempt++ {
+		err = rl.client.Watch(ctx, txn, rl.usersKey)
+		if !errors.Is(err, redis.TxFailedErr) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// RemoveUser deletes a user from the leaderboard
+func (rl *RedisLeaderboard) RemoveUser(username string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	removed, err := redisRemoveUser.Run(ctx, rl.client, rl.keys(), username, strings.ToLower(username)).Int()
+	if err != nil {
+		log.Printf("redis: remove %s: %v", username, err)
+		return false
+	}
+	return removed == 1
+}
+
+// UpdateScores merges plugin score fields into a user's record. Redis boards don't
+// support composite sort keys, so the scores are stored and returned but never ranked.
+func (rl *RedisLeaderboard) UpdateScores(username string, scores map[string]int) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	encoded, err := json.Marshal(scores)
+	if err != nil {
+		return false
+	}
+	updated, err := redisUpdateScores.Run(ctx, rl.client, rl.keys(), username, string(encoded)).Int()
+	if err != nil {
+		log.Printf("redis: update scores %s: %v", username, err)
+		return false
+	}
+	return updated == 1
+}
+
+// Version returns the board's modification counter
+func (rl *RedisLeaderboard) Version() uint64 {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	version, err := rl.client.Get(ctx, rl.versionKey).Uint64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("redis: version: %v", err)
+	}
+	return version
+}
+
+// attachScores fills in the plugin scores of each entry
+func (rl *RedisLeaderboard) attachScores(ctx context.Context, entries []models.LeaderboardEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	usernames := make([]string, len(entries))
+	for i, entry := range entries {
+		usernames[i] = entry.Username
+	}
+
+	stored, err := rl.client.HMGet(ctx, rl.scoresKey, usernames...).Result()
+	if err != nil {
+		log.Printf("redis: scores: %v", err)
+		return
+	}
+	for i, value := range stored {
+		encoded, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var scores map[string]int
+		if json.Unmarshal([]byte(encoded), &scores) == nil && len(scores) > 0 {
+			entries[i].Scores = scores
+		}
+	}
+}
+
+// denseRank returns the dense rank of a rating: distinct ratings above it, plus one
+func (rl *RedisLeaderboard) denseRank(ctx context.Context, rating int64) (int, error) {
+	above, err := rl.client.ZCount(ctx, rl.ratingsKey, "("+strconv.FormatInt(rating, 10), "+inf").Result()
+	return int(above) + 1, err
+}
+
+// CompetitionRank returns the standard competition rank for a rating
+func (rl *RedisLeaderboard) CompetitionRank(rating int64) int {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	above, err := rl.client.ZCount(ctx, rl.usersKey, "("+strconv.FormatInt(rating, 10), "+inf").Result()
+	if err != nil {
+		log.Printf("redis: competition rank: %v", err)
+	}
+	return int(above) + 1
+}
+
+// RatingAtRank returns the rating of the user in position rank (from 1)
+func (rl *RedisLeaderboard) RatingAtRank(rank int) (int64, bool) {
+	if rank < 1 {
+		return 0, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	members, err := rl.client.ZRevRangeWithScores(ctx, rl.usersKey, int64(rank-1), int64(rank-1)).Result()
+	if err != nil {
+		log.Printf("redis: rating at rank: %v", err)
+		return 0, false
+	}
+	if len(members) == 0 {
+		return 0, false
+	}
+	return int64(members[0].Score), true
+}
+
+// GetLeaderboard returns paginated leaderboard entries with tie-aware ranking
+func (rl *RedisLeaderboard) GetLeaderboard(limit, offset int) []models.LeaderboardEntry {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	entries := make([]models.LeaderboardEntry, 0, limit)
+	members, err := rl.client.ZRevRangeWithScores(ctx, rl.usersKey, int64(offset), int64(offset+limit-1)).Result()
+	if err != nil || len(members) == 0 {
+		if err != nil {
+			log.Printf("redis: leaderboard page: %v", err)
+		}
+		return entries
+	}
+
+	rank, err := rl.denseRank(ctx, int64(members[0].Score))
+	if err != nil {
+		log.Printf("redis: rank lookup: %v", err)
+		return entries
+	}
+
+	// Consecutive distinct ratings on a page are adjacent in the ratings set
+	for i, member := range members {
+		rating := int64(member.Score)
+		if i > 0 && rating != entries[i-1].Rating {
+			rank++
+		}
+		entries = append(entries, models.LeaderboardEntry{
+			Rank:     rank,
+			Username: member.Member.(string),
+			Rating:   rating,
+			Display:  FormatScore(rl.meta.ScoreFormat, rating),
+		})
+	}
+	rl.attachScores(ctx, entries)
+	return entries
+}
+
+// GetRankRange returns all entries whose dense rank falls within [fromRank, toRank].
+// Tied users share a rank, so the result may hold more entries than toRank-fromRank+1.
+func (rl *RedisLeaderboard) GetRankRange(fromRank, toRank int) []models.LeaderboardEntry {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	entries := make([]models.LeaderboardEntry, 0)
+	if fromRank < 1 {
+		fromRank = 1
+	}
+	if toRank < fromRank {
+		return entries
+	}
+
+	// Dense rank N is the Nth distinct rating, so the ratings set bounds the range
+	ratings, err := rl.client.ZRevRange(ctx, rl.ratingsKey, int64(fromRank-1), int64(toRank-1)).Result()
+	if err != nil || len(ratings) == 0 {
+		if err != nil {
+			log.Printf("redis: rank range: %v", err)
+		}
+		return entries
+	}
+
+	members, err := rl.client.ZRevRangeByScoreWithScores(ctx, rl.usersKey, &redis.ZRangeBy{
+		Max: ratings[0],
+		Min: ratings[len(ratings)-1],
+	}).Result()
+	if err != nil {
+		log.Printf("redis: rank range members: %v", err)
+		return entries
+	}
+
+	rank := fromRank
+	for i, member := range members {
+		rating := int64(member.Score)
+		if i > 0 && rating != entries[i-1].Rating {
+			rank++
+		}
+		entries = append(entries, models.LeaderboardEntry{
+			Rank:     rank,
+			Username: member.Member.(string),
+			Rating:   rating,
+			Display:  FormatScore(rl.meta.ScoreFormat, rating),
+		})
+	}
+	rl.attachScores(ctx, entries)
+	return entries
+}
+
+// GetUserRank gets a specific user's rank by username
+func (rl *RedisLeaderboard) GetUserRank(username string) (*models.SearchResult, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	score, err := rl.client.ZScore(ctx, rl.usersKey, username).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("redis: user %s: %v", username, err)
+		}
+		return nil, false
+	}
+
+	result, err := rl.searchResult(ctx, username, int64(score))
+	if err != nil {
+		log.Printf("redis: rank for %s: %v", username, err)
+		return nil, false
+	}
+	return result, true
+}
+
+// searchResult builds a ranked result, including the percentile
+func (rl *RedisLeaderboard) searchResult(ctx context.Context, username string, rating int64) (*models.SearchResult, error) {
+	results, err := rl.searchResults(ctx, []string{username}, []int64{rating})
+	if err != nil {
+		return nil, err
+	}
+	return &results[0], nil
+}
+
+// searchResults builds ranked results for users with the given ratings, fetching
+// every rank in one round trip
+func (rl *RedisLeaderboard) searchResults(ctx context.Context, usernames []string, ratings []int64) ([]models.SearchResult, error) {
+	pipe := rl.client.Pipeline()
+	dense := make([]*redis.IntCmd, len(ratings))
+	above := make([]*redis.IntCmd, len(ratings))
+	for i, rating := range ratings {
+		bound := "(" + strconv.FormatInt(rating, 10)
+		dense[i] = pipe.ZCount(ctx, rl.ratingsKey, bound, "+inf")
+		above[i] = pipe.ZCount(ctx, rl.usersKey, bound, "+inf")
+	}
+	total := pipe.ZCard(ctx, rl.usersKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0, len(usernames))
+	for i, username := range usernames {
+		rating, higher := ratings[i], above[i].Val()
+		percentile := 0.0
+		if n := total.Val(); n > 0 {
+			percentile = math.Round(float64(higher+1)/float64(n)*100*100) / 100
+		}
+		results = append(results, models.SearchResult{
+			GlobalRank:      int(dense[i].Val()) + 1,
+			CompetitionRank: int(higher) + 1,
+			Username:        username,
+			Rating:          rating,
+			Percentile:      percentile,
+			Display:         FormatScore(rl.meta.ScoreFormat, rating),
+		})
+	}
+	return results, nil
+}
+
+// Bounds on the substring scan SearchUsers falls back to, so a query matching few
+// users doesn't walk the whole names set
+const (
+	redisSearchScanCount = 1000 // ZSCAN count hint
+	redisSearchScanPages = 10   // ZSCAN calls at most
+)
+
+// SearchUsers searches for users by username prefix, falling back to a bounded
+// substring scan. It ranks the first limit matches found, best first: in username
+// order for a prefix, in scan order for a substring.
+func (rl *RedisLeaderboard) SearchUsers(query string, limit int) []models.SearchResult {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	results := make([]models.SearchResult, 0)
+	query = strings.ToLower(query)
+	if query == "" || limit <= 0 {
+		return results
+	}
+
+	names, err := rl.client.ZRangeByLex(ctx, rl.namesKey, &redis.ZRangeBy{
+		Min:   "[" + query,
+		Max:   "[" + query + "\xff",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		log.Printf("redis: prefix search: %v", err)
+		return results
+	}
+
+	if len(names) == 0 {
+		pattern := "*" + escapeGlob(query) + "*\x00*"
+		var cursor uint64
+		for page := 0; page < redisSearchScanPages && len(names) < limit; page++ {
+			pairs, next, err := rl.client.ZScan(ctx, rl.namesKey, cursor, pattern, redisSearchScanCount).Result()
+			if err != nil {
+				log.Printf("redis: substring search: %v", err)
+				return results
+			}
+			// ZSCAN yields member, score pairs; keep only the members
+			for i := 0; i < len(pairs); i += 2 {
+				names = append(names, pairs[i])
+			}
+			if next == 0 {
+				break
+			}
+			cursor = next
+		}
+		if len(names) > limit {
+			names = names[:limit]
+		}
+	}
+	if len(names) == 0 {
+		return results
+	}
+
+	usernames := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, username, found := strings.Cut(name, "\x00"); found {
+			usernames = append(usernames, username)
+		}
+	}
+
+	scores, err := rl.client.ZMScore(ctx, rl.usersKey, usernames...).Result()
+	if err != nil {
+		log.Printf("redis: search scores: %v", err)
+		return results
+	}
+
+	type match struct {
+		username string
+		rating   int64
+	}
+	matches := make([]match, 0, len(usernames))
+	for i, username := range usernames {
+		matches = append(matches, match{username, int64(scores[i])})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rating != matches[j].rating {
+			return matches[i].rating > matches[j].rating
+		}
+		return matches[i].username < matches[j].username
+	})
+
+	ratings := make([]int64, len(matches))
+	for i, m := range matches {
+		usernames[i], ratings[i] = m.username, m.rating
+	}
+	ranked, err := rl.searchResults(ctx, usernames, ratings)
+	if err != nil {
+		log.Printf("redis: search ranks: %v", err)
+		return results
+	}
+	return ranked
+}
+
+// SuggestUsernames returns up to limit usernames starting with prefix (case-insensitive),
+// in alphabetical order
+func (rl *RedisLeaderboard) SuggestUsernames(prefix string, limit int) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	suggestions := make([]string, 0)
+	prefix = strings.ToLower(prefix)
+	if prefix == "" {
+		return suggestions
+	}
+
+	names, err := rl.client.ZRangeByLex(ctx, rl.namesKey, &redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		log.Printf("redis: suggest: %v", err)
+		return suggestions
+	}
+	for _, name := range names {
+		if _, username, found := strings.Cut(name, "\x00"); found {
+			suggestions = append(suggestions, username)
+		}
+	}
+	return suggestions
+}
+
+// GetRandomUser returns the user at index (mod total) in rating order
+func (rl *RedisLeaderboard) GetRandomUser(index int) *models.User {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	total := rl.GetTotalUsers()
+	if total == 0 {
+		return nil
+	}
+	idx := int64(index % total)
+	members, err := rl.client.ZRevRangeWithScores(ctx, rl.usersKey, idx, idx).Result()
+	if err != nil || len(members) == 0 {
+		return nil
+	}
+	username := members[0].Member.(string)
+	return &models.User{ID: username, Username: username, Rating: int64(members[0].Score)}
+}
+
+// GetTotalUsers returns total number of users
+func (rl *RedisLeaderboard) GetTotalUsers() int {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	total, err := rl.client.ZCard(ctx, rl.usersKey).Result()
+	if err != nil {
+		log.Printf("redis: count: %v", err)
+	}
+	return int(total)
+}
+
+// GetStats returns leaderboard statistics
+func (rl *RedisLeaderboard) GetStats() models.StatsResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	stats := models.StatsResponse{TotalUsers: rl.GetTotalUsers()}
+	if stats.TotalUsers == 0 {
+		return stats
+	}
+
+	if lowest, err := rl.client.ZRangeWithScores(ctx, rl.ratingsKey, 0, 0).Result(); err == nil && len(lowest) > 0 {
+		stats.MinRating = int64(lowest[0].Score)
+	}
+	if highest, err := rl.client.ZRevRangeWithScores(ctx, rl.ratingsKey, 0, 0).Result(); err == nil && len(highest) > 0 {
+		stats.MaxRating = int64(highest[0].Score)
+	}
+
+	// The distribution comes from the per-rating counts, one field per distinct rating
+	counts, err := rl.client.HGetAll(ctx, rl.countsKey).Result()
+	if err != nil {
+		log.Printf("redis: rating counts: %v", err)
+		return stats
+	}
+	ratings := make([]int64, 0, len(counts))
+	held := make(map[int64]int, len(counts))
+	for field, value := range counts {
+		rating, err1 := strconv.ParseInt(field, 10, 64)
+		count, err2 := strconv.Atoi(value)
+		if err1 != nil || err2 != nil || count <= 0 {
+			continue
+		}
+		ratings = append(ratings, rating)
+		held[rating] = count
+	}
+	sort.Slice(ratings, func(i, j int) bool { return ratings[i] < ratings[j] })
+
+	// Users at or below each rating, for finding the kth lowest
+	n := 0
+	var sum, sumSquares float64
+	through := make([]int, len(ratings))
+	for i, rating := range ratings {
+		n += held[rating]
+		through[i] = n
+		sum += float64(rating) * float64(held[rating])
+		sumSquares += float64(rating) * float64(rating) * float64(held[rating])
+	}
+	if n > 0 {
+		describeRatings(&stats, n, sum, sumSquares, func(k int) int64 {
+			return ratings[sort.SearchInts(through, k)]
+		})
+	}
+	return stats
+}
+
+// escapeGlob escapes Redis MATCH pattern metacharacters
+func escapeGlob(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+	return replacer.Replace(s)
+}
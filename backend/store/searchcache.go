@@ -0,0 +1,84 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"strings"
+	"sync"
+)
+
+// maxSearchCacheEntries bounds the cache; it is cleared wholesale when full
+const maxSearchCacheEntries = 1024
+
+type searchCacheKey struct {
+	query   string
+	ranking string
+	limit   int
+}
+
+// searchCache memoizes search results for a single store version. Any version bump
+// invalidates every entry, so cached results are never stale.
+type searchCache struct {
+	mu      sync.Mutex
+	version uint64
+	entries map[searchCacheKey][]models.SearchResult
+	hits    uint64
+	misses  uint64
+}
+
+func newSearchCache() *searchCache {
+	return &searchCache{entries: make(map[searchCacheKey][]models.SearchResult)}
+}
+
+// get returns cached results for (query, ranking, limit) at the given store version
+func (sc *searchCache) get(query, ranking string, limit int, version uint64) ([]models.SearchResult, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if version == sc.version {
+		if results, found := sc.entries[searchCacheKey{strings.ToLower(query), ranking, limit}]; found {
+			sc.hits++
+			return results, true
+		}
+	}
+	sc.misses++
+	return nil, false
+}
+
+// put stores results computed at the given store version
+func (sc *searchCache) put(query, ranking string, limit int, version uint64, results []models.SearchResult) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if version < sc.version {
+		return
+	}
+	if version > sc.version || len(sc.entries) >= maxSearchCacheEntries {
+		sc.entries = make(map[searchCacheKey][]models.SearchResult)
+		sc.version = version
+	}
+	sc.entries[searchCacheKey{strings.ToLower(query), ranking, limit}] = results
+}
+
+// clear drops every cached entry, keeping the counters
+func (sc *searchCache) clear() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.entries = make(map[searchCacheKey][]models.SearchResult)
+}
+
+// stats returns a snapshot of the cache counters
+func (sc *searchCache) stats() models.CacheStats {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	stats := models.CacheStats{
+		Hits:    sc.hits,
+		Misses:  sc.misses,
+		Entries: len(sc.entries),
+		Version: sc.version,
+	}
+	if total := sc.hits + sc.misses; total > 0 {
+		stats.HitRate = float64(sc.hits) / float64(total)
+	}
+	return stats
+}
@@ -0,0 +1,302 @@
+package store
+
+import (
+	"errors"
+	"leaderboard-api/models"
+	"math"
+	"sync"
+	"time"
+)
+
+var ErrInvalidResetPolicy = errors.New("reset mode must be \"hard\" or \"soft\" with factor in [0, 1]")
+
+// SeasonArchive keeps immutable snapshots of past seasons for a board
+type SeasonArchive struct {
+	mu sync.RWMutex
+
+	// Board whose standings are archived
+	board *Leaderboard
+
+	// Archived seasons, oldest first; season IDs start at 1
+	seasons []*archivedSeason
+
+	// When the current (live) season started
+	currentStartedAt time.Time
+
+	// How long archived seasons accept late submissions before they are finalized
+	gracePeriod time.Duration
+}
+
+// archivedSeason is an archived season along with the late submissions applied to
+// it; submitted and achieved are dropped once the season is finalized
+type archivedSeason struct {
+	*models.Season
+	submitted map[string]submissionMark
+
+	// When each user reached their rating, on boards that break ties by it
+	achieved map[string]time.Time
+}
+
+// NewSeasonArchive creates an archive for lb; the live season starts now
+func NewSeasonArchive(lb *Leaderboard) *SeasonArchive {
+	return &SeasonArchive{
+		board:            lb,
+		seasons:          make([]*archivedSeason, 0),
+		currentStartedAt: time.Now(),
+		gracePeriod:      DefaultGracePeriod,
+	}
+}
+
+// CurrentSeason returns the ID and start time of the live season
+func (sa *SeasonArchive) CurrentSeason() (int, time.Time) {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+	return len(sa.seasons) + 1, sa.currentStartedAt
+}
+
+// Rotate archives the current standings and resets ratings for a new season
+func (sa *SeasonArchive) Rotate(policy models.ResetPolicy) (models.SeasonSummary, error) {
+	reset, err := resetFunc(policy, sa.board.Metadata().ScoreFormat)
+	if err != nil {
+		return models.SeasonSummary{}, err
+	}
+
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	standings, achieved := sa.board.ResetRatings(reset)
+	now := time.Now()
+
+	season := &models.Season{
+		SeasonSummary: models.SeasonSummary{
+			ID:         len(sa.seasons) + 1,
+			Board:      sa.board.Metadata().Name,
+			StartedAt:  sa.currentStartedAt,
+			EndedAt:    now,
+			TotalUsers: len(standings),
+		},
+		Standings: standings,
+	}
+	sa.seasons = append(sa.seasons, &archivedSeason{
+		Season:    season,
+		submitted: make(map[string]submissionMark),
+		achieved:  achieved,
+	})
+	sa.currentStartedAt = now
+
+	return season.SeasonSummary, nil
+}
+
+// List returns summaries of all archived seasons, oldest first
+func (sa *SeasonArchive) List() []models.SeasonSummary {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+
+	summaries := make([]models.SeasonSummary, 0, len(sa.seasons))
+	for _, season := range sa.seasons {
+		summaries = append(summaries, season.SeasonSummary)
+	}
+	return summaries
+}
+
+// GetStandings returns a page of an archived season's final standings
+func (sa *SeasonArchive) GetStandings(id, limit, offset int) (models.SeasonSummary, []models.LeaderboardEntry, bool) {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+
+	if id < 1 || id > len(sa.seasons) {
+		return models.SeasonSummary{}, nil, false
+	}
+	season := sa.seasons[id-1]
+
+	if offset >= len(season.Standings) {
+		return season.SeasonSummary, []models.LeaderboardEntry{}, true
+	}
+	end := offset + limit
+	if end > len(season.Standings) {
+		end = len(season.Standings)
+	}
+
+	// Standings are replaced rather than mutated, so the sub-slice can be shared safely
+	return season.SeasonSummary, season.Standings[offset:end], true
+}
+
+// ApplySubmissions routes a batch of timestamped submissions to the season each one
+// was made in. Submissions from the live season go to the board; ones dated in an
+// archived season update that season's standings, which are then re-ranked as the
+// board ranks its users, unless the season has been finalized.
+func (sa *SeasonArchive) ApplySubmissions(subs []models.Submission) []models.SubmissionResult {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	results := make([]models.SubmissionResult, len(subs))
+	live := make([]models.Submission, 0, len(subs))
+	liveIndex := make([]int, 0, len(subs))
+	touched := make(map[*archivedSeason]bool)
+	for _, i := range submissionOrder(subs) {
+		sub := subs[i]
+		if sub.At.IsZero() || !sub.At.Before(sa.currentStartedAt) {
+			live = append(live, sub)
+			liveIndex = append(liveIndex, i)
+			continue
+		}
+		season := sa.seasonAt(sub.At)
+		if season.Finalized {
+			results[i] = models.SubmissionResult{
+				ID:       sub.ID,
+				Username: sub.Username,
+				At:       sub.At,
+				Status:   models.SubmissionFinalized,
+				Reason:   ErrPeriodFinalized.Error(),
+				Late:     true,
+				Season:   season.ID,
+			}
+			continue
+		}
+		if !touched[season] {
+			// Work on a copy so pages already handed out stay intact
+			standings := make([]models.LeaderboardEntry, len(season.Standings))
+			copy(standings, season.Standings)
+			season.Standings = standings
+		}
+		results[i] = season.apply(sub)
+		if results[i].Status == models.SubmissionArchived {
+			touched[season] = true
+		}
+	}
+
+	meta := sa.board.Metadata()
+	mode := sa.board.rankingModeFor(meta)
+	for season := range touched {
+		season.rerank(meta, mode)
+	}
+
+	for j, result := range sa.board.ApplySubmissions(live) {
+		results[liveIndex[j]] = result
+	}
+	return results
+}
+
+// StandingAt returns username's entry in the final standings of the archived season
+// covering t, and how many users finished rated strictly higher. found is false if t
+// falls in the live season, or the user wasn't in that season's standings.
+func (sa *SeasonArchive) StandingAt(username string, t time.Time) (summary models.SeasonSummary, entry models.LeaderboardEntry, above int, found bool) {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+
+	if len(sa.seasons) == 0 || !t.Before(sa.currentStartedAt) {
+		return models.SeasonSummary{}, models.LeaderboardEntry{}, 0, false
+	}
+	season := sa.seasonAt(t)
+	for _, standing := range season.Standings {
+		if standing.Username != username {
+			continue
+		}
+		// Standings follow the board's sort keys, which needn't order them by rating
+		for _, other := range season.Standings {
+			if other.Rating > standing.Rating {
+				above++
+			}
+		}
+		return season.SeasonSummary, standing, above, true
+	}
+	return season.SeasonSummary, models.LeaderboardEntry{}, 0, false
+}
+
+// seasonAt returns the archived season covering t; t must be before the live season.
+// Anything earlier than the first season counts towards it.
+func (sa *SeasonArchive) seasonAt(t time.Time) *archivedSeason {
+	for _, season := range sa.seasons {
+		if t.Before(season.EndedAt) {
+			return season
+		}
+	}
+	return sa.seasons[len(sa.seasons)-1]
+}
+
+// apply records a late submission against the season's standings, which the caller
+// must already have copied
+func (season *archivedSeason) apply(sub models.Submission) models.SubmissionResult {
+	result := models.SubmissionResult{ID: sub.ID, Username: sub.Username, At: sub.At, Season: season.ID, Late: true}
+
+	last := season.submitted[sub.Username]
+	switch {
+	case sub.ID != "" && sub.ID == last.id:
+		result.Status = models.SubmissionDuplicate
+		return result
+	case !sub.At.After(last.at) && !last.at.IsZero():
+		result.Status = models.SubmissionSuperseded
+		return result
+	}
+
+	found := false
+	for i := range season.Standings {
+		if season.Standings[i].Username == sub.Username {
+			season.Standings[i].Rating = sub.Rating
+			found = true
+			break
+		}
+	}
+	if !found {
+		result.Status, result.Reason = models.SubmissionRejected, "user did not play in season"
+		return result
+	}
+
+	season.submitted[sub.Username] = submissionMark{at: sub.At, id: sub.ID}
+	if season.achieved != nil {
+		season.achieved[sub.Username] = sub.At
+	}
+	season.LateSubmissions++
+
+	result.Status, result.Rating = models.SubmissionArchived, sub.Rating
+	return result
+}
+
+// rerank re-sorts a season's standings and recomputes their ranks the way the live
+// board described by meta ranks its users under mode
+func (season *archivedSeason) rerank(meta models.BoardMetadata, mode string) {
+	v := &view{meta: meta, users: make([]models.User, len(season.Standings))}
+	entries := make(map[string]models.LeaderboardEntry, len(season.Standings))
+	for i, standing := range season.Standings {
+		entries[standing.Username] = standing
+		v.users[i] = models.User{
+			Username:        standing.Username,
+			Rating:          standing.Rating,
+			Scores:          standing.Scores,
+			GamesPlayed:     standing.GamesPlayed,
+			Wins:            standing.Wins,
+			RatingUpdatedAt: season.achieved[standing.Username],
+		}
+	}
+	v.order()
+
+	for i := range v.users {
+		standing := entries[v.users[i].Username]
+		standing.Rank = v.rank(i, mode)
+		standing.Display = FormatScore(meta.ScoreFormat, standing.Rating)
+		season.Standings[i] = standing
+	}
+}
+
+// resetFunc turns a reset policy into a rating transform for a board with format.
+// The default base is 1500 points; a base given in the policy is in stored units.
+func resetFunc(policy models.ResetPolicy, format models.ScoreFormat) (func(int64) int64, error) {
+	base := policy.BaseRating
+	if base == 0 {
+		base = ScaleRating(format, 1500)
+	}
+
+	switch policy.Mode {
+	case "", "hard":
+		return func(int64) int64 { return base }, nil
+	case "soft":
+		if policy.Factor < 0 || policy.Factor > 1 {
+			return nil, ErrInvalidResetPolicy
+		}
+		return func(rating int64) int64 {
+			return base + int64(math.Round(float64(rating-base)*policy.Factor))
+		}, nil
+	default:
+		return nil, ErrInvalidResetPolicy
+	}
+}
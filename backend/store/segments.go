@@ -0,0 +1,147 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"sort"
+)
+
+// segmentIndex is a view's secondary index of one segment of its users, such as a
+// country or a tier: their positions in the view, best first, and their ranks among
+// each other
+type segmentIndex struct {
+	positions []int
+
+	// Per entry of positions, as for the view as a whole: dense rank, number of
+	// segment members strictly ahead, and number ahead or tied including the user
+	ranks   []int
+	above   []int
+	through []int
+}
+
+// buildSegments indexes every user by the segment key returns for them; users with an
+// empty key are left out
+func (v *view) buildSegments(key func(user *models.User) string) map[string]*segmentIndex {
+	segments := make(map[string]*segmentIndex)
+	for i := range v.users {
+		name := key(&v.users[i])
+		if name == "" {
+			continue
+		}
+		index, exists := segments[name]
+		if !exists {
+			index = &segmentIndex{}
+			segments[name] = index
+		}
+		index.add(v, i)
+	}
+	for _, index := range segments {
+		index.finish()
+	}
+	return segments
+}
+
+// add appends the user at view position i, which must come after every member so
+// far. Users tie within a segment exactly when they tie on the whole board.
+func (s *segmentIndex) add(v *view, i int) {
+	k := len(s.positions)
+	rank, above := 1, 0
+	if k > 0 {
+		rank, above = s.ranks[k-1], s.above[k-1]
+		if v.ranks[s.positions[k-1]] != v.ranks[i] {
+			rank, above = rank+1, k
+		}
+	}
+	s.positions = append(s.positions, i)
+	s.ranks = append(s.ranks, rank)
+	s.above = append(s.above, above)
+}
+
+// finish works out where each tie group ends once every member has been added
+func (s *segmentIndex) finish() {
+	n := len(s.positions)
+	s.through = make([]int, n)
+	for k := n - 1; k >= 0; k-- {
+		if k == n-1 || s.ranks[k+1] != s.ranks[k] {
+			s.through[k] = k + 1
+		} else {
+			s.through[k] = s.through[k+1]
+		}
+	}
+}
+
+// rank returns the rank of the k-th member of the segment under mode
+func (s *segmentIndex) rank(k int, mode string) int {
+	switch mode {
+	case models.RankingStandard:
+		return s.above[k] + 1
+	case models.RankingModified:
+		return s.through[k]
+	case models.RankingOrdinal:
+		return k + 1
+	default:
+		return s.ranks[k]
+	}
+}
+
+// find returns where the user at view position i sits in the segment
+func (s *segmentIndex) find(i int) (int, bool) {
+	k := sort.SearchInts(s.positions, i)
+	return k, k < len(s.positions) && s.positions[k] == i
+}
+
+// segmentPage returns a page of one segment's standings, ranked within the segment,
+// and how many members it has. segments picks the index to read out of a view.
+func (lb *Leaderboard) segmentPage(segments func(v *view) map[string]*segmentIndex, name string, limit, offset int) ([]models.LeaderboardEntry, int) {
+	v := lb.current()
+	mode := lb.rankingMode(v)
+	base := lb.baselineFor(v)
+
+	index, exists := segments(v)[name]
+	if !exists || offset >= len(index.positions) {
+		total := 0
+		if exists {
+			total = len(index.positions)
+		}
+		return []models.LeaderboardEntry{}, total
+	}
+
+	end := offset + limit
+	if end > len(index.positions) {
+		end = len(index.positions)
+	}
+
+	var baseIndex *segmentIndex
+	if base != nil {
+		baseIndex = segments(base)[name]
+	}
+	entries := make([]models.LeaderboardEntry, 0, end-offset)
+	for k := offset; k < end; k++ {
+		i := index.positions[k]
+		entry := v.entry(i, mode)
+		entry.GlobalRank = entry.Rank
+		entry.Rank = index.rank(k, mode)
+		if base != nil {
+			entry = withSegmentDeltas(entry, base, baseIndex, mode)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, len(index.positions)
+}
+
+// withSegmentDeltas fills in how an entry has moved within its segment since the
+// baseline view. Users who weren't in the segment then count as new.
+func withSegmentDeltas(entry models.LeaderboardEntry, base *view, baseIndex *segmentIndex, mode string) models.LeaderboardEntry {
+	j, existed := base.index[entry.Username]
+	if !existed || baseIndex == nil {
+		entry.New = true
+		return entry
+	}
+	k, member := baseIndex.find(j)
+	if !member {
+		entry.New = true
+		return entry
+	}
+	entry.RankDelta = baseIndex.rank(k, mode) - entry.Rank
+	entry.RatingDelta = entry.Rating - base.users[j].Rating
+	return entry
+}
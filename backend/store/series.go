@@ -0,0 +1,164 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"time"
+)
+
+// maxSeriesEvents bounds the number of series events kept for streaming
+const maxSeriesEvents = 1000
+
+// EnableSeries turns on promotion/demotion series. Crossing a tier boundary
+// starts a best-of-N series instead of moving the user straight into the new tier.
+// A bestOf of 0 disables series mode.
+func (lb *Leaderboard) EnableSeries(bestOf int) {
+	if bestOf < 0 {
+		bestOf = 0
+	}
+	lb.seriesBestOf.Store(int64(bestOf))
+	if bestOf == 0 {
+		for _, shard := range lb.shards {
+			unlock := lb.lockShard(shard)
+			shard.series = make(map[string]*models.SeriesState)
+			unlock()
+		}
+	}
+}
+
+// GetSeries returns the active series for a user, if any
+func (lb *Leaderboard) GetSeries(username string) (*models.SeriesState, bool) {
+	shard := lb.shardFor(username)
+	unlock := lb.rlockShard(shard)
+	defer unlock()
+
+	state, exists := shard.series[username]
+	if !exists {
+		return nil, false
+	}
+	copied := *state
+	return &copied, true
+}
+
+// SeriesEventsSince returns series events with a sequence number greater than seq,
+// along with the latest sequence number
+func (lb *Leaderboard) SeriesEventsSince(seq uint64) ([]models.SeriesEvent, uint64) {
+	lb.seriesMu.Lock()
+	defer lb.seriesMu.Unlock()
+
+	events := make([]models.SeriesEvent, 0)
+	for _, event := range lb.seriesEvents {
+		if event.Seq > seq {
+			events = append(events, event)
+		}
+	}
+	return events, lb.seriesSeq
+}
+
+// applySeries adjusts a proposed rating change according to the series rules.
+// Must be called with the user's shard locked; returns the rating to store.
+func (lb *Leaderboard) applySeries(shard *userShard, username string, oldRating, newRating int64) int64 {
+	bestOf := int(lb.seriesBestOf.Load())
+	if bestOf == 0 || newRating == oldRating {
+		return newRating
+	}
+
+	scale := lb.pointScale.Load()
+	state, inSeries := shard.series[username]
+	if !inSeries {
+		fromIdx := tierIndex(oldRating, scale)
+		toIdx := tierIndex(newRating, scale)
+		if fromIdx == toIdx {
+			return newRating
+		}
+
+		// Only the adjacent tier is contested, even after a large jump
+		seriesType := "promotion"
+		if toIdx > fromIdx {
+			toIdx = fromIdx + 1
+		} else {
+			seriesType = "demotion"
+			toIdx = fromIdx - 1
+		}
+
+		state = &models.SeriesState{
+			Type:      seriesType,
+			FromTier:  Tiers[fromIdx].Name,
+			ToTier:    Tiers[toIdx].Name,
+			BestOf:    bestOf,
+			StartedAt: time.Now(),
+		}
+		shard.series[username] = state
+		lb.recordSeriesEvent("series_started", username, state)
+		return clampToTier(newRating, fromIdx, scale)
+	}
+
+	// Every rating change during a series counts as one match
+	if newRating > oldRating {
+		state.Wins++
+	} else {
+		state.Losses++
+	}
+
+	needed := state.BestOf/2 + 1
+	fromIdx := tierIndex(oldRating, scale)
+	toIdx := tierIndexByName(state.ToTier)
+
+	switch {
+	case state.Wins >= needed:
+		delete(shard.series, username)
+		lb.recordSeriesEvent("series_won", username, state)
+		if state.Type == "promotion" {
+			return clampToTier(newRating, toIdx, scale)
+		}
+		return clampToTier(newRating, fromIdx, scale)
+	case state.Losses >= needed:
+		delete(shard.series, username)
+		lb.recordSeriesEvent("series_lost", username, state)
+		if state.Type == "demotion" {
+			return clampToTier(newRating, toIdx, scale)
+		}
+		return clampToTier(newRating, fromIdx, scale)
+	}
+
+	return clampToTier(newRating, fromIdx, scale)
+}
+
+// recordSeriesEvent appends an event to the bounded series event log
+func (lb *Leaderboard) recordSeriesEvent(eventType, username string, state *models.SeriesState) {
+	lb.seriesMu.Lock()
+	defer lb.seriesMu.Unlock()
+
+	lb.seriesSeq++
+	lb.seriesEvents = append(lb.seriesEvents, models.SeriesEvent{
+		Seq:       lb.seriesSeq,
+		Type:      eventType,
+		Username:  username,
+		Series:    *state,
+		Timestamp: time.Now(),
+	})
+	if len(lb.seriesEvents) > maxSeriesEvents {
+		lb.seriesEvents = lb.seriesEvents[len(lb.seriesEvents)-maxSeriesEvents:]
+	}
+}
+
+// clampToTier keeps a rating held in units of 1/scale point within the bounds of the
+// tier at idx
+func clampToTier(rating int64, idx int, scale int64) int64 {
+	if floor := Tiers[idx].MinRating * scale; rating < floor {
+		return floor
+	}
+	if idx+1 < len(Tiers) && rating >= Tiers[idx+1].MinRating*scale {
+		return Tiers[idx+1].MinRating*scale - 1
+	}
+	return rating
+}
+
+// tierIndexByName returns the index into Tiers for a tier name
+func tierIndexByName(name string) int {
+	for i, tier := range Tiers {
+		if tier.Name == name {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"hash/fnv"
+	"leaderboard-api/models"
+	"sync"
+)
+
+// shardCount is the number of independently locked user shards
+const shardCount = 32
+
+// userShard holds the users whose names hash to it, under its own lock, so rating
+// updates for users in different shards never wait on each other
+type userShard struct {
+	mu sync.RWMutex
+
+	// Users indexed by username for O(1) lookup
+	users map[string]*models.User
+
+	// Active series by username
+	series map[string]*models.SeriesState
+
+	// Recent rating changes by username
+	history map[string]*ratingHistory
+
+	// Latest offline-queue submission applied, by username
+	submitted map[string]submissionMark
+}
+
+func newUserShards() [shardCount]*userShard {
+	var shards [shardCount]*userShard
+	for i := range shards {
+		shards[i] = &userShard{
+			users:     make(map[string]*models.User),
+			series:    make(map[string]*models.SeriesState),
+			history:   make(map[string]*ratingHistory),
+			submitted: make(map[string]submissionMark),
+		}
+	}
+	return shards
+}
+
+// shardFor returns the shard that owns username
+func (lb *Leaderboard) shardFor(username string) *userShard {
+	return lb.shards[shardIndex(username)]
+}
+
+// shardIndex returns the position of username's shard, the order shards are locked in
+func shardIndex(username string) int {
+	h := fnv.New32a()
+	h.Write([]byte(username))
+	return int(h.Sum32() % shardCount)
+}
+
+// lockShard takes a shard's exclusive lock and returns the function that releases it
+func (lb *Leaderboard) lockShard(shard *userShard) func() {
+	return lb.timedLock(&lb.locks.shardWrite, shard.mu.Lock, shard.mu.Unlock)
+}
+
+// rlockShard takes a shard's shared lock and returns the function that releases it
+func (lb *Leaderboard) rlockShard(shard *userShard) func() {
+	return lb.timedLock(&lb.locks.shardRead, shard.mu.RLock, shard.mu.RUnlock)
+}
+
+// lockAllShards takes every shard's exclusive lock, in order, for operations that
+// must change all users at once
+func (lb *Leaderboard) lockAllShards() func() {
+	unlocks := make([]func(), 0, shardCount)
+	for _, shard := range lb.shards {
+		unlocks = append(unlocks, lb.lockShard(shard))
+	}
+	return func() {
+		for _, unlock := range unlocks {
+			unlock()
+		}
+	}
+}
+
+// rlockAllShards takes every shard's shared lock, in order, to read all users at
+// one consistent point
+func (lb *Leaderboard) rlockAllShards() func() {
+	unlocks := make([]func(), 0, shardCount)
+	for _, shard := range lb.shards {
+		unlocks = append(unlocks, lb.rlockShard(shard))
+	}
+	return func() {
+		for _, unlock := range unlocks {
+			unlock()
+		}
+	}
+}
+
+// ratingOf returns a user's current rating
+func (lb *Leaderboard) ratingOf(username string) (int64, bool) {
+	shard := lb.shardFor(username)
+	unlock := lb.rlockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
+	if !exists {
+		return 0, false
+	}
+	return user.Rating, true
+}
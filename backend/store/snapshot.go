@@ -0,0 +1,215 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard-api/models"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// snapshotFormatVersion is bumped whenever the on-disk layout changes incompatibly.
+// Version 2 added columnar snapshots; row snapshots are still written as version 1.
+const (
+	snapshotFormatVersion         = 1
+	columnarSnapshotFormatVersion = 2
+)
+
+// snapshotFile is the on-disk JSON representation of a leaderboard
+type snapshotFile struct {
+	FormatVersion int                  `json:"formatVersion"`
+	TakenAt       time.Time            `json:"takenAt"`
+	Metadata      models.BoardMetadata `json:"metadata"`
+	Users         []models.User        `json:"users,omitempty"`
+	Columns       *snapshotColumns     `json:"columns,omitempty"`  // users, on columnar boards
+	Inactive      []models.User        `json:"inactive,omitempty"` // users archived by expiry
+	Records       *models.Records      `json:"records,omitempty"`
+}
+
+// users returns the snapshot's users in whichever layout they were written
+func (s *snapshotFile) users() ([]models.User, error) {
+	if s.FormatVersion > columnarSnapshotFormatVersion {
+		return nil, fmt.Errorf("snapshot format version %d is newer than this server supports", s.FormatVersion)
+	}
+	if s.Columns != nil {
+		return decodeColumns(s.Columns)
+	}
+	return s.Users, nil
+}
+
+// SaveSnapshot writes the leaderboard's users to path as JSON. The file is written to a
+// temporary sibling and renamed into place so a crash never leaves a partial snapshot.
+func (lb *Leaderboard) SaveSnapshot(path string) error {
+	// Taken before copying, so every change dated earlier is in the snapshot
+	takenAt := time.Now()
+	unlock := lb.lockRead()
+	v := lb.copyLocked()
+	inactive := make([]models.User, 0, len(lb.inactive))
+	for _, user := range lb.inactive {
+		inactive = append(inactive, *user)
+	}
+	unlock()
+	records := lb.Records()
+
+	snapshot := snapshotFile{
+		FormatVersion: snapshotFormatVersion,
+		TakenAt:       takenAt,
+		Metadata:      v.meta,
+		Users:         v.users,
+		Inactive:      inactive,
+		Records:       &records,
+	}
+	if v.meta.Layout == models.LayoutColumnar {
+		snapshot.FormatVersion = columnarSnapshotFormatVersion
+		snapshot.Users = nil
+		snapshot.Columns = encodeColumns(v.users)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(snapshot); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot adds the users stored at path to the leaderboard and returns them.
+// A missing file is reported via os.IsNotExist on the returned error.
+func (lb *Leaderboard) LoadSnapshot(path string) ([]*models.User, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var snapshot snapshotFile
+	if err := json.NewDecoder(file).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	stored, err := snapshot.users()
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*models.User, 0, len(stored))
+	for i := range stored {
+		users = append(users, &stored[i])
+	}
+	lb.BulkAddUsers(users)
+
+	unlock := lb.lockWrite()
+	for i := range snapshot.Inactive {
+		user := &snapshot.Inactive[i]
+		if _, active := lb.ratingOf(user.Username); !active {
+			lb.inactive[user.Username] = user
+		}
+	}
+	unlock()
+
+	if snapshot.Records != nil {
+		lb.restoreRecords(*snapshot.Records)
+	}
+	return users, nil
+}
+
+// Snapshotter periodically saves a leaderboard snapshot to disk
+type Snapshotter struct {
+	leaderboard *Leaderboard
+	path        string
+	interval    time.Duration
+	stopChan    chan struct{}
+	done        chan struct{}
+	once        sync.Once
+
+	mu    sync.Mutex // guards stats
+	stats models.SnapshotStats
+}
+
+// NewSnapshotter creates a snapshotter writing lb to path
+func NewSnapshotter(lb *Leaderboard, path string) *Snapshotter {
+	return &Snapshotter{
+		leaderboard: lb,
+		path:        path,
+		stopChan:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start begins saving a snapshot every interval
+func (s *Snapshotter) Start(interval time.Duration) {
+	s.mu.Lock()
+	s.interval = interval
+	s.mu.Unlock()
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.save()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts periodic snapshots and writes one final snapshot
+func (s *Snapshotter) Stop() {
+	s.once.Do(func() {
+		close(s.stopChan)
+		<-s.done
+		s.save()
+	})
+}
+
+// save writes a snapshot, logging rather than failing on errors
+func (s *Snapshotter) save() {
+	start := time.Now()
+	version := s.leaderboard.Version()
+	err := s.leaderboard.SaveSnapshot(s.path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.stats.Failures++
+		s.stats.LastError = err.Error()
+		log.Printf("Snapshot to %s failed: %v", s.path, err)
+		return
+	}
+	s.stats.Saves++
+	s.stats.LastError = ""
+	s.stats.LastSavedAt = &start
+	s.stats.LastVersion = version
+	s.stats.LastDurationMs = float64(time.Since(start).Microseconds()) / 1000
+	log.Printf("Snapshot saved to %s in %v", s.path, time.Since(start))
+}
+
+// Stats reports how snapshots have gone and how far the board has moved past the last
+// one saved
+func (s *Snapshotter) Stats() models.SnapshotStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	stats.Path = s.path
+	stats.IntervalMs = float64(s.interval.Milliseconds())
+	if stats.LastSavedAt != nil {
+		stats.SinceLastSaveMs = float64(time.Since(*stats.LastSavedAt).Milliseconds())
+	}
+	stats.PendingWrites = s.leaderboard.Version() - stats.LastVersion
+	return stats
+}
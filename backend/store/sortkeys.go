@@ -0,0 +1,121 @@
+package store
+
+import (
+	"fmt"
+	"leaderboard-api/models"
+	"strings"
+)
+
+// ParseSortKeys parses a spec like "wins:desc,losses:asc,time:asc" into sort keys.
+// The direction defaults to descending when omitted.
+func ParseSortKeys(spec string) ([]models.SortKey, error) {
+	keys := make([]models.SortKey, 0)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, dir, _ := strings.Cut(part, ":")
+		key := models.SortKey{Field: strings.TrimSpace(field), Desc: true}
+		switch strings.ToLower(strings.TrimSpace(dir)) {
+		case "", "desc":
+		case "asc":
+			key.Desc = false
+		default:
+			return nil, fmt.Errorf("invalid sort direction %q for field %q", dir, field)
+		}
+		if key.Field == "" {
+			return nil, fmt.Errorf("empty sort field in %q", spec)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// fieldValue returns the value of a sort field for a user. "rating", "gamesPlayed",
+// "wins" and "winRate" (in basis points, 0 with no games) are built in; any other
+// name refers to a plugin score (missing scores count as 0).
+func fieldValue(user *models.User, field string) int64 {
+	switch field {
+	case "rating":
+		return user.Rating
+	case "gamesPlayed":
+		return int64(user.GamesPlayed)
+	case "wins":
+		return int64(user.Wins)
+	case "winRate":
+		if user.GamesPlayed == 0 {
+			return 0
+		}
+		return int64(user.Wins) * 10000 / int64(user.GamesPlayed)
+	}
+	return int64(user.Scores[field])
+}
+
+// compareComposite orders two users by the configured sort keys.
+// Returns a negative number when a ranks ahead of b, positive when behind, 0 when tied.
+func compareComposite(keys []models.SortKey, a, b *models.User) int {
+	for _, key := range keys {
+		va, vb := fieldValue(a, key.Field), fieldValue(b, key.Field)
+		if va == vb {
+			continue
+		}
+		if (va > vb) == key.Desc {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// UpdateScores merges plugin score fields into a user's record
+func (lb *Leaderboard) UpdateScores(username string, scores map[string]int) bool {
+	shard := lb.shardFor(username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
+	if !exists {
+		return false
+	}
+
+	if user.Scores == nil {
+		user.Scores = make(map[string]int, len(scores))
+	}
+	for field, value := range scores {
+		user.Scores[field] = value
+	}
+
+	lb.version.Add(1)
+	return true
+}
+
+// UpdateGameStats sets a user's games played and wins
+func (lb *Leaderboard) UpdateGameStats(username string, gamesPlayed, wins int) bool {
+	shard := lb.shardFor(username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
+	if !exists {
+		return false
+	}
+
+	user.GamesPlayed = gamesPlayed
+	user.Wins = wins
+
+	lb.version.Add(1)
+	return true
+}
+
+// copyScores returns a copy of a plugin score map safe to hand out after unlocking
+func copyScores(scores map[string]int) map[string]int {
+	if len(scores) == 0 {
+		return nil
+	}
+	copied := make(map[string]int, len(scores))
+	for field, value := range scores {
+		copied[field] = value
+	}
+	return copied
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"sync"
+	"time"
+)
+
+// StatsRecorder samples a board's stats every interval into a ring of fixed capacity,
+// so activity over the retained span can be charted; the oldest sample is overwritten
+// once the ring is full
+type StatsRecorder struct {
+	board    Store
+	interval time.Duration
+
+	mu      sync.Mutex // guards the ring and the previous write count
+	samples []models.StatsSample
+	next    int // slot the next sample goes in
+	full    bool
+	writes  uint64
+	at      time.Time // when writes was read
+
+	stopChan chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewStatsRecorder creates a recorder keeping the last capacity samples of board
+func NewStatsRecorder(board Store, capacity int) *StatsRecorder {
+	return &StatsRecorder{
+		board:    board,
+		samples:  make([]models.StatsSample, max(capacity, 1)),
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start takes a sample every interval. The first only sets the baseline write rates
+// are measured from.
+func (s *StatsRecorder) Start(interval time.Duration) {
+	s.mu.Lock()
+	s.interval = interval
+	s.writes, s.at = writesOf(s.board), time.Now()
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.sample()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts sampling; the samples taken stay readable
+func (s *StatsRecorder) Stop() {
+	s.once.Do(func() {
+		close(s.stopChan)
+		<-s.done
+	})
+}
+
+// Interval returns how often samples are taken
+func (s *StatsRecorder) Interval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interval
+}
+
+// Retention returns the span a full ring covers
+func (s *StatsRecorder) Retention() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interval * time.Duration(len(s.samples))
+}
+
+// History returns the samples taken at or after since, oldest first
+func (s *StatsRecorder) History(since time.Time) []models.StatsSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, first := s.next, 0
+	if s.full {
+		n, first = len(s.samples), s.next
+	}
+	history := make([]models.StatsSample, 0, n)
+	for i := 0; i < n; i++ {
+		sample := s.samples[(first+i)%len(s.samples)]
+		if !sample.At.Before(since) {
+			history = append(history, sample)
+		}
+	}
+	return history
+}
+
+// sample records the board's stats now and the rate it was written to since the last
+func (s *StatsRecorder) sample() {
+	stats := s.board.GetStats()
+	writes, now := writesOf(s.board), time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sample := models.StatsSample{
+		At:         now,
+		TotalUsers: stats.TotalUsers,
+		AvgRating:  stats.Mean,
+		MinRating:  stats.MinRating,
+		MaxRating:  stats.MaxRating,
+	}
+	if elapsed := now.Sub(s.at).Seconds(); elapsed > 0 && writes >= s.writes {
+		sample.UpdatesPerSec = float64(writes-s.writes) / elapsed
+	}
+	s.writes, s.at = writes, now
+
+	s.samples[s.next] = sample
+	s.next++
+	if s.next == len(s.samples) {
+		s.next, s.full = 0, true
+	}
+}
+
+// writesOf counts the writes made to board so far. An in-memory board's own counter
+// keeps moving while it is frozen or published in the background, where Version
+// doesn't.
+func writesOf(board Store) uint64 {
+	if lb, ok := board.(*Leaderboard); ok {
+		return lb.version.Load()
+	}
+	return board.Version()
+}
@@ -0,0 +1,353 @@
+package store
+
+import (
+	"context"
+	"iter"
+	"leaderboard-api/models"
+	"time"
+)
+
+// Store is the set of board operations the HTTP handlers rely on. Leaderboard keeps a
+// board in memory; RedisLeaderboard shares one between API instances.
+type Store interface {
+	// Metadata returns the board's name and score semantics
+	Metadata() models.BoardMetadata
+
+	AddUser(user *models.User) error // ErrUserExists if the username is taken
+	BulkAddUsers(users []*models.User)
+	UpdateRating(username string, newRating int64) bool
+	UpdateScores(username string, scores map[string]int) bool
+	RemoveUser(username string) bool
+
+	GetLeaderboard(limit, offset int) []models.LeaderboardEntry
+	GetRankRange(fromRank, toRank int) []models.LeaderboardEntry
+	GetUserRank(username string) (*models.SearchResult, bool)
+	// CompetitionRank returns the standard competition rank for a rating: the number
+	// of users rated strictly higher, plus one
+	CompetitionRank(rating int64) int
+	SearchUsers(query string, limit int) []models.SearchResult
+	SuggestUsernames(prefix string, limit int) []string
+	GetRandomUser(index int) *models.User
+	GetTotalUsers() int
+	GetStats() models.StatsResponse
+
+	// Version changes whenever the board is modified
+	Version() uint64
+}
+
+// WindowedStore is implemented by stores that track rating gains over time
+type WindowedStore interface {
+	GetWindowLeaderboard(window string, limit, offset int) ([]models.WindowEntry, int, error)
+}
+
+// TimeTravelStore is implemented by stores that can say where a user stood in the past
+type TimeTravelStore interface {
+	RankAt(username string, at time.Time) (rank *models.HistoricalRank, found bool)
+}
+
+// MoversStore is implemented by stores that report the biggest rating gains and
+// losses over a recent window
+type MoversStore interface {
+	Movers(window time.Duration, limit int) (gainers, losers []models.WindowEntry)
+}
+
+// SeriesStore is implemented by stores that run promotion/demotion series
+type SeriesStore interface {
+	GetSeries(username string) (*models.SeriesState, bool)
+	SeriesEventsSince(seq uint64) ([]models.SeriesEvent, uint64)
+}
+
+// RankingStore is implemented by stores that can rank ties by any models.Ranking* mode
+type RankingStore interface {
+	// WithRanking returns a handle onto the same store that ranks by mode
+	WithRanking(mode string) Store
+}
+
+// HistoryStore is implemented by stores that keep each user's recent rating changes
+type HistoryStore interface {
+	// GetHistory returns up to limit of a user's most recent changes, oldest first
+	GetHistory(username string, limit int) ([]models.RatingChange, bool)
+}
+
+// SubmissionStore is implemented by stores that accept timestamped submissions from
+// client offline queues
+type SubmissionStore interface {
+	ApplySubmissions(subs []models.Submission) []models.SubmissionResult
+}
+
+// TransactionalStore is implemented by stores that change several users' ratings as
+// one step, computed from the ratings they hold at that moment
+type TransactionalStore interface {
+	// TransformRatings passes the users' current ratings to transform and stores the
+	// ratings it returns, all or none. It fails with an error wrapping ErrUserNotFound
+	// or a *ValidationError without changing anything. A transform that returns nil
+	// cancels the change, and TransformRatings fails without writing.
+	TransformRatings(usernames []string, transform func(ratings []int64) []int64) (before, after []int64, err error)
+}
+
+// ThresholdStore is implemented by stores that can say what rating a rank takes
+type ThresholdStore interface {
+	// RatingAtRank returns the rating of the user in position rank (from 1); false
+	// past the last user
+	RatingAtRank(rank int) (int64, bool)
+}
+
+// CaseInsensitiveStore is implemented by stores that look users up regardless of the
+// case of their usernames
+type CaseInsensitiveStore interface {
+	// LookupUser is GetUserRank ignoring case, returning the username as stored
+	LookupUser(username string) (*models.SearchResult, bool)
+}
+
+// ExternalIDStore is implemented by stores that index users by the IDs game backends
+// know them by
+type ExternalIDStore interface {
+	// ExternalIDHolder returns the username holding externalID, if any
+	ExternalIDHolder(externalID string) (string, bool)
+
+	UserByExternalID(externalID string) (*models.SearchResult, bool)
+}
+
+// BaselineStore is implemented by stores whose entries carry rank and rating deltas
+// measured against earlier standings
+type BaselineStore interface {
+	// BaselineGeneration changes whenever the standings deltas are measured against do
+	BaselineGeneration() uint64
+}
+
+// BatchStore is implemented by stores that apply a batch of ratings as one
+// transaction, reporting the outcome of each entry
+type BatchStore interface {
+	UpdateRatings(updates []models.ScoreUpdate) []models.ScoreResult
+}
+
+// GameStatsStore is implemented by stores that keep each user's games played and
+// wins, for use as secondary sort keys
+type GameStatsStore interface {
+	UpdateGameStats(username string, gamesPlayed, wins int) bool
+}
+
+// CompositeStore is implemented by stores that can compute ratings as a weighted sum
+// of submitted metrics
+type CompositeStore interface {
+	// UpdateMetrics merges metrics into a user's record and recomputes their rating;
+	// it reports false if the user doesn't exist
+	UpdateMetrics(username string, metrics map[string]float64) (bool, error)
+}
+
+// ProfileStore is implemented by stores that hold users' country, avatar and display
+// name
+type ProfileStore interface {
+	// UpdateProfile applies update to a user's profile; it reports false if the user
+	// doesn't exist, ErrInvalidProfile if a field is malformed and ErrDisplayNameTaken
+	// if the display name must be unique and isn't
+	UpdateProfile(username string, update models.ProfileUpdate) (bool, error)
+}
+
+// CountryStore is implemented by stores that index users by country
+type CountryStore interface {
+	// GetCountryLeaderboard returns a page of the standings among one country's
+	// users, ranked within the country, and how many users the country has
+	GetCountryLeaderboard(country string, limit, offset int) ([]models.LeaderboardEntry, int)
+}
+
+// TierStore is implemented by stores that index users by ladder tier
+type TierStore interface {
+	// GetTierLeaderboard returns a page of the standings among one tier's users,
+	// ranked within the tier, and how many users the tier holds
+	GetTierLeaderboard(tier string, limit, offset int) ([]models.LeaderboardEntry, int)
+}
+
+// SubsetStore is implemented by stores that can rank an arbitrary set of users
+// against each other
+type SubsetStore interface {
+	// GetSubset ranks the named users within the set; it also returns the usernames
+	// that aren't on the board
+	GetSubset(usernames []string) ([]models.LeaderboardEntry, []string)
+}
+
+// CertifyingStore is implemented by stores that can certify users' standings
+type CertifyingStore interface {
+	// Certify returns unattested certificates for the named users, all as of one
+	// moment, and the usernames that aren't on the board
+	Certify(usernames []string) ([]models.Certificate, []string)
+}
+
+// CursorStore is implemented by stores that can page by keyset cursors, which stay
+// stable while ratings change between requests
+type CursorStore interface {
+	// GetLeaderboardAfter returns up to limit entries after cursor (from the top when
+	// empty) and the cursor for the next page, empty at the end of the board
+	GetLeaderboardAfter(cursor string, limit int) ([]models.LeaderboardEntry, string, error)
+}
+
+// ObservableStore is implemented by stores that push every change to subscribers
+type ObservableStore interface {
+	// Subscribe calls fn for each user added, updated or removed until unsubscribed
+	Subscribe(fn func(models.ChangeEvent)) (unsubscribe func())
+
+	// DroppedChanges counts changes subscribers missed by falling behind
+	DroppedChanges() uint64
+
+	// WithSource returns a handle onto the same store whose changes are attributed
+	// to source and actor
+	WithSource(source, actor string) Store
+}
+
+// HookableStore is implemented by stores that call back embedding applications on
+// particular kinds of change, each until the returned function is called
+type HookableStore interface {
+	OnRatingChanged(fn func(models.ChangeEvent)) (unsubscribe func())
+	OnUserAdded(fn func(models.ChangeEvent)) (unsubscribe func())
+	OnRankCacheRebuilt(fn func(models.RankCacheRebuild)) (unsubscribe func())
+}
+
+// GoalStore is implemented by stores that let users set rating or rank goals
+type GoalStore interface {
+	// SetGoal replaces a user's goal; found is false if the user isn't on the board
+	SetGoal(username, kind string, target int64) (progress *models.GoalProgress, found bool, err error)
+	ClearGoal(username string) bool
+	GetGoal(username string) (*models.GoalProgress, bool)
+}
+
+// MilestoneStore is implemented by stores that record milestone events at write time
+type MilestoneStore interface {
+	// MilestoneEventsSince returns events after seq and the latest sequence number
+	MilestoneEventsSince(seq uint64) ([]models.MilestoneEvent, uint64)
+}
+
+// LayoutStore is implemented by stores that can switch between row and columnar
+// layouts at runtime
+type LayoutStore interface {
+	SetLayout(layout string) error
+}
+
+// QueryStore is implemented by stores that can answer analytics queries
+type QueryStore interface {
+	Query(q models.Query) (models.QueryResult, error)
+}
+
+// ExportingStore is implemented by stores that can walk every user from one point in
+// time for bulk export
+type ExportingStore interface {
+	// Export yields each user in standings order with Rank set, stopping with ctx's
+	// error once ctx is done
+	Export(ctx context.Context) iter.Seq2[models.User, error]
+}
+
+// FreezableStore is implemented by stores whose standings can be frozen while writes
+// carry on, as contests do near their end
+type FreezableStore interface {
+	// Freeze and Unfreeze report false when the board is already in that state
+	Freeze() bool
+	Unfreeze() bool
+	FreezeStatus() models.FreezeStatus
+
+	// Live returns a handle onto the same store that reads through a freeze
+	Live() Store
+}
+
+// StampedStore is implemented by stores that remember when recent versions were
+// reached, so stream deliveries can report how long writes took to propagate
+type StampedStore interface {
+	VersionTime(version uint64) (t time.Time, ok bool)
+}
+
+// CachingStore is implemented by stores that cache search results
+type CachingStore interface {
+	SearchCacheStats() models.CacheStats
+}
+
+// LockTracedStore is implemented by stores that time their internal locks and can
+// attribute those timings to a request
+type LockTracedStore interface {
+	// WithLockTrace returns a handle onto the same store that also records into t
+	WithLockTrace(t *LockTrace) Store
+	LockStats() map[string]models.LockStats
+}
+
+// InactiveStore is implemented by stores that archive users evicted for inactivity
+type InactiveStore interface {
+	InactiveUsers(limit, offset int) ([]models.User, int)
+	RestoreUser(username string) bool
+}
+
+// WarmingStore is implemented by stores that build read structures lazily and can
+// build them ahead of the first request
+type WarmingStore interface {
+	WarmUp()
+}
+
+// CompactingStore is implemented by stores that can rebuild their structures to give
+// back memory retained after churn
+type CompactingStore interface {
+	Compact() models.CompactionReport
+}
+
+// ValidatingStore is implemented by stores that check the ratings clients write against
+// the board's validation rules and any registered Validators
+type ValidatingStore interface {
+	// CheckRating returns the *ValidationError writing rating for username would hit,
+	// or nil; previous is the rating it replaces, nil for a user joining
+	CheckRating(username string, previous *int64, rating int64) error
+
+	// UpdateRatingChecked is UpdateRating that refuses a rating breaking a rule with
+	// a *ValidationError; it reports false if the user doesn't exist
+	UpdateRatingChecked(username string, newRating int64) (bool, error)
+}
+
+// IncrementingStore is implemented by stores that apply relative rating changes
+// atomically, for callers that know a match's delta rather than the new rating
+type IncrementingStore interface {
+	// IncrementRating adds delta to the user's rating, returning the rating stored. It
+	// reports false if the user doesn't exist, and fails with ErrRatingOverflow or a
+	// *ValidationError without changing anything.
+	IncrementRating(username string, delta int64) (int64, bool, error)
+}
+
+var (
+	_ Store           = (*Leaderboard)(nil)
+	_ WindowedStore   = (*Leaderboard)(nil)
+	_ SeriesStore     = (*Leaderboard)(nil)
+	_ RankingStore    = (*Leaderboard)(nil)
+	_ HistoryStore    = (*Leaderboard)(nil)
+	_ SubmissionStore = (*Leaderboard)(nil)
+	_ GameStatsStore  = (*Leaderboard)(nil)
+	_ CompositeStore  = (*Leaderboard)(nil)
+	_ ProfileStore    = (*Leaderboard)(nil)
+	_ CountryStore    = (*Leaderboard)(nil)
+	_ TierStore       = (*Leaderboard)(nil)
+	_ SubsetStore     = (*Leaderboard)(nil)
+	_ CertifyingStore = (*Leaderboard)(nil)
+	_ CursorStore     = (*Leaderboard)(nil)
+	_ ObservableStore = (*Leaderboard)(nil)
+	_ MilestoneStore  = (*Leaderboard)(nil)
+	_ GoalStore       = (*Leaderboard)(nil)
+	_ LayoutStore     = (*Leaderboard)(nil)
+	_ QueryStore      = (*Leaderboard)(nil)
+	_ ExportingStore  = (*Leaderboard)(nil)
+	_ FreezableStore  = (*Leaderboard)(nil)
+	_ CachingStore    = (*Leaderboard)(nil)
+	_ LockTracedStore = (*Leaderboard)(nil)
+	_ CompactingStore = (*Leaderboard)(nil)
+	_ InactiveStore   = (*Leaderboard)(nil)
+	_ WarmingStore    = (*Leaderboard)(nil)
+	_ ValidatingStore = (*Leaderboard)(nil)
+	_ StampedStore    = (*Leaderboard)(nil)
+
+	_ IncrementingStore    = (*Leaderboard)(nil)
+	_ BatchStore           = (*Leaderboard)(nil)
+	_ TransactionalStore   = (*Leaderboard)(nil)
+	_ ExternalIDStore      = (*Leaderboard)(nil)
+	_ CaseInsensitiveStore = (*Leaderboard)(nil)
+	_ ThresholdStore       = (*Leaderboard)(nil)
+	_ HookableStore        = (*Leaderboard)(nil)
+	_ MoversStore          = (*Leaderboard)(nil)
+	_ TimeTravelStore      = (*Leaderboard)(nil)
+
+	_ Store              = (*RedisLeaderboard)(nil)
+	_ IncrementingStore  = (*RedisLeaderboard)(nil)
+	_ ThresholdStore     = (*RedisLeaderboard)(nil)
+	_ BatchStore         = (*RedisLeaderboard)(nil)
+	_ TransactionalStore = (*RedisLeaderboard)(nil)
+)
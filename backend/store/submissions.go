@@ -0,0 +1,102 @@
+package store
+
+import (
+	"errors"
+	"leaderboard-api/models"
+	"sort"
+	"time"
+)
+
+// maxSubmissionSkew is how far in the future a submission's timestamp may be
+const maxSubmissionSkew = 5 * time.Minute
+
+// submissionMark is the latest submission applied for a user
+type submissionMark struct {
+	at time.Time
+	id string
+}
+
+// ApplySubmissions applies a batch of timestamped ratings from client offline queues.
+// Each user's submissions are applied in timestamp order, and any submission older
+// than one already applied for that user is reported as superseded rather than
+// overwriting the newer rating. Rating gains are credited to the windows covering
+// each submission's timestamp; submissions dated in finalized days are refused.
+// Results are in the order of subs.
+func (lb *Leaderboard) ApplySubmissions(subs []models.Submission) []models.SubmissionResult {
+	results := make([]models.SubmissionResult, len(subs))
+	now := time.Now()
+	rules := lb.Metadata().Validation
+	for _, i := range submissionOrder(subs) {
+		results[i] = lb.applySubmission(subs[i], rules, now)
+	}
+	return results
+}
+
+// submissionOrder returns the indexes of subs grouped by user, oldest first
+func submissionOrder(subs []models.Submission) []int {
+	order := make([]int, len(subs))
+	for i := range subs {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := &subs[order[a]], &subs[order[b]]
+		if sa.Username != sb.Username {
+			return sa.Username < sb.Username
+		}
+		return sa.At.Before(sb.At)
+	})
+	return order
+}
+
+// applySubmission applies one submission under its user's shard lock, if its rating
+// passes rules
+func (lb *Leaderboard) applySubmission(sub models.Submission, rules *models.ValidationRules, now time.Time) models.SubmissionResult {
+	result := models.SubmissionResult{ID: sub.ID, Username: sub.Username, At: sub.At}
+	reject := func(reason string) models.SubmissionResult {
+		result.Status, result.Reason = models.SubmissionRejected, reason
+		return result
+	}
+	switch {
+	case sub.At.IsZero():
+		return reject("missing timestamp")
+	case sub.At.After(now.Add(maxSubmissionSkew)):
+		return reject("timestamp is in the future")
+	}
+	if sub.At.Before(lb.FinalizedBefore()) {
+		result.Status, result.Reason = models.SubmissionFinalized, ErrPeriodFinalized.Error()
+		return result
+	}
+
+	shard := lb.shardFor(sub.Username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[sub.Username]
+	if !exists {
+		return reject("user not found")
+	}
+
+	last := shard.submitted[sub.Username]
+	switch {
+	case sub.ID != "" && sub.ID == last.id:
+		result.Status = models.SubmissionDuplicate
+		return result
+	case !sub.At.After(last.at):
+		result.Status = models.SubmissionSuperseded
+		return result
+	}
+
+	previous := user.Rating
+	var invalid *ValidationError
+	if err := lb.validate(rules, sub.Username, &previous, sub.Rating); errors.As(err, &invalid) {
+		result.Violation = &invalid.RuleViolation
+		return reject(invalid.Message)
+	}
+
+	lb.setRatingLocked(shard, user, sub.Rating, sub.At)
+	shard.submitted[sub.Username] = submissionMark{at: sub.At, id: sub.ID}
+
+	result.Status, result.Rating = models.SubmissionApplied, user.Rating
+	result.Late = sub.At.Before(now.Truncate(24 * time.Hour))
+	return result
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"sort"
+)
+
+// MaxSubsetSize bounds how many usernames one subset leaderboard may name
+const MaxSubsetSize = 1000
+
+// GetSubset ranks just the named users against each other, such as a player and their
+// friends. Rank is the rank within the subset under the board's ranking mode and
+// GlobalRank the rank on the whole board. Usernames not on the board are returned
+// separately; duplicates count once.
+func (lb *Leaderboard) GetSubset(usernames []string) ([]models.LeaderboardEntry, []string) {
+	v := lb.current()
+	mode := lb.rankingMode(v)
+	base := lb.baselineFor(v)
+
+	positions := make([]int, 0, len(usernames))
+	missing := make([]string, 0)
+	seen := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		if i, exists := v.index[username]; exists {
+			positions = append(positions, i)
+		} else {
+			missing = append(missing, username)
+		}
+	}
+	subset := subsetIndex(v, positions)
+
+	// Movement is measured within the same set of users at the baseline
+	var baseSubset *segmentIndex
+	if base != nil {
+		basePositions := make([]int, 0, len(positions))
+		for _, i := range positions {
+			if j, existed := base.index[v.users[i].Username]; existed {
+				basePositions = append(basePositions, j)
+			}
+		}
+		baseSubset = subsetIndex(base, basePositions)
+	}
+
+	entries := make([]models.LeaderboardEntry, 0, len(positions))
+	for k, i := range subset.positions {
+		entry := v.entry(i, mode)
+		entry.GlobalRank = entry.Rank
+		entry.Rank = subset.rank(k, mode)
+		if base != nil {
+			entry = withSegmentDeltas(entry, base, baseSubset, mode)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, missing
+}
+
+// subsetIndex ranks the users at the given view positions among themselves
+func subsetIndex(v *view, positions []int) *segmentIndex {
+	sort.Ints(positions)
+	subset := &segmentIndex{}
+	for _, i := range positions {
+		subset.add(v, i)
+	}
+	subset.finish()
+	return subset
+}
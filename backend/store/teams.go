@@ -0,0 +1,288 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrTeamExists   = errors.New("team already exists")
+	ErrTeamNotFound = errors.New("team not found")
+)
+
+// Ways a team's score is aggregated from its members' ratings
+const (
+	TeamScoreSum = "sum"
+	TeamScoreAvg = "avg"
+	TeamScoreTop = "top" // sum of the best TopN members
+)
+
+// TeamScoring says how a team's score is computed from its members' ratings
+type TeamScoring struct {
+	Mode string
+	TopN int // for TeamScoreTop
+}
+
+// ParseTeamScoring parses "sum", "avg" or "top:N"; an empty spec means sum
+func ParseTeamScoring(spec string) (TeamScoring, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	switch spec {
+	case "", TeamScoreSum:
+		return TeamScoring{Mode: TeamScoreSum}, nil
+	case TeamScoreAvg:
+		return TeamScoring{Mode: TeamScoreAvg}, nil
+	}
+	if n, ok := strings.CutPrefix(spec, TeamScoreTop+":"); ok {
+		if top, err := strconv.Atoi(n); err == nil && top > 0 {
+			return TeamScoring{Mode: TeamScoreTop, TopN: top}, nil
+		}
+	}
+	return TeamScoring{}, fmt.Errorf("unknown team scoring %q (want sum, avg or top:N)", spec)
+}
+
+// String renders the scoring the way ParseTeamScoring reads it
+func (s TeamScoring) String() string {
+	if s.Mode == TeamScoreTop {
+		return fmt.Sprintf("%s:%d", TeamScoreTop, s.TopN)
+	}
+	return s.Mode
+}
+
+// score aggregates member ratings, best first
+func (s TeamScoring) score(ratings []int64) int64 {
+	if len(ratings) == 0 {
+		return 0
+	}
+	if s.Mode == TeamScoreTop && len(ratings) > s.TopN {
+		ratings = ratings[:s.TopN]
+	}
+	var sum int64
+	for _, rating := range ratings {
+		sum += rating
+	}
+	if s.Mode == TeamScoreAvg {
+		return sum / int64(len(ratings))
+	}
+	return sum
+}
+
+// TeamLeaderboard ranks teams (clans) by a score aggregated from their members'
+// ratings on a source board. A user belongs to at most one team. Team scores are kept
+// on an inner Leaderboard keyed by team name and brought up to date with the source
+// board whenever it has changed since they were last computed.
+type TeamLeaderboard struct {
+	mu sync.Mutex
+
+	source  *Leaderboard
+	scoring TeamScoring
+
+	// Inner board keyed by team name
+	board *Leaderboard
+
+	// Members of each team, and the team each user belongs to
+	members map[string]map[string]bool
+	teamOf  map[string]string
+
+	// Source version the team scores were computed from; 0 forces a recompute
+	computedAt uint64
+}
+
+// NewTeamLeaderboard creates a team board aggregating member ratings from source
+func NewTeamLeaderboard(source *Leaderboard, scoring TeamScoring) *TeamLeaderboard {
+	board := NewLeaderboard()
+	board.SetMetadata(models.BoardMetadata{
+		Name:        "teams",
+		Description: fmt.Sprintf("Teams scored by the %s of member ratings", scoring),
+		ScoreFormat: source.Metadata().ScoreFormat,
+	})
+
+	return &TeamLeaderboard{
+		source:  source,
+		scoring: scoring,
+		board:   board,
+		members: make(map[string]map[string]bool),
+		teamOf:  make(map[string]string),
+	}
+}
+
+// Scoring returns how team scores are aggregated
+func (tl *TeamLeaderboard) Scoring() TeamScoring {
+	return tl.scoring
+}
+
+// Metadata returns the team board's name and score semantics
+func (tl *TeamLeaderboard) Metadata() models.BoardMetadata {
+	return tl.board.Metadata()
+}
+
+// CreateTeam registers a team with its initial members. Members already on another
+// team move to this one.
+func (tl *TeamLeaderboard) CreateTeam(name string, members []string) error {
+	if name == "" {
+		return errors.New("team name must not be empty")
+	}
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if _, exists := tl.members[name]; exists {
+		return ErrTeamExists
+	}
+	tl.members[name] = make(map[string]bool, len(members))
+	for _, username := range members {
+		if username != "" {
+			tl.joinLocked(name, username)
+		}
+	}
+	tl.board.AddUser(&models.User{ID: name, Username: name})
+	tl.computedAt = 0
+	return nil
+}
+
+// DeleteTeam removes a team; its members become teamless
+func (tl *TeamLeaderboard) DeleteTeam(name string) bool {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	members, exists := tl.members[name]
+	if !exists {
+		return false
+	}
+	for username := range members {
+		delete(tl.teamOf, username)
+	}
+	delete(tl.members, name)
+	tl.board.RemoveUser(name)
+	return true
+}
+
+// Join puts a user on a team, leaving any team they were on
+func (tl *TeamLeaderboard) Join(name, username string) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if _, exists := tl.members[name]; !exists {
+		return ErrTeamNotFound
+	}
+	tl.joinLocked(name, username)
+	tl.computedAt = 0
+	return nil
+}
+
+// joinLocked moves a user onto a team; caller must hold tl.mu
+func (tl *TeamLeaderboard) joinLocked(name, username string) {
+	if previous, on := tl.teamOf[username]; on {
+		delete(tl.members[previous], username)
+	}
+	tl.members[name][username] = true
+	tl.teamOf[username] = name
+}
+
+// Leave takes a user off a team. It reports false if they weren't on it.
+func (tl *TeamLeaderboard) Leave(name, username string) bool {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if tl.teamOf[username] != name {
+		return false
+	}
+	delete(tl.members[name], username)
+	delete(tl.teamOf, username)
+	tl.computedAt = 0
+	return true
+}
+
+// TeamOf returns the team a user belongs to
+func (tl *TeamLeaderboard) TeamOf(username string) (string, bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	name, on := tl.teamOf[username]
+	return name, on
+}
+
+// refreshLocked recomputes team scores if the source board has moved since they were
+// last computed. Every score comes from one view of the source, so teams are always
+// compared on the same standings. Caller must hold tl.mu.
+func (tl *TeamLeaderboard) refreshLocked() {
+	v := tl.source.current()
+	if tl.computedAt != 0 && tl.computedAt == v.version {
+		return
+	}
+
+	ratings := make([]int64, 0)
+	for name, members := range tl.members {
+		ratings = ratings[:0]
+		for username := range members {
+			// Members not on the source board (yet) don't count
+			if i, on := v.index[username]; on {
+				ratings = append(ratings, v.users[i].Rating)
+			}
+		}
+		sort.Slice(ratings, func(i, j int) bool { return ratings[i] > ratings[j] })
+
+		score := tl.scoring.score(ratings)
+		if current, _ := tl.board.ratingOf(name); current != score {
+			tl.board.UpdateRating(name, score)
+		}
+	}
+	tl.computedAt = v.version
+}
+
+// GetLeaderboard returns a page of teams, best first, and how many teams there are
+func (tl *TeamLeaderboard) GetLeaderboard(limit, offset int) ([]models.TeamEntry, int) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.refreshLocked()
+
+	entries := tl.board.GetLeaderboard(limit, offset)
+	results := make([]models.TeamEntry, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, tl.entryLocked(entry.Username, entry.Rank, entry.Rating, entry.Display))
+	}
+	return results, tl.board.GetTotalUsers()
+}
+
+// GetTeam returns a single team with its rank and members
+func (tl *TeamLeaderboard) GetTeam(name string) (*models.TeamEntry, bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.refreshLocked()
+
+	if _, exists := tl.members[name]; !exists {
+		return nil, false
+	}
+	rank, found := tl.board.GetUserRank(name)
+	if !found {
+		return nil, false
+	}
+	entry := tl.entryLocked(name, rank.GlobalRank, rank.Rating, rank.Display)
+	entry.Members = tl.sortedMembersLocked(name)
+	return &entry, true
+}
+
+// entryLocked builds a ranked entry for a team; caller must hold tl.mu
+func (tl *TeamLeaderboard) entryLocked(name string, rank int, rating int64, display string) models.TeamEntry {
+	return models.TeamEntry{
+		Rank:        rank,
+		Team:        name,
+		MemberCount: len(tl.members[name]),
+		Rating:      rating,
+		Display:     display,
+	}
+}
+
+// sortedMembersLocked lists a team's members alphabetically; caller must hold tl.mu
+func (tl *TeamLeaderboard) sortedMembersLocked(name string) []string {
+	members := make([]string, 0, len(tl.members[name]))
+	for username := range tl.members[name] {
+		members = append(members, username)
+	}
+	sort.Strings(members)
+	return members
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"strings"
+)
+
+// Tier is a named rating band on the ladder
+type Tier struct {
+	Name      string `json:"name"`
+	MinRating int64  `json:"minRating"`
+}
+
+// Tiers lists the ladder tiers in ascending order of rating, in whole points; boards
+// holding fractional points scale the thresholds by their score format
+var Tiers = []Tier{
+	{Name: "bronze", MinRating: 100},
+	{Name: "silver", MinRating: 1200},
+	{Name: "gold", MinRating: 1800},
+	{Name: "platinum", MinRating: 2400},
+	{Name: "diamond", MinRating: 3000},
+	{Name: "master", MinRating: 3800},
+}
+
+// tierIndex returns the index into Tiers for a rating held in units of 1/scale point
+func tierIndex(rating, scale int64) int {
+	idx := 0
+	for i, tier := range Tiers {
+		if rating >= tier.MinRating*scale {
+			idx = i
+		}
+	}
+	return idx
+}
+
+// TierForRating returns the tier name a rating on a board with format falls into
+func TierForRating(format models.ScoreFormat, rating int64) string {
+	return Tiers[tierIndex(rating, ScaleRating(format, 1))].Name
+}
+
+// ParseTier validates a tier name, ignoring case
+func ParseTier(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, tier := range Tiers {
+		if tier.Name == name {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// GetTierLeaderboard returns a page of the standings among users in tier and how many
+// users the tier holds. Entries are ranked within the tier; GlobalRank keeps their
+// rank on the whole board.
+func (lb *Leaderboard) GetTierLeaderboard(tier string, limit, offset int) ([]models.LeaderboardEntry, int) {
+	return lb.segmentPage(func(v *view) map[string]*segmentIndex { return v.tiers }, tier, limit, offset)
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"math"
+	"time"
+)
+
+// RankAt reconstructs where username stood at a past moment by winding every user's
+// rating back through their history to at. Users whose history doesn't reach back
+// that far (it keeps their last historySize changes) count at the oldest rating on
+// record and are tallied in Estimated. Users are counted if they are on the board now,
+// so anyone who left since is missing and anyone who joined since is included at
+// their rating before their first change. found is false if username isn't on the
+// board.
+func (lb *Leaderboard) RankAt(username string, at time.Time) (rank *models.HistoricalRank, found bool) {
+	unlock := lb.lockRead()
+	defer unlock()
+	unlockShards := lb.rlockAllShards()
+	defer unlockShards()
+
+	user, found := lb.shardFor(username).users[username]
+	if !found {
+		return nil, false
+	}
+	rating, exact := lb.ratingAtLocked(user, at)
+
+	rank = &models.HistoricalRank{
+		Username:   user.Username,
+		At:         at,
+		Rating:     rating,
+		TotalUsers: len(lb.users),
+		Source:     models.HistoricalFromHistory,
+		Estimate:   !exact,
+	}
+	above := make(map[int64]bool)
+	for _, other := range lb.users {
+		otherRating, otherExact := lb.ratingAtLocked(other, at)
+		if other != user && !otherExact {
+			rank.Estimated++
+		}
+		if otherRating > rating {
+			rank.CompetitionRank++
+			above[otherRating] = true
+		}
+	}
+	rank.Rank = len(above) + 1
+	rank.CompetitionRank++
+	top := float64(rank.CompetitionRank) / float64(rank.TotalUsers) * 100
+	rank.Percentile = math.Round(top*100) / 100
+	return rank, true
+}
+
+// ratingAtLocked winds user's rating back to at through their history, reporting
+// whether the history reached back that far. Caller must hold the user's shard lock.
+func (lb *Leaderboard) ratingAtLocked(user *models.User, at time.Time) (rating int64, exact bool) {
+	rating = user.Rating
+	history, exists := lb.shardFor(user.Username).history[user.Username]
+	if !exists {
+		return rating, true
+	}
+	for i := 1; i <= history.count; i++ {
+		change := history.changes[(history.next-i+historySize)%historySize]
+		if !change.At.After(at) {
+			return rating, true
+		}
+		rating = change.Old
+	}
+	// Every change on record came after at; earlier ones may have been overwritten
+	return rating, history.count < historySize
+}
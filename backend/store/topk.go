@@ -0,0 +1,280 @@
+package store
+
+import (
+	"container/heap"
+	"leaderboard-api/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// topListSize is how many of the best users the top list tracks. Most reads ask for
+// the first page of 50, so this leaves room for users to drop out before a refill.
+const topListSize = 256
+
+// topList keeps the board's best users in ranking order, updated on every rating
+// change, so the first pages are served without building a view. Users who drop below
+// the last one it holds leave it, since someone outside may now rank ahead of them;
+// once too few remain for a read the list is refilled with one pass over the board.
+// Only boards ranked by rating alone are tracked; composite boards always use a view.
+type topList struct {
+	mu sync.Mutex
+
+	// Ranking rules the list is ordered by
+	meta models.BoardMetadata
+
+	// Best first
+	entries []topEntry
+
+	// Whether entries are exactly the board's best len(entries) users, and whether
+	// they are all of its users
+	valid bool
+	all   bool
+}
+
+type topEntry struct {
+	user   *models.User
+	rating int64
+	at     time.Time // when the rating was reached, for the achieved tie-break
+}
+
+func newTopList(meta models.BoardMetadata) *topList {
+	return &topList{meta: meta, valid: true, all: true}
+}
+
+// tracked reports whether the board ranks by rating alone, so the list applies
+func (t *topList) tracked() bool {
+	return len(t.meta.SortKeys) == 0
+}
+
+// less orders entries the way views order users (see view.build)
+func (t *topList) less(a, b *topEntry) bool {
+	if a.rating != b.rating {
+		return a.rating > b.rating
+	}
+	if t.meta.TieBreak == models.TieBreakAchieved && !a.at.Equal(b.at) {
+		return a.at.Before(b.at)
+	}
+	return a.user.Username < b.user.Username
+}
+
+// applies reports whether the list tracks the board under its current ranking rules
+func (t *topList) applies() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tracked()
+}
+
+// setMeta changes the ranking rules, leaving the list to be refilled
+func (t *topList) setMeta(meta models.BoardMetadata) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.meta = meta
+	t.invalidateLocked()
+}
+
+// invalidate drops the list after ratings changed wholesale, leaving it to be refilled
+func (t *topList) invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.invalidateLocked()
+}
+
+func (t *topList) invalidateLocked() {
+	t.entries = nil
+	t.valid, t.all = false, false
+}
+
+// update places user at its current rating, whether it just joined or was already on
+// the board; caller must hold the user's shard lock
+func (t *topList) update(user *models.User) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.valid || !t.tracked() {
+		return
+	}
+
+	t.removeLocked(user)
+	e := topEntry{user: user, rating: user.Rating, at: user.RatingUpdatedAt}
+	n := len(t.entries)
+	if !t.all && (n == 0 || !t.less(&e, &t.entries[n-1])) {
+		// Behind the last user the list holds, where others may rank ahead of it
+		return
+	}
+
+	i := sort.Search(n, func(i int) bool { return t.less(&e, &t.entries[i]) })
+	t.entries = append(t.entries, topEntry{})
+	copy(t.entries[i+1:], t.entries[i:])
+	t.entries[i] = e
+	if len(t.entries) > topListSize {
+		t.entries = t.entries[:topListSize]
+		t.all = false
+	}
+}
+
+// remove takes a user leaving the board off the list; caller must hold its shard lock
+func (t *topList) remove(user *models.User) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removeLocked(user)
+}
+
+func (t *topList) removeLocked(user *models.User) {
+	for i := range t.entries {
+		if t.entries[i].user == user {
+			t.entries = append(t.entries[:i], t.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// prefix copies the best n entries, or all of them on a smaller board, carrying on to
+// the end of the last tie group. closed reports whether that group is known to end
+// there. It reports false when the list can't answer: it is invalid, the board isn't
+// ranked by rating alone, or too many users have left it to cover n.
+func (t *topList) prefix(n int) (entries []topEntry, meta models.BoardMetadata, closed, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.valid || !t.tracked() || (len(t.entries) < n && !t.all) {
+		return nil, t.meta, false, false
+	}
+
+	end := min(n, len(t.entries))
+	for end > 0 && end < len(t.entries) && t.entries[end].rating == t.entries[end-1].rating {
+		end++
+	}
+	return append([]topEntry(nil), t.entries[:end]...), t.meta, t.all || end < len(t.entries), true
+}
+
+// refillTop rebuilds the top list with one pass over the board, keeping the best in
+// a bounded heap rather than sorting everyone. Writers wait for the pass.
+func (lb *Leaderboard) refillTop() {
+	unlock := lb.lockRead()
+	defer unlock()
+	unlockShards := lb.rlockAllShards()
+	defer unlockShards()
+
+	t := lb.top
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.tracked() || (t.valid && len(t.entries) == min(topListSize, len(lb.users))) {
+		// Another reader refilled it meanwhile
+		return
+	}
+
+	worst := &topHeap{list: t}
+	for _, user := range lb.users {
+		e := topEntry{user: user, rating: user.Rating, at: user.RatingUpdatedAt}
+		switch {
+		case worst.Len() < topListSize:
+			heap.Push(worst, e)
+		case t.less(&e, &worst.entries[0]):
+			worst.entries[0] = e
+			heap.Fix(worst, 0)
+		}
+	}
+
+	entries := worst.entries
+	sort.Slice(entries, func(i, j int) bool { return t.less(&entries[i], &entries[j]) })
+	t.entries = entries
+	t.valid, t.all = true, len(entries) == len(lb.users)
+}
+
+// topHeap holds candidates for the top list with the one ranked last on top
+type topHeap struct {
+	list    *topList
+	entries []topEntry
+}
+
+func (h *topHeap) Len() int           { return len(h.entries) }
+func (h *topHeap) Less(i, j int) bool { return h.list.less(&h.entries[j], &h.entries[i]) }
+func (h *topHeap) Swap(i, j int)      { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *topHeap) Push(x any)         { h.entries = append(h.entries, x.(topEntry)) }
+
+func (h *topHeap) Pop() any {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
+
+// topPage serves GetLeaderboard from the top list when it can: the board isn't frozen
+// or served from a background-published view, it ranks by rating alone, and the page
+// lies within the list. Ranks count ties within the list; entries carry the users'
+// other fields as they are now.
+func (lb *Leaderboard) topPage(limit, offset int) ([]models.LeaderboardEntry, bool) {
+	end := offset + limit
+	if limit <= 0 || offset < 0 || end > topListSize || lb.served() != nil {
+		return nil, false
+	}
+	if !lb.top.applies() {
+		// Composite boards are never tracked, and refilling takes every lock only to
+		// find that out
+		return nil, false
+	}
+
+	items, meta, closed, ok := lb.top.prefix(end)
+	if !ok {
+		lb.refillTop()
+		if items, meta, closed, ok = lb.top.prefix(end); !ok {
+			return nil, false
+		}
+	}
+	mode := lb.rankingModeFor(meta)
+	if mode == models.RankingModified && !closed {
+		// The last tie group may run past the list, so its rank is unknown here
+		return nil, false
+	}
+
+	// Dense rank, users strictly ahead and users ahead or tied, as in a view
+	n := len(items)
+	ranks, above, through := make([]int, n), make([]int, n), make([]int, n)
+	for i := range items {
+		switch {
+		case i == 0:
+			ranks[i] = 1
+		case items[i].rating == items[i-1].rating:
+			ranks[i], above[i] = ranks[i-1], above[i-1]
+		default:
+			ranks[i], above[i] = ranks[i-1]+1, i
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		if i == n-1 || items[i+1].rating != items[i].rating {
+			through[i] = i + 1
+		} else {
+			through[i] = through[i+1]
+		}
+	}
+
+	base := lb.liveBaseline()
+	entries := make([]models.LeaderboardEntry, 0, max(min(end, n)-offset, 0))
+	for i := offset; i < min(end, n); i++ {
+		item := &items[i]
+		entry := models.LeaderboardEntry{
+			Username: item.user.Username,
+			Rating:   item.rating,
+			Display:  FormatScore(meta.ScoreFormat, item.rating),
+		}
+		switch mode {
+		case models.RankingStandard:
+			entry.Rank = above[i] + 1
+		case models.RankingModified:
+			entry.Rank = through[i]
+		case models.RankingOrdinal:
+			entry.Rank = i + 1
+		default:
+			entry.Rank = ranks[i]
+		}
+
+		unlock := lb.rlockShard(lb.shardFor(item.user.Username))
+		entry.Profile = item.user.Profile
+		entry.Scores = copyScores(item.user.Scores)
+		entry.Metrics = copyMetrics(item.user.Metrics)
+		entry.GamesPlayed = item.user.GamesPlayed
+		entry.Wins = item.user.Wins
+		unlock()
+
+		entries = append(entries, deltasSince(base, entry, entry.Rank, mode))
+	}
+	return entries, true
+}
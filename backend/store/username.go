@@ -0,0 +1,35 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidUsername wraps every reason a new username is rejected
+var ErrInvalidUsername = errors.New("invalid username")
+
+// maxUsernameLength bounds usernames, in characters
+const maxUsernameLength = 64
+
+// ValidateUsername checks a username chosen for a new user. Usernames appear in URL
+// paths and keys, so they must be printable, without surrounding whitespace or '/'.
+func ValidateUsername(username string) error {
+	switch {
+	case username == "":
+		return fmt.Errorf("%w: username is required", ErrInvalidUsername)
+	case !utf8.ValidString(username):
+		return fmt.Errorf("%w: username is not valid UTF-8", ErrInvalidUsername)
+	case utf8.RuneCountInString(username) > maxUsernameLength:
+		return fmt.Errorf("%w: username is longer than %d characters", ErrInvalidUsername, maxUsernameLength)
+	case strings.TrimSpace(username) != username:
+		return fmt.Errorf("%w: username has leading or trailing whitespace", ErrInvalidUsername)
+	case strings.IndexFunc(username, unicode.IsControl) >= 0:
+		return fmt.Errorf("%w: username contains control characters", ErrInvalidUsername)
+	case strings.Contains(username, "/"):
+		return fmt.Errorf("%w: username contains '/'", ErrInvalidUsername)
+	}
+	return nil
+}
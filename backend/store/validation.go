@@ -0,0 +1,131 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard-api/models"
+	"time"
+)
+
+// ValidationError is returned when a write breaks one of a board's validation rules
+type ValidationError struct {
+	models.RuleViolation
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Validator checks a rating a client wants to write before it is applied. previous
+// is the user's current rating, or nil for a user joining the board. It returns nil
+// to allow the write.
+type Validator func(username string, previous *int64, rating int64) *models.RuleViolation
+
+// ValidateRules checks that a board's validation rules can be met
+func ValidateRules(rules *models.ValidationRules) error {
+	if rules == nil {
+		return nil
+	}
+	if rules.MaxDelta < 0 {
+		return errors.New("validation maxDelta must not be negative")
+	}
+	if rules.MinRating != nil && rules.MaxRating != nil && *rules.MinRating > *rules.MaxRating {
+		return errors.New("validation minRating must not exceed maxRating")
+	}
+	return nil
+}
+
+// checkRules applies a board's own rules to a write
+func checkRules(rules *models.ValidationRules, username string, previous *int64, rating int64) *models.RuleViolation {
+	if rules == nil {
+		return nil
+	}
+	violation := func(rule string, limit *int64, format string, args ...interface{}) *models.RuleViolation {
+		return &models.RuleViolation{
+			Rule:     rule,
+			Username: username,
+			Rating:   rating,
+			Previous: previous,
+			Limit:    limit,
+			Message:  fmt.Sprintf(format, args...),
+		}
+	}
+
+	switch {
+	case rules.MinRating != nil && rating < *rules.MinRating:
+		return violation(models.RuleMinRating, rules.MinRating, "rating %d is below the minimum of %d", rating, *rules.MinRating)
+	case rules.MaxRating != nil && rating > *rules.MaxRating:
+		return violation(models.RuleMaxRating, rules.MaxRating, "rating %d is above the maximum of %d", rating, *rules.MaxRating)
+	case previous == nil:
+		// The remaining rules compare against a rating the user already has
+		return nil
+	case rules.Monotonic && rating < *previous:
+		return violation(models.RuleMonotonic, previous, "rating %d is lower than the current %d and ratings may only rise", rating, *previous)
+	case rules.MaxDelta > 0 && (rating-*previous > rules.MaxDelta || *previous-rating > rules.MaxDelta):
+		limit := rules.MaxDelta
+		return violation(models.RuleMaxDelta, &limit, "rating %d moves %d from the current %d, more than the %d one write may",
+			rating, rating-*previous, *previous, rules.MaxDelta)
+	}
+	return nil
+}
+
+// AddValidator registers a check run on every rating clients write, after the board's
+// own rules. Checks should be quick: they run under the user's shard lock.
+func (lb *Leaderboard) AddValidator(v Validator) {
+	lb.validatorsMu.Lock()
+	defer lb.validatorsMu.Unlock()
+	var validators []Validator
+	if current := lb.validators.Load(); current != nil {
+		validators = append(validators, *current...)
+	}
+	validators = append(validators, v)
+	lb.validators.Store(&validators)
+}
+
+// validate checks a write against rules and every registered validator, returning a
+// *ValidationError for the first it breaks
+func (lb *Leaderboard) validate(rules *models.ValidationRules, username string, previous *int64, rating int64) error {
+	if violation := checkRules(rules, username, previous, rating); violation != nil {
+		return &ValidationError{*violation}
+	}
+	if validators := lb.validators.Load(); validators != nil {
+		for _, v := range *validators {
+			if violation := v(username, previous, rating); violation != nil {
+				if violation.Rule == "" {
+					violation.Rule = models.RuleCustom
+				}
+				return &ValidationError{*violation}
+			}
+		}
+	}
+	return nil
+}
+
+// CheckRating reports the *ValidationError writing rating for username would hit, or
+// nil. previous is the rating the write replaces, or nil for a user joining.
+func (lb *Leaderboard) CheckRating(username string, previous *int64, rating int64) error {
+	return lb.validate(lb.Metadata().Validation, username, previous, rating)
+}
+
+// UpdateRatingChecked is UpdateRating for ratings written by clients: it refuses with a
+// *ValidationError a rating that breaks one of the board's rules
+func (lb *Leaderboard) UpdateRatingChecked(username string, newRating int64) (bool, error) {
+	// Rules are read under the board lock, which is taken before the shard's
+	rules := lb.Metadata().Validation
+
+	shard := lb.shardFor(username)
+	unlock := lb.lockShard(shard)
+	defer unlock()
+
+	user, exists := shard.users[username]
+	if !exists {
+		return false, nil
+	}
+	previous := user.Rating
+	if err := lb.validate(rules, username, &previous, newRating); err != nil {
+		return true, err
+	}
+
+	lb.setRatingLocked(shard, user, newRating, time.Now())
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// versionStamps is how many recent versions remember when they were reached; enough
+// to cover several seconds of writes at full tilt
+const versionStamps = 4096
+
+// versionClock is the store version, stamped with when each recent version was
+// reached so readers can tell how long a write took to reach them
+type versionClock struct {
+	n atomic.Uint64
+
+	mu     sync.Mutex
+	stamps [versionStamps]versionStamp
+}
+
+type versionStamp struct {
+	version uint64
+	at      time.Time
+}
+
+// Add bumps the version by delta, stamping the new version with the time
+func (c *versionClock) Add(delta uint64) uint64 {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v := c.n.Add(delta)
+	c.stamps[v%versionStamps] = versionStamp{version: v, at: now}
+	return v
+}
+
+// Load returns the current version
+func (c *versionClock) Load() uint64 {
+	return c.n.Load()
+}
+
+// at reports when version was reached, or false if it is too old to remember or
+// never existed, such as one skipped by a bump of several
+func (c *versionClock) at(version uint64) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stamp := c.stamps[version%versionStamps]
+	if stamp.version != version {
+		return time.Time{}, false
+	}
+	return stamp.at, true
+}
+
+// VersionTime reports when the board reached version, for measuring how long writes
+// take to reach readers
+func (lb *Leaderboard) VersionTime(version uint64) (time.Time, bool) {
+	return lb.version.at(version)
+}
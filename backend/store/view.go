@@ -0,0 +1,372 @@
+package store
+
+import (
+	"leaderboard-api/models"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// view is an immutable, fully indexed copy of a board. Readers share the latest
+// published view without taking the store lock; a new one is built once writes have
+// moved the store past it, on demand or by a background Publisher.
+//
+// A rebuild never touches the records it copies: it holds only the read lock, and
+// shard read locks one at a time, while copying, and sorts and indexes the private
+// copy with no store lock held. A view is published only once fully built, so
+// readers never see one half-built, and at most one rebuild runs per version.
+type view struct {
+	// Store version, membership version and display name version the view was built from
+	version      uint64
+	members      uint64
+	displayNames uint64
+
+	meta models.BoardMetadata
+
+	// Copies of every user, in ranking order
+	users []models.User
+
+	// Position of each username in users
+	index map[string]int
+
+	// Per position: dense rank, number of users strictly ahead, and number of users
+	// ahead or tied (including the user). Together they give every ranking mode.
+	ranks   []int
+	above   []int
+	through []int
+
+	// Usernames and ratings as parallel slices, on columnar boards only
+	cols *viewColumns
+
+	// Secondary indexes of each country's and each tier's users, by country code
+	// and tier name
+	countries map[string]*segmentIndex
+	tiers     map[string]*segmentIndex
+
+	// Lowercase prefix -> usernames in alphabetical order. Only depends on
+	// membership, so it is carried over between views until users join or leave.
+	prefixIndex map[string][]string
+
+	// Lowercase username -> usernames that fold to it, alphabetically; usually one.
+	// Built and carried over along with prefixIndex.
+	folded map[string][]string
+
+	// Lowercase display name prefix -> usernames in alphabetical order, carried over
+	// until users join, leave or rename
+	displayIndex map[string][]string
+
+	// Active series by username
+	series map[string]models.SeriesState
+
+	minRating int64
+	maxRating int64
+}
+
+// current returns the view readers are served: the frozen standings while the board
+// is frozen, the latest view the background publisher built while one runs (see
+// publisher.go), and otherwise one that includes every write completed before the
+// call. Live handles always get the latest.
+func (lb *Leaderboard) current() *view {
+	if v := lb.served(); v != nil {
+		return v
+	}
+	return lb.latest()
+}
+
+// served returns the view readers get without a rebuild, or nil if they need the latest
+func (lb *Leaderboard) served() *view {
+	if lb.live {
+		return nil
+	}
+	if state := lb.frozen.Load(); state != nil {
+		return state.view
+	}
+	if lb.background.Load() {
+		return lb.published.Load()
+	}
+	return nil
+}
+
+// latest returns a view that includes every write completed before the call.
+// Concurrent readers that find the view stale wait for a single rebuild rather than
+// each building their own.
+func (lb *Leaderboard) latest() *view {
+	want := lb.version.Load()
+	if v := lb.published.Load(); v != nil && v.version >= want {
+		return v
+	}
+
+	unlockPublish := lb.lockPublish()
+	defer unlockPublish()
+
+	// Another reader may have published a fresh enough view while we waited
+	if v := lb.published.Load(); v != nil && v.version >= want {
+		return v
+	}
+
+	start := time.Now()
+	unlock := lb.lockRead()
+	v := lb.copyLocked()
+	unlock()
+
+	v.build(lb.published.Load())
+	lb.published.Store(v)
+	lb.notifyRebuilt(v, time.Since(start))
+	return v
+}
+
+// WarmUp builds and publishes a view, with its ranks and prefix index, and the
+// baseline deltas are measured against, so the first reads don't pay for them
+func (lb *Leaderboard) WarmUp() {
+	lb.baselineFor(lb.latest())
+}
+
+// copyLocked copies the state a view needs; caller must hold mu (read or write).
+// Shards are locked one at a time, so writers to other shards keep going meanwhile.
+func (lb *Leaderboard) copyLocked() *view {
+	v := lb.newView()
+	for _, shard := range lb.shards {
+		unlock := lb.rlockShard(shard)
+		v.copyShard(shard)
+		unlock()
+	}
+	return v
+}
+
+// copyAllLocked is copyLocked for callers already holding every shard lock
+func (lb *Leaderboard) copyAllLocked() *view {
+	v := lb.newView()
+	for _, shard := range lb.shards {
+		v.copyShard(shard)
+	}
+	return v
+}
+
+// newView starts an empty view of the current version. The version is read before
+// any user is copied, so the view never claims writes it might have missed.
+func (lb *Leaderboard) newView() *view {
+	return &view{
+		version:      lb.version.Load(),
+		members:      lb.members,
+		displayNames: lb.displayNamesGen.Load(),
+		meta:         lb.meta,
+		users:        make([]models.User, 0, len(lb.users)),
+		series:       make(map[string]models.SeriesState),
+	}
+}
+
+// copyShard appends copies of a shard's users and series; caller must hold its lock
+func (v *view) copyShard(shard *userShard) {
+	for _, user := range shard.users {
+		copied := *user
+		copied.Scores = copyScores(user.Scores)
+		copied.Metrics = copyMetrics(user.Metrics)
+		v.users = append(v.users, copied)
+	}
+	for username, state := range shard.series {
+		v.series[username] = *state
+	}
+}
+
+// build sorts the copied users and derives ranks and indexes. The prefix index is
+// reused from prev when membership hasn't changed since it was built.
+func (v *view) build(prev *view) {
+	v.order()
+
+	v.countries = v.buildSegments(func(user *models.User) string { return user.Country })
+	v.tiers = v.buildSegments(func(user *models.User) string { return TierForRating(v.meta.ScoreFormat, user.Rating) })
+	if v.meta.Layout == models.LayoutColumnar {
+		v.buildColumns()
+	}
+
+	if prev != nil && prev.members == v.members {
+		v.prefixIndex, v.folded = prev.prefixIndex, prev.folded
+	} else {
+		v.buildPrefixIndex()
+	}
+	if prev != nil && prev.members == v.members && prev.displayNames == v.displayNames {
+		v.displayIndex = prev.displayIndex
+	} else {
+		v.buildDisplayIndex()
+	}
+}
+
+// order sorts the view's users by the board's sort keys or rating, then its tie
+// break, and derives the position of each user and the ranks under every mode
+func (v *view) order() {
+	keys := v.meta.SortKeys
+	sort.Slice(v.users, func(i, j int) bool {
+		a, b := &v.users[i], &v.users[j]
+		if len(keys) > 0 {
+			if c := compareComposite(keys, a, b); c != 0 {
+				return c < 0
+			}
+		} else if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		if v.meta.TieBreak == models.TieBreakAchieved && !a.RatingUpdatedAt.Equal(b.RatingUpdatedAt) {
+			return a.RatingUpdatedAt.Before(b.RatingUpdatedAt)
+		}
+		return a.Username < b.Username
+	})
+
+	n := len(v.users)
+	v.index = make(map[string]int, n)
+	v.ranks = make([]int, n)
+	v.above = make([]int, n)
+	v.through = make([]int, n)
+
+	rank, above := 0, 0
+	for i := range v.users {
+		user := &v.users[i]
+		if i == 0 || !v.tied(&v.users[i-1], user) {
+			rank++
+			above = i
+		}
+		v.index[user.Username] = i
+		v.ranks[i] = rank
+		v.above[i] = above
+
+		if i == 0 || user.Rating < v.minRating {
+			v.minRating = user.Rating
+		}
+		if i == 0 || user.Rating > v.maxRating {
+			v.maxRating = user.Rating
+		}
+	}
+
+	// Walk backwards so each tie group learns where it ends
+	for i := n - 1; i >= 0; i-- {
+		if i == n-1 || v.ranks[i+1] != v.ranks[i] {
+			v.through[i] = i + 1
+		} else {
+			v.through[i] = v.through[i+1]
+		}
+	}
+}
+
+// tied reports whether two users share a rank
+func (v *view) tied(a, b *models.User) bool {
+	if len(v.meta.SortKeys) > 0 {
+		return compareComposite(v.meta.SortKeys, a, b) == 0
+	}
+	return a.Rating == b.Rating
+}
+
+// buildPrefixIndex indexes every prefix of every lowercase username, and every
+// username by its lowercase form
+func (v *view) buildPrefixIndex() {
+	v.prefixIndex = make(map[string][]string)
+	v.folded = make(map[string][]string, len(v.users))
+	for _, username := range v.sortedUsernames() {
+		usernameL := strings.ToLower(username)
+		v.folded[usernameL] = append(v.folded[usernameL], username)
+		for i := 1; i <= len(usernameL); i++ {
+			prefix := usernameL[:i]
+			v.prefixIndex[prefix] = append(v.prefixIndex[prefix], username)
+		}
+	}
+}
+
+// sortedUsernames lists the view's usernames alphabetically, so indexes built in this
+// order keep every list alphabetical
+func (v *view) sortedUsernames() []string {
+	usernames := make([]string, 0, len(v.users))
+	for _, user := range v.users {
+		usernames = append(usernames, user.Username)
+	}
+	sort.Strings(usernames)
+	return usernames
+}
+
+// rank returns the rank of the user at position i under mode
+func (v *view) rank(i int, mode string) int {
+	switch mode {
+	case models.RankingStandard:
+		return v.above[i] + 1
+	case models.RankingModified:
+		return v.through[i]
+	case models.RankingOrdinal:
+		return i + 1
+	default:
+		return v.ranks[i]
+	}
+}
+
+// entry builds the leaderboard entry for the user at position i
+func (v *view) entry(i int, mode string) models.LeaderboardEntry {
+	user := &v.users[i]
+	return models.LeaderboardEntry{
+		Rank:     v.rank(i, mode),
+		Username: user.Username,
+		Profile:  user.Profile,
+		Rating:   user.Rating,
+		Display:  FormatScore(v.meta.ScoreFormat, user.Rating),
+		Scores:   copyScores(user.Scores),
+		Metrics:  copyMetrics(user.Metrics),
+
+		GamesPlayed: user.GamesPlayed,
+		Wins:        user.Wins,
+	}
+}
+
+// result builds the search result for the user at position i
+func (v *view) result(i int, mode string) models.SearchResult {
+	user := &v.users[i]
+	peak, peakAt, lowest := user.PeakRating, user.PeakRatingAt, user.LowestRating
+	var goal *models.GoalProgress
+	if user.Goal != nil {
+		progress := goalProgress(user.Goal, user.Rating, v.above[i]+1)
+		goal = &progress
+	}
+	return models.SearchResult{
+		ID:              user.ID,
+		ExternalID:      user.ExternalID,
+		GlobalRank:      v.rank(i, mode),
+		CompetitionRank: v.above[i] + 1,
+		Username:        user.Username,
+		Profile:         user.Profile,
+		Rating:          user.Rating,
+		Percentile:      v.percentile(i),
+		Display:         FormatScore(v.meta.ScoreFormat, user.Rating),
+		Source:          user.Source,
+		Metrics:         copyMetrics(user.Metrics),
+		GamesPlayed:     user.GamesPlayed,
+		Wins:            user.Wins,
+		PeakRating:      &peak,
+		PeakRatingAt:    &peakAt,
+		LowestRating:    &lowest,
+		Goal:            goal,
+	}
+}
+
+// describe fills in the rating distribution of stats from the view's users, sorting a
+// copy of their ratings; composite boards aren't in rating order
+func (v *view) describe(stats *models.StatsResponse) {
+	n := len(v.users)
+	if n == 0 {
+		return
+	}
+	ratings := make([]int64, n)
+	var sum, sumSquares float64
+	for i := range v.users {
+		ratings[i] = v.users[i].Rating
+		sum += float64(ratings[i])
+		sumSquares += float64(ratings[i]) * float64(ratings[i])
+	}
+	sort.Slice(ratings, func(i, j int) bool { return ratings[i] < ratings[j] })
+	describeRatings(stats, n, sum, sumSquares, func(k int) int64 { return ratings[k-1] })
+}
+
+// percentile returns the "top X%" figure for the user at position i, rounded to two
+// decimals. Tied users count as one position, so everyone sharing a rank gets the
+// same percentile.
+func (v *view) percentile(i int) float64 {
+	total := len(v.users)
+	if total == 0 {
+		return 0
+	}
+	top := float64(v.above[i]+1) / float64(total) * 100
+	return math.Round(top*100) / 100
+}
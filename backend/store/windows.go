@@ -0,0 +1,185 @@
+package store
+
+import (
+	"fmt"
+	"leaderboard-api/models"
+	"sort"
+	"time"
+)
+
+const (
+	// Recent changes are kept in hourly buckets, older ones are folded into daily buckets
+	hourlyRetention = 48 * time.Hour
+	deltaRetention  = 31 * 24 * time.Hour
+)
+
+// Windows maps the supported window names to their duration
+var Windows = map[string]time.Duration{
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+}
+
+// deltaBucket aggregates rating gains per user over [start, start+span)
+type deltaBucket struct {
+	start time.Time
+	span  time.Duration
+	gains map[string]int64
+}
+
+// recordDelta adds a rating change made at the given time to the time-indexed delta
+// log. Changes dated in the past (late submissions) land in the bucket covering
+// their time, so the windows they fall in are recomputed on the next read; changes
+// older than deltaRetention are dropped.
+func (lb *Leaderboard) recordDelta(username string, delta int64, at time.Time) {
+	if delta == 0 {
+		return
+	}
+
+	lb.deltaMu.Lock()
+	defer lb.deltaMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(at) > deltaRetention {
+		return
+	}
+	lb.bucketFor(at, now).gains[username] += delta
+	if now.Sub(at) <= moverRetention {
+		lb.moverBucketFor(at, now).gains[username] += delta
+	}
+}
+
+// bucketFor returns the bucket covering at, creating it in order if needed: hourly
+// within hourlyRetention and daily beyond. Caller must hold deltaMu.
+func (lb *Leaderboard) bucketFor(at, now time.Time) *deltaBucket {
+	span := time.Hour
+	if now.Sub(at.Truncate(time.Hour)) > hourlyRetention {
+		span = 24 * time.Hour
+	}
+	start := at.Truncate(span)
+
+	// Buckets are ordered by start and nearly every change belongs to the newest
+	find := func() (int, *deltaBucket) {
+		i := len(lb.deltaBuckets)
+		for i > 0 && lb.deltaBuckets[i-1].start.After(start) {
+			i--
+		}
+		if i > 0 {
+			if prev := lb.deltaBuckets[i-1]; !at.Before(prev.start) && at.Before(prev.start.Add(prev.span)) {
+				return i, prev
+			}
+		}
+		return i, nil
+	}
+	if _, bucket := find(); bucket != nil {
+		return bucket
+	}
+
+	// Fold aged hourly buckets before adding, so the new bucket can't be folded away
+	lb.compactDeltas(now)
+	i, bucket := find()
+	if bucket != nil {
+		return bucket
+	}
+	bucket = &deltaBucket{start: start, span: span, gains: make(map[string]int64)}
+	lb.deltaBuckets = append(lb.deltaBuckets, nil)
+	copy(lb.deltaBuckets[i+1:], lb.deltaBuckets[i:])
+	lb.deltaBuckets[i] = bucket
+	return bucket
+}
+
+// compactDeltas folds hourly buckets older than hourlyRetention into daily buckets and
+// drops anything past deltaRetention. Buckets stay ordered by start time.
+// Caller must hold deltaMu.
+func (lb *Leaderboard) compactDeltas(now time.Time) {
+	compacted := make([]*deltaBucket, 0, len(lb.deltaBuckets))
+	for _, bucket := range lb.deltaBuckets {
+		if now.Sub(bucket.start) > deltaRetention {
+			continue
+		}
+		if bucket.span == time.Hour && now.Sub(bucket.start) > hourlyRetention {
+			day := bucket.start.Truncate(24 * time.Hour)
+			last := len(compacted) - 1
+			if last < 0 || !compacted[last].start.Equal(day) || compacted[last].span != 24*time.Hour {
+				compacted = append(compacted, &deltaBucket{
+					start: day,
+					span:  24 * time.Hour,
+					gains: make(map[string]int64),
+				})
+				last++
+			}
+			for username, gain := range bucket.gains {
+				compacted[last].gains[username] += gain
+			}
+			continue
+		}
+		compacted = append(compacted, bucket)
+	}
+	lb.deltaBuckets = compacted
+}
+
+// gainsSince totals each user's rating gains in the buckets reaching past cutoff, so
+// the far edge has the granularity of the bucket it falls in
+func (lb *Leaderboard) gainsSince(cutoff time.Time) map[string]int64 {
+	gains := make(map[string]int64)
+	lb.deltaMu.Lock()
+	defer lb.deltaMu.Unlock()
+	for _, bucket := range lb.deltaBuckets {
+		if bucket.start.Add(bucket.span).Before(cutoff) {
+			continue
+		}
+		for username, gain := range bucket.gains {
+			gains[username] += gain
+		}
+	}
+	return gains
+}
+
+// GetWindowLeaderboard ranks users by rating gained within the trailing window.
+// Only users with activity in the window are ranked; ties share a dense rank.
+// Windows longer than hourlyRetention have day granularity at the far edge.
+func (lb *Leaderboard) GetWindowLeaderboard(window string, limit, offset int) ([]models.WindowEntry, int, error) {
+	span, ok := Windows[window]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown window %q", window)
+	}
+
+	gains := lb.gainsSince(time.Now().Add(-span))
+	ranked := make([]models.WindowEntry, 0, len(gains))
+	for username, gain := range gains {
+		rating, exists := lb.ratingOf(username)
+		if !exists {
+			continue
+		}
+		ranked = append(ranked, models.WindowEntry{
+			Username: username,
+			Rating:   rating,
+			Gain:     gain,
+		})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Gain != ranked[j].Gain {
+			return ranked[i].Gain > ranked[j].Gain
+		}
+		return ranked[i].Username < ranked[j].Username
+	})
+
+	rank := 0
+	for i := range ranked {
+		if i == 0 || ranked[i].Gain != ranked[i-1].Gain {
+			rank++
+		}
+		ranked[i].Rank = rank
+	}
+
+	total := len(ranked)
+	if offset >= total {
+		return []models.WindowEntry{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return ranked[offset:end], total, nil
+}
@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body, prefixed with
+// "sha256=", when a signing key is set
+const SignatureHeader = "X-Leaderboard-Signature"
+
+// Feed returns the events recorded after since and the sequence number of the latest
+// one. Events are posted in the order returned.
+type Feed func(since uint64) (events []interface{}, latest uint64)
+
+// Stats summarizes a dispatcher's deliveries
+type Stats struct {
+	URL       string    `json:"url"`
+	Delivered uint64    `json:"delivered"` // events
+	Failed    uint64    `json:"failed"`    // posts
+	LastSent  time.Time `json:"lastSent,omitempty"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Dispatcher posts new events from a feed to a URL as JSON batches of the form
+// {"kind": ..., "events": [...]}. A failed post is retried with the same events on
+// the next poll; events that age out of the feed meanwhile are lost.
+type Dispatcher struct {
+	url    string
+	kind   string
+	feed   Feed
+	client *http.Client
+
+	// Key returns the signing key, or nil to send unsigned; it is called per post so
+	// rotated keys take effect immediately
+	Key func() []byte
+
+	// Sequence number of the last event delivered
+	since uint64
+
+	statsMu sync.Mutex
+	stats   Stats
+
+	stopChan chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewDispatcher creates a dispatcher posting kind events from feed to url. Only events
+// recorded after it is created are delivered.
+func NewDispatcher(url, kind string, feed Feed) *Dispatcher {
+	_, since := feed(0)
+	return &Dispatcher{
+		url:      url,
+		kind:     kind,
+		feed:     feed,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		since:    since,
+		stats:    Stats{URL: url},
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins polling the feed every interval
+func (d *Dispatcher) Start(interval time.Duration) {
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				d.dispatch()
+			case <-d.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts polling and makes one last delivery attempt
+func (d *Dispatcher) Stop() {
+	d.once.Do(func() {
+		close(d.stopChan)
+		<-d.done
+		d.dispatch()
+	})
+}
+
+// Stats returns a snapshot of the delivery counters
+func (d *Dispatcher) Stats() Stats {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+	return d.stats
+}
+
+// dispatch posts every event since the last delivery, logging rather than failing
+func (d *Dispatcher) dispatch() {
+	events, latest := d.feed(d.since)
+	if len(events) == 0 {
+		d.since = latest
+		return
+	}
+
+	err := d.post(events)
+
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+	if err != nil {
+		d.stats.Failed++
+		d.stats.LastError = err.Error()
+		log.Printf("Webhook %s to %s failed: %v", d.kind, d.url, err)
+		return
+	}
+	d.since = latest
+	d.stats.Delivered += uint64(len(events))
+	d.stats.LastSent = time.Now()
+	d.stats.LastError = ""
+}
+
+// post sends one batch
+func (d *Dispatcher) post(events []interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"kind": d.kind, "events": events})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.Key != nil {
+		if key := d.Key(); len(key) > 0 {
+			mac := hmac.New(sha256.New, key)
+			mac.Write(body)
+			req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return nil
+}
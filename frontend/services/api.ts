@@ -5,12 +5,15 @@ export interface LeaderboardEntry {
   rank: number;
   username: string;
   rating: number;
+  display: string;
 }
 
 export interface SearchResult {
   globalRank: number;
   username: string;
   rating: number;
+  percentile: number;
+  display: string;
 }
 
 export interface LeaderboardResponse {